@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+type createAPIKeyRequest struct {
+	Name           string   `json:"name" binding:"required,max=100"`
+	Scopes         []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInHours int64    `json:"expires_in_hours" binding:"omitempty,min=1,max=87600"`
+}
+
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	CompanyID  int64      `json:"company_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Key        string     `json:"key,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	rsp := apiKeyResponse{
+		ID:        apiKey.ID,
+		CompanyID: apiKey.CompanyID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt.Valid {
+		rsp.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	if apiKey.LastUsedAt.Valid {
+		rsp.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	if apiKey.RevokedAt.Valid {
+		rsp.RevokedAt = &apiKey.RevokedAt.Time
+	}
+	return rsp
+}
+
+// createAPIKey lets company owners and admins create a key for an integration.
+// The key is only ever returned in this response.
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !token.IsKnownScope(scope) {
+			respondError(ctx, http.StatusBadRequest, fmt.Errorf("unknown scope %q", scope))
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can create api keys")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !authPayload.HasScope(scope) {
+			respondError(ctx, http.StatusForbidden, fmt.Errorf("cannot grant scope %q you do not hold", scope))
+			return
+		}
+	}
+
+	key, prefix, secret, err := service.NewAPIKey()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	arg := db.CreateApiKeyParams{
+		CompanyID:  authPayload.CompanyID,
+		CreatedBy:  sql.NullInt64{Int64: authPayload.UserID, Valid: true},
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: util.HashSecret(secret),
+		Scopes:     req.Scopes,
+	}
+	if req.ExpiresInHours > 0 {
+		arg.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour), Valid: true}
+	}
+
+	apiKey, err := server.dbStore.CreateApiKey(ctx, arg)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	// the key itself stays out of the audit log
+	setAuditEntity(ctx, apiKey.ID, nil, newAPIKeyResponse(apiKey))
+
+	rsp := newAPIKeyResponse(apiKey)
+	rsp.Key = key
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	var req listRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can view api keys")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := server.dbStore.ListCompanyApiKeys(ctx, db.ListCompanyApiKeysParams{
+		CompanyID: authPayload.CompanyID,
+		AfterID:   p.afterID,
+		Limit:     p.limit(),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]apiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		items[i] = newAPIKeyResponse(apiKey)
+	}
+
+	rsp := newListResponse(p, items, func(item apiKeyResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.dbStore.CountCompanyApiKeys(ctx, authPayload.CompanyID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeAPIKeyURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	var uri revokeAPIKeyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can revoke api keys")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	apiKey, err := server.dbStore.RevokeApiKey(ctx, db.RevokeApiKeyParams{
+		ID:        uri.ID,
+		CompanyID: authPayload.CompanyID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, errors.New("No active api key found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, apiKey.ID, nil, newAPIKeyResponse(apiKey))
+	ctx.JSON(http.StatusOK, newAPIKeyResponse(apiKey))
+}
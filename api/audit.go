@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+)
+
+const auditEntityKey = "audit_entity"
+
+// setAuditEntity records the entity changed by the request for the audit middleware
+func setAuditEntity(ctx *gin.Context, id int64, before, after any) {
+	ctx.Set(auditEntityKey, &service.AuditEntity{ID: id, Before: before, After: after})
+}
+
+// errChangeFailed rolls back the changes of a request its handler did not answer with success
+var errChangeFailed = errors.New("the handler failed")
+
+// audit creates a gin middleware that appends the action to the audit log of the
+// company of the principal, in the transaction of the change the handler makes. The
+// entity type is the part of the action before the dot, as in bot.update. The response
+// is held back until the transaction commits: a handler failing rolls its changes back,
+// and a change that cannot be logged is rolled back and answered with a server error.
+// It must run after authMiddleware.
+func (server *Server) audit(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		request := ctx.Request
+		writer := newHeldResponseWriter(ctx.Writer)
+		ctx.Writer = writer
+		defer func() {
+			ctx.Request = request
+			ctx.Writer = writer.ResponseWriter
+		}()
+
+		err := server.service.RecordChange(request.Context(), authPayload, func(txCtx context.Context) (service.AuditEntry, error) {
+			// the store calls of the handler made with ctx join the transaction
+			ctx.Request = request.WithContext(txCtx)
+			ctx.Next()
+
+			if status := writer.Status(); status < 200 || status >= 300 {
+				return service.AuditEntry{}, errChangeFailed
+			}
+
+			// the client IP is the peer address unless the peer is one of the trusted proxies
+			entry := service.AuditEntry{
+				Action:    action,
+				IP:        ctx.ClientIP(),
+				UserAgent: request.UserAgent(),
+				RequestID: ctx.GetString(requestIDKey),
+			}
+			if value, ok := ctx.Get(auditEntityKey); ok {
+				entry.Entity = value.(*service.AuditEntity)
+			}
+			return entry, nil
+		})
+		if err != nil && !errors.Is(err, errChangeFailed) {
+			ctx.Writer = writer.ResponseWriter
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		writer.release()
+	}
+}
+
+// heldResponseWriter holds a response back until it is released, so that it can be
+// replaced by another one
+type heldResponseWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func newHeldResponseWriter(w gin.ResponseWriter) *heldResponseWriter {
+	return &heldResponseWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+func (w *heldResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *heldResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *heldResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *heldResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *heldResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *heldResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *heldResponseWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *heldResponseWriter) Written() bool {
+	return w.written
+}
+
+// release writes the response held back
+func (w *heldResponseWriter) release() {
+	header := w.ResponseWriter.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+type listAuditLogsRequest struct {
+	listRequest
+	ActorUserID   int64     `form:"actor_user_id" binding:"omitempty,min=1"`
+	Action        string    `form:"action" binding:"omitempty,max=100"`
+	EntityType    string    `form:"entity_type" binding:"omitempty,max=100"`
+	EntityID      int64     `form:"entity_id" binding:"omitempty,min=1"`
+	CreatedAfter  time.Time `form:"created_after"`
+	CreatedBefore time.Time `form:"created_before"`
+}
+
+type auditLogResponse struct {
+	ID            int64           `json:"id"`
+	ActorUserID   *int64          `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID *int64          `json:"actor_api_key_id,omitempty"`
+	ActorName     string          `json:"actor_name"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      *int64          `json:"entity_id,omitempty"`
+	Changes       json.RawMessage `json:"changes"`
+	IP            string          `json:"ip"`
+	UserAgent     string          `json:"user_agent"`
+	RequestID     string          `json:"request_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func newAuditLogResponse(log db.AuditLog) auditLogResponse {
+	rsp := auditLogResponse{
+		ID:         log.ID,
+		ActorName:  log.ActorName,
+		Action:     log.Action,
+		EntityType: log.EntityType,
+		Changes:    log.Changes,
+		IP:         log.Ip,
+		UserAgent:  log.UserAgent,
+		RequestID:  log.RequestID,
+		CreatedAt:  log.CreatedAt,
+	}
+	if log.ActorUserID.Valid {
+		rsp.ActorUserID = &log.ActorUserID.Int64
+	}
+	if log.ActorApiKeyID.Valid {
+		rsp.ActorAPIKeyID = &log.ActorApiKeyID.Int64
+	}
+	if log.EntityID.Valid {
+		rsp.EntityID = &log.EntityID.Int64
+	}
+	return rsp
+}
+
+// listAuditLogs lets company owners and admins read the audit log of their company, newest first
+func (server *Server) listAuditLogs(ctx *gin.Context) {
+	var req listAuditLogsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can view the audit log")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req.listRequest)
+	if !ok {
+		return
+	}
+
+	actorUserID := sql.NullInt64{Int64: req.ActorUserID, Valid: req.ActorUserID != 0}
+	action := sql.NullString{String: req.Action, Valid: req.Action != ""}
+	entityType := sql.NullString{String: req.EntityType, Valid: req.EntityType != ""}
+	entityID := sql.NullInt64{Int64: req.EntityID, Valid: req.EntityID != 0}
+
+	logs, err := server.dbStore.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		CompanyID:     authPayload.CompanyID,
+		ActorUserID:   actorUserID,
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityID,
+		CreatedAfter:  nullTime(req.CreatedAfter),
+		CreatedBefore: nullTime(req.CreatedBefore),
+		AfterID:       p.after(),
+		Limit:         p.limit(),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]auditLogResponse, len(logs))
+	for i, log := range logs {
+		items[i] = newAuditLogResponse(log)
+	}
+
+	rsp := newListResponse(p, items, func(item auditLogResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.dbStore.CountAuditLogs(ctx, db.CountAuditLogsParams{
+			CompanyID:     authPayload.CompanyID,
+			ActorUserID:   actorUserID,
+			Action:        action,
+			EntityType:    entityType,
+			EntityID:      entityID,
+			CreatedAfter:  nullTime(req.CreatedAfter),
+			CreatedBefore: nullTime(req.CreatedBefore),
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+// allowAuditLog lets the handlers of a test append to the audit log
+func allowAuditLog(store *mockdb.MockStore) {
+	allowTx(store)
+	store.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.AuditLog{}, nil)
+}
+
+// allowTx runs the transactions of a test, which the mock store cannot roll back
+func allowTx(store *mockdb.MockStore) {
+	store.EXPECT().
+		ExecTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+}
+
+// testTxKey marks the context of the transactions of the mock store
+type testTxKey struct{}
+
+func TestAuditMiddleware(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	user.ID = util.RandInt(1, 100)
+	bot := randomBot(t, company.ID)
+	renamed := bot
+	renamed.Title = util.RandomString(6)
+
+	// the requests come from 192.0.2.1 and claim to be forwarded for 203.0.113.7
+	expectUpdate := func(store *mockdb.MockStore, ip string) {
+		store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+		store.EXPECT().
+			UpdateBot(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, arg db.UpdateBotParams) (db.Bot, error) {
+				// the change is made in the transaction of the audit log entry
+				require.Equal(t, true, ctx.Value(testTxKey{}))
+				return renamed, nil
+			})
+
+		changes, err := json.Marshal(map[string]service.FieldChange{"title": {From: bot.Title, To: renamed.Title}})
+		require.NoError(t, err)
+		arg := db.CreateAuditLogParams{
+			CompanyID:   company.ID,
+			ActorUserID: sql.NullInt64{Int64: user.ID, Valid: true},
+			ActorName:   user.Name,
+			Action:      "bot.update",
+			EntityType:  "bot",
+			EntityID:    sql.NullInt64{Int64: bot.ID, Valid: true},
+			Changes:     changes,
+			Ip:          ip,
+			UserAgent:   "audit-test",
+			RequestID:   "audit-request",
+		}
+		store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AuditLog{}, nil)
+	}
+
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		buildStubs     func(store *mockdb.MockStore)
+		status         int
+	}{
+		{
+			// the header is not believed from a peer that is not a trusted proxy
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUpdate(store, "192.0.2.1")
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: []string{"192.0.2.1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectUpdate(store, "203.0.113.7")
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "FailedChange",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(1).Return(db.Bot{}, sql.ErrConnDone)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "AuditLogError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(1).Return(renamed, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, sql.ErrConnDone)
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			store.EXPECT().
+				ExecTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(context.WithValue(ctx, testTxKey{}, true))
+				})
+
+			server := newTestServer(t, store)
+			require.NoError(t, server.router.SetTrustedProxies(tc.trustedProxies))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"title": renamed.Title, "company_id": company.ID})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/bots/%d", bot.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:54321"
+			request.Header.Set("X-Forwarded-For", "203.0.113.7")
+			request.Header.Set("User-Agent", "audit-test")
+			request.Header.Set(requestIDHeader, "audit-request")
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+			if tc.status == http.StatusOK {
+				require.NotEmpty(t, recorder.Header().Get("ETag"))
+				requireBodyMatchBot(t, recorder.Body, renamed)
+			} else {
+				// neither the bot nor its version are sent when the change is rolled back
+				require.Empty(t, recorder.Header().Get("ETag"))
+				require.NotContains(t, recorder.Body.String(), renamed.Title)
+			}
+		})
+	}
+}
+
+func TestListAuditLogsAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	user.Role = util.AdminRole
+
+	n := 2
+	logs := make([]db.AuditLog, n+1)
+	for i := range logs {
+		logs[i] = db.AuditLog{
+			ID:         int64(n + 1 - i),
+			CompanyID:  company.ID,
+			ActorName:  user.Name,
+			Action:     "bot.update",
+			EntityType: "bot",
+			EntityID:   sql.NullInt64{Int64: 7, Valid: true},
+			Changes:    json.RawMessage(`{}`),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Filters",
+			role: util.AdminRole,
+			query: url.Values{
+				"entity_type":   {"bot"},
+				"entity_id":     {"7"},
+				"page_size":     {fmt.Sprint(n)},
+				"include_total": {"true"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					CompanyID:  company.ID,
+					EntityType: sql.NullString{String: "bot", Valid: true},
+					EntityID:   sql.NullInt64{Int64: 7, Valid: true},
+					Limit:      int32(n) + 1,
+				}
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Eq(arg)).Times(1).Return(logs, nil)
+
+				countArg := db.CountAuditLogsParams{
+					CompanyID:  company.ID,
+					EntityType: sql.NullString{String: "bot", Valid: true},
+					EntityID:   sql.NullInt64{Int64: 7, Valid: true},
+				}
+				store.EXPECT().CountAuditLogs(gomock.Any(), gomock.Eq(countArg)).Times(1).Return(int64(len(logs)), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[auditLogResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Data, n)
+				require.Equal(t, "bot.update", rsp.Data[0].Action)
+				require.Equal(t, int64(7), *rsp.Data[0].EntityID)
+				require.Equal(t, int64(len(logs)), *rsp.Total)
+
+				cursor, err := decodeCursor(rsp.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, logs[n-1].ID, cursor.AfterID)
+			},
+		},
+		{
+			name:  "FromCursor",
+			role:  util.OwnerRole,
+			query: url.Values{"cursor": {encodeCursor(pageCursor{AfterID: logs[n-1].ID})}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					CompanyID: company.ID,
+					AfterID:   sql.NullInt64{Int64: logs[n-1].ID, Valid: true},
+					Limit:     defaultPageSize + 1,
+				}
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Eq(arg)).Times(1).Return(logs[n:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			role:  util.MemberRole,
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidEntityID",
+			role:  util.AdminRole,
+			query: url.Values{"entity_id": {"-1"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  util.AdminRole,
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit-logs?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
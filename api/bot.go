@@ -2,7 +2,6 @@ package api
 
 import (
 	"database/sql"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -18,31 +17,23 @@ type createBotRequest struct {
 func (server *Server) createBot(ctx *gin.Context) {
 	var req createBotRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	company, err := server.dbStore.GetCompanyByID(ctx, req.CompanyID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	arg := db.CreateBotParams{
 		Title:     req.Title,
-		CompanyID: company.ID,
+		CompanyID: req.CompanyID,
 	}
-	bot, err := server.dbStore.CreateBot(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	bot, err := server.service.CreateBot(ctx, authPayload, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
 
+	setAuditEntity(ctx, bot.ID, nil, bot)
+	setETag(ctx, bot.UpdatedAt)
 	ctx.JSON(http.StatusOK, bot)
 }
 
@@ -61,38 +52,61 @@ func (server *Server) updateBot(ctx *gin.Context) {
 	var req updateBotRequestParams
 
 	if err := ctx.ShouldBindUri(&uri); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if req.CompanyID != authPayload.CompanyID {
-		err := errors.New("bot doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
-	arg := db.UpdateBotParams{
+	server.saveBot(ctx, db.UpdateBotParams{
 		Title:     sql.NullString{String: req.Title, Valid: true},
 		CompanyID: sql.NullInt64{Int64: req.CompanyID, Valid: true},
 		ID:        uri.ID,
+	})
+}
+
+type patchBotRequest struct {
+	Title     *string `json:"title" binding:"omitempty,min=1"`
+	CompanyID *int64  `json:"company_id" binding:"omitempty,min=1"`
+}
+
+// patchBot applies a JSON Merge Patch to a bot, changing only the fields it contains
+func (server *Server) patchBot(ctx *gin.Context) {
+	var uri updateBotRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req patchBotRequest
+	if !bindMergePatch(ctx, &req) {
+		return
+	}
+
+	arg := db.UpdateBotParams{ID: uri.ID}
+	if req.Title != nil {
+		arg.Title = sql.NullString{String: *req.Title, Valid: true}
+	}
+	if req.CompanyID != nil {
+		arg.CompanyID = sql.NullInt64{Int64: *req.CompanyID, Valid: true}
 	}
 
-	bot, err := server.dbStore.UpdateBot(ctx, arg)
+	server.saveBot(ctx, arg)
+}
 
+// saveBot updates a bot of the authenticated company, honouring the If-Match header of the request
+func (server *Server) saveBot(ctx *gin.Context, arg db.UpdateBotParams) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	before, bot, err := server.service.UpdateBot(ctx, authPayload, arg, ifMatch(ctx))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, bot.ID, before, bot)
+	setETag(ctx, bot.UpdatedAt)
 	ctx.JSON(http.StatusOK, bot)
 }
 
@@ -100,90 +114,130 @@ type deleteBotRequestURI struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+// deleteBot soft deletes the bot and its questions, they can be restored until they are purged
 func (server *Server) deleteBot(ctx *gin.Context) {
 	var req deleteBotRequestURI
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	bot, err := server.dbStore.GetBot(ctx, req.ID)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	bot, err := server.service.DeleteBot(ctx, authPayload, req.ID, ifMatch(ctx))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, bot.ID, bot, nil)
+	ctx.JSON(http.StatusOK, nil)
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+}
+
+// restoreBot brings back a deleted bot of the authenticated company with its questions
+func (server *Server) restoreBot(ctx *gin.Context) {
+	var req deleteBotRequestURI
 
-	if bot.CompanyID != authPayload.CompanyID {
-		err := errors.New("You cannot delete a bot that does not belong to your company")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err = server.dbStore.DeleteBot(ctx, req.ID)
-
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	bot, err := server.service.RestoreBot(ctx, authPayload, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, nil)
-
+	setAuditEntity(ctx, bot.ID, nil, bot)
+	setETag(ctx, bot.UpdatedAt)
+	ctx.JSON(http.StatusOK, bot)
 }
 
 type listBotsRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	searchRequest
+	Sort string `form:"sort" binding:"omitempty,oneof=title -title updated_at -updated_at"`
 }
 
+// listBots lists the bots of the company, of every company for operators. q searches the titles, sort orders by title or
+// updated_at, descending with a - prefix, and by id by default.
 func (server *Server) listBots(ctx *gin.Context) {
 	var req listBotsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.ListAllBotsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-	}
-
-	bots, err := server.dbStore.ListAllBots(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-	}
-	ctx.JSON(http.StatusOK, bots)
+	server.searchBots(ctx, req, sql.NullInt64{})
 }
 
 type listCompanyBotsRequest struct {
 	CompanyID int64 `form:"company_id" binding:"required,min=1"`
-	PageID    int32 `form:"page_id" binding:"required,min=1"`
-	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+	listBotsRequest
 }
 
+// listCompanyBots is listBots for the bots of one company
 func (server *Server) listCompanyBots(ctx *gin.Context) {
 	var req listCompanyBotsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.ListCompanyBotsParams{
-		CompanyID: req.CompanyID,
-		Limit:     req.PageSize,
-		Offset:    (req.PageID - 1) * req.PageSize,
+	server.searchBots(ctx, req.listBotsRequest, sql.NullInt64{Int64: req.CompanyID, Valid: true})
+}
+
+func (server *Server) searchBots(ctx *gin.Context, req listBotsRequest, companyID sql.NullInt64) {
+	p, ok := server.bindSortedPage(ctx, req.listRequest, req.Sort)
+	if !ok {
+		return
 	}
 
-	bots, err := server.dbStore.ListCompanyBots(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	bots, err := server.service.SearchBots(ctx, authPayload, db.SearchBotsParams{
+		CompanyID:     companyID,
+		Search:        req.searchPattern(),
+		CreatedAfter:  nullTime(req.CreatedAfter),
+		CreatedBefore: nullTime(req.CreatedBefore),
+		UpdatedAfter:  nullTime(req.UpdatedAfter),
+		UpdatedBefore: nullTime(req.UpdatedBefore),
+		AfterID:       p.after(),
+		Sort:          p.sort,
+		AfterText:     p.afterText,
+		AfterTime:     p.afterTime,
+		Limit:         p.limit(),
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
+		return
+	}
+
+	rsp := newSortedListResponse(p, bots, func(bot db.Bot) pageCursor {
+		cursor := pageCursor{AfterID: bot.ID}
+		switch sortKey(p.sort) {
+		case "title":
+			cursor.AfterText = bot.Title
+		case "updated_at":
+			cursor.AfterTime = &bot.UpdatedAt
+		}
+		return cursor
+	})
+	if req.IncludeTotal {
+		total, err := server.service.CountBots(ctx, authPayload, db.CountBotsParams{
+			CompanyID:     companyID,
+			Search:        req.searchPattern(),
+			CreatedAfter:  nullTime(req.CreatedAfter),
+			CreatedBefore: nullTime(req.CreatedBefore),
+			UpdatedAfter:  nullTime(req.UpdatedAfter),
+			UpdatedBefore: nullTime(req.UpdatedBefore),
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
 	}
-	ctx.JSON(http.StatusOK, bots)
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type getBotRequest struct {
@@ -194,25 +248,16 @@ func (server *Server) getBot(ctx *gin.Context) {
 	var req getBotRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	bot, err := server.dbStore.GetBot(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	if bot.CompanyID != authPayload.CompanyID {
-		err := errors.New("No access rights for that bot")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	bot, err := server.service.GetBot(ctx, authPayload, req.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
 		return
 	}
+	setETag(ctx, bot.UpdatedAt)
 	ctx.JSON(http.StatusOK, bot)
 }
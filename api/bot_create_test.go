@@ -45,6 +45,7 @@ func TestCreateBotAPI(t *testing.T) {
 	bot := randomBot(t, company.ID)
 
 	user, _ := randomUser(t, company.ID)
+	other, _ := randomUser(t, company.ID+1)
 
 	testCases := []struct {
 		name          string
@@ -61,7 +62,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.CreateBotParams{
@@ -95,6 +96,26 @@ func TestCreateBotAPI(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		//Company of another principal
+		{
+			name: "OtherCompany",
+			body: gin.H{
+				"title":      bot.Title,
+				"company_id": bot.CompanyID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Phone, other.ID, other.Name, other.CompanyID, other.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().
+					CreateBot(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		//CompanyWithIDSpecifiedNotFound
 		{
 			name: "CompanyNotFound",
@@ -103,7 +124,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			}, buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrNoRows)
 				store.EXPECT().
@@ -123,7 +144,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
@@ -144,7 +165,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrConnDone)
@@ -166,7 +187,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": -1,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -185,7 +206,7 @@ func TestCreateBotAPI(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -207,6 +228,8 @@ func TestCreateBotAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
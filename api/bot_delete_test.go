@@ -31,12 +31,12 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "OK",
 			botID: bot.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Return(bot, nil)
 				store.EXPECT().
-					DeleteBot(gomock.Any(), gomock.Eq(bot.ID)).
+					DeleteBotTx(gomock.Any(), gomock.Eq(db.SoftDeleteBotParams{ID: bot.ID})).
 					Times(1)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -47,12 +47,12 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "Invalid ID",
 			botID: -1,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(0)
 				store.EXPECT().
-					DeleteBot(gomock.Any(), gomock.Eq(-1)).
+					DeleteBotTx(gomock.Any(), gomock.Eq(-1)).
 					Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -63,11 +63,11 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "Not found",
 			botID: bot.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrNoRows)
-				store.EXPECT().DeleteBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
 
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -78,11 +78,11 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "internal server error",
 			botID: bot.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
-				store.EXPECT().DeleteBot(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Bot{}, sql.ErrConnDone)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -92,11 +92,11 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "GetBotInternalServerError",
 			botID: bot.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrConnDone)
-				store.EXPECT().DeleteBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -106,11 +106,11 @@ func TestDeleteBotAPI(t *testing.T) {
 			name:  "NotAuthorizedUser",
 			botID: bot.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
-				store.EXPECT().DeleteBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -123,7 +123,7 @@ func TestDeleteBotAPI(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(0)
-				store.EXPECT().DeleteBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -139,6 +139,8 @@ func TestDeleteBotAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -154,3 +156,100 @@ func TestDeleteBotAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreBotAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	user, _ := randomUser(t, company.ID)
+
+	testcases := []struct {
+		name           string
+		botID          int64
+		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub      func(store *mockdb.MockStore)
+		checkResponses func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			botID: bot.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreBotTx(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchBot(t, recorder.Body, bot)
+			},
+		},
+		{
+			name:  "NotDeleted",
+			botID: bot.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrNoRows)
+				store.EXPECT().RestoreBotTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAuthorizedUser",
+			botID: bot.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreBotTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalServerError",
+			botID: bot.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreBotTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Bot{}, sql.ErrConnDone)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testcases {
+		testcase := testcases[i]
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/bots/%d/restore", testcase.botID)
+
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require := require.New(t)
+			require.NoError(err)
+			testcase.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			testcase.checkResponses(recorder)
+		})
+	}
+}
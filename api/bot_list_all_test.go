@@ -24,22 +24,24 @@ func requireBodyMatchListBots(t *testing.T, body *bytes.Buffer, bots []db.Bot) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(err)
 
-	var gotBots []db.Bot
+	var gotBots listResponse[db.Bot]
 	err = json.Unmarshal(data, &gotBots)
 	require.NoError(err)
 
-	require.Equal(bots, gotBots)
+	require.Equal(bots, gotBots.Data)
 }
 
 func TestListAllBotsAPI(t *testing.T) {
 	user, _ := randomUser(t, util.RandInt(1, 100))
+	operator, _ := randomUser(t, util.RandInt(1, 100))
+	operator.Role = util.OperatorRole
 	n := 5
 	bots := make([]db.Bot, n)
 	for i := 0; i < n; i++ {
-		bots[i] = randomBot(t, util.RandInt(1, 100))
+		bots[i] = randomBot(t, user.CompanyID)
 	}
 	type Query struct {
-		pageID   int
+		cursor   string
 		pageSize int
 	}
 
@@ -53,19 +55,40 @@ func TestListAllBotsAPI(t *testing.T) {
 		{
 			name: "OK",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.ListAllBotsParams{
-					Limit:  int32(n),
-					Offset: 0,
+				arg := db.SearchBotsParams{
+					CompanyID: sql.NullInt64{Int64: user.CompanyID, Valid: true},
+					Limit:     int32(n) + 1,
 				}
 				store.EXPECT().
-					ListAllBots(gomock.Any(), arg).
+					SearchBots(gomock.Any(), arg).
+					Times(1).
+					Return(bots, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchListBots(t, recorder.Body, bots)
+			},
+		},
+		{
+			name: "Operator",
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Phone, operator.ID, operator.Name, operator.CompanyID, operator.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{
+					Limit: int32(n) + 1,
+				}
+				store.EXPECT().
+					SearchBots(gomock.Any(), arg).
 					Times(1).
 					Return(bots, nil)
 			},
@@ -77,15 +100,14 @@ func TestListAllBotsAPI(t *testing.T) {
 		{
 			name: "Internal Server Error",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAllBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Bot{}, sql.ErrConnDone)
 			},
@@ -96,15 +118,14 @@ func TestListAllBotsAPI(t *testing.T) {
 		{
 			name: "Invalid Page Size",
 			query: Query{
-				pageID:   1,
 				pageSize: 1000,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAllBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -112,17 +133,17 @@ func TestListAllBotsAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Invalid Page ID",
+			name: "Invalid Cursor",
 			query: Query{
-				pageID:   -1,
+				cursor:   "not-a-cursor",
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAllBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -132,14 +153,13 @@ func TestListAllBotsAPI(t *testing.T) {
 		{
 			name: "NoAuthorizationHeader",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListAllBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -156,6 +176,7 @@ func TestListAllBotsAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -168,7 +189,9 @@ func TestListAllBotsAPI(t *testing.T) {
 
 			// Add query parameters to request URL
 			q := request.URL.Query()
-			q.Add("page_id", fmt.Sprintf("%d", testcase.query.pageID))
+			if testcase.query.cursor != "" {
+				q.Add("cursor", testcase.query.cursor)
+			}
 			q.Add("page_size", fmt.Sprintf("%d", testcase.query.pageSize))
 
 			request.URL.RawQuery = q.Encode()
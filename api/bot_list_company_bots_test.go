@@ -25,7 +25,7 @@ func TestListCompanyBotsAPI(t *testing.T) {
 	}
 	type Query struct {
 		companyID int64
-		pageID    int
+		cursor    string
 		pageSize  int
 	}
 
@@ -40,20 +40,18 @@ func TestListCompanyBotsAPI(t *testing.T) {
 			name: "OK",
 			query: Query{
 				companyID: company.ID,
-				pageID:    1,
 				pageSize:  n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.ListCompanyBotsParams{
-					CompanyID: company.ID,
-					Limit:     int32(n),
-					Offset:    0,
+				arg := db.SearchBotsParams{
+					CompanyID: sql.NullInt64{Int64: company.ID, Valid: true},
+					Limit:     int32(n) + 1,
 				}
 				store.EXPECT().
-					ListCompanyBots(gomock.Any(), arg).
+					SearchBots(gomock.Any(), arg).
 					Times(1).
 					Return(bots, nil)
 			},
@@ -62,19 +60,36 @@ func TestListCompanyBotsAPI(t *testing.T) {
 				requireBodyMatchListBots(t, recorder.Body, bots)
 			},
 		},
+		{
+			name: "OtherCompany",
+			query: Query{
+				companyID: company.ID + 1,
+				pageSize:  n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchBots(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "InternalServerError",
 			query: Query{
 				companyID: company.ID,
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanyBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Bot{}, sql.ErrConnDone)
 			},
@@ -86,15 +101,14 @@ func TestListCompanyBotsAPI(t *testing.T) {
 			name: "Invalid Page Size",
 			query: Query{
 				companyID: company.ID,
-				pageID:   1,
 				pageSize: 1000,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanyBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -102,18 +116,18 @@ func TestListCompanyBotsAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InvalidPageID",
+			name: "InvalidCursor",
 			query: Query{
 				companyID: company.ID,
-				pageID:   -1,
+				cursor:   "not-a-cursor",
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanyBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -124,14 +138,13 @@ func TestListCompanyBotsAPI(t *testing.T) {
 			name: "NoAuthorizationHeader",
 			query: Query{
 				companyID: company.ID,
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanyBots(gomock.Any(), gomock.Any()).
+					SearchBots(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -148,6 +161,7 @@ func TestListCompanyBotsAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -161,7 +175,9 @@ func TestListCompanyBotsAPI(t *testing.T) {
 			// Add query parameters to request URL
 			q := request.URL.Query()
 			q.Add("company_id", fmt.Sprintf("%d", testcase.query.companyID))
-			q.Add("page_id", fmt.Sprintf("%d", testcase.query.pageID))
+			if testcase.query.cursor != "" {
+				q.Add("cursor", testcase.query.cursor)
+			}
 			q.Add("page_size", fmt.Sprintf("%d", testcase.query.pageSize))
 
 			request.URL.RawQuery = q.Encode()
@@ -61,7 +61,7 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.UpdateBotParams{
@@ -70,6 +70,10 @@ func TestUpdateBot(t *testing.T) {
 					ID:        bot.ID,
 				}
 
+				store.EXPECT().
+					GetBot(gomock.Any(), gomock.Eq(bot.ID)).
+					Times(1).
+					Return(bot, nil)
 				store.EXPECT().
 					UpdateBot(gomock.Any(), EqUpdateBotParams(arg)).
 					Times(1).
@@ -90,7 +94,7 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -132,13 +136,17 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
+				store.EXPECT().
+					GetBot(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Bot{}, sql.ErrNoRows)
 				store.EXPECT().
 					UpdateBot(gomock.Any(), gomock.Any()).
-					Times(1).Return(db.Bot{}, sql.ErrNoRows)
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				//check response
@@ -154,7 +162,7 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
@@ -176,7 +184,7 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
@@ -198,7 +206,7 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": -1,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
@@ -220,10 +228,14 @@ func TestUpdateBot(t *testing.T) {
 				"company_id": bot.CompanyID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
+				store.EXPECT().
+					GetBot(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(bot, nil)
 				store.EXPECT().
 					UpdateBot(gomock.Any(), gomock.Any()).
 					Times(1).Return(db.Bot{}, sql.ErrConnDone)
@@ -244,6 +256,8 @@ func TestUpdateBot(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
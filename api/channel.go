@@ -6,7 +6,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/lenimbugua/bot/db/sqlc"
-	"github.com/lib/pq"
 )
 
 type createChannelRequest struct {
@@ -16,25 +15,19 @@ type createChannelRequest struct {
 func (server *Server) createChannel(ctx *gin.Context) {
 	var req createChannelRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	name := req.Name
 
-	channel, err := server.dbStore.CreateChannel(ctx, name)
+	channel, err := server.service.CreateChannel(ctx, name)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
 
+	setAuditEntity(ctx, int64(channel.ID), nil, channel)
 	ctx.JSON(http.StatusOK, channel)
 }
 
@@ -46,17 +39,13 @@ func (server *Server) getChannel(ctx *gin.Context) {
 	var req getChannelRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	channel, err := server.dbStore.GetChannel(ctx, req.Name)
+	channel, err := server.service.GetChannel(ctx, req.Name)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
 
@@ -64,28 +53,34 @@ func (server *Server) getChannel(ctx *gin.Context) {
 
 }
 
-type listChannelsRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-}
-
 func (server *Server) listChannels(ctx *gin.Context) {
-	var req listChannelsRequest
+	var req listRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.ListChannelsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
 	}
 
-	channels, err := server.dbStore.ListChannels(ctx, arg)
+	channels, err := server.service.ListChannels(ctx, int32(p.afterID), p.limit())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
 	}
-	ctx.JSON(http.StatusOK, channels)
+
+	rsp := newListResponse(p, channels, func(channel db.Channel) int64 { return int64(channel.ID) })
+	if req.IncludeTotal {
+		total, err := server.service.CountChannels(ctx)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type deleteChannelURI struct {
@@ -96,20 +91,16 @@ func (server *Server) deleteChannel(ctx *gin.Context) {
 	var req deleteChannelURI
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err := server.dbStore.DeleteChannel(ctx, req.ID)
-
+	channel, err := server.service.DeleteChannel(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, int64(channel.ID), channel, nil)
 	ctx.JSON(http.StatusOK, nil)
 
 }
@@ -127,29 +118,53 @@ func (server *Server) updateChannel(ctx *gin.Context) {
 	var req updateChannelRequestParams
 
 	if err := ctx.ShouldBindUri(&uri); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.UpdateChannelParams{
+	server.saveChannel(ctx, db.UpdateChannelParams{
 		Name: sql.NullString{String: req.Name, Valid: true},
 		ID:   uri.ID,
+	})
+}
+
+type patchChannelRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=1"`
+}
+
+// patchChannel applies a JSON Merge Patch to a channel, changing only the fields it contains
+func (server *Server) patchChannel(ctx *gin.Context) {
+	var uri updateChannelURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req patchChannelRequest
+	if !bindMergePatch(ctx, &req) {
+		return
 	}
 
-	channel, err := server.dbStore.UpdateChannel(ctx, arg)
+	arg := db.UpdateChannelParams{ID: uri.ID}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: *req.Name, Valid: true}
+	}
 
+	server.saveChannel(ctx, arg)
+}
+
+// saveChannel updates a channel and answers with the updated channel
+func (server *Server) saveChannel(ctx *gin.Context, arg db.UpdateChannelParams) {
+	before, channel, err := server.service.UpdateChannel(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, int64(channel.ID), before, channel)
 	ctx.JSON(http.StatusOK, channel)
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
 	"github.com/lenimbugua/bot/token"
 	"github.com/stretchr/testify/require"
 )
@@ -30,12 +31,13 @@ func TestDeleteChannelAPI(t *testing.T) {
 			name:      "OK",
 			channelID: channel.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					DeleteChannel(gomock.Any(), gomock.Eq(channel.ID)).
-					Times(1)
+					Times(1).
+					Return(channel, nil)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -45,7 +47,7 @@ func TestDeleteChannelAPI(t *testing.T) {
 			name:      "InvalidID",
 			channelID: -1,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -60,10 +62,10 @@ func TestDeleteChannelAPI(t *testing.T) {
 			name:      "Not found",
 			channelID: channel.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteChannel(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrNoRows)
+				store.EXPECT().DeleteChannel(gomock.Any(), gomock.Any()).Times(1).Return(db.Channel{}, sql.ErrNoRows)
 
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -74,10 +76,10 @@ func TestDeleteChannelAPI(t *testing.T) {
 			name:      "InternalServerError",
 			channelID: channel.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteChannel(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().DeleteChannel(gomock.Any(), gomock.Any()).Times(1).Return(db.Channel{}, sql.ErrConnDone)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -105,6 +107,8 @@ func TestDeleteChannelAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
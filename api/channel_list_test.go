@@ -24,11 +24,11 @@ func requireBodyMatchListChannels(t *testing.T, body *bytes.Buffer, channels []d
 	data, err := ioutil.ReadAll(body)
 	require.NoError(err)
 
-	var gotChannels []db.Channel
+	var gotChannels listResponse[db.Channel]
 	err = json.Unmarshal(data, &gotChannels)
 	require.NoError(err)
 
-	require.Equal(channels, gotChannels)
+	require.Equal(channels, gotChannels.Data)
 }
 
 func TestListChannelsAPI(t *testing.T) {
@@ -39,7 +39,7 @@ func TestListChannelsAPI(t *testing.T) {
 		channels[i] = randomChannel()
 	}
 	type Query struct {
-		pageID   int
+		cursor   string
 		pageSize int
 	}
 
@@ -53,16 +53,15 @@ func TestListChannelsAPI(t *testing.T) {
 		{
 			name: "OK",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				arg := db.ListChannelsParams{
-					Limit:  int32(n),
-					Offset: 0,
+					AfterID: 0,
+					Limit:   int32(n) + 1,
 				}
 				store.EXPECT().
 					ListChannels(gomock.Any(), arg).
@@ -77,11 +76,10 @@ func TestListChannelsAPI(t *testing.T) {
 		{
 			name: "InternalServerError",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -96,11 +94,10 @@ func TestListChannelsAPI(t *testing.T) {
 		{
 			name: "Invalid Page Size",
 			query: Query{
-				pageID:   1,
 				pageSize: 1000,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -112,13 +109,13 @@ func TestListChannelsAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Invalid Page ID",
+			name: "Invalid Cursor",
 			query: Query{
-				pageID:   -1,
+				cursor:   "not-a-cursor",
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -132,7 +129,6 @@ func TestListChannelsAPI(t *testing.T) {
 		{
 			name: "NoAuthorizationHeader",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -156,6 +152,7 @@ func TestListChannelsAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -168,7 +165,9 @@ func TestListChannelsAPI(t *testing.T) {
 
 			// Add query parameters to request URL
 			q := request.URL.Query()
-			q.Add("page_id", fmt.Sprintf("%d", testcase.query.pageID))
+			if testcase.query.cursor != "" {
+				q.Add("cursor", testcase.query.cursor)
+			}
 			q.Add("page_size", fmt.Sprintf("%d", testcase.query.pageSize))
 
 			request.URL.RawQuery = q.Encode()
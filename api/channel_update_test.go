@@ -60,7 +60,7 @@ func TestUpdateChannel(t *testing.T) {
 				"name": channel.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.UpdateChannelParams{
@@ -68,6 +68,10 @@ func TestUpdateChannel(t *testing.T) {
 					ID:   channel.ID,
 				}
 
+				store.EXPECT().
+					GetChannelByID(gomock.Any(), gomock.Eq(channel.ID)).
+					Times(1).
+					Return(channel, nil)
 				store.EXPECT().
 					UpdateChannel(gomock.Any(), EqUpdateChannelParams(arg)).
 					Times(1).
@@ -108,13 +112,17 @@ func TestUpdateChannel(t *testing.T) {
 				"name": channel.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
+				store.EXPECT().
+					GetChannelByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Channel{}, sql.ErrNoRows)
 				store.EXPECT().
 					UpdateChannel(gomock.Any(), gomock.Any()).
-					Times(1).Return(db.Channel{}, sql.ErrNoRows)
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				//check response
@@ -129,7 +137,7 @@ func TestUpdateChannel(t *testing.T) {
 				"name": channel.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
@@ -150,7 +158,7 @@ func TestUpdateChannel(t *testing.T) {
 				"name": "",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
@@ -171,10 +179,14 @@ func TestUpdateChannel(t *testing.T) {
 				"name": channel.Name,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 
+				store.EXPECT().
+					GetChannelByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(channel, nil)
 				store.EXPECT().
 					UpdateChannel(gomock.Any(), gomock.Any()).
 					Times(1).Return(db.Channel{}, sql.ErrConnDone)
@@ -195,6 +207,8 @@ func TestUpdateChannel(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
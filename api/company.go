@@ -2,13 +2,11 @@ package api
 
 import (
 	"database/sql"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/lenimbugua/bot/db/sqlc"
 	"github.com/lenimbugua/bot/token"
-	"github.com/lib/pq"
 )
 
 type createCompanyRequest struct {
@@ -20,7 +18,7 @@ type createCompanyRequest struct {
 func (server *Server) createCompany(ctx *gin.Context) {
 	var req createCompanyRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -29,19 +27,14 @@ func (server *Server) createCompany(ctx *gin.Context) {
 		Email: req.Email,
 		Name:  req.Name,
 	}
-	company, err := server.dbStore.CreateCompany(ctx, arg)
+	company, err := server.service.CreateCompany(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
 
+	setAuditEntity(ctx, company.ID, nil, company)
+	setETag(ctx, company.UpdatedAt)
 	ctx.JSON(http.StatusOK, company)
 }
 
@@ -53,27 +46,17 @@ func (server *Server) getCompanyByEmail(ctx *gin.Context) {
 	var req getCompanyByEmailRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	company, err := server.dbStore.GetCompanyByEmail(ctx, req.Email)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	if company.ID != authPayload.CompanyID {
-		err := errors.New("No access rights for that company")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	company, err := server.service.GetCompanyByEmail(ctx, authPayload, req.Email)
+	if err != nil {
+		respondServiceError(ctx, err)
 		return
 	}
+	setETag(ctx, company.UpdatedAt)
 	ctx.JSON(http.StatusOK, company)
 }
 
@@ -85,51 +68,84 @@ func (server *Server) getCompanyByID(ctx *gin.Context) {
 	var req getCompanyByIDRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	company, err := server.dbStore.GetCompanyByID(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	if company.ID != authPayload.CompanyID {
-		err := errors.New("No access rights for that company")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	company, err := server.service.GetCompany(ctx, authPayload, req.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
 		return
 	}
+	setETag(ctx, company.UpdatedAt)
 	ctx.JSON(http.StatusOK, company)
 }
 
 type listCompaniesRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	searchRequest
+	Sort string `form:"sort" binding:"omitempty,oneof=name -name email -email updated_at -updated_at"`
 }
 
+// listCompanies lists the company of the user, every company for operators. q searches the names and emails, sort orders by
+// name, email or updated_at, descending with a - prefix, and by id by default.
 func (server *Server) listCompanies(ctx *gin.Context) {
 	var req listCompaniesRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.ListCompaniesParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	p, ok := server.bindSortedPage(ctx, req.listRequest, req.Sort)
+	if !ok {
+		return
 	}
 
-	companies, err := server.dbStore.ListCompanies(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	companies, err := server.service.SearchCompanies(ctx, authPayload, db.SearchCompaniesParams{
+		Search:        req.searchPattern(),
+		CreatedAfter:  nullTime(req.CreatedAfter),
+		CreatedBefore: nullTime(req.CreatedBefore),
+		UpdatedAfter:  nullTime(req.UpdatedAfter),
+		UpdatedBefore: nullTime(req.UpdatedBefore),
+		AfterID:       p.after(),
+		Sort:          p.sort,
+		AfterText:     p.afterText,
+		AfterTime:     p.afterTime,
+		Limit:         p.limit(),
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := newSortedListResponse(p, companies, func(company db.Company) pageCursor {
+		cursor := pageCursor{AfterID: company.ID}
+		switch sortKey(p.sort) {
+		case "name":
+			cursor.AfterText = company.Name
+		case "email":
+			cursor.AfterText = company.Email
+		case "updated_at":
+			cursor.AfterTime = &company.UpdatedAt
+		}
+		return cursor
+	})
+	if req.IncludeTotal {
+		total, err := server.service.CountCompanies(ctx, authPayload, db.CountCompaniesParams{
+			Search:        req.searchPattern(),
+			CreatedAfter:  nullTime(req.CreatedAfter),
+			CreatedBefore: nullTime(req.CreatedBefore),
+			UpdatedAfter:  nullTime(req.UpdatedAfter),
+			UpdatedBefore: nullTime(req.UpdatedBefore),
+		})
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
 	}
-	ctx.JSON(http.StatusOK, companies)
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type updateCompanyURI struct {
@@ -148,53 +164,86 @@ func (server *Server) updateCompany(ctx *gin.Context) {
 	var req updateCompanyRequest
 
 	if err := ctx.ShouldBindUri(&uri); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	arg := db.UpdateCompanyParams{
+	server.saveCompany(ctx, db.UpdateCompanyParams{
 		Name:  sql.NullString{String: req.Name, Valid: true},
 		Phone: sql.NullString{String: req.Phone, Valid: true},
 		Email: sql.NullString{String: req.Email, Valid: true},
 		ID:    uri.ID,
+	})
+}
+
+type patchCompanyRequest struct {
+	Phone *string `json:"phone" binding:"omitempty,e164"`
+	Name  *string `json:"name" binding:"omitempty,min=1"`
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+// patchCompany applies a JSON Merge Patch to a company, changing only the fields it contains
+func (server *Server) patchCompany(ctx *gin.Context) {
+	var uri updateCompanyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
 	}
 
-	company, err := server.dbStore.UpdateCompany(ctx, arg)
+	var req patchCompanyRequest
+	if !bindMergePatch(ctx, &req) {
+		return
+	}
 
+	arg := db.UpdateCompanyParams{ID: uri.ID}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: *req.Name, Valid: true}
+	}
+	if req.Phone != nil {
+		arg.Phone = sql.NullString{String: *req.Phone, Valid: true}
+	}
+	if req.Email != nil {
+		arg.Email = sql.NullString{String: *req.Email, Valid: true}
+	}
+
+	server.saveCompany(ctx, arg)
+}
+
+// saveCompany updates a company, honouring the If-Match header of the request
+func (server *Server) saveCompany(ctx *gin.Context, arg db.UpdateCompanyParams) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	before, company, err := server.service.UpdateCompany(ctx, authPayload, arg, ifMatch(ctx))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, company.ID, before, company)
+	setETag(ctx, company.UpdatedAt)
 	ctx.JSON(http.StatusOK, company)
 }
 
+// deleteCompany soft deletes the company with its bots. Its users cannot log in
+// until the company is restored or purged.
 func (server *Server) deleteCompany(ctx *gin.Context) {
 	var req getCompanyByIDRequest
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err := server.dbStore.DeleteCompany(ctx, req.ID)
-
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	company, err := server.service.DeleteCompany(ctx, authPayload, req.ID, ifMatch(ctx))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
+	setAuditEntity(ctx, company.ID, company, nil)
 	ctx.JSON(http.StatusOK, req.ID)
 
 }
@@ -10,13 +10,19 @@ import (
 
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
 	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestDeleteCompanyAPI(t *testing.T) {
 	company := randomCompany()
 	user, _ := randomUser(t, company.ID)
+	user.Role = util.OwnerRole
+	member, _ := randomUser(t, company.ID)
+	other, _ := randomUser(t, company.ID+1)
+	other.Role = util.OwnerRole
 
 	testcases := []struct {
 		name           string
@@ -29,12 +35,17 @@ func TestDeleteCompanyAPI(t *testing.T) {
 			name:      "OK",
 			companyID: company.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteCompany(gomock.Any(), gomock.Eq(company.ID)).
-					Times(1)
+					GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).
+					Times(1).
+					Return(company, nil)
+				store.EXPECT().
+					DeleteCompanyTx(gomock.Any(), gomock.Eq(db.SoftDeleteCompanyParams{ID: company.ID})).
+					Times(1).
+					Return(company, nil)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -44,11 +55,11 @@ func TestDeleteCompanyAPI(t *testing.T) {
 			name:      "Invalid ID",
 			companyID: -1,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					DeleteCompany(gomock.Any(), gomock.Eq(-1)).
+					DeleteCompanyTx(gomock.Any(), gomock.Eq(-1)).
 					Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -59,10 +70,11 @@ func TestDeleteCompanyAPI(t *testing.T) {
 			name:      "Not found",
 			companyID: company.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteCompany(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrNoRows)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrNoRows)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
 
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
@@ -73,22 +85,51 @@ func TestDeleteCompanyAPI(t *testing.T) {
 			name:      "internal server error",
 			companyID: company.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteCompany(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrConnDone)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:      "OtherCompany",
+			companyID: company.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Phone, other.ID, other.Name, other.CompanyID, other.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotAdmin",
+			companyID: company.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, member.Phone, member.ID, member.Name, member.CompanyID, member.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:      "NoAuthorization",
 			companyID: company.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().DeleteCompany(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponses: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -104,6 +145,8 @@ func TestDeleteCompanyAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
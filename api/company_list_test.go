@@ -18,13 +18,15 @@ import (
 
 func TestListCompaniesAPI(t *testing.T) {
 	user, _ := randomUser(t, util.RandInt(1, 100))
+	operator, _ := randomUser(t, util.RandInt(1, 100))
+	operator.Role = util.OperatorRole
 	n := 5
 	companies := make([]db.Company, n)
 	for i := 0; i < n; i++ {
 		companies[i] = randomCompany()
 	}
 	type Query struct {
-		pageID   int
+		cursor   string
 		pageSize int
 	}
 
@@ -38,19 +40,40 @@ func TestListCompaniesAPI(t *testing.T) {
 		{
 			name: "OK",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.ListCompaniesParams{
-					Limit:  int32(n),
-					Offset: 0,
+				arg := db.SearchCompaniesParams{
+					ID:    sql.NullInt64{Int64: user.CompanyID, Valid: true},
+					Limit: int32(n) + 1,
 				}
 				store.EXPECT().
-					ListCompanies(gomock.Any(), arg).
+					SearchCompanies(gomock.Any(), arg).
+					Times(1).
+					Return(companies[:1], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchListCompanies(t, recorder.Body, companies[:1])
+			},
+		},
+		{
+			name: "Operator",
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, operator.Phone, operator.ID, operator.Name, operator.CompanyID, operator.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.SearchCompaniesParams{
+					Limit: int32(n) + 1,
+				}
+				store.EXPECT().
+					SearchCompanies(gomock.Any(), arg).
 					Times(1).
 					Return(companies, nil)
 			},
@@ -62,15 +85,14 @@ func TestListCompaniesAPI(t *testing.T) {
 		{
 			name: "Internal Server Error",
 			query: Query{
-				pageID:   1,
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanies(gomock.Any(), gomock.Any()).
+					SearchCompanies(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return([]db.Company{}, sql.ErrConnDone)
 			},
@@ -81,15 +103,14 @@ func TestListCompaniesAPI(t *testing.T) {
 		{
 			name: "Invalid Page Size",
 			query: Query{
-				pageID:   1,
 				pageSize: 1000,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanies(gomock.Any(), gomock.Any()).
+					SearchCompanies(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -97,17 +118,17 @@ func TestListCompaniesAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Invalid Page ID",
+			name: "Invalid Cursor",
 			query: Query{
-				pageID:   -1,
+				cursor:   "not-a-cursor",
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					ListCompanies(gomock.Any(), gomock.Any()).
+					SearchCompanies(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -124,6 +145,7 @@ func TestListCompaniesAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testcase.buildStub(store)
+			allowAuthUserLookup(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -136,7 +158,9 @@ func TestListCompaniesAPI(t *testing.T) {
 
 			// Add query parameters to request URL
 			q := request.URL.Query()
-			q.Add("page_id", fmt.Sprintf("%d", testcase.query.pageID))
+			if testcase.query.cursor != "" {
+				q.Add("cursor", testcase.query.cursor)
+			}
 			q.Add("page_size", fmt.Sprintf("%d", testcase.query.pageSize))
 
 			request.URL.RawQuery = q.Encode()
@@ -16,12 +16,17 @@ import (
 	mockdb "github.com/lenimbugua/bot/db/mock"
 	db "github.com/lenimbugua/bot/db/sqlc"
 	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateCompanyAPI(t *testing.T) {
 	company := randomCompany()
 	user, _ := randomUser(t, company.ID)
+	user.Role = util.OwnerRole
+	member, _ := randomUser(t, company.ID)
+	other, _ := randomUser(t, company.ID+1)
+	other.Role = util.OwnerRole
 	testcases := []struct {
 		name          string
 		id            int64
@@ -39,7 +44,7 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": company.Email,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name,user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name,user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				arg := db.UpdateCompanyParams{
@@ -48,6 +53,10 @@ func TestUpdateCompanyAPI(t *testing.T) {
 					Phone: sql.NullString{String: company.Phone, Valid: true},
 					ID:    company.ID,
 				}
+				store.EXPECT().
+					GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).
+					Times(1).
+					Return(company, nil)
 				store.EXPECT().
 					UpdateCompany(gomock.Any(), EqUpdateCompanyParams(arg)).
 					Times(1).
@@ -69,7 +78,7 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": "InvalidEmail",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name,user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name,user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 
@@ -90,7 +99,7 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": company.Email,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 
@@ -111,7 +120,7 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": company.Email,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 
@@ -133,18 +142,69 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": company.Email,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateCompany(gomock.Any(), gomock.Any()).
+					GetCompanyByID(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Company{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpdateCompany(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "OtherCompany",
+			id:   company.ID,
+			body: gin.H{
+				"name":  company.Name,
+				"phone": company.Phone,
+				"email": company.Email,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, other.Phone, other.ID, other.Name, other.CompanyID, other.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).
+					Times(1).
+					Return(company, nil)
+				store.EXPECT().
+					UpdateCompany(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			id:   company.ID,
+			body: gin.H{
+				"name":  company.Name,
+				"phone": company.Phone,
+				"email": company.Email,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, member.Phone, member.ID, member.Name, member.CompanyID, member.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).
+					Times(1).
+					Return(company, nil)
+				store.EXPECT().
+					UpdateCompany(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "Internal server error",
 			id:   company.ID,
@@ -154,9 +214,13 @@ func TestUpdateCompanyAPI(t *testing.T) {
 				"email": company.Email,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetCompanyByID(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(company, nil)
 				store.EXPECT().
 					UpdateCompany(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -177,6 +241,8 @@ func TestUpdateCompanyAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			testCase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
 			//start server and send requests
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+)
+
+type companyUserResponse struct {
+	ID            int64      `json:"id"`
+	CompanyID     int64      `json:"company_id"`
+	Name          string     `json:"name"`
+	Phone         string     `json:"phone"`
+	Email         string     `json:"email,omitempty"`
+	Role          string     `json:"role"`
+	TOTPEnabled   bool       `json:"totp_enabled"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newCompanyUserResponse(user db.User) companyUserResponse {
+	rsp := companyUserResponse{
+		ID:          user.ID,
+		CompanyID:   user.CompanyID,
+		Name:        user.Name,
+		Phone:       user.Phone,
+		Email:       user.Email.String,
+		Role:        user.Role,
+		TOTPEnabled: user.TotpEnabledAt.Valid,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+	if user.VerifiedAt.Valid {
+		rsp.VerifiedAt = &user.VerifiedAt.Time
+	}
+	if user.DeactivatedAt.Valid {
+		rsp.DeactivatedAt = &user.DeactivatedAt.Time
+	}
+	return rsp
+}
+
+type companyUserRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// abortUserChange answers a failed change of a user
+func abortUserChange(ctx *gin.Context, err error) {
+	respondServiceError(ctx, err)
+}
+
+// listUsers lists the users of the authenticated company
+func (server *Server) listUsers(ctx *gin.Context) {
+	var req listRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	users, err := server.service.ListUsers(ctx, authPayload, p.afterID, p.limit())
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]companyUserResponse, len(users))
+	for i, user := range users {
+		items[i] = newCompanyUserResponse(user)
+	}
+
+	rsp := newListResponse(p, items, func(item companyUserResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.service.CountUsers(ctx, authPayload)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) getUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.service.GetUser(ctx, authPayload, uri.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newCompanyUserResponse(user))
+}
+
+type updateUserRequest struct {
+	Name  string `json:"name" binding:"omitempty,max=20"`
+	Phone string `json:"phone" binding:"omitempty,e164"`
+	Role  string `json:"role" binding:"omitempty,oneof=owner admin member viewer"`
+}
+
+// updateUser changes the name, phone or role of a user. A new phone has to be verified again
+// and a new role applies from the next login of the user.
+func (server *Server) updateUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req updateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	server.saveUser(ctx, uri.ID, req)
+}
+
+type patchUserRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=20"`
+	Phone *string `json:"phone" binding:"omitempty,e164"`
+	Role  *string `json:"role" binding:"omitempty,oneof=owner admin member viewer"`
+}
+
+// patchUser applies a JSON Merge Patch to a user, changing only the fields it contains
+func (server *Server) patchUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req patchUserRequest
+	if !bindMergePatch(ctx, &req) {
+		return
+	}
+
+	var update updateUserRequest
+	if req.Name != nil {
+		update.Name = *req.Name
+	}
+	if req.Phone != nil {
+		update.Phone = *req.Phone
+	}
+	if req.Role != nil {
+		update.Role = *req.Role
+	}
+
+	server.saveUser(ctx, uri.ID, update)
+}
+
+// saveUser changes the fields of a user that are not empty in req
+func (server *Server) saveUser(ctx *gin.Context, id int64, req updateUserRequest) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, updated, err := server.service.UpdateUser(ctx, authPayload, db.UpdateUserParams{
+		Name:  sql.NullString{String: req.Name, Valid: req.Name != ""},
+		Phone: sql.NullString{String: req.Phone, Valid: req.Phone != ""},
+		Role:  sql.NullString{String: req.Role, Valid: req.Role != ""},
+		ID:    id,
+	})
+	if err != nil {
+		abortUserChange(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, newCompanyUserResponse(user), newCompanyUserResponse(updated))
+	ctx.JSON(http.StatusOK, newCompanyUserResponse(updated))
+}
+
+// deactivateUser stops a user from logging in without deleting them
+func (server *Server) deactivateUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, deactivated, err := server.service.DeactivateUser(ctx, authPayload, uri.ID)
+	if err != nil {
+		abortUserChange(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, newCompanyUserResponse(user), newCompanyUserResponse(deactivated))
+	ctx.JSON(http.StatusOK, newCompanyUserResponse(deactivated))
+}
+
+func (server *Server) reactivateUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, reactivated, err := server.service.ReactivateUser(ctx, authPayload, uri.ID)
+	if err != nil {
+		abortUserChange(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, newCompanyUserResponse(user), newCompanyUserResponse(reactivated))
+	ctx.JSON(http.StatusOK, newCompanyUserResponse(reactivated))
+}
+
+func (server *Server) deleteUser(ctx *gin.Context) {
+	var uri companyUserRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.service.DeleteUser(ctx, authPayload, uri.ID)
+	if err != nil {
+		abortUserChange(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, newCompanyUserResponse(user), nil)
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,559 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// randomCompanyUsers returns an owner, an admin and a member of the same company with distinct ids
+func randomCompanyUsers(t *testing.T, companyID int64) (owner db.User, admin db.User, member db.User) {
+	owner, _ = randomUser(t, companyID)
+	owner.ID = 1001
+	owner.Role = util.OwnerRole
+
+	admin, _ = randomUser(t, companyID)
+	admin.ID = 1002
+	admin.Role = util.AdminRole
+
+	member, _ = randomUser(t, companyID)
+	member.ID = 1003
+	return
+}
+
+func authorizeAs(user db.User) func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+	return func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+	}
+}
+
+func TestListUsersAPI(t *testing.T) {
+	company := randomCompany()
+	owner, admin, member := randomCompanyUsers(t, company.ID)
+	viewer, _ := randomUser(t, company.ID)
+	viewer.ID = 1004
+	viewer.Role = util.ViewerRole
+	users := []db.User{owner, admin, member, viewer}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     "page_size=5",
+			setupAuth: authorizeAs(viewer),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListCompanyUsersParams{CompanyID: company.ID, AfterID: 0, Limit: 6}
+				store.EXPECT().
+					ListCompanyUsers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[companyUserResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Data, len(users))
+				for i, user := range users {
+					require.Equal(t, user.ID, rsp.Data[i].ID)
+					require.Equal(t, user.Role, rsp.Data[i].Role)
+				}
+				require.NotContains(t, recorder.Body.String(), "password")
+			},
+		},
+		{
+			name:      "InvalidPageSize",
+			query:     "page_size=500",
+			setupAuth: authorizeAs(owner),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCompanyUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingScope",
+			query: "page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken(owner.Phone, owner.ID, owner.Name, owner.CompanyID, owner.Role, []string{token.ScopeBotsRead}, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListCompanyUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users?"+tc.query, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetUserAPI(t *testing.T) {
+	company := randomCompany()
+	_, admin, member := randomCompanyUsers(t, company.ID)
+	outsider, _ := randomUser(t, company.ID+1)
+	outsider.ID = 2001
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			userID: member.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp companyUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, member.ID, rsp.ID)
+				require.Equal(t, member.Phone, rsp.Phone)
+				require.NotNil(t, rsp.VerifiedAt)
+				require.Nil(t, rsp.DeactivatedAt)
+			},
+		},
+		{
+			name:   "OtherCompany",
+			userID: outsider.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(outsider.ID)).Times(1).Return(outsider, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			userID: 3001,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(3001))).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d", tc.userID), nil)
+			require.NoError(t, err)
+
+			authorizeAs(admin)(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateUserAPI(t *testing.T) {
+	company := randomCompany()
+	owner, admin, member := randomCompanyUsers(t, company.ID)
+
+	testCases := []struct {
+		name          string
+		target        db.User
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			target:    member,
+			body:      gin.H{"name": "new name", "role": util.AdminRole},
+			setupAuth: authorizeAs(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Name: sql.NullString{String: "new name", Valid: true},
+					Role: sql.NullString{String: util.AdminRole, Valid: true},
+					ID:   member.ID,
+				}
+				updated := member
+				updated.Name = "new name"
+				updated.Role = util.AdminRole
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp companyUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, "new name", rsp.Name)
+				require.Equal(t, util.AdminRole, rsp.Role)
+			},
+		},
+		{
+			name:      "OwnerPromotesToOwner",
+			target:    admin,
+			body:      gin.H{"role": util.OwnerRole},
+			setupAuth: authorizeAs(owner),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "AdminPromotesToOwner",
+			target:    member,
+			body:      gin.H{"role": util.OwnerRole},
+			setupAuth: authorizeAs(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "AdminChangesOwner",
+			target:    owner,
+			body:      gin.H{"role": util.MemberRole},
+			setupAuth: authorizeAs(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "MemberChangesUser",
+			target:    admin,
+			body:      gin.H{"name": "new name"},
+			setupAuth: authorizeAs(member),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "LastOwner",
+			target:    owner,
+			body:      gin.H{"role": util.AdminRole},
+			setupAuth: authorizeAs(owner),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, db.ErrLastOwner)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "DuplicatePhone",
+			target:    member,
+			body:      gin.H{"phone": admin.Phone},
+			setupAuth: authorizeAs(admin),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRole",
+			target:    member,
+			body:      gin.H{"role": "superuser"},
+			setupAuth: authorizeAs(owner),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPhone",
+			target:    member,
+			body:      gin.H{"phone": "0700"},
+			setupAuth: authorizeAs(owner),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(tc.target.ID)).AnyTimes().Return(tc.target, nil)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/users/%d", tc.target.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUserLifecycleAPI(t *testing.T) {
+	company := randomCompany()
+	owner, admin, member := randomCompanyUsers(t, company.ID)
+	deactivated := member
+	deactivated.DeactivatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		target        db.User
+		actor         db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Deactivate",
+			method: http.MethodPost,
+			path:   "/deactivate",
+			target: member,
+			actor:  admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(deactivated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp companyUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotNil(t, rsp.DeactivatedAt)
+			},
+		},
+		{
+			name:   "DeactivateLastOwner",
+			method: http.MethodPost,
+			path:   "/deactivate",
+			target: owner,
+			actor:  owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Eq(owner.ID)).Times(1).Return(db.User{}, db.ErrLastOwner)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "DeactivateAsMember",
+			method: http.MethodPost,
+			path:   "/deactivate",
+			target: admin,
+			actor:  member,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Reactivate",
+			method: http.MethodPost,
+			path:   "/reactivate",
+			target: deactivated,
+			actor:  admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReactivateUser(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp companyUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Nil(t, rsp.DeactivatedAt)
+			},
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			target: member,
+			actor:  owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteUserTx(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteLastOwner",
+			method: http.MethodDelete,
+			target: owner,
+			actor:  owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteUserTx(gomock.Any(), gomock.Eq(owner.ID)).Times(1).Return(db.ErrLastOwner)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteOwnerAsAdmin",
+			method: http.MethodDelete,
+			target: owner,
+			actor:  admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteWithHistory",
+			method: http.MethodDelete,
+			target: member,
+			actor:  admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteUserTx(gomock.Any(), gomock.Any()).Times(1).Return(&pq.Error{Code: "23503"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(tc.target.ID)).AnyTimes().Return(tc.target, nil)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d%s", tc.target.ID, tc.path)
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			authorizeAs(tc.actor)(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeactivatedUserCannotAuthenticate(t *testing.T) {
+	user, password := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+	user.DeactivatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	allowLoginAttempts(store)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+	store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+
+	data, err := json.Marshal(gin.H{"phone": user.Phone, "password": password})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// access tokens issued before the deactivation stop working
+	request, err = http.NewRequest(http.MethodGet, "/users?page_size=5", nil)
+	require.NoError(t, err)
+	authorizeAs(user)(t, request, server.tokenMaker)
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+)
+
+type conversationResponse struct {
+	Question  *db.Question `json:"question,omitempty"`
+	Completed bool         `json:"completed"`
+}
+
+func newConversationResponse(step service.ConversationStep) conversationResponse {
+	if step.Completed {
+		return conversationResponse{Completed: true}
+	}
+	return conversationResponse{Question: &step.Question}
+}
+
+type startConversationRequest struct {
+	SessionID string `json:"session_id" binding:"required,uuid"`
+	BotID     int64  `json:"bot_id" binding:"required,min=1"`
+	Channel   string `json:"channel" binding:"required"`
+}
+
+// startConversation starts a conversation of a session of the user with a bot on a channel,
+// answering with the first question of the bot
+func (server *Server) startConversation(ctx *gin.Context) {
+	var req startConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	step, err := server.service.StartConversation(ctx, authPayload, uuid.MustParse(req.SessionID), req.BotID, req.Channel)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newConversationResponse(step))
+}
+
+type conversationURI struct {
+	SessionID string `uri:"session_id" binding:"required,uuid"`
+}
+
+type answerConversationRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// answerConversation gives the answer of the user to the question the conversation of a
+// session waits for, answering with the next question or with the conversation completed
+func (server *Server) answerConversation(ctx *gin.Context) {
+	var uri conversationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	var req answerConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	step, err := server.service.AnswerConversation(ctx, authPayload, uuid.MustParse(uri.SessionID), req.Text)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newConversationResponse(step))
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func requireBodyMatchConversation(t *testing.T, body *bytes.Buffer, question *db.Question, completed bool) {
+	var got conversationResponse
+	err := json.Unmarshal(body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, completed, got.Completed)
+	if question == nil {
+		require.Nil(t, got.Question)
+		return
+	}
+	require.NotNil(t, got.Question)
+	require.Equal(t, question.ID, got.Question.ID)
+	require.Equal(t, question.Question, got.Question.Question)
+}
+
+func TestStartConversationAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	question := randomQuestion(bot.ID)
+	user, _ := randomUser(t, company.ID)
+	user.ID = util.RandInt(1, 1000)
+	channel := db.Channel{ID: int32(util.RandInt(1, 100)), Name: util.RandomString(6)}
+	session := db.Session{ID: uuid.New(), UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"session_id": session.ID, "bot_id": bot.ID, "channel": channel.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().GetChannel(gomock.Any(), gomock.Eq(channel.Name)).Times(1).Return(channel, nil)
+				store.EXPECT().GetFirstQuestion(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(question, nil)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: int64(channel.ID), QuestionID: question.ID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchConversation(t, recorder.Body, &question, false)
+			},
+		},
+		{
+			name: "OtherUserSession",
+			body: gin.H{"session_id": session.ID, "bot_id": bot.ID, "channel": channel.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := session
+				other.UserID = user.ID + 1
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(other, nil)
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BotWithoutQuestions",
+			body: gin.H{"session_id": session.ID, "bot_id": bot.ID, "channel": channel.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().GetChannel(gomock.Any(), gomock.Eq(channel.Name)).Times(1).Return(channel, nil)
+				store.EXPECT().GetFirstQuestion(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidSessionID",
+			body: gin.H{"session_id": "invalid", "bot_id": bot.ID, "channel": channel.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/conversations", bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, company.ID, user.Role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAnswerConversationAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	question := randomQuestion(bot.ID)
+	next := randomQuestion(bot.ID)
+	next.ID = question.ID + 1
+	user, _ := randomUser(t, company.ID)
+	user.ID = util.RandInt(1, 1000)
+	channel := db.Channel{ID: int32(util.RandInt(1, 100)), Name: util.RandomString(6)}
+	session := db.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		ChannelID:  int64(channel.ID),
+		QuestionID: question.ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		sessionID     string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "NextQuestion",
+			sessionID: session.ID.String(),
+			body:      gin.H{"text": "yes"},
+			buildStubs: func(store *mockdb.MockStore) {
+				response := db.Response{ID: util.RandInt(1, 1000), QuestionID: question.ID, NextQuestionID: next.ID}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetChannelByID(gomock.Any(), gomock.Eq(channel.ID)).Times(1).Return(channel, nil)
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().MatchResponse(gomock.Any(), gomock.Any()).Times(1).Return(response, nil)
+				store.EXPECT().CreateUserResponse(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(next.ID)).Times(1).Return(next, nil)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: session.ChannelID, QuestionID: next.ID, ResponseID: response.ID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchConversation(t, recorder.Body, &next, false)
+			},
+		},
+		{
+			name:      "Completed",
+			sessionID: session.ID.String(),
+			body:      gin.H{"text": "no"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetChannelByID(gomock.Any(), gomock.Eq(channel.ID)).Times(1).Return(channel, nil)
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().MatchResponse(gomock.Any(), gomock.Any()).Times(1).Return(db.Response{}, sql.ErrNoRows)
+				store.EXPECT().CreateUserResponse(gomock.Any(), gomock.Any()).Times(1)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: session.ChannelID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchConversation(t, recorder.Body, nil, true)
+			},
+		},
+		{
+			name:      "NoConversation",
+			sessionID: session.ID.String(),
+			body:      gin.H{"text": "yes"},
+			buildStubs: func(store *mockdb.MockStore) {
+				idle := session
+				idle.QuestionID = 0
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(idle, nil)
+				store.EXPECT().CreateUserResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "EmptyText",
+			sessionID: session.ID.String(),
+			body:      gin.H{"text": ""},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidSessionID",
+			sessionID: "invalid",
+			body:      gin.H{"text": "yes"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/conversations/%s/messages", tc.sessionID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, company.ID, user.Role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
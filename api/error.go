@@ -0,0 +1,340 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/sso"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lib/pq"
+)
+
+// Error codes returned in the code field of every error response.
+// Clients may rely on them; the messages are for humans and can change.
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeValidationFailed     = "validation_failed"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeConflict             = "conflict"
+	codeGone                 = "gone"
+	codePreconditionFailed   = "precondition_failed"
+	codePreconditionRequired = "precondition_required"
+	codeRateLimited          = "rate_limited"
+	codeInternal             = "internal_error"
+	codeBadGateway           = "bad_gateway"
+	codeAlreadyExists        = "already_exists"
+	codeInvalidReference     = "invalid_reference"
+	codeInUse                = "in_use"
+	codeInvalidValue         = "invalid_value"
+)
+
+// statusCodes gives the code of an error that is not recognised more precisely
+var statusCodes = map[int]string{
+	http.StatusBadRequest:           codeInvalidRequest,
+	http.StatusUnauthorized:         codeUnauthorized,
+	http.StatusForbidden:            codeForbidden,
+	http.StatusNotFound:             codeNotFound,
+	http.StatusConflict:             codeConflict,
+	http.StatusGone:                 codeGone,
+	http.StatusPreconditionFailed:   codePreconditionFailed,
+	http.StatusPreconditionRequired: codePreconditionRequired,
+	http.StatusTooManyRequests:      codeRateLimited,
+	http.StatusInternalServerError:  codeInternal,
+	http.StatusBadGateway:           codeBadGateway,
+}
+
+// errorCodes gives the code of the errors of the other packages that reach clients.
+// The first entry an error matches wins, so that an error wrapping several of them
+// always gets the same code.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{token.ErrInvalidToken, "invalid_token"},
+	{token.ErrExpiredToken, "token_expired"},
+	{otp.ErrInvalidCode, "invalid_code"},
+	{otp.ErrTooManyAttempts, "too_many_attempts"},
+	{otp.ErrResendCooldown, "resend_cooldown"},
+	{sso.ErrInvalidIDToken, "invalid_id_token"},
+	{sso.ErrNonceMismatch, "invalid_id_token"},
+	{sso.ErrEmailMissing, "email_missing"},
+	{sso.ErrEmailNotProven, "email_not_verified"},
+	{sso.ErrUnsafeURL, "unsafe_issuer"},
+	{db.ErrInvitationUnusable, "invitation_unusable"},
+	{db.ErrInvitationPhoneMismatch, "invitation_phone_mismatch"},
+	{db.ErrInvitationPhoneRequired, "invitation_phone_required"},
+	{db.ErrLastOwner, "last_owner"},
+	{service.ErrInvalidAPIKey, "invalid_api_key"},
+	{service.ErrRevokedAPIKey, "api_key_revoked"},
+	{service.ErrExpiredAPIKey, "api_key_expired"},
+	{service.ErrUserDeactivated, "user_deactivated"},
+	{service.ErrPhoneNotVerified, "phone_not_verified"},
+	{service.ErrInvalidSecondFactor, "invalid_second_factor"},
+	{service.ErrVersionMismatch, codePreconditionFailed},
+	{service.ErrVersionRequired, codePreconditionRequired},
+	{service.ErrOwnerOnly, "owner_only"},
+}
+
+// constraintMessages replaces the messages of postgres for the unique constraints of the schema
+var constraintMessages = map[string]string{
+	"users_phone_key":                   "phone is already registered",
+	"users_lower_idx":                   "email is already registered",
+	"companies_email_key":               "company email is already registered",
+	"companies_phone_key":               "company phone is already registered",
+	"channels_name_key":                 "channel name is already taken",
+	"sso_connections_email_domain_key":  "email domain already has a single sign-on connection",
+	"sso_domains_company_id_domain_idx": "email domain is already claimed by the company",
+	"sso_domains_verified_domain_idx":   "email domain is verified by another company",
+}
+
+// fieldError describes why one field of a request failed validation
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// apiError is the body of every error response
+type apiError struct {
+	Status    int          `json:"-"`
+	Code      string       `json:"code"`
+	Message   string       `json:"error"`
+	Fields    []fieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+func (apiErr *apiError) Error() string {
+	return apiErr.Message
+}
+
+// newAPIError creates an error with a code more precise than the one of the status it is answered with
+func newAPIError(code string, message string) *apiError {
+	return &apiError{Code: code, Message: message}
+}
+
+// toAPIError translates err into the body answered with status.
+// Database and validation errors get their own code and a message that does not leak internals.
+func toAPIError(status int, err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		copied := *apiErr
+		copied.Status = status
+		return &copied
+	}
+
+	apiErr = &apiError{Status: status, Code: statusCodes[status], Message: err.Error()}
+	if apiErr.Code == "" {
+		apiErr.Code = codeInternal
+		if status < http.StatusInternalServerError {
+			apiErr.Code = codeInvalidRequest
+		}
+	}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var pqErr *pq.Error
+
+	switch {
+	case errors.As(err, &validationErrs):
+		apiErr.Code = codeValidationFailed
+		apiErr.Message = "request is invalid"
+		for _, fieldErr := range validationErrs {
+			apiErr.Fields = append(apiErr.Fields, newFieldError(fieldErr))
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		apiErr.Message = "request body is not valid JSON"
+	case errors.As(err, &typeErr):
+		apiErr.Code = codeValidationFailed
+		apiErr.Message = "request is invalid"
+		apiErr.Fields = []fieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type),
+		}}
+	case errors.Is(err, sql.ErrNoRows):
+		apiErr.Message = "resource not found"
+	case errors.As(err, &pqErr):
+		translatePQError(apiErr, pqErr)
+	default:
+		for _, known := range errorCodes {
+			if errors.Is(err, known.err) {
+				apiErr.Code = known.code
+				break
+			}
+		}
+	}
+
+	// the details of a server failure are logged, not shown
+	if apiErr.Status == http.StatusInternalServerError && apiErr.Code == codeInternal {
+		apiErr.Message = "internal server error"
+	}
+	return apiErr
+}
+
+// translatePQError turns a constraint violation into a friendly code and message.
+// Handlers that did not expect the violation answered 500, which becomes the status of the code.
+func translatePQError(apiErr *apiError, pqErr *pq.Error) {
+	unexpected := apiErr.Status == http.StatusInternalServerError
+
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		apiErr.Code = codeAlreadyExists
+		apiErr.Message = "resource already exists"
+		if message, ok := constraintMessages[pqErr.Constraint]; ok {
+			apiErr.Message = message
+		}
+		if unexpected {
+			apiErr.Status = http.StatusConflict
+		}
+	case "foreign_key_violation":
+		apiErr.Code = codeInvalidReference
+		apiErr.Message = "referenced resource does not exist"
+		if strings.Contains(pqErr.Detail, "is still referenced") {
+			apiErr.Code = codeInUse
+			apiErr.Message = "resource is still referenced by other resources"
+		}
+		if unexpected {
+			apiErr.Status = http.StatusConflict
+		}
+	case "check_violation", "not_null_violation", "string_data_right_truncation":
+		apiErr.Code = codeInvalidValue
+		apiErr.Message = "a value is not allowed"
+		if pqErr.Column != "" {
+			apiErr.Message = fmt.Sprintf("value of %s is not allowed", pqErr.Column)
+		}
+		if unexpected {
+			apiErr.Status = http.StatusBadRequest
+		}
+	}
+}
+
+// validationMessages explains the binding rules used by the request structs
+var validationMessages = map[string]string{
+	"required":         "is required",
+	"required_without": "is required",
+	"email":            "must be a valid email address",
+	"e164":             "must be a phone number in E.164 format",
+	"numeric":          "must only contain digits",
+	"oneof":            "must be one of %s",
+	"min":              "must be at least %s",
+	"max":              "must be at most %s",
+	"len":              "must have a length of %s",
+	"url":              "must be a valid URL",
+	"dive":             "is invalid",
+}
+
+func newFieldError(fieldErr validator.FieldError) fieldError {
+	message := "is invalid"
+	if format, ok := validationMessages[fieldErr.Tag()]; ok {
+		message = format
+		if strings.Contains(format, "%s") {
+			message = fmt.Sprintf(format, fieldErr.Param())
+		}
+	}
+
+	return fieldError{
+		Field:   fieldErr.Field(),
+		Rule:    fieldErr.Tag(),
+		Message: message,
+	}
+}
+
+// requestFieldName names fields after their json, form or uri key so that errors match the request
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func init() {
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// respondError answers the request with the error
+func respondError(ctx *gin.Context, status int, err error) {
+	apiErr := toAPIError(status, err)
+	apiErr.RequestID = ctx.GetString(requestIDKey)
+	if apiErr.Status >= http.StatusInternalServerError {
+		_ = ctx.Error(err)
+	}
+	ctx.JSON(apiErr.Status, apiErr)
+}
+
+// serviceErrorStatus gives the status answering an error of the service layer
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrNotOwner):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrNotAdmin), errors.Is(err, service.ErrOwnerOnly):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrLastOwner):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrVersionRequired):
+		return http.StatusPreconditionRequired
+	}
+	return http.StatusInternalServerError
+}
+
+// respondServiceError answers the request with an error of the service layer
+func respondServiceError(ctx *gin.Context, err error) {
+	respondError(ctx, serviceErrorStatus(err), err)
+}
+
+// abortWithError answers the request with the error and stops the handlers that follow
+func abortWithError(ctx *gin.Context, status int, err error) {
+	respondError(ctx, status, err)
+	ctx.Abort()
+}
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+)
+
+// requestIDPattern limits the request ids accepted from clients to something safe to log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestIDMiddleware gives every request an id, reusing the one sent by the client or proxy
+// when it looks sane. The id is echoed in the X-Request-ID header and in error responses.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// joinedErrors wraps several errors at once, as errors.Join does
+type joinedErrors []error
+
+func (errs joinedErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (errs joinedErrors) Unwrap() []error {
+	return errs
+}
+
+func TestToAPIError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		err     error
+		want    apiError
+		notWant string
+	}{
+		{
+			name:   "Plain",
+			status: http.StatusForbidden,
+			err:    errors.New("only company owners and admins can invite users"),
+			want:   apiError{Status: http.StatusForbidden, Code: codeForbidden, Message: "only company owners and admins can invite users"},
+		},
+		{
+			name:   "Coded",
+			status: http.StatusForbidden,
+			err:    service.ErrPhoneNotVerified,
+			want:   apiError{Status: http.StatusForbidden, Code: "phone_not_verified", Message: "phone number is not verified"},
+		},
+		{
+			name:   "KnownError",
+			status: http.StatusUnauthorized,
+			err:    fmt.Errorf("cannot verify: %w", token.ErrExpiredToken),
+			want:   apiError{Status: http.StatusUnauthorized, Code: "token_expired", Message: "cannot verify: token has expired"},
+		},
+		{
+			name:   "SeveralKnownErrors",
+			status: http.StatusUnauthorized,
+			err:    joinedErrors{service.ErrRevokedAPIKey, token.ErrInvalidToken},
+			want:   apiError{Status: http.StatusUnauthorized, Code: "invalid_token", Message: "api key has been revoked; token is invalid"},
+		},
+		{
+			name:   "NoRows",
+			status: http.StatusNotFound,
+			err:    sql.ErrNoRows,
+			want:   apiError{Status: http.StatusNotFound, Code: codeNotFound, Message: "resource not found"},
+		},
+		{
+			name:    "Internal",
+			status:  http.StatusInternalServerError,
+			err:     errors.New("pq: connection refused on 10.0.0.5"),
+			want:    apiError{Status: http.StatusInternalServerError, Code: codeInternal, Message: "internal server error"},
+			notWant: "10.0.0.5",
+		},
+		{
+			name:    "UniqueViolation",
+			status:  http.StatusForbidden,
+			err:     &pq.Error{Code: "23505", Constraint: "users_phone_key", Detail: "Key (phone)=(+254700000000) already exists."},
+			want:    apiError{Status: http.StatusForbidden, Code: codeAlreadyExists, Message: "phone is already registered"},
+			notWant: "+254700000000",
+		},
+		{
+			name:   "UnexpectedUniqueViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23505", Constraint: "some_new_key"},
+			want:   apiError{Status: http.StatusConflict, Code: codeAlreadyExists, Message: "resource already exists"},
+		},
+		{
+			name:   "MissingReference",
+			status: http.StatusForbidden,
+			err:    &pq.Error{Code: "23503", Detail: `Key (company_id)=(5) is not present in table "companies".`},
+			want:   apiError{Status: http.StatusForbidden, Code: codeInvalidReference, Message: "referenced resource does not exist"},
+		},
+		{
+			name:   "StillReferenced",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23503", Detail: `Key (id)=(5) is still referenced from table "bots".`},
+			want:   apiError{Status: http.StatusConflict, Code: codeInUse, Message: "resource is still referenced by other resources"},
+		},
+		{
+			name:   "CheckViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23514", Column: "role"},
+			want:   apiError{Status: http.StatusBadRequest, Code: codeInvalidValue, Message: "value of role is not allowed"},
+		},
+		{
+			name:   "Wrapped",
+			status: http.StatusInternalServerError,
+			err:    fmt.Errorf("tx err: %w", db.ErrLastOwner),
+			want:   apiError{Status: http.StatusInternalServerError, Code: "last_owner", Message: "tx err: a company must keep at least one active owner"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := toAPIError(tc.status, tc.err)
+			require.Equal(t, tc.want, *apiErr)
+			if tc.notWant != "" {
+				require.NotContains(t, apiErr.Message, tc.notWant)
+			}
+		})
+	}
+}
+
+func TestValidationErrorResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	testCases := []struct {
+		name          string
+		body          string
+		checkResponse func(rsp apiError)
+	}{
+		{
+			name: "InvalidFields",
+			body: `{"phone": "0700", "password": "abc"}`,
+			checkResponse: func(rsp apiError) {
+				require.Equal(t, codeValidationFailed, rsp.Code)
+				require.Equal(t, []fieldError{
+					{Field: "phone", Rule: "e164", Message: "must be a phone number in E.164 format"},
+					{Field: "password", Rule: "min", Message: "must be at least 6"},
+				}, rsp.Fields)
+			},
+		},
+		{
+			name: "MissingFields",
+			body: `{}`,
+			checkResponse: func(rsp apiError) {
+				require.Equal(t, codeValidationFailed, rsp.Code)
+				require.Len(t, rsp.Fields, 2)
+				require.Equal(t, "required", rsp.Fields[0].Rule)
+			},
+		},
+		{
+			name: "WrongType",
+			body: `{"phone": 254700000000, "password": "secret"}`,
+			checkResponse: func(rsp apiError) {
+				require.Equal(t, codeValidationFailed, rsp.Code)
+				require.Equal(t, "phone", rsp.Fields[0].Field)
+			},
+		},
+		{
+			name: "NotJSON",
+			body: `{"phone": `,
+			checkResponse: func(rsp apiError) {
+				require.Equal(t, codeInvalidRequest, rsp.Code)
+				require.Equal(t, "request body is not valid JSON", rsp.Message)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var rsp apiError
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			require.NotEmpty(t, rsp.RequestID)
+			tc.checkResponse(rsp)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(requestIDMiddleware())
+	router.GET("/fail", func(ctx *gin.Context) {
+		respondError(ctx, http.StatusNotFound, sql.ErrNoRows)
+	})
+
+	testCases := []struct {
+		name      string
+		requestID string
+		reused    bool
+	}{
+		{name: "Generated"},
+		{name: "FromClient", requestID: "req-123_abc.9", reused: true},
+		{name: "Unsafe", requestID: "bad id\nwith newline"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/fail", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeader, tc.requestID)
+			}
+
+			router.ServeHTTP(recorder, request)
+
+			requestID := recorder.Header().Get(requestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tc.reused {
+				require.Equal(t, tc.requestID, requestID)
+			} else {
+				require.NotEqual(t, tc.requestID, requestID)
+			}
+
+			var rsp apiError
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			require.Equal(t, requestID, rsp.RequestID)
+			require.Equal(t, codeNotFound, rsp.Code)
+		})
+	}
+}
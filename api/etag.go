@@ -0,0 +1,34 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/service"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+// setETag sends the entity tag of the version of the entity in the response
+func setETag(ctx *gin.Context, updatedAt time.Time) {
+	ctx.Header(etagHeader, service.ETag(updatedAt))
+}
+
+// ifMatch returns the entity tags listed in the If-Match header of the request,
+// none when the request is not conditional
+func ifMatch(ctx *gin.Context) service.IfMatch {
+	header := ctx.GetHeader(ifMatchHeader)
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+
+	var tags service.IfMatch
+	for _, tag := range strings.Split(header, ",") {
+		tags = append(tags, strings.TrimSpace(tag))
+	}
+	return tags
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatches(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 10, 0, 0, 123456000, time.UTC)
+	current := service.ETag(updatedAt)
+	stale := service.ETag(updatedAt.Add(-time.Microsecond))
+	require.NotEqual(t, current, stale)
+
+	for header, ok := range map[string]bool{
+		"":                     true,
+		"*":                    true,
+		current:                true,
+		stale + ", " + current: true,
+		stale:                  false,
+		"W/" + current:         false,
+	} {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+		ctx.Request.Header.Set(ifMatchHeader, header)
+		require.Equal(t, ok, ifMatch(ctx).Matches(updatedAt), header)
+	}
+}
+
+func TestBotConditionalRequests(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	bot := randomBot(t, company.ID)
+	bot.UpdatedAt = time.Date(2026, 10, 19, 10, 0, 0, 123456000, time.UTC)
+	stale := service.ETag(bot.UpdatedAt.Add(-time.Second))
+
+	testCases := []struct {
+		name          string
+		method        string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "GetSendsETag",
+			method: http.MethodGet,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, service.ETag(bot.UpdatedAt), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:    "UpdateMatching",
+			method:  http.MethodPut,
+			ifMatch: service.ETag(bot.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := bot
+				updated.UpdatedAt = bot.UpdatedAt.Add(time.Minute)
+
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				arg := db.UpdateBotParams{
+					Title:     sql.NullString{String: bot.Title, Valid: true},
+					CompanyID: sql.NullInt64{Int64: bot.CompanyID, Valid: true},
+					ID:        bot.ID,
+					UpdatedAt: sql.NullTime{Time: bot.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, service.ETag(bot.UpdatedAt.Add(time.Minute)), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:    "UpdateStale",
+			method:  http.MethodPut,
+			ifMatch: stale,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+
+				var rsp apiError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, codePreconditionFailed, rsp.Code)
+			},
+		},
+		{
+			name:    "UpdateLostRace",
+			method:  http.MethodPut,
+			ifMatch: service.ETag(bot.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(1).Return(db.Bot{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "UpdateAnyVersion",
+			method:  http.MethodPut,
+			ifMatch: "*",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				arg := db.UpdateBotParams{
+					Title:     sql.NullString{String: bot.Title, Valid: true},
+					CompanyID: sql.NullInt64{Int64: bot.CompanyID, Valid: true},
+					ID:        bot.ID,
+				}
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteStale",
+			method:  http.MethodDelete,
+			ifMatch: stale,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteMatching",
+			method:  http.MethodDelete,
+			ifMatch: service.ETag(bot.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteBotParams{ID: bot.ID, UpdatedAt: sql.NullTime{Time: bot.UpdatedAt, Valid: true}}
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteLostRace",
+			method:  http.MethodDelete,
+			ifMatch: service.ETag(bot.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				// the bot changed between the read and the conditional delete
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Bot{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"title": bot.Title, "company_id": bot.CompanyID})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, fmt.Sprintf("/bots/%d", bot.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCompanyConditionalRequests(t *testing.T) {
+	company := randomCompany()
+	company.UpdatedAt = time.Date(2026, 10, 19, 10, 0, 0, 123456000, time.UTC)
+	user, _ := randomUser(t, company.ID)
+	user.Role = util.OwnerRole
+	stale := service.ETag(company.UpdatedAt.Add(-time.Second))
+
+	testCases := []struct {
+		name          string
+		method        string
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "UpdateMatching",
+			method:  http.MethodPut,
+			ifMatch: service.ETag(company.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				arg := db.UpdateCompanyParams{
+					Name:      sql.NullString{String: company.Name, Valid: true},
+					Phone:     sql.NullString{String: company.Phone, Valid: true},
+					Email:     sql.NullString{String: company.Email, Valid: true},
+					ID:        company.ID,
+					UpdatedAt: sql.NullTime{Time: company.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Eq(arg)).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, service.ETag(company.UpdatedAt), recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:    "UpdateStale",
+			method:  http.MethodPut,
+			ifMatch: stale,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteStale",
+			method:  http.MethodDelete,
+			ifMatch: stale,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteMatching",
+			method:  http.MethodDelete,
+			ifMatch: service.ETag(company.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteCompanyParams{ID: company.ID, UpdatedAt: sql.NullTime{Time: company.UpdatedAt, Valid: true}}
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "DeleteLostRace",
+			method:  http.MethodDelete,
+			ifMatch: service.ETag(company.UpdatedAt),
+			buildStubs: func(store *mockdb.MockStore) {
+				// the company changed between the read and the conditional delete
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:   "DeleteUnconditional",
+			method: http.MethodDelete,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Eq(db.SoftDeleteCompanyParams{ID: company.ID})).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"name": company.Name, "phone": company.Phone, "email": company.Email})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, fmt.Sprintf("/companies/%d", company.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
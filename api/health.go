@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkTimeout bounds every readiness check, so that a dependency that hangs fails
+// the probe instead of hanging it
+const checkTimeout = 2 * time.Second
+
+// readinessCheck is one of the conditions for the server to take traffic
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// AddReadinessCheck makes /readyz report not ready while check fails, as for a background
+// worker the server relies on. Checks are added before the server starts.
+func (server *Server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	server.checks = append(server.checks, readinessCheck{name: name, check: check})
+}
+
+// checkMigrations fails unless the database is at the version of the newest migration
+func (server *Server) checkMigrations(expected uint) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		version, dirty, err := server.dbStore.MigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("database is dirty at version %d", version)
+		}
+		if version != int64(expected) {
+			return fmt.Errorf("database is at version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
+
+type checkResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// liveness reports that the process is up. It checks nothing else, so that a failing
+// dependency does not get the process restarted.
+func (server *Server) liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readiness reports whether the server takes traffic, with the outcome and latency of
+// every check. It answers 503 when a check fails and while the server is draining.
+func (server *Server) readiness(ctx *gin.Context) {
+	if server.draining.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, readinessResponse{Status: "draining"})
+		return
+	}
+
+	rsp := readinessResponse{Status: "ready", Checks: make(map[string]checkResult, len(server.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range server.checks {
+		wg.Add(1)
+		go func(c readinessCheck) {
+			defer wg.Done()
+			result := runCheck(ctx.Request.Context(), c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			rsp.Checks[c.name] = result
+			if result.Error != "" {
+				rsp.Status = "not_ready"
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if rsp.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, rsp)
+}
+
+func runCheck(ctx context.Context, c readinessCheck) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(ctx)
+	result := checkResult{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lenimbugua/bot/db/migrator"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testMigrationURL = "file://../db/migration"
+
+func TestLiveness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestReadiness(t *testing.T) {
+	latest, err := migrator.LatestVersion(testMigrationURL)
+	require.NoError(t, err)
+	version := int64(latest)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		workerErr     error
+		checkResponse func(t *testing.T, status int, rsp readinessResponse)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, false, nil)
+			},
+			checkResponse: func(t *testing.T, status int, rsp readinessResponse) {
+				require.Equal(t, http.StatusOK, status)
+				require.Equal(t, "ready", rsp.Status)
+				require.Len(t, rsp.Checks, 3)
+				for _, check := range rsp.Checks {
+					require.Equal(t, "ok", check.Status)
+					require.Empty(t, check.Error)
+				}
+			},
+		},
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(int64(0), false, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, status int, rsp readinessResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, "not_ready", rsp.Status)
+				require.Equal(t, "fail", rsp.Checks["database"].Status)
+				require.Equal(t, "fail", rsp.Checks["migrations"].Status)
+				require.Equal(t, "ok", rsp.Checks["worker"].Status)
+			},
+		},
+		{
+			name: "MigrationsBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version-1, false, nil)
+			},
+			checkResponse: func(t *testing.T, status int, rsp readinessResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, "fail", rsp.Checks["migrations"].Status)
+				require.Contains(t, rsp.Checks["migrations"].Error, "expected")
+			},
+		},
+		{
+			name: "DirtyDatabase",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, true, nil)
+			},
+			checkResponse: func(t *testing.T, status int, rsp readinessResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Contains(t, rsp.Checks["migrations"].Error, "dirty")
+			},
+		},
+		{
+			name: "WorkerStopped",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).Return(version, false, nil)
+			},
+			workerErr: errors.New("worker is not running"),
+			checkResponse: func(t *testing.T, status int, rsp readinessResponse) {
+				require.Equal(t, http.StatusServiceUnavailable, status)
+				require.Equal(t, "ok", rsp.Checks["database"].Status)
+				require.Equal(t, "fail", rsp.Checks["worker"].Status)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			config := util.Config{
+				TokenSymmetricKey: util.RandomString(32),
+				MigrationURL:      testMigrationURL,
+			}
+			server, err := NewServer(config, store)
+			require.NoError(t, err)
+			server.AddReadinessCheck("worker", func(ctx context.Context) error { return tc.workerErr })
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			server.router.ServeHTTP(recorder, request)
+
+			var rsp readinessResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			tc.checkResponse(t, recorder.Code, rsp)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+const (
+	invitationTokenSize       = 32
+	defaultInvitationDuration = 72 * time.Hour
+)
+
+type createInvitationRequest struct {
+	Role           string `json:"role" binding:"required,oneof=admin member viewer"`
+	Phone          string `json:"phone" binding:"required_without=Email,omitempty,e164"`
+	Email          string `json:"email" binding:"required_without=Phone,omitempty,email"`
+	ExpiresInHours int64  `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+type invitationResponse struct {
+	ID         int64      `json:"id"`
+	CompanyID  int64      `json:"company_id"`
+	InvitedBy  int64      `json:"invited_by"`
+	Role       string     `json:"role"`
+	Phone      string     `json:"phone,omitempty"`
+	Email      string     `json:"email,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newInvitationResponse(invitation db.Invitation) invitationResponse {
+	rsp := invitationResponse{
+		ID:        invitation.ID,
+		CompanyID: invitation.CompanyID,
+		InvitedBy: invitation.InvitedBy,
+		Role:      invitation.Role,
+		Phone:     invitation.Phone.String,
+		Email:     invitation.Email.String,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+	if invitation.AcceptedAt.Valid {
+		rsp.AcceptedAt = &invitation.AcceptedAt.Time
+	}
+	if invitation.RevokedAt.Valid {
+		rsp.RevokedAt = &invitation.RevokedAt.Time
+	}
+	return rsp
+}
+
+// createInvitation lets company owners and admins invite a teammate.
+// The invitation token is only ever returned in this response.
+func (server *Server) createInvitation(ctx *gin.Context) {
+	var req createInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can invite users")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	invitationToken, err := util.NewSecret(invitationTokenSize)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	duration := defaultInvitationDuration
+	if req.ExpiresInHours > 0 {
+		duration = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	arg := db.CreateInvitationParams{
+		CompanyID: authPayload.CompanyID,
+		InvitedBy: authPayload.UserID,
+		Role:      req.Role,
+		Phone:     sql.NullString{String: req.Phone, Valid: req.Phone != ""},
+		Email:     sql.NullString{String: req.Email, Valid: req.Email != ""},
+		TokenHash: util.HashSecret(invitationToken),
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	invitation, err := server.dbStore.CreateInvitation(ctx, arg)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, invitation.ID, nil, newInvitationResponse(invitation))
+
+	rsp := newInvitationResponse(invitation)
+	rsp.Token = invitationToken
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) listInvitations(ctx *gin.Context) {
+	var req listRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can view invitations")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
+	}
+
+	invitations, err := server.dbStore.ListCompanyInvitations(ctx, db.ListCompanyInvitationsParams{
+		CompanyID: authPayload.CompanyID,
+		AfterID:   p.afterID,
+		Limit:     p.limit(),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]invitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		items[i] = newInvitationResponse(invitation)
+	}
+
+	rsp := newListResponse(p, items, func(item invitationResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.dbStore.CountCompanyInvitations(ctx, authPayload.CompanyID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeInvitationURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeInvitation(ctx *gin.Context) {
+	var uri revokeInvitationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can revoke invitations")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	invitation, err := server.dbStore.RevokeInvitation(ctx, db.RevokeInvitationParams{
+		ID:        uri.ID,
+		CompanyID: authPayload.CompanyID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, errors.New("No pending invitation found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, invitation.ID, nil, newInvitationResponse(invitation))
+	ctx.JSON(http.StatusOK, newInvitationResponse(invitation))
+}
+
+type acceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Phone    string `json:"phone" binding:"omitempty,e164"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// acceptInvitation redeems an invitation token, creating the invited user
+// in the inviting company, and sends the new user a code to verify the phone number
+func (server *Server) acceptInvitation(ctx *gin.Context) {
+	var req acceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	arg := db.AcceptInvitationTxParams{
+		TokenHash:    util.HashSecret(req.Token),
+		Name:         req.Name,
+		Phone:        req.Phone,
+		PasswordHash: hashedPassword,
+	}
+
+	result, err := server.dbStore.AcceptInvitationTx(ctx, arg)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			respondError(ctx, http.StatusNotFound, errors.New("Invitation not found"))
+			return
+		case err == db.ErrInvitationUnusable:
+			respondError(ctx, http.StatusGone, err)
+			return
+		case err == db.ErrInvitationPhoneMismatch, err == db.ErrInvitationPhoneRequired:
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		respondServiceError(ctx, err)
+		return
+	}
+
+	company, err := server.dbStore.GetCompanyByID(ctx, result.User.CompanyID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.startVerification(ctx, result.User, company)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/token"
+)
+
+type jwksResponse struct {
+	Keys []token.JSONWebKey `json:"keys"`
+}
+
+// getJWKS publishes the public keys other services verify our tokens with.
+// Symmetric token makers have nothing to publish.
+func (server *Server) getJWKS(ctx *gin.Context) {
+	provider, ok := server.tokenMaker.(token.PublicKeyProvider)
+	if !ok {
+		respondError(ctx, http.StatusNotFound, errors.New("tokens are not signed with public keys"))
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwksResponse{Keys: provider.PublicKeys()})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	"github.com/lenimbugua/bot/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJWKSAPI(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		setupMaker    func(t *testing.T, server *Server)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "PublicKeyMaker",
+			setupMaker: func(t *testing.T, server *Server) {
+				maker, err := token.NewJWTPublicMaker("k1", privateKey, nil)
+				require.NoError(t, err)
+				server.tokenMaker = maker
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp jwksResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Keys, 1)
+				require.Equal(t, "k1", rsp.Keys[0].KeyID)
+				require.Equal(t, "OKP", rsp.Keys[0].KeyType)
+				require.Equal(t, "EdDSA", rsp.Keys[0].Algorithm)
+			},
+		},
+		{
+			name:       "SymmetricMaker",
+			setupMaker: func(t *testing.T, server *Server) {},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			tc.setupMaker(t, server)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
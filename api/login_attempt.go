@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/token"
+)
+
+const accountLockedCode = "account_locked"
+
+// abortLocked answers a login attempt made while the account or the client IP is locked
+func abortLocked(ctx *gin.Context, lockedUntil time.Time) {
+	retryAfter := int64(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	ctx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	message := fmt.Sprintf("too many failed login attempts, try again in %d seconds", retryAfter)
+	respondError(ctx, http.StatusTooManyRequests, newAPIError(accountLockedCode, message))
+}
+
+type unlockUserRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// unlockUser clears the failed login counter of a user in the company of the admin
+func (server *Server) unlockUser(ctx *gin.Context) {
+	var req unlockUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.service.UnlockUser(ctx, authPayload, req.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, nil, nil)
+	ctx.JSON(http.StatusOK, nil)
+}
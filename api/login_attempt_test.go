@@ -0,0 +1,332 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+// allowLoginAttempts lets login tests run without caring about brute force protection
+func allowLoginAttempts(store *mockdb.MockStore) {
+	store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).AnyTimes().Return(db.LoginAttempt{}, sql.ErrNoRows)
+	store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).AnyTimes().Return(db.LoginAttempt{FailedCount: 1}, nil)
+	store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).AnyTimes()
+}
+
+func phoneLoginKey(phone string) string {
+	return "phone:" + phone
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+func TestLoginLockoutAPI(t *testing.T) {
+	company := randomCompany()
+	user, password := randomUser(t, company.ID)
+
+	lockedUntil := sql.NullTime{Time: time.Now().Add(5 * time.Minute), Valid: true}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		forwardedFor  string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "PhoneLocked",
+			body: gin.H{"phone": user.Phone, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginAttempt(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).
+					Times(1).
+					Return(db.LoginAttempt{Key: phoneLoginKey(user.Phone), LockedUntil: lockedUntil}, nil)
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+				retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+				require.NoError(t, err)
+				require.InDelta(t, 300, retryAfter, 2)
+
+				var rsp gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, accountLockedCode, rsp["code"])
+			},
+		},
+		{
+			name: "IPLocked",
+			body: gin.H{"phone": user.Phone, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetLoginAttempt(gomock.Any(), gomock.Eq(ipLoginKey("192.0.2.1"))).
+					Times(1).
+					Return(db.LoginAttempt{LockedUntil: lockedUntil}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			// no proxy is trusted, so the header of the client does not escape the lock of its IP
+			name:         "IPLockedSpoofedHeader",
+			body:         gin.H{"phone": user.Phone, "password": password},
+			forwardedFor: "203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetLoginAttempt(gomock.Any(), gomock.Eq(ipLoginKey("192.0.2.1"))).
+					Times(1).
+					Return(db.LoginAttempt{LockedUntil: lockedUntil}, nil)
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Eq(ipLoginKey("203.0.113.7"))).Times(0)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredLock",
+			body: gin.H{"phone": user.Phone, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true}
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{LockedUntil: expired}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FailureLocksAccount",
+			body: gin.H{"phone": user.Phone, "password": "incorrect"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RecordFailedLoginParams) (db.LoginAttempt, error) {
+						require.Equal(t, phoneLoginKey(user.Phone), arg.Key)
+						require.WithinDuration(t, time.Now().Add(-24*time.Hour), arg.ResetBefore, time.Second)
+						return db.LoginAttempt{Key: arg.Key, FailedCount: 5}, nil
+					})
+				store.EXPECT().
+					LockLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.LockLoginAttemptParams) (db.LoginAttempt, error) {
+						require.Equal(t, phoneLoginKey(user.Phone), arg.Key)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.LockedUntil.Time, time.Second)
+						return db.LoginAttempt{}, nil
+					})
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginAttempt{FailedCount: 1}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownPhoneCounted",
+			body: gin.H{"phone": user.Phone, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{FailedCount: 1}, nil)
+				store.EXPECT().LockLoginAttempt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "GetLoginAttemptInternalError",
+			body: gin.H{"phone": user.Phone, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginAttempt{}, sql.ErrConnDone)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/login"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:1234"
+			if tc.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", tc.forwardedFor)
+				request.Header.Set("X-Real-IP", tc.forwardedFor)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUnlockUserAPI(t *testing.T) {
+	company := randomCompany()
+	admin, _ := randomUser(t, company.ID)
+	admin.ID = util.RandInt(1, 1000)
+	admin.Role = util.AdminRole
+	user, _ := randomUser(t, company.ID)
+	user.ID = admin.ID + 1
+	member := user
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID, admin.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotAdmin",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, member.Phone, member.ID, member.Name, member.CompanyID, member.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(0)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "OtherCompany",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID+1, admin.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID, admin.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidID",
+			userID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID, admin.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			userID: user.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d/unlock", tc.userID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
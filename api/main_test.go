@@ -1,24 +1,32 @@
 package api
 
 import (
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/sso"
 	"github.com/lenimbugua/bot/util"
 	"github.com/stretchr/testify/require"
 )
 
+// testSSOSecretKey encrypts the client secrets of single sign-on connections of test servers
+var testSSOSecretKey = util.RandomString(util.SecretBoxKeySize)
+
 // newTestServer creates a new test server
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
 		TokenSymmetricKey:   util.RandomString(32),
 		AccessTokenDuration: time.Minute,
+		SSOSecretKey:        testSSOSecretKey,
 	}
 	server, err := NewServer(config, store)
 	require.NoError(t, err)
+	// the mock identity providers of the tests are served over http on the loopback
+	server.sso = sso.NewClient(http.DefaultClient)
 	return server
 }
 
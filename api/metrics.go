@@ -0,0 +1,26 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/metrics"
+)
+
+// unmatchedRoute labels the requests matching no route, so that unknown paths do not make a metric each
+const unmatchedRoute = "unmatched"
+
+// metricsMiddleware records the count and latency of the requests by the route they matched,
+// as in /bots/:id, and by status
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	for _, path := range []string{"/healthz", "/bots/1", "/no/such/route"} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		server.router.ServeHTTP(recorder, request)
+	}
+
+	// the metrics are not served by the public API but by a server of their own
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `bot_http_requests_total{method="GET",route="/healthz",status="200"}`)
+	require.Contains(t, body, `bot_http_requests_total{method="GET",route="/bots/:id",status="401"}`)
+	require.Contains(t, body, `bot_http_requests_total{method="GET",route="unmatched",status="404"}`)
+	require.Contains(t, body, `bot_http_request_duration_seconds_bucket{method="GET",route="/healthz",status="200",le="0.005"}`)
+}
@@ -1,50 +1,31 @@
 package api
 
 import (
-	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/util"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
-	authorizationTypeBearer = "bearer"
+	authorizationTypeBearer = service.AuthorizationTypeBearer
+	authorizationTypeAPIKey = service.AuthorizationTypeAPIKey
 	authorizationPayloadKey = "authorization_payload"
 )
 
-// AuthMiddleware creates a gin middleware for authorization
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleware creates a gin middleware for authorization.
+// Requests authenticate with a bearer access token or with a company API key.
+func authMiddleware(svc *service.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
-
-		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-
-		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := svc.Authenticate(ctx, ctx.GetHeader(authorizationHeaderKey))
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			if service.IsUnauthenticated(err) {
+				abortWithError(ctx, http.StatusUnauthorized, err)
+				return
+			}
+			abortWithError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -52,3 +33,8 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// isCompanyAdmin reports whether the role may manage other users of its company
+func isCompanyAdmin(role string) bool {
+	return role == util.OwnerRole || role == util.AdminRole
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes used when DEFAULT_PAGE_SIZE and MAX_PAGE_SIZE are not set
+const (
+	defaultPageSize    = 10
+	defaultMaxPageSize = 100
+)
+
+var errInvalidCursor = newAPIError("invalid_cursor", "cursor is invalid, use the next_cursor of a previous page")
+
+// listRequest holds the pagination parameters shared by every list endpoint.
+// The first page is requested without a cursor; the next ones with the next_cursor of the previous page.
+type listRequest struct {
+	PageSize     int32  `form:"page_size" binding:"omitempty,min=1"`
+	Cursor       string `form:"cursor"`
+	IncludeTotal bool   `form:"include_total"`
+}
+
+// pageCursor is the position after which a page starts. It is encoded opaquely so
+// that clients do not depend on its content.
+// Sorted lists also keep the sort order and the sort key of the last row of the previous page.
+type pageCursor struct {
+	AfterID   int64      `json:"after_id"`
+	Sort      string     `json:"sort,omitempty"`
+	AfterText string     `json:"after_text,omitempty"`
+	AfterTime *time.Time `json:"after_time,omitempty"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+	if encoded == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.AfterID < 0 {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// page is a validated listRequest
+type page struct {
+	afterID   int64
+	afterText string
+	afterTime time.Time
+	sort      string
+	next      bool
+	size      int32
+}
+
+// limit is the number of rows to query: one more than the page size tells whether another page follows
+func (p page) limit() int32 {
+	return p.size + 1
+}
+
+// after is the id after which a sorted page starts, null for the first page
+func (p page) after() sql.NullInt64 {
+	return sql.NullInt64{Int64: p.afterID, Valid: p.next}
+}
+
+// bindPage validates the pagination parameters against the configured page sizes.
+// It answers the request itself when they are invalid.
+func (server *Server) bindPage(ctx *gin.Context, req listRequest) (page, bool) {
+	return server.bindSortedPage(ctx, req, "")
+}
+
+// bindSortedPage is bindPage for a list in the given sort order.
+// A cursor is only valid for the order of the list it comes from.
+func (server *Server) bindSortedPage(ctx *gin.Context, req listRequest, sort string) (page, bool) {
+	size := server.config.DefaultPageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	maxSize := server.config.MaxPageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxPageSize
+	}
+
+	if req.PageSize > maxSize {
+		err := newAPIError(codeValidationFailed, "request is invalid")
+		err.Fields = []fieldError{{
+			Field:   "page_size",
+			Rule:    "max",
+			Message: fmt.Sprintf("must be at most %d", maxSize),
+		}}
+		respondError(ctx, http.StatusBadRequest, err)
+		return page{}, false
+	}
+	if req.PageSize > 0 {
+		size = req.PageSize
+	}
+
+	cursor, err := decodeCursor(req.Cursor)
+	if err == nil && req.Cursor != "" && cursor.Sort != sort {
+		err = errInvalidCursor
+	}
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return page{}, false
+	}
+
+	p := page{
+		afterID:   cursor.AfterID,
+		afterText: cursor.AfterText,
+		sort:      sort,
+		next:      req.Cursor != "",
+		size:      size,
+	}
+	if cursor.AfterTime != nil {
+		p.afterTime = *cursor.AfterTime
+	}
+	return p, true
+}
+
+// listResponse is the envelope of every list endpoint
+type listResponse[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// newListResponse trims the extra row queried by page.limit and turns it into the cursor of the next page
+func newListResponse[T any](p page, items []T, id func(T) int64) listResponse[T] {
+	return newSortedListResponse(p, items, func(item T) pageCursor {
+		return pageCursor{AfterID: id(item)}
+	})
+}
+
+// newSortedListResponse is newListResponse for a sorted list, cursor gives the position of an item in the sort order
+func newSortedListResponse[T any](p page, items []T, cursor func(T) pageCursor) listResponse[T] {
+	rsp := listResponse[T]{Data: items}
+	if rsp.Data == nil {
+		rsp.Data = []T{}
+	}
+
+	if int32(len(items)) > p.size {
+		rsp.Data = items[:p.size]
+		next := cursor(rsp.Data[p.size-1])
+		next.Sort = p.sort
+		rsp.NextCursor = encodeCursor(next)
+	}
+	return rsp
+}
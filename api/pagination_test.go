@@ -0,0 +1,218 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	cursor, err := decodeCursor(encodeCursor(pageCursor{AfterID: 42}))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), cursor.AfterID)
+
+	cursor, err = decodeCursor("")
+	require.NoError(t, err)
+	require.Zero(t, cursor.AfterID)
+
+	for _, encoded := range []string{"not-a-cursor", "%%%", encodeCursor(pageCursor{AfterID: -1})} {
+		_, err = decodeCursor(encoded)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	companyID := sql.NullInt64{Int64: company.ID, Valid: true}
+
+	n := 3
+	bots := make([]db.Bot, n+1)
+	for i := range bots {
+		bots[i] = randomBot(t, company.ID)
+		bots[i].ID = int64(i + 1)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "NextPage",
+			query: fmt.Sprintf("page_size=%d", n),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{CompanyID: companyID, Limit: int32(n) + 1}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bots, nil)
+				store.EXPECT().CountBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Bot]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, bots[:n], rsp.Data)
+				require.Nil(t, rsp.Total)
+
+				cursor, err := decodeCursor(rsp.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, bots[n-1].ID, cursor.AfterID)
+			},
+		},
+		{
+			name:  "FromCursor",
+			query: fmt.Sprintf("page_size=%d&cursor=%s", n, encodeCursor(pageCursor{AfterID: bots[n-1].ID})),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{CompanyID: companyID, AfterID: sql.NullInt64{Int64: bots[n-1].ID, Valid: true}, Limit: int32(n) + 1}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bots[n:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Bot]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, bots[n:], rsp.Data)
+				require.Empty(t, rsp.NextCursor)
+			},
+		},
+		{
+			name:  "DefaultPageSize",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{CompanyID: companyID, Limit: defaultPageSize + 1}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Bot{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"data": []}`, recorder.Body.String())
+			},
+		},
+		{
+			name:  "IncludeTotal",
+			query: fmt.Sprintf("page_size=%d&include_total=true", n),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(1).Return(bots, nil)
+				store.EXPECT().CountBots(gomock.Any(), gomock.Eq(db.CountBotsParams{CompanyID: companyID})).Times(1).Return(int64(len(bots)), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Bot]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotNil(t, rsp.Total)
+				require.Equal(t, int64(len(bots)), *rsp.Total)
+			},
+		},
+		{
+			name:  "CountError",
+			query: "include_total=true",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(1).Return(bots, nil)
+				store.EXPECT().CountBots(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "cursor=not-a-cursor",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp apiError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, errInvalidCursor.Code, rsp.Code)
+			},
+		},
+		{
+			name:  "PageSizeTooLarge",
+			query: fmt.Sprintf("page_size=%d", defaultMaxPageSize+1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp apiError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, codeValidationFailed, rsp.Code)
+				require.Equal(t, "page_size", rsp.Fields[0].Field)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/list/companybots?company_id=%d&%s", company.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfiguredPageSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t, util.RandInt(1, 100))
+	store := mockdb.NewMockStore(ctrl)
+	allowAuthUserLookup(store)
+	arg := db.SearchBotsParams{
+		CompanyID: sql.NullInt64{Int64: user.CompanyID, Valid: true},
+		Limit:     3,
+	}
+	store.EXPECT().
+		SearchBots(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return([]db.Bot{}, nil)
+
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		DefaultPageSize:     2,
+		MaxPageSize:         4,
+	}
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	for query, status := range map[string]int{"": http.StatusOK, "page_size=5": http.StatusBadRequest} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/list/bots?"+query, nil)
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, status, recorder.Code)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+var errInvalidResetCode = newAPIError("invalid_reset_code", "reset code is invalid or has expired")
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// changePassword replaces the password of the authenticated user.
+// Every session and access token issued before the change stops working.
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.dbStore.GetUserByID(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = util.CheckPassword(req.OldPassword, user.PasswordHash)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, errors.New("old password is incorrect"))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, err = server.dbStore.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		UserID:       user.ID,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, nil, nil)
+	ctx.JSON(http.StatusOK, nil)
+}
+
+type forgotPasswordRequest struct {
+	Phone string `json:"phone" binding:"required,e164"`
+}
+
+// forgotPassword sends a password reset code to the phone of the user.
+// It answers the same way whether or not the phone is registered, so a request made
+// during the resend cooldown is dropped silently rather than refused.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := server.dbStore.GetUser(ctx, req.Phone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, nil)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = server.otp.Issue(ctx, user, otp.PurposePasswordReset)
+	if err != nil && err != otp.ErrResendCooldown {
+		log.Printf("cannot send password reset code: %v", err)
+		respondError(ctx, http.StatusInternalServerError, errors.New("cannot send reset code"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
+
+type resetPasswordRequest struct {
+	Phone       string `json:"phone" binding:"required,e164"`
+	Code        string `json:"code" binding:"required,numeric"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword sets a new password for the user owning a valid reset code
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := server.dbStore.GetUser(ctx, req.Phone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errInvalidResetCode)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	otpCode, err := server.otp.Verify(ctx, user.ID, otp.PurposePasswordReset, req.Code)
+	if err != nil {
+		if code, ok := otpErrorStatus(err); ok {
+			respondError(ctx, code, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, err = server.dbStore.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		UserID:       user.ID,
+		PasswordHash: hashedPassword,
+		OtpCodeID:    otpCode.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errInvalidResetCode)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,500 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOTPSender records the last message instead of delivering it
+type fakeOTPSender struct {
+	phone   string
+	message string
+}
+
+func (sender *fakeOTPSender) Send(ctx context.Context, phone string, message string) error {
+	sender.phone = phone
+	sender.message = message
+	return nil
+}
+
+func randomOtpCode(t *testing.T, userID int64, purpose string) (otpCode db.OtpCode, code string) {
+	code = util.RandomDigits(6)
+	codeHash, err := util.HashPassword(code)
+	require.NoError(t, err)
+
+	otpCode = db.OtpCode{
+		ID:        util.RandInt(1, 1000),
+		UserID:    userID,
+		Purpose:   purpose,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(time.Minute),
+		CreatedAt: time.Now(),
+	}
+	return
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+	newPassword := util.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(2).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ChangePasswordTxParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Zero(t, arg.OtpCodeID)
+						require.NoError(t, util.CheckPassword(newPassword, arg.PasswordHash))
+						return user, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongOldPassword",
+			body: gin.H{
+				"old_password": "incorrect",
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(2).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TooShortNewPassword",
+			body: gin.H{
+				"old_password": password,
+				"new_password": "123",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByID(gomock.Any(), gomock.Eq(user.ID)).
+					Times(2).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/password"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder, sender *fakeOTPSender)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Phone)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OtpCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOtpCodeParams) (db.OtpCode, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, otp.PurposePasswordReset, arg.Purpose)
+						require.WithinDuration(t, time.Now().Add(otp.DefaultDuration), arg.ExpiresAt, time.Second)
+						return db.OtpCode{CodeHash: arg.CodeHash}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, user.Phone, sender.phone)
+				require.Regexp(t, regexp.MustCompile(`\d{6}`), sender.message)
+			},
+		},
+		{
+			name: "UnknownPhone",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+		{
+			name: "InvalidPhone",
+			body: gin.H{"phone": "invalid"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OtpCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OtpCode{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+		{
+			name: "ResendCooldown",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				otpCode, _ := randomOtpCode(t, user.ID, otp.PurposePasswordReset)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(otpCode, nil)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			sender := &fakeOTPSender{}
+			server.otp = otp.NewManager(store, sender, otp.Config{})
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/password/forgot"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, sender)
+		})
+	}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+	otpCode, code := randomOtpCode(t, user.ID, otp.PurposePasswordReset)
+	newPassword := util.RandomString(8)
+
+	body := gin.H{
+		"phone":        user.Phone,
+		"code":         code,
+		"new_password": newPassword,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				attempted := otpCode
+				attempted.Attempts = 1
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Eq(db.GetLatestOtpCodeParams{UserID: user.ID, Purpose: otp.PurposePasswordReset})).
+					Times(1).
+					Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Eq(otpCode.ID)).Times(1).Return(attempted, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ChangePasswordTxParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, otpCode.ID, arg.OtpCodeID)
+						require.NoError(t, util.CheckPassword(newPassword, arg.PasswordHash))
+						return user, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{
+				"phone":        user.Phone,
+				"code":         "0000000",
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredCode",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := otpCode
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(expired, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				exhausted := otpCode
+				exhausted.Attempts = otp.DefaultMaxAttempts
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(exhausted, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "NoPendingCode",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(db.OtpCode{}, sql.ErrNoRows)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CodeAlreadyConsumed",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().ChangePasswordTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownPhone",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/password/reset"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var errUnsupportedPatch = newAPIError("unsupported_media_type", "patches must be sent as "+mergePatchContentType)
+
+// bindMergePatch binds a JSON Merge Patch (RFC 7396) body into req. The fields of req
+// are pointers, left nil for the members the patch leaves out, and only the members
+// present are validated. None of the patchable fields can be removed, so a member
+// set to null is rejected. It answers the request itself when the patch is invalid.
+func bindMergePatch(ctx *gin.Context, req any) bool {
+	contentType, _, _ := mime.ParseMediaType(ctx.ContentType())
+	if contentType != mergePatchContentType && contentType != binding.MIMEJSON {
+		respondError(ctx, http.StatusUnsupportedMediaType, errUnsupportedPatch)
+		return false
+	}
+
+	if err := ctx.ShouldBindBodyWith(req, binding.JSON); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return false
+	}
+
+	body, _ := ctx.Get(gin.BodyBytesKey)
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body.([]byte), &members); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return false
+	}
+
+	var removed []fieldError
+	for name, value := range members {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			removed = append(removed, fieldError{Field: name, Rule: "not_null", Message: "cannot be removed"})
+		}
+	}
+	if len(removed) > 0 {
+		sort.Slice(removed, func(i, j int) bool { return removed[i].Field < removed[j].Field })
+		err := newAPIError(codeValidationFailed, "request is invalid")
+		err.Fields = removed
+		respondError(ctx, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
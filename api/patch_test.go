@@ -0,0 +1,263 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAPI(t *testing.T) {
+	company := randomCompany()
+	owner, admin, member := randomCompanyUsers(t, company.ID)
+	bot := randomBot(t, company.ID)
+	channel := randomChannel()
+
+	testCases := []struct {
+		name          string
+		url           string
+		contentType   string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "BotTitleOnly",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `{"title": "renamed"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				renamed := bot
+				renamed.Title = "renamed"
+
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				arg := db.UpdateBotParams{
+					Title: sql.NullString{String: "renamed", Valid: true},
+					ID:    bot.ID,
+				}
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(renamed, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.Bot
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, "renamed", rsp.Title)
+				require.Equal(t, bot.CompanyID, rsp.CompanyID)
+			},
+		},
+		{
+			name:        "BotJSONContentType",
+			url:         fmt.Sprintf("/bots/%d", bot.ID),
+			contentType: "application/json",
+			body:        `{"company_id": ` + fmt.Sprint(company.ID) + `}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				arg := db.UpdateBotParams{
+					CompanyID: sql.NullInt64{Int64: company.ID, Valid: true},
+					ID:        bot.ID,
+				}
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BotOfOtherCompany",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `{"title": "renamed"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := bot
+				other.CompanyID = company.ID + 1
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(other, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BotMovedToOtherCompany",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `{"company_id": ` + fmt.Sprint(company.ID+1) + `}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BotEmptyTitle",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `{"title": ""}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BotRemovedTitle",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `{"title": null}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp apiError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, codeValidationFailed, rsp.Code)
+				require.Equal(t, []fieldError{{Field: "title", Rule: "not_null", Message: "cannot be removed"}}, rsp.Fields)
+			},
+		},
+		{
+			name:        "UnsupportedContentType",
+			url:         fmt.Sprintf("/bots/%d", bot.ID),
+			contentType: "text/plain",
+			body:        `{"title": "renamed"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name: "NotAnObject",
+			url:  fmt.Sprintf("/bots/%d", bot.ID),
+			body: `["title"]`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CompanyEmailOnly",
+			url:  fmt.Sprintf("/companies/%d", company.ID),
+			body: `{"email": "support@example.com"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				arg := db.UpdateCompanyParams{
+					Email: sql.NullString{String: "support@example.com", Valid: true},
+					ID:    company.ID,
+				}
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Eq(arg)).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CompanyInvalidPhone",
+			url:  fmt.Sprintf("/companies/%d", company.ID),
+			body: `{"phone": "0712"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ChannelName",
+			url:  fmt.Sprintf("/channels/%d", channel.ID),
+			body: `{"name": "sms"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetChannelByID(gomock.Any(), gomock.Eq(channel.ID)).Times(1).Return(channel, nil)
+				arg := db.UpdateChannelParams{
+					Name: sql.NullString{String: "sms", Valid: true},
+					ID:   channel.ID,
+				}
+				store.EXPECT().UpdateChannel(gomock.Any(), gomock.Eq(arg)).Times(1).Return(channel, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserRoleOnly",
+			url:  fmt.Sprintf("/users/%d", member.ID),
+			body: `{"role": "viewer"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				arg := db.UpdateUserParams{
+					Role: sql.NullString{String: util.ViewerRole, Valid: true},
+					ID:   member.ID,
+				}
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(member, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserInvalidRole",
+			url:  fmt.Sprintf("/users/%d", member.ID),
+			body: `{"role": "root"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UserOwnerByAdmin",
+			url:  fmt.Sprintf("/users/%d", owner.ID),
+			body: `{"name": "renamed"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(owner.ID)).Times(1).Return(owner, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPatch, tc.url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = mergePatchContentType
+			}
+			request.Header.Set("Content-Type", contentType)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID, admin.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomQuestion(botID int64) db.Question {
+	return db.Question{
+		ID:        util.RandInt(1, 1000),
+		Question:  util.RandomString(12),
+		Type:      util.RandomString(6),
+		BotID:     botID,
+		CreatedAt: time.Now().Truncate(time.Second),
+		UpdatedAt: time.Now().Truncate(time.Second),
+	}
+}
+
+func requireBodyMatchQuestion(t *testing.T, body *bytes.Buffer, question db.Question) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotQuestion db.Question
+	err = json.Unmarshal(data, &gotQuestion)
+	require.NoError(t, err)
+	require.Equal(t, question.ID, gotQuestion.ID)
+	require.Equal(t, question.Question, gotQuestion.Question)
+	require.Equal(t, question.BotID, gotQuestion.BotID)
+}
+
+func TestDeleteQuestionAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	question := randomQuestion(bot.ID)
+	user, _ := randomUser(t, company.ID)
+
+	testcases := []struct {
+		name           string
+		questionID     int64
+		companyID      int64
+		ifMatch        string
+		buildStub      func(store *mockdb.MockStore)
+		checkResponses func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			questionID: question.ID,
+			companyID:  company.ID,
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteQuestionParams{ID: question.ID}
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(question, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "IfMatch",
+			questionID: question.ID,
+			companyID:  company.ID,
+			ifMatch:    service.ETag(question.UpdatedAt),
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteQuestionParams{
+					ID:        question.ID,
+					UpdatedAt: sql.NullTime{Time: question.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(question, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "VersionMismatch",
+			questionID: question.ID,
+			companyID:  company.ID,
+			ifMatch:    `"stale"`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:       "ChangedConcurrently",
+			questionID: question.ID,
+			companyID:  company.ID,
+			ifMatch:    service.ETag(question.UpdatedAt),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Any()).Times(1).Return(db.Question{}, sql.ErrNoRows)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:       "OtherCompany",
+			questionID: question.ID,
+			companyID:  company.ID + 1,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			questionID: question.ID,
+			companyID:  company.ID,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			questionID: 0,
+			companyID:  company.ID,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testcases {
+		testcase := testcases[i]
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/questions/%d", testcase.questionID)
+
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require := require.New(t)
+			require.NoError(err)
+			if testcase.ifMatch != "" {
+				request.Header.Set(ifMatchHeader, testcase.ifMatch)
+			}
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, testcase.companyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			testcase.checkResponses(recorder)
+		})
+	}
+}
+
+func TestPatchQuestionAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	question := randomQuestion(bot.ID)
+	user, _ := randomUser(t, company.ID)
+	renamed := question
+	renamed.Question = "renamed"
+	renamed.UpdatedAt = question.UpdatedAt.Add(time.Second)
+	arg := db.UpdateQuestionParams{
+		Question:  sql.NullString{String: renamed.Question, Valid: true},
+		ID:        question.ID,
+		UpdatedAt: sql.NullTime{Time: question.UpdatedAt, Valid: true},
+	}
+
+	testcases := []struct {
+		name           string
+		companyID      int64
+		ifMatch        string
+		body           string
+		buildStub      func(store *mockdb.MockStore)
+		checkResponses func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			companyID: company.ID,
+			ifMatch:   service.ETag(question.UpdatedAt),
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(renamed, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, service.ETag(renamed.UpdatedAt), recorder.Header().Get("ETag"))
+				requireBodyMatchQuestion(t, recorder.Body, renamed)
+			},
+		},
+		{
+			name:      "AnyVersion",
+			companyID: company.ID,
+			ifMatch:   "*",
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				any := arg
+				any.UpdatedAt = sql.NullTime{}
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Eq(any)).Times(1).Return(renamed, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "VersionMismatch",
+			companyID: company.ID,
+			ifMatch:   `"stale"`,
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:      "ChangedConcurrently",
+			companyID: company.ID,
+			ifMatch:   service.ETag(question.UpdatedAt),
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingIfMatch",
+			companyID: company.ID,
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:      "NullMember",
+			companyID: company.ID,
+			ifMatch:   service.ETag(question.UpdatedAt),
+			body:      `{"question": null}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "OtherCompany",
+			companyID: company.ID + 1,
+			ifMatch:   service.ETag(question.UpdatedAt),
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			companyID: company.ID,
+			ifMatch:   service.ETag(question.UpdatedAt),
+			body:      `{"question": "renamed"}`,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testcases {
+		testcase := testcases[i]
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/questions/%d", question.ID)
+
+			request, err := http.NewRequest(http.MethodPatch, url, strings.NewReader(testcase.body))
+			require := require.New(t)
+			require.NoError(err)
+			request.Header.Set("Content-Type", mergePatchContentType)
+			if testcase.ifMatch != "" {
+				request.Header.Set(ifMatchHeader, testcase.ifMatch)
+			}
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, testcase.companyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			testcase.checkResponses(recorder)
+		})
+	}
+}
+
+func TestRestoreQuestionAPI(t *testing.T) {
+	company := randomCompany()
+	bot := randomBot(t, company.ID)
+	question := randomQuestion(bot.ID)
+	user, _ := randomUser(t, company.ID)
+
+	testcases := []struct {
+		name           string
+		questionID     int64
+		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStub      func(store *mockdb.MockStore)
+		checkResponses func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			questionID: question.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, service.ETag(question.UpdatedAt), recorder.Header().Get(etagHeader))
+				requireBodyMatchQuestion(t, recorder.Body, question)
+			},
+		},
+		{
+			name:       "NotDeleted",
+			questionID: question.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "BotDeleted",
+			questionID: question.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrNoRows)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "OtherCompany",
+			questionID: question.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID+1, user.Role, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "MissingScope",
+			questionID: question.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, util.ViewerRole, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponses: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testcases {
+		testcase := testcases[i]
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testcase.buildStub(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/questions/%d/restore", testcase.questionID)
+
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require := require.New(t)
+			require.NoError(err)
+			testcase.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			testcase.checkResponses(recorder)
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/lenimbugua/bot/db/sqlc"
-	"github.com/lib/pq"
+	"github.com/lenimbugua/bot/token"
 )
 
 type createQuestionRequest struct {
@@ -20,7 +21,7 @@ type createQuestionRequest struct {
 func (server *Server) createQuestion(ctx *gin.Context) {
 	var req createQuestionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -31,20 +32,102 @@ func (server *Server) createQuestion(ctx *gin.Context) {
 		BotID:          req.BotID,
 		NextQuestionID: req.NextQuestionID,
 	}
-	company, err := server.dbStore.CreateQuestion(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	question, err := server.service.CreateQuestion(ctx, authPayload, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondServiceError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, company)
+	ctx.JSON(http.StatusOK, question)
+}
+
+type questionRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type patchQuestionRequest struct {
+	Question       *string `json:"question" binding:"omitempty,min=1"`
+	Type           *string `json:"type" binding:"omitempty,min=1"`
+	ParentID       *int64  `json:"parent_id" binding:"omitempty,min=0"`
+	NextQuestionID *int64  `json:"next_question_id" binding:"omitempty,min=0"`
+}
+
+// patchQuestion applies a JSON Merge Patch to a question of a bot of the authenticated
+// company. The If-Match header must give the version the patch was made to.
+func (server *Server) patchQuestion(ctx *gin.Context) {
+	var uri questionRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var req patchQuestionRequest
+	if !bindMergePatch(ctx, &req) {
+		return
+	}
+
+	arg := db.UpdateQuestionParams{ID: uri.ID}
+	if req.Question != nil {
+		arg.Question = sql.NullString{String: *req.Question, Valid: true}
+	}
+	if req.Type != nil {
+		arg.Type = sql.NullString{String: *req.Type, Valid: true}
+	}
+	if req.ParentID != nil {
+		arg.ParentID = sql.NullInt64{Int64: *req.ParentID, Valid: true}
+	}
+	if req.NextQuestionID != nil {
+		arg.NextQuestionID = sql.NullInt64{Int64: *req.NextQuestionID, Valid: true}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	before, question, err := server.service.UpdateQuestion(ctx, authPayload, arg, ifMatch(ctx))
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	setAuditEntity(ctx, question.ID, before, question)
+	setETag(ctx, question.UpdatedAt)
+	ctx.JSON(http.StatusOK, question)
+}
+
+// deleteQuestion soft deletes a question of a bot of the authenticated company.
+// With an If-Match header it only deletes the versions listed.
+func (server *Server) deleteQuestion(ctx *gin.Context) {
+	var req questionRequestURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	question, err := server.service.DeleteQuestion(ctx, authPayload, req.ID, ifMatch(ctx))
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	setAuditEntity(ctx, question.ID, question, nil)
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// restoreQuestion brings back a deleted question of a bot of the authenticated company
+func (server *Server) restoreQuestion(ctx *gin.Context) {
+	var req questionRequestURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	question, err := server.service.RestoreQuestion(ctx, authPayload, req.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	setAuditEntity(ctx, question.ID, nil, question)
+	setETag(ctx, question.UpdatedAt)
+	ctx.JSON(http.StatusOK, question)
 }
 
 // type getCompanyByEmailRequest struct {
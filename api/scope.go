@@ -0,0 +1,25 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/token"
+)
+
+// requireScope creates a gin middleware that rejects principals missing any of the scopes.
+// It must run after authMiddleware.
+func requireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		for _, scope := range scopes {
+			if !authPayload.HasScope(scope) {
+				err := fmt.Errorf("missing required scope %s", scope)
+				abortWithError(ctx, http.StatusForbidden, err)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
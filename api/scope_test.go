@@ -0,0 +1,203 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScopeAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	bot := randomBot(t, company.ID)
+	apiKey, key := randomAPIKey(t, company.ID)
+
+	addToken := func(t *testing.T, request *http.Request, tokenMaker token.Maker, role string, scopes []string) {
+		accessToken, _, err := tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, role, scopes, time.Minute)
+		require.NoError(t, err)
+		testUserRoles[user.ID] = role
+		request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ViewerCanRead",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addToken(t, request, tokenMaker, util.ViewerRole, service.RoleScopes(util.ViewerRole))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ViewerCannotDelete",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addToken(t, request, tokenMaker, util.ViewerRole, service.RoleScopes(util.ViewerRole))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingScope",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addToken(t, request, tokenMaker, util.MemberRole, []string{token.ScopeChannelsRead})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "TokenWithoutScopesUsesRole",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addToken(t, request, tokenMaker, util.MemberRole, nil)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Eq(db.SoftDeleteBotParams{ID: bot.ID})).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "APIKeyCanRead",
+			method: http.MethodGet,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", "ApiKey", key))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchApiKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "APIKeyCannotDelete",
+			method: http.MethodDelete,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", "ApiKey", key))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchApiKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1)
+				store.EXPECT().DeleteBotTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/bots/%d", bot.ID)
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAccountRouteScopesAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	user.Role = util.OwnerRole
+
+	testCases := []struct {
+		method string
+		url    string
+	}{
+		{method: http.MethodPost, url: "/users/password"},
+		{method: http.MethodPost, url: "/users/1/unlock"},
+		{method: http.MethodPost, url: "/users/totp/enroll"},
+		{method: http.MethodPost, url: "/users/totp/enable"},
+		{method: http.MethodPost, url: "/users/totp/disable"},
+		{method: http.MethodPost, url: "/invitations"},
+		{method: http.MethodGet, url: "/invitations"},
+		{method: http.MethodDelete, url: "/invitations/1"},
+		{method: http.MethodPost, url: "/api-keys"},
+		{method: http.MethodGet, url: "/api-keys"},
+		{method: http.MethodDelete, url: "/api-keys/1"},
+		{method: http.MethodPost, url: "/sso/connections"},
+		{method: http.MethodGet, url: "/sso/connections"},
+		{method: http.MethodDelete, url: "/sso/connections/1"},
+		{method: http.MethodGet, url: "/audit-logs"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// only the user behind the token is looked up, the handlers never run
+			store := mockdb.NewMockStore(ctrl)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			// an owner whose token only grants reading bots, as a dashboard token would
+			accessToken, _, err := server.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, []string{token.ScopeBotsRead}, time.Minute)
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// searchRequest holds the filters shared by the searchable list endpoints.
+// Dates are in RFC 3339 format, a range includes its start and excludes its end.
+type searchRequest struct {
+	listRequest
+	Query         string    `form:"q" binding:"omitempty,max=100"`
+	CreatedAfter  time.Time `form:"created_after"`
+	CreatedBefore time.Time `form:"created_before"`
+	UpdatedAfter  time.Time `form:"updated_after"`
+	UpdatedBefore time.Time `form:"updated_before"`
+}
+
+// likeEscaper escapes the wildcards of LIKE so that a search only matches the text typed
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchPattern is the ILIKE pattern matching the values that contain the query, null when there is no query
+func (req searchRequest) searchPattern() sql.NullString {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: "%" + likeEscaper.Replace(query) + "%", Valid: true}
+}
+
+// nullTime is null for a date filter that was not given
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// sortKey is the column a sort order is on, without its direction
+func sortKey(sort string) string {
+	return strings.TrimPrefix(sort, "-")
+}
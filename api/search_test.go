@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchBotsAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	companyID := sql.NullInt64{Int64: company.ID, Valid: true}
+
+	n := 2
+	bots := make([]db.Bot, n+1)
+	for i := range bots {
+		bots[i] = randomBot(t, company.ID)
+		bots[i].ID = int64(i + 1)
+		bots[i].UpdatedAt = time.Date(2026, 10, 19, 10, i, 0, 123456000, time.UTC)
+	}
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Filters",
+			query: url.Values{
+				"q":              {" 50%_off "},
+				"created_after":  {from.Format(time.RFC3339)},
+				"created_before": {to.Format(time.RFC3339)},
+				"updated_after":  {from.Format(time.RFC3339)},
+				"updated_before": {to.Format(time.RFC3339)},
+				"include_total":  {"true"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				search := sql.NullString{String: `%50\%\_off%`, Valid: true}
+				arg := db.SearchBotsParams{
+					CompanyID:     companyID,
+					Search:        search,
+					CreatedAfter:  sql.NullTime{Time: from, Valid: true},
+					CreatedBefore: sql.NullTime{Time: to, Valid: true},
+					UpdatedAfter:  sql.NullTime{Time: from, Valid: true},
+					UpdatedBefore: sql.NullTime{Time: to, Valid: true},
+					Limit:         defaultPageSize + 1,
+				}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bots, nil)
+
+				countArg := db.CountBotsParams{
+					CompanyID:     companyID,
+					Search:        search,
+					CreatedAfter:  sql.NullTime{Time: from, Valid: true},
+					CreatedBefore: sql.NullTime{Time: to, Valid: true},
+					UpdatedAfter:  sql.NullTime{Time: from, Valid: true},
+					UpdatedBefore: sql.NullTime{Time: to, Valid: true},
+				}
+				store.EXPECT().CountBots(gomock.Any(), gomock.Eq(countArg)).Times(1).Return(int64(len(bots)), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Bot]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Data, len(bots))
+				require.Equal(t, int64(len(bots)), *rsp.Total)
+			},
+		},
+		{
+			name:  "SortedNextPage",
+			query: url.Values{"sort": {"-updated_at"}, "page_size": {fmt.Sprint(n)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{CompanyID: companyID, Sort: "-updated_at", Limit: int32(n) + 1}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bots, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Bot]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Data, n)
+
+				cursor, err := decodeCursor(rsp.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, "-updated_at", cursor.Sort)
+				require.Equal(t, bots[n-1].ID, cursor.AfterID)
+				require.True(t, bots[n-1].UpdatedAt.Equal(*cursor.AfterTime))
+			},
+		},
+		{
+			name: "SortedFromCursor",
+			query: url.Values{
+				"sort":   {"title"},
+				"cursor": {encodeCursor(pageCursor{AfterID: bots[0].ID, Sort: "title", AfterText: bots[0].Title})},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchBotsParams{
+					CompanyID: companyID,
+					AfterID:   sql.NullInt64{Int64: bots[0].ID, Valid: true},
+					Sort:      "title",
+					AfterText: bots[0].Title,
+					Limit:     defaultPageSize + 1,
+				}
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bots[1:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CursorOfOtherSort",
+			query: url.Values{
+				"sort":   {"title"},
+				"cursor": {encodeCursor(pageCursor{AfterID: bots[0].ID})},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp apiError
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, errInvalidCursor.Code, rsp.Code)
+			},
+		},
+		{
+			name:  "InvalidSort",
+			query: url.Values{"sort": {"company_id"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDate",
+			query: url.Values{"created_after": {"yesterday"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchBots(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			tc.query.Set("company_id", fmt.Sprint(company.ID))
+			request, err := http.NewRequest(http.MethodGet, "/list/companybots?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestSearchCompaniesAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "SearchNameAndEmail",
+			query: url.Values{"q": {company.Name}, "sort": {"-email"}, "page_size": {"1"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchCompaniesParams{
+					ID:     sql.NullInt64{Int64: company.ID, Valid: true},
+					Search: sql.NullString{String: "%" + company.Name + "%", Valid: true},
+					Sort:   "-email",
+					Limit:  2,
+				}
+				store.EXPECT().SearchCompanies(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Company{company, randomCompany()}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listResponse[db.Company]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.Data, 1)
+
+				cursor, err := decodeCursor(rsp.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, pageCursor{AfterID: company.ID, Sort: "-email", AfterText: company.Email}, cursor)
+			},
+		},
+		{
+			name:  "SortByTitle",
+			query: url.Values{"sort": {"title"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchCompanies(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "QueryTooLong",
+			query: url.Values{"q": {string(make([]byte, 101))}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchCompanies(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/list/companies?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,83 +1,223 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lenimbugua/bot/db/migrator"
 	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/sso"
 	"github.com/lenimbugua/bot/token"
 	"github.com/lenimbugua/bot/util"
 )
 
+// Timeouts used when HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT and HTTP_IDLE_TIMEOUT are not set
+const (
+	defaultReadTimeout  = 15 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	defaultIdleTimeout  = 2 * time.Minute
+)
+
 // server serves HTTP  requests for bot
 type Server struct {
 	config     util.Config
 	dbStore    db.Store
 	tokenMaker token.Maker
+	service    *service.Service
+	otp        *otp.Manager
+	sso        *sso.Client
+	ssoSecrets *util.SecretBox
+	lookupTXT  sso.LookupTXT
 	router     *gin.Engine
+	httpServer *http.Server
+	checks     []readinessCheck
+	// draining is set once the server is shutting down, /readyz then reports not ready
+	draining atomic.Bool
 }
 
 // Newserver creates a new HTTP server and sets up routing
 func NewServer(config util.Config, dbStore db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := token.NewMakerFromConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot Create token %w", err)
 	}
+	otpSender, err := otp.NewSenderFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot Create otp sender %w", err)
+	}
+	otpManager := otp.NewManager(dbStore, otpSender, otp.Config{
+		Length:         config.OTPLength,
+		Duration:       config.OTPDuration,
+		MaxAttempts:    config.OTPMaxAttempts,
+		ResendCooldown: config.OTPResendCooldown,
+	})
 	server := &Server{
 		dbStore:    dbStore,
 		tokenMaker: tokenMaker,
+		service:    service.New(config, dbStore, tokenMaker),
+		otp:        otpManager,
+		sso:        sso.NewPublicClient(10 * time.Second),
+		lookupTXT:  net.DefaultResolver.LookupTXT,
 		config:     config,
 	}
-	server.setupRouter()
+	// without a key single sign-on connections cannot be created, which leaves it off
+	if config.SSOSecretKey != "" {
+		server.ssoSecrets, err = util.NewSecretBox(config.SSOSecretKey)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot Create sso secret box %w", err)
+		}
+	}
+	server.AddReadinessCheck("database", dbStore.Ping)
+	if config.MigrationURL != "" {
+		version, err := migrator.LatestVersion(config.MigrationURL)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read migrations %w", err)
+		}
+		server.AddReadinessCheck("migrations", server.checkMigrations(version))
+	}
+	err = server.setupRouter()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot set up router %w", err)
+	}
+	server.httpServer = &http.Server{
+		Handler:           server.router,
+		ReadHeaderTimeout: durationOr(config.HTTPReadTimeout, defaultReadTimeout),
+		ReadTimeout:       durationOr(config.HTTPReadTimeout, defaultReadTimeout),
+		WriteTimeout:      durationOr(config.HTTPWriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       durationOr(config.HTTPIdleTimeout, defaultIdleTimeout),
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
-	router.POST("/users", server.createUser)
+	// the client IP counts failed logins and is written to the audit log, so the headers
+	// giving it are only believed from the proxies of the configuration
+	err := router.SetTrustedProxies(server.config.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	// the context of a request carries its values, the transaction of the audit middleware among them
+	router.ContextWithFallback = true
+	router.Use(requestIDMiddleware(), metricsMiddleware())
+	router.GET("/healthz", server.liveness)
+	router.GET("/readyz", server.readiness)
+	router.POST("/users/signup", server.signup)
 	router.POST("/users/login", server.loginUser)
-
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
-	authRoutes.POST("/channels", server.createChannel)
-	authRoutes.GET("/channels/:name", server.getChannel)
-	authRoutes.GET("/list/channels", server.listChannels)
-	authRoutes.PUT("/channels/:id", server.updateChannel)
-	authRoutes.DELETE("/channels/:id", server.deleteChannel)
-
-	authRoutes.POST("/companies", server.createCompany)
+	router.POST("/users/login/mfa", server.loginMFA)
+	router.POST("/users/password/forgot", server.forgotPassword)
+	router.POST("/users/password/reset", server.resetPassword)
+	router.POST("/users/verify", server.verifyPhone)
+	router.POST("/users/verify/send", server.sendVerificationCode)
+	router.POST("/invitations/accept", server.acceptInvitation)
+	router.GET("/.well-known/jwks.json", server.getJWKS)
+	router.GET("/sso/login", server.ssoLogin)
+	router.GET("/sso/callback", server.ssoCallback)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.service))
+	authRoutes.POST("/users/password", requireScope(token.ScopeAccountWrite), server.audit("user.change_password"), server.changePassword)
+	authRoutes.POST("/users/:id/unlock", requireScope(token.ScopeUsersWrite), server.audit("user.unlock"), server.unlockUser)
+	authRoutes.GET("/users", requireScope(token.ScopeUsersRead), server.listUsers)
+	authRoutes.GET("/users/:id", requireScope(token.ScopeUsersRead), server.getUser)
+	authRoutes.PUT("/users/:id", requireScope(token.ScopeUsersWrite), server.audit("user.update"), server.updateUser)
+	authRoutes.PATCH("/users/:id", requireScope(token.ScopeUsersWrite), server.audit("user.update"), server.patchUser)
+	authRoutes.DELETE("/users/:id", requireScope(token.ScopeUsersWrite), server.audit("user.delete"), server.deleteUser)
+	authRoutes.POST("/users/:id/deactivate", requireScope(token.ScopeUsersWrite), server.audit("user.deactivate"), server.deactivateUser)
+	authRoutes.POST("/users/:id/reactivate", requireScope(token.ScopeUsersWrite), server.audit("user.reactivate"), server.reactivateUser)
+	authRoutes.POST("/users/totp/enroll", requireScope(token.ScopeAccountWrite), server.audit("user.enroll_totp"), server.enrollTOTP)
+	authRoutes.POST("/users/totp/enable", requireScope(token.ScopeAccountWrite), server.audit("user.enable_totp"), server.enableTOTP)
+	authRoutes.POST("/users/totp/disable", requireScope(token.ScopeAccountWrite), server.audit("user.disable_totp"), server.disableTOTP)
+	authRoutes.POST("/invitations", requireScope(token.ScopeUsersWrite), server.audit("invitation.create"), server.createInvitation)
+	authRoutes.GET("/invitations", requireScope(token.ScopeUsersRead), server.listInvitations)
+	authRoutes.DELETE("/invitations/:id", requireScope(token.ScopeUsersWrite), server.audit("invitation.revoke"), server.revokeInvitation)
+	authRoutes.POST("/api-keys", requireScope(token.ScopeAPIKeysWrite), server.audit("api_key.create"), server.createAPIKey)
+	authRoutes.GET("/api-keys", requireScope(token.ScopeAPIKeysRead), server.listAPIKeys)
+	authRoutes.DELETE("/api-keys/:id", requireScope(token.ScopeAPIKeysWrite), server.audit("api_key.revoke"), server.revokeAPIKey)
+	authRoutes.POST("/sso/domains", requireScope(token.ScopeSSOWrite), server.audit("sso_domain.create"), server.createSSODomain)
+	authRoutes.GET("/sso/domains", requireScope(token.ScopeSSORead), server.listSSODomains)
+	authRoutes.POST("/sso/domains/:id/verify", requireScope(token.ScopeSSOWrite), server.audit("sso_domain.verify"), server.verifySSODomain)
+	authRoutes.DELETE("/sso/domains/:id", requireScope(token.ScopeSSOWrite), server.audit("sso_domain.delete"), server.deleteSSODomain)
+	authRoutes.POST("/sso/connections", requireScope(token.ScopeSSOWrite), server.audit("sso_connection.create"), server.createSSOConnection)
+	authRoutes.GET("/sso/connections", requireScope(token.ScopeSSORead), server.listSSOConnections)
+	authRoutes.DELETE("/sso/connections/:id", requireScope(token.ScopeSSOWrite), server.audit("sso_connection.delete"), server.deleteSSOConnection)
+	authRoutes.GET("/audit-logs", requireScope(token.ScopeAuditRead), server.listAuditLogs)
+
+	authRoutes.POST("/channels", requireScope(token.ScopeChannelsWrite), server.audit("channel.create"), server.createChannel)
+	authRoutes.GET("/channels/:name", requireScope(token.ScopeChannelsRead), server.getChannel)
+	authRoutes.GET("/list/channels", requireScope(token.ScopeChannelsRead), server.listChannels)
+	authRoutes.PUT("/channels/:id", requireScope(token.ScopeChannelsWrite), server.audit("channel.update"), server.updateChannel)
+	authRoutes.PATCH("/channels/:id", requireScope(token.ScopeChannelsWrite), server.audit("channel.update"), server.patchChannel)
+	authRoutes.DELETE("/channels/:id", requireScope(token.ScopeChannelsWrite), server.audit("channel.delete"), server.deleteChannel)
+
+	authRoutes.POST("/companies", requireScope(token.ScopeCompaniesWrite), server.audit("company.create"), server.createCompany)
 
 	//getCompanyByEmail uses query string
-	authRoutes.GET("/companies", server.getCompanyByEmail)
+	authRoutes.GET("/companies", requireScope(token.ScopeCompaniesRead), server.getCompanyByEmail)
 	//getCompanyByID uses query uri
-	authRoutes.GET("/companies/:id", server.getCompanyByID)
+	authRoutes.GET("/companies/:id", requireScope(token.ScopeCompaniesRead), server.getCompanyByID)
 
-	authRoutes.GET("/list/companies", server.listCompanies)
+	authRoutes.GET("/list/companies", requireScope(token.ScopeCompaniesRead), server.listCompanies)
 
-	authRoutes.PUT("/companies/:id", server.updateCompany)
+	authRoutes.PUT("/companies/:id", requireScope(token.ScopeCompaniesWrite), server.audit("company.update"), server.updateCompany)
+	authRoutes.PATCH("/companies/:id", requireScope(token.ScopeCompaniesWrite), server.audit("company.update"), server.patchCompany)
 
-	authRoutes.DELETE("/companies/:id", server.deleteCompany)
+	authRoutes.DELETE("/companies/:id", requireScope(token.ScopeCompaniesWrite), server.audit("company.delete"), server.deleteCompany)
 
-	authRoutes.POST("/bots", server.createBot)
-	authRoutes.GET("/bots/:id", server.getBot)
-	authRoutes.PUT("/bots/:id", server.updateBot)
-	authRoutes.DELETE("/bots/:id", server.deleteBot)
-	authRoutes.GET("/list/bots", server.listBots)
-	authRoutes.GET("/list/companybots", server.listCompanyBots)
+	authRoutes.POST("/bots", requireScope(token.ScopeBotsWrite), server.audit("bot.create"), server.createBot)
+	authRoutes.GET("/bots/:id", requireScope(token.ScopeBotsRead), server.getBot)
+	authRoutes.PUT("/bots/:id", requireScope(token.ScopeBotsWrite), server.audit("bot.update"), server.updateBot)
+	authRoutes.PATCH("/bots/:id", requireScope(token.ScopeBotsWrite), server.audit("bot.update"), server.patchBot)
+	authRoutes.DELETE("/bots/:id", requireScope(token.ScopeBotsWrite), server.audit("bot.delete"), server.deleteBot)
+	authRoutes.POST("/bots/:id/restore", requireScope(token.ScopeBotsWrite), server.audit("bot.restore"), server.restoreBot)
+	authRoutes.GET("/list/bots", requireScope(token.ScopeBotsRead), server.listBots)
+	authRoutes.GET("/list/companybots", requireScope(token.ScopeBotsRead), server.listCompanyBots)
 
-	server.router = router
+	authRoutes.PATCH("/questions/:id", requireScope(token.ScopeQuestionsWrite), server.audit("question.update"), server.patchQuestion)
+	authRoutes.DELETE("/questions/:id", requireScope(token.ScopeQuestionsWrite), server.audit("question.delete"), server.deleteQuestion)
+	authRoutes.POST("/questions/:id/restore", requireScope(token.ScopeQuestionsWrite), server.audit("question.restore"), server.restoreQuestion)
+
+	authRoutes.POST("/conversations", requireScope(token.ScopeConversationsWrite), server.startConversation)
+	authRoutes.POST("/conversations/:session_id/messages", requireScope(token.ScopeConversationsWrite), server.answerConversation)
 
+	server.router = router
+	return nil
 }
 
-// start runs the server on a specific address
+// Start runs the server on a specific address until Shutdown is called
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	server.httpServer.Addr = address
+	err := server.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-// create a basic gin error
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Drain makes /readyz report not ready so that load balancers stop sending requests,
+// which the server still serves until Shutdown
+func (server *Server) Drain() {
+	server.draining.Store(true)
 }
 
-func descriptiveError(err string) gin.H {
-	return gin.H{"error": err}
+// Shutdown drains the server and stops it, waiting for the requests in flight to complete
+// until ctx is done
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.Drain()
+	return server.httpServer.Shutdown(ctx)
+}
+
+// durationOr returns d, or fallback when d is not set
+func durationOr(d time.Duration, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
 }
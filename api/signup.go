@@ -0,0 +1,59 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+)
+
+type signupRequest struct {
+	CompanyName  string `json:"company_name" binding:"required"`
+	CompanyEmail string `json:"company_email" binding:"required,email"`
+	CompanyPhone string `json:"company_phone" binding:"required,e164"`
+	Name         string `json:"name" binding:"required"`
+	Phone        string `json:"phone" binding:"required,e164"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Password     string `json:"password" binding:"required,min=6"`
+}
+
+// signup registers a new company together with its owner and sends the owner
+// a code to verify the phone number before the first login
+func (server *Server) signup(ctx *gin.Context) {
+	var req signupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	arg := db.SignupTxParams{
+		Company: db.CreateCompanyParams{
+			Phone: req.CompanyPhone,
+			Name:  req.CompanyName,
+			Email: req.CompanyEmail,
+		},
+		Owner: db.CreateUserParams{
+			Name:         req.Name,
+			PasswordHash: hashedPassword,
+			Phone:        req.Phone,
+			Role:         util.OwnerRole,
+			Email:        sql.NullString{String: req.Email, Valid: req.Email != ""},
+		},
+	}
+
+	result, err := server.dbStore.SignupTx(ctx, arg)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	server.startVerification(ctx, result.Owner, result.Company)
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type eqSignupTxParamsMatcher struct {
+	arg      db.SignupTxParams
+	password string
+}
+
+func (e eqSignupTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.SignupTxParams)
+	if !ok {
+		return false
+	}
+
+	err := util.CheckPassword(e.password, arg.Owner.PasswordHash)
+	if err != nil {
+		return false
+	}
+
+	e.arg.Owner.PasswordHash = arg.Owner.PasswordHash
+	return reflect.DeepEqual(e.arg, arg)
+}
+
+func (e eqSignupTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
+}
+
+func EqSignupTxParams(arg db.SignupTxParams, password string) gomock.Matcher {
+	return eqSignupTxParamsMatcher{arg, password}
+}
+
+func TestSignupAPI(t *testing.T) {
+	company := randomCompany()
+	owner, password := randomUser(t, company.ID)
+	owner.Role = util.OwnerRole
+
+	body := gin.H{
+		"company_name":  company.Name,
+		"company_email": company.Email,
+		"company_phone": company.Phone,
+		"name":          owner.Name,
+		"phone":         owner.Phone,
+		"password":      password,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SignupTxParams{
+					Company: db.CreateCompanyParams{
+						Phone: company.Phone,
+						Name:  company.Name,
+						Email: company.Email,
+					},
+					Owner: db.CreateUserParams{
+						Name:  owner.Name,
+						Phone: owner.Phone,
+						Role:  util.OwnerRole,
+					},
+				}
+				store.EXPECT().
+					SignupTx(gomock.Any(), EqSignupTxParams(arg, password)).
+					Times(1).
+					Return(db.SignupTxResult{Company: company, Owner: owner}, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OtpCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOtpCodeParams) (db.OtpCode, error) {
+						require.Equal(t, owner.ID, arg.UserID)
+						require.Equal(t, otp.PurposePhoneVerification, arg.Purpose)
+						return db.OtpCode{CodeHash: arg.CodeHash}, nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchSignup(t, recorder.Body, owner, company)
+			},
+		},
+		{
+			name: "DuplicateCompany",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignupTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SignupTxResult{}, &pq.Error{Code: "23505"})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignupTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SignupTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "SendCodeInternalError",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignupTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SignupTxResult{Company: company, Owner: owner}, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OtpCode{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// the owner exists already and can ask for a new code
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchSignup(t, recorder.Body, owner, company)
+			},
+		},
+		{
+			name: "InvalidCompanyEmail",
+			body: gin.H{
+				"company_name":  company.Name,
+				"company_email": "invalid-email",
+				"company_phone": company.Phone,
+				"name":          owner.Name,
+				"phone":         owner.Phone,
+				"password":      password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignupTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooShortPassword",
+			body: gin.H{
+				"company_name":  company.Name,
+				"company_email": company.Email,
+				"company_phone": company.Phone,
+				"name":          owner.Name,
+				"phone":         owner.Phone,
+				"password":      "123",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignupTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// Marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/signup"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireBodyMatchSignup(t *testing.T, body *bytes.Buffer, owner db.User, company db.Company) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp pendingVerificationResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+
+	require.True(t, rsp.VerificationRequired)
+	require.Equal(t, owner.Phone, rsp.User.Phone)
+	require.Equal(t, util.OwnerRole, rsp.User.Role)
+	require.Equal(t, company.ID, rsp.User.Company.ID)
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/sso"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+const (
+	ssoStateSize            = 32
+	defaultSSOStateDuration = 10 * time.Minute
+)
+
+// emailDomain returns the lower case domain of the email address
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// errSSONotConfigured refuses single sign-on while SSO_SECRET_KEY is not set
+var errSSONotConfigured = errors.New("single sign-on is not configured")
+
+// ssoConfig returns the client configuration of the connection, whose client secret
+// is stored encrypted
+func (server *Server) ssoConfig(connection db.SsoConnection) (sso.Config, error) {
+	if server.ssoSecrets == nil {
+		return sso.Config{}, errSSONotConfigured
+	}
+
+	clientSecret, err := server.ssoSecrets.Open(connection.ClientSecret)
+	if err != nil {
+		return sso.Config{}, fmt.Errorf("cannot read the client secret of sso connection %d: %w", connection.ID, err)
+	}
+
+	return sso.Config{
+		ClientID:     connection.ClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  server.config.SSORedirectURL,
+	}, nil
+}
+
+type ssoLoginRequest struct {
+	Email string `form:"email" binding:"required,email"`
+}
+
+// ssoLogin starts a login with the identity provider of the email domain.
+// The browser is redirected to the provider, which sends it back to ssoCallback.
+func (server *Server) ssoLogin(ctx *gin.Context) {
+	var req ssoLoginRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	connection, err := server.dbStore.GetSsoConnectionByDomain(ctx, emailDomain(req.Email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, errors.New("No single sign-on connection for this email domain"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	config, err := server.ssoConfig(connection)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	provider, err := server.sso.Provider(ctx, connection.Issuer)
+	if err != nil {
+		respondError(ctx, http.StatusBadGateway, err)
+		return
+	}
+
+	state, err := util.NewSecret(ssoStateSize)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	nonce, err := util.NewSecret(ssoStateSize)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	verifier, err := sso.NewPKCEVerifier()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	duration := server.config.SSOStateDuration
+	if duration <= 0 {
+		duration = defaultSSOStateDuration
+	}
+
+	_, err = server.dbStore.CreateSsoLoginState(ctx, db.CreateSsoLoginStateParams{
+		State:        state,
+		ConnectionID: connection.ID,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(duration),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, provider.AuthCodeURL(config, state, nonce, verifier))
+}
+
+type ssoCallbackRequest struct {
+	State            string `form:"state" binding:"required"`
+	Code             string `form:"code" binding:"required_without=Error"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// ssoCallback finishes a login started by ssoLogin. The verified email of the
+// id token must belong to the domain of the connection and to a user of its company.
+// The provider stands in for the password only: the rest of the login is the one of
+// loginUser, which asks for the second factor of users who turned it on.
+func (server *Server) ssoCallback(ctx *gin.Context) {
+	var req ssoCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// the state is consumed first so that it cannot be replayed, whatever the outcome
+	loginState, err := server.dbStore.ConsumeSsoLoginState(ctx, req.State)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errors.New("Single sign-on login is unknown, already used or has expired"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if time.Now().After(loginState.ExpiresAt) {
+		respondError(ctx, http.StatusBadRequest, errors.New("Single sign-on login is unknown, already used or has expired"))
+		return
+	}
+
+	if req.Error != "" {
+		err := fmt.Errorf("identity provider refused the login: %s %s", req.Error, req.ErrorDescription)
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	connection, err := server.dbStore.GetSsoConnection(ctx, loginState.ConnectionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errors.New("Single sign-on login is unknown, already used or has expired"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	config, err := server.ssoConfig(connection)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	provider, err := server.sso.Provider(ctx, connection.Issuer)
+	if err != nil {
+		respondError(ctx, http.StatusBadGateway, err)
+		return
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, config, req.Code, loginState.CodeVerifier)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, config, rawIDToken, loginState.Nonce)
+	if err != nil {
+		switch err {
+		case sso.ErrInvalidIDToken, sso.ErrNonceMismatch, sso.ErrEmailMissing, sso.ErrEmailNotProven:
+			respondError(ctx, http.StatusUnauthorized, err)
+		default:
+			respondError(ctx, http.StatusBadGateway, err)
+		}
+		return
+	}
+
+	if emailDomain(claims.Email) != connection.EmailDomain {
+		err := fmt.Errorf("email domain must be %s", connection.EmailDomain)
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	user, err := server.dbStore.GetUserByEmail(ctx, claims.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, errors.New("No user with this email, ask a company admin for an invitation"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if user.CompanyID != connection.CompanyID {
+		err := errors.New("user does not belong to the company of this connection")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	login, err := server.service.LoginSSO(ctx, loginClient(ctx), user)
+	if err != nil {
+		respondLoginError(ctx, err)
+		return
+	}
+	if login.MFARequired() {
+		ctx.JSON(http.StatusOK, newMFARequiredResponse(login))
+		return
+	}
+
+	rsp := newLoginUserResponse(login.Session)
+	rsp.User = newUserResponse(login.User, login.Company)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type createSSOConnectionRequest struct {
+	EmailDomain  string `json:"email_domain" binding:"required,fqdn"`
+	Issuer       string `json:"issuer" binding:"required,url"`
+	ClientID     string `json:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" binding:"required"`
+}
+
+type ssoConnectionResponse struct {
+	ID          int64     `json:"id"`
+	CompanyID   int64     `json:"company_id"`
+	EmailDomain string    `json:"email_domain"`
+	Issuer      string    `json:"issuer"`
+	ClientID    string    `json:"client_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// newSSOConnectionResponse leaves out the client secret
+func newSSOConnectionResponse(connection db.SsoConnection) ssoConnectionResponse {
+	return ssoConnectionResponse{
+		ID:          connection.ID,
+		CompanyID:   connection.CompanyID,
+		EmailDomain: connection.EmailDomain,
+		Issuer:      connection.Issuer,
+		ClientID:    connection.ClientID,
+		CreatedAt:   connection.CreatedAt,
+	}
+}
+
+// createSSOConnection lets company owners and admins log their users in with
+// the identity provider of an email domain the company verified. The issuer must
+// support discovery and be served over https from a public address.
+// The client secret is stored encrypted with SSO_SECRET_KEY and never returned.
+func (server *Server) createSSOConnection(ctx *gin.Context) {
+	var req createSSOConnectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	if server.ssoSecrets == nil {
+		respondError(ctx, http.StatusInternalServerError, errSSONotConfigured)
+		return
+	}
+
+	domain := strings.ToLower(req.EmailDomain)
+	_, err := server.dbStore.GetVerifiedSsoDomain(ctx, db.GetVerifiedSsoDomainParams{
+		CompanyID: authPayload.CompanyID,
+		Domain:    domain,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("email domain must first be verified by the company")
+			respondError(ctx, http.StatusForbidden, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := server.sso.Provider(ctx, req.Issuer); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	clientSecret, err := server.ssoSecrets.Seal(req.ClientSecret)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	connection, err := server.dbStore.CreateSsoConnection(ctx, db.CreateSsoConnectionParams{
+		CompanyID:    authPayload.CompanyID,
+		EmailDomain:  domain,
+		Issuer:       req.Issuer,
+		ClientID:     req.ClientID,
+		ClientSecret: clientSecret,
+	})
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, connection.ID, nil, newSSOConnectionResponse(connection))
+	ctx.JSON(http.StatusOK, newSSOConnectionResponse(connection))
+}
+
+func (server *Server) listSSOConnections(ctx *gin.Context) {
+	var req listRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
+	}
+
+	connections, err := server.dbStore.ListCompanySsoConnections(ctx, db.ListCompanySsoConnectionsParams{
+		CompanyID: authPayload.CompanyID,
+		AfterID:   p.afterID,
+		Limit:     p.limit(),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]ssoConnectionResponse, len(connections))
+	for i, connection := range connections {
+		items[i] = newSSOConnectionResponse(connection)
+	}
+
+	rsp := newListResponse(p, items, func(item ssoConnectionResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.dbStore.CountCompanySsoConnections(ctx, authPayload.CompanyID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type deleteSSOConnectionURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteSSOConnection(ctx *gin.Context) {
+	var uri deleteSSOConnectionURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	connection, err := server.dbStore.DeleteSsoConnection(ctx, db.DeleteSsoConnectionParams{
+		ID:        uri.ID,
+		CompanyID: authPayload.CompanyID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, errors.New("No single sign-on connection found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, connection.ID, newSSOConnectionResponse(connection), nil)
+	ctx.JSON(http.StatusOK, newSSOConnectionResponse(connection))
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/sso"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// ssoDomainTokenSize is the size of the secret a company publishes to verify a domain
+const ssoDomainTokenSize = 24
+
+type createSSODomainRequest struct {
+	Domain string `json:"domain" binding:"required,fqdn"`
+}
+
+type ssoDomainResponse struct {
+	ID         int64      `json:"id"`
+	CompanyID  int64      `json:"company_id"`
+	Domain     string     `json:"domain"`
+	Record     string     `json:"record"`
+	Value      string     `json:"value"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newSSODomainResponse tells the TXT record to publish to verify the domain
+func newSSODomainResponse(domain db.SsoDomain) ssoDomainResponse {
+	rsp := ssoDomainResponse{
+		ID:        domain.ID,
+		CompanyID: domain.CompanyID,
+		Domain:    domain.Domain,
+		Record:    sso.DomainRecord(domain.Domain),
+		Value:     sso.DomainRecordValue(domain.VerificationToken),
+		CreatedAt: domain.CreatedAt,
+	}
+	if domain.VerifiedAt.Valid {
+		rsp.VerifiedAt = &domain.VerifiedAt.Time
+	}
+	return rsp
+}
+
+// createSSODomain claims an email domain for the single sign-on of the company.
+// Connections can only be made for the domain once verifySSODomain found the TXT record
+// of the response, which only the owner of the domain can publish.
+func (server *Server) createSSODomain(ctx *gin.Context) {
+	var req createSSODomainRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	verificationToken, err := util.NewSecret(ssoDomainTokenSize)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	domain, err := server.dbStore.CreateSsoDomain(ctx, db.CreateSsoDomainParams{
+		CompanyID:         authPayload.CompanyID,
+		Domain:            strings.ToLower(req.Domain),
+		VerificationToken: verificationToken,
+	})
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, domain.ID, nil, newSSODomainResponse(domain))
+	ctx.JSON(http.StatusOK, newSSODomainResponse(domain))
+}
+
+type ssoDomainURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// verifySSODomain looks the TXT record of a domain of the company up and marks the domain
+// verified when it holds the verification token. A domain is verified for one company only.
+func (server *Server) verifySSODomain(ctx *gin.Context) {
+	var uri ssoDomainURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	domain, err := server.dbStore.GetCompanySsoDomain(ctx, db.GetCompanySsoDomainParams{
+		ID:        uri.ID,
+		CompanyID: authPayload.CompanyID,
+	})
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	if domain.VerifiedAt.Valid {
+		ctx.JSON(http.StatusOK, newSSODomainResponse(domain))
+		return
+	}
+
+	verified, err := sso.VerifyDomain(ctx, server.lookupTXT, domain.Domain, domain.VerificationToken)
+	if err != nil {
+		respondError(ctx, http.StatusBadGateway, err)
+		return
+	}
+	if !verified {
+		err := errors.New("TXT record " + sso.DomainRecord(domain.Domain) + " does not hold the verification value")
+		respondError(ctx, http.StatusPreconditionFailed, err)
+		return
+	}
+
+	before := newSSODomainResponse(domain)
+	domain, err = server.dbStore.VerifySsoDomain(ctx, domain.ID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, domain.ID, before, newSSODomainResponse(domain))
+	ctx.JSON(http.StatusOK, newSSODomainResponse(domain))
+}
+
+func (server *Server) listSSODomains(ctx *gin.Context) {
+	var req listRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	p, ok := server.bindPage(ctx, req)
+	if !ok {
+		return
+	}
+
+	domains, err := server.dbStore.ListCompanySsoDomains(ctx, db.ListCompanySsoDomainsParams{
+		CompanyID: authPayload.CompanyID,
+		AfterID:   p.afterID,
+		Limit:     p.limit(),
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items := make([]ssoDomainResponse, len(domains))
+	for i, domain := range domains {
+		items[i] = newSSODomainResponse(domain)
+	}
+
+	rsp := newListResponse(p, items, func(item ssoDomainResponse) int64 { return item.ID })
+	if req.IncludeTotal {
+		total, err := server.dbStore.CountCompanySsoDomains(ctx, authPayload.CompanyID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		rsp.Total = &total
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// deleteSSODomain gives a domain up. The connection of the domain is no longer used to log in.
+func (server *Server) deleteSSODomain(ctx *gin.Context) {
+	var uri ssoDomainURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isCompanyAdmin(authPayload.Role) {
+		err := errors.New("only company owners and admins can manage single sign-on")
+		respondError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	domain, err := server.dbStore.DeleteSsoDomain(ctx, db.DeleteSsoDomainParams{
+		ID:        uri.ID,
+		CompanyID: authPayload.CompanyID,
+	})
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	setAuditEntity(ctx, domain.ID, newSSODomainResponse(domain), nil)
+	ctx.JSON(http.StatusOK, newSSODomainResponse(domain))
+}
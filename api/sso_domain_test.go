@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/sso"
+	"github.com/lenimbugua/bot/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSSODomain(companyID int64) db.SsoDomain {
+	return db.SsoDomain{
+		ID:                util.RandInt(1, 1000),
+		CompanyID:         companyID,
+		Domain:            util.RandomString(8) + ".com",
+		VerificationToken: util.RandomString(32),
+		CreatedAt:         time.Now(),
+	}
+}
+
+func TestCreateSSODomainAPI(t *testing.T) {
+	company := randomCompany()
+	admin, _ := randomUser(t, company.ID)
+	admin.Role = util.AdminRole
+	member, _ := randomUser(t, company.ID)
+	domain := randomSSODomain(company.ID)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"domain": domain.Domain},
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateSsoDomain(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSsoDomainParams) (db.SsoDomain, error) {
+						require.Equal(t, company.ID, arg.CompanyID)
+						require.Equal(t, domain.Domain, arg.Domain)
+						require.NotEmpty(t, arg.VerificationToken)
+						domain.VerificationToken = arg.VerificationToken
+						return domain, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp ssoDomainResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, sso.DomainRecord(domain.Domain), rsp.Record)
+				require.Equal(t, sso.DomainRecordValue(domain.VerificationToken), rsp.Value)
+				require.Nil(t, rsp.VerifiedAt)
+			},
+		},
+		{
+			name: "AlreadyClaimed",
+			body: gin.H{"domain": domain.Domain},
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateSsoDomain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SsoDomain{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDomain",
+			body: gin.H{"domain": "not a domain"},
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSsoDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: gin.H{"domain": domain.Domain},
+			user: member,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSsoDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/sso/domains", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Phone, tc.user.ID, tc.user.Name, tc.user.CompanyID, tc.user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifySSODomainAPI(t *testing.T) {
+	company := randomCompany()
+	admin, _ := randomUser(t, company.ID)
+	admin.Role = util.OwnerRole
+	domain := randomSSODomain(company.ID)
+	verified := domain
+	verified.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	arg := db.GetCompanySsoDomainParams{ID: domain.ID, CompanyID: company.ID}
+
+	published := func(_ context.Context, name string) ([]string, error) {
+		require.Equal(t, sso.DomainRecord(domain.Domain), name)
+		return []string{sso.DomainRecordValue(domain.VerificationToken)}, nil
+	}
+	missing := func(_ context.Context, name string) ([]string, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	testCases := []struct {
+		name          string
+		lookupTXT     sso.LookupTXT
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			lookupTXT: published,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanySsoDomain(gomock.Any(), gomock.Eq(arg)).Times(1).Return(domain, nil)
+				store.EXPECT().VerifySsoDomain(gomock.Any(), gomock.Eq(domain.ID)).Times(1).Return(verified, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp ssoDomainResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotNil(t, rsp.VerifiedAt)
+			},
+		},
+		{
+			name:      "RecordMissing",
+			lookupTXT: missing,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanySsoDomain(gomock.Any(), gomock.Eq(arg)).Times(1).Return(domain, nil)
+				store.EXPECT().VerifySsoDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadyVerified",
+			lookupTXT: missing,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanySsoDomain(gomock.Any(), gomock.Eq(arg)).Times(1).Return(verified, nil)
+				store.EXPECT().VerifySsoDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "VerifiedByOtherCompany",
+			lookupTXT: published,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanySsoDomain(gomock.Any(), gomock.Eq(arg)).Times(1).Return(domain, nil)
+				store.EXPECT().
+					VerifySsoDomain(gomock.Any(), gomock.Eq(domain.ID)).
+					Times(1).
+					Return(db.SsoDomain{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			lookupTXT: published,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanySsoDomain(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.SsoDomain{}, sql.ErrNoRows)
+				store.EXPECT().VerifySsoDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			server.lookupTXT = tc.lookupTXT
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/sso/domains/%d/verify", domain.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, company.ID, admin.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
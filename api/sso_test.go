@@ -0,0 +1,662 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/sso"
+	mocksso "github.com/lenimbugua/bot/sso/mock"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testSSORedirectURL = "http://localhost:8080/sso/callback"
+
+func newMockSSOProvider(t *testing.T) *mocksso.Provider {
+	provider, err := mocksso.NewProvider()
+	require.NoError(t, err)
+	t.Cleanup(provider.Close)
+	return provider
+}
+
+// randomSSOConnection returns a connection to the provider as stored, its client secret sealed
+// with the key of test servers
+func randomSSOConnection(t *testing.T, companyID int64, provider *mocksso.Provider) db.SsoConnection {
+	box, err := util.NewSecretBox(testSSOSecretKey)
+	require.NoError(t, err)
+	clientSecret, err := box.Seal(provider.ClientSecret)
+	require.NoError(t, err)
+
+	return db.SsoConnection{
+		ID:           util.RandInt(1, 1000),
+		CompanyID:    companyID,
+		EmailDomain:  util.RandomString(8) + ".com",
+		Issuer:       provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: clientSecret,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	require.Equal(t, "acme.com", emailDomain("alice@ACME.com"))
+	require.Equal(t, "acme.com", emailDomain("\"a@b\"@acme.com"))
+	require.Empty(t, emailDomain("alice"))
+}
+
+func TestSSOLoginAPI(t *testing.T) {
+	provider := newMockSSOProvider(t)
+	connection := randomSSOConnection(t, util.RandInt(1, 1000), provider)
+	email := "alice@" + connection.EmailDomain
+
+	testCases := []struct {
+		name          string
+		email         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			email: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSsoConnectionByDomain(gomock.Any(), gomock.Eq(connection.EmailDomain)).
+					Times(1).
+					Return(connection, nil)
+				store.EXPECT().
+					CreateSsoLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSsoLoginStateParams) (db.SsoLoginState, error) {
+						require.Equal(t, connection.ID, arg.ConnectionID)
+						require.NotEmpty(t, arg.State)
+						require.NotEmpty(t, arg.Nonce)
+						require.NotEmpty(t, arg.CodeVerifier)
+						require.WithinDuration(t, time.Now().Add(defaultSSOStateDuration), arg.ExpiresAt, time.Second)
+						return db.SsoLoginState{State: arg.State}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusFound, recorder.Code)
+
+				location, err := url.Parse(recorder.Header().Get("Location"))
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(location.String(), provider.Issuer()+"/authorize?"))
+				require.Equal(t, provider.ClientID, location.Query().Get("client_id"))
+				require.Equal(t, testSSORedirectURL, location.Query().Get("redirect_uri"))
+				require.Equal(t, "S256", location.Query().Get("code_challenge_method"))
+			},
+		},
+		{
+			name:  "UnknownDomain",
+			email: util.RandomEmail(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSsoConnectionByDomain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SsoConnection{}, sql.ErrNoRows)
+				store.EXPECT().CreateSsoLoginState(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidEmail",
+			email: "alice",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSsoConnectionByDomain(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "ProviderUnreachable",
+			email: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				unreachable := connection
+				unreachable.Issuer = "http://127.0.0.1:1"
+				store.EXPECT().
+					GetSsoConnectionByDomain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(unreachable, nil)
+				store.EXPECT().CreateSsoLoginState(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadGateway, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			email: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSsoConnectionByDomain(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(connection, nil)
+				store.EXPECT().
+					CreateSsoLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SsoLoginState{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.SSORedirectURL = testSSORedirectURL
+			recorder := httptest.NewRecorder()
+
+			url := "/sso/login?email=" + url.QueryEscape(tc.email)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestSSOCallbackAPI(t *testing.T) {
+	provider := newMockSSOProvider(t)
+	company := randomCompany()
+	connection := randomSSOConnection(t, company.ID, provider)
+
+	user, _ := randomUser(t, company.ID)
+	user.ID = util.RandInt(1, 1000)
+	user.Email = sql.NullString{String: "alice@" + connection.EmailDomain, Valid: true}
+
+	emailVerified := true
+	identity := mocksso.Identity{Subject: "1", Email: user.Email.String, EmailVerified: &emailVerified}
+
+	testCases := []struct {
+		name          string
+		identity      mocksso.Identity
+		query         func(state string, code string) string
+		buildStubs    func(store *mockdb.MockStore, loginState db.SsoLoginState)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Eq(loginState.State)).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Eq(connection.ID)).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email.String)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.Equal(t, user.Email.String, rsp.User.Email)
+			},
+		},
+		{
+			name:     "SecondFactorRequired",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				withTOTP := user
+				withTOTP.TotpEnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Eq(loginState.State)).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Eq(connection.ID)).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email.String)).Times(1).Return(withTOTP, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp mfaRequiredResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.MFARequired)
+				require.NotEmpty(t, rsp.MFAToken)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+			},
+		},
+		{
+			name:     "PhoneNotVerified",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				unverified := user
+				unverified.VerifiedAt = sql.NullTime{}
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(unverified, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Deactivated",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				deactivated := user
+				deactivated.DeactivatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(deactivated, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownState",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(db.SsoLoginState{}, sql.ErrNoRows)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExpiredState",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				loginState.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ProviderRefused",
+			identity: identity,
+			query: func(state string, code string) string {
+				return fmt.Sprintf("state=%s&error=access_denied", state)
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCode",
+			identity: identity,
+			query: func(state string, code string) string {
+				return fmt.Sprintf("state=%s&code=%s", state, "wrong")
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailNotVerified",
+			identity: mocksso.Identity{Subject: "1", Email: user.Email.String, EmailVerified: new(bool)},
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailVerifiedMissing",
+			identity: mocksso.Identity{Subject: "1", Email: user.Email.String},
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherDomain",
+			identity: mocksso.Identity{Subject: "1", Email: util.RandomEmail(), EmailVerified: &emailVerified},
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownUser",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "UserOfOtherCompany",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, loginState db.SsoLoginState) {
+				other := user
+				other.CompanyID = company.ID + 1
+				store.EXPECT().ConsumeSsoLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(connection, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(other, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// log in at the provider like the browser would after ssoLogin
+			discovered, err := sso.Discover(context.Background(), http.DefaultClient, provider.Issuer())
+			require.NoError(t, err)
+
+			verifier, err := sso.NewPKCEVerifier()
+			require.NoError(t, err)
+			loginState := db.SsoLoginState{
+				State:        util.RandomString(32),
+				ConnectionID: connection.ID,
+				CodeVerifier: verifier,
+				Nonce:        util.RandomString(32),
+				ExpiresAt:    time.Now().Add(time.Minute),
+			}
+
+			provider.SetIdentity(tc.identity)
+			config := sso.Config{ClientID: connection.ClientID, RedirectURL: testSSORedirectURL}
+			code, state, err := provider.Authorize(discovered.AuthCodeURL(config, loginState.State, loginState.Nonce, verifier))
+			require.NoError(t, err)
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, loginState)
+
+			server := newTestServer(t, store)
+			server.config.SSORedirectURL = testSSORedirectURL
+			recorder := httptest.NewRecorder()
+
+			query := fmt.Sprintf("state=%s&code=%s", state, code)
+			if tc.query != nil {
+				query = tc.query(state, code)
+			}
+
+			request, err := http.NewRequest(http.MethodGet, "/sso/callback?"+query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateSSOConnectionAPI(t *testing.T) {
+	provider := newMockSSOProvider(t)
+	company := randomCompany()
+	admin, _ := randomUser(t, company.ID)
+	admin.Role = util.OwnerRole
+	member, _ := randomUser(t, company.ID)
+	connection := randomSSOConnection(t, company.ID, provider)
+
+	body := gin.H{
+		"email_domain":  strings.ToUpper(connection.EmailDomain),
+		"issuer":        connection.Issuer,
+		"client_id":     connection.ClientID,
+		"client_secret": provider.ClientSecret,
+	}
+
+	verifiedDomain := db.GetVerifiedSsoDomainParams{CompanyID: company.ID, Domain: connection.EmailDomain}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		user          db.User
+		publicClient  bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedSsoDomain(gomock.Any(), gomock.Eq(verifiedDomain)).Times(1).Return(db.SsoDomain{}, nil)
+				store.EXPECT().
+					CreateSsoConnection(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSsoConnectionParams) (db.SsoConnection, error) {
+						require.Equal(t, company.ID, arg.CompanyID)
+						require.Equal(t, connection.EmailDomain, arg.EmailDomain)
+						require.Equal(t, connection.Issuer, arg.Issuer)
+						require.Equal(t, connection.ClientID, arg.ClientID)
+
+						// the client secret is only stored encrypted
+						require.NotContains(t, arg.ClientSecret, provider.ClientSecret)
+						box, err := util.NewSecretBox(testSSOSecretKey)
+						require.NoError(t, err)
+						clientSecret, err := box.Open(arg.ClientSecret)
+						require.NoError(t, err)
+						require.Equal(t, provider.ClientSecret, clientSecret)
+						return connection, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), provider.ClientSecret)
+				require.NotContains(t, recorder.Body.String(), connection.ClientSecret)
+
+				var rsp ssoConnectionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, connection.ID, rsp.ID)
+				require.Equal(t, connection.EmailDomain, rsp.EmailDomain)
+			},
+		},
+		{
+			name: "IssuerWithoutDiscovery",
+			body: gin.H{
+				"email_domain":  connection.EmailDomain,
+				"issuer":        "http://127.0.0.1:1",
+				"client_id":     connection.ClientID,
+				"client_secret": provider.ClientSecret,
+			},
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedSsoDomain(gomock.Any(), gomock.Eq(verifiedDomain)).Times(1).Return(db.SsoDomain{}, nil)
+				store.EXPECT().CreateSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "PrivateIssuer",
+			body:         body,
+			user:         admin,
+			publicClient: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedSsoDomain(gomock.Any(), gomock.Eq(verifiedDomain)).Times(1).Return(db.SsoDomain{}, nil)
+				store.EXPECT().CreateSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// the mock provider is served over http on the loopback
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), sso.ErrUnsafeURL.Error())
+			},
+		},
+		{
+			name: "DomainNotVerified",
+			body: body,
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedSsoDomain(gomock.Any(), gomock.Eq(verifiedDomain)).Times(1).Return(db.SsoDomain{}, sql.ErrNoRows)
+				store.EXPECT().CreateSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDomain",
+			body: gin.H{
+				"email_domain":  "not a domain",
+				"issuer":        connection.Issuer,
+				"client_id":     connection.ClientID,
+				"client_secret": provider.ClientSecret,
+			},
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: body,
+			user: member,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSsoConnection(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			if tc.publicClient {
+				server.sso = sso.NewPublicClient(time.Second)
+			}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/sso/connections", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Phone, tc.user.ID, tc.user.Name, tc.user.CompanyID, tc.user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteSSOConnectionAPI(t *testing.T) {
+	provider := newMockSSOProvider(t)
+	company := randomCompany()
+	admin, _ := randomUser(t, company.ID)
+	admin.Role = util.AdminRole
+	connection := randomSSOConnection(t, company.ID, provider)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteSsoConnectionParams{ID: connection.ID, CompanyID: company.ID}
+				store.EXPECT().DeleteSsoConnection(gomock.Any(), gomock.Eq(arg)).Times(1).Return(connection, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteSsoConnection(gomock.Any(), gomock.Any()).Times(1).Return(db.SsoConnection{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuthUserLookup(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/sso/connections/%d", connection.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Phone, admin.ID, admin.Name, admin.CompanyID, admin.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+const (
+	totpIssuer        = "Bot"
+	recoveryCodeCount = 10
+)
+
+type mfaRequiredResponse struct {
+	MFARequired       bool      `json:"mfa_required"`
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+func newMFARequiredResponse(login service.Login) mfaRequiredResponse {
+	return mfaRequiredResponse{
+		MFARequired:       true,
+		MFAToken:          login.MFAToken,
+		MFATokenExpiresAt: login.MFATokenExpiresAt,
+	}
+}
+
+type loginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// loginMFA completes a login started with a password by checking the second factor
+func (server *Server) loginMFA(ctx *gin.Context) {
+	var req loginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	login, err := server.service.LoginMFA(ctx, loginClient(ctx), req.MFAToken, req.Code)
+	if err != nil {
+		respondLoginError(ctx, err)
+		return
+	}
+
+	rsp := newLoginUserResponse(login.Session)
+	rsp.User = newUserResponse(login.User, login.Company)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// authenticatedUser loads the user behind the access token of the request
+func (server *Server) authenticatedUser(ctx *gin.Context) (db.User, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.dbStore.GetUserByID(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusNotFound, err)
+			return db.User{}, false
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return db.User{}, false
+	}
+	return user, true
+}
+
+type enrollTOTPRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type enrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// enrollTOTP creates a new authenticator secret for the user. Two factor
+// authentication only turns on once enableTOTP confirms a code from it.
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	var req enrollTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := server.authenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.PasswordHash); err != nil {
+		respondError(ctx, http.StatusUnauthorized, errors.New("password is incorrect"))
+		return
+	}
+
+	secret, err := otp.NewTOTPSecret()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, err = server.dbStore.SetUserTotpSecret(ctx, db.SetUserTotpSecretParams{
+		TotpSecret: sql.NullString{String: secret, Valid: true},
+		ID:         user.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errors.New("two factor authentication is already enabled"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, nil, nil)
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret: secret,
+		URI:    otp.TOTPURI(totpIssuer, user.Phone, secret),
+	})
+}
+
+type enableTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type enableTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// enableTOTP turns two factor authentication on with a code from the enrolled secret.
+// The recovery codes are only ever returned in this response.
+func (server *Server) enableTOTP(ctx *gin.Context) {
+	var req enableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := server.authenticatedUser(ctx)
+	if !ok {
+		return
+	}
+	if !user.TotpSecret.Valid || user.TotpEnabledAt.Valid {
+		respondError(ctx, http.StatusBadRequest, errors.New("no two factor enrollment is pending"))
+		return
+	}
+
+	counter, ok := otp.ValidateTOTP(user.TotpSecret.String, req.Code, time.Now())
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, service.ErrInvalidSecondFactor)
+		return
+	}
+
+	recoveryCodes, err := otp.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = util.HashSecret(otp.NormalizeRecoveryCode(code))
+	}
+
+	_, err = server.dbStore.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		UserID:             user.ID,
+		Counter:            counter,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, errors.New("no two factor enrollment is pending"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, nil, nil)
+	ctx.JSON(http.StatusOK, enableTOTPResponse{RecoveryCodes: recoveryCodes})
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// disableTOTP turns two factor authentication off. Both the password and
+// a second factor code are needed so that a stolen access token is not enough.
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	var req disableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := server.authenticatedUser(ctx)
+	if !ok {
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondError(ctx, http.StatusBadRequest, errors.New("two factor authentication is not enabled"))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.PasswordHash); err != nil {
+		respondError(ctx, http.StatusUnauthorized, errors.New("password is incorrect"))
+		return
+	}
+
+	ok, err := server.service.CheckSecondFactor(ctx, user, req.Code)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !ok {
+		respondError(ctx, http.StatusUnauthorized, service.ErrInvalidSecondFactor)
+		return
+	}
+
+	_, err = server.dbStore.DisableTOTPTx(ctx, user.ID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	setAuditEntity(ctx, user.ID, nil, nil)
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,550 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+// randomTOTPUser returns a user with two factor authentication on
+func randomTOTPUser(t *testing.T, companyID int64) (user db.User, password string) {
+	user, password = randomUser(t, companyID)
+	user.ID = util.RandInt(1, 1000)
+
+	secret, err := otp.NewTOTPSecret()
+	require.NoError(t, err)
+	user.TotpSecret = sql.NullString{String: secret, Valid: true}
+	user.TotpEnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return
+}
+
+func currentTOTPCode(t *testing.T, user db.User) string {
+	code, err := otp.TOTPCode(user.TotpSecret.String, otp.TOTPCounter(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+// wrongTOTPCode returns a code that no period around now accepts
+func wrongTOTPCode(t *testing.T, user db.User) string {
+	for {
+		code := util.RandomDigits(otp.TOTPDigits)
+		if _, ok := otp.ValidateTOTP(user.TotpSecret.String, code, time.Now()); !ok {
+			return code
+		}
+	}
+}
+
+func newMFAToken(t *testing.T, tokenMaker token.Maker, user db.User) string {
+	mfaToken, _, err := tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, []string{token.ScopeMFAPending}, time.Minute)
+	require.NoError(t, err)
+	return mfaToken
+}
+
+func TestLoginUserMFARequired(t *testing.T) {
+	company := randomCompany()
+	user, password := randomTOTPUser(t, company.ID)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).AnyTimes().Return(db.LoginAttempt{}, sql.ErrNoRows)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+	store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"phone": user.Phone, "password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp mfaRequiredResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.True(t, rsp.MFARequired)
+	require.NotEmpty(t, rsp.MFAToken)
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), rsp.MFATokenExpiresAt, time.Second)
+	require.NotContains(t, recorder.Body.String(), "access_token")
+
+	// the pending token does not authenticate any other request
+	payload, err := server.tokenMaker.VerifyToken(rsp.MFAToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{token.ScopeMFAPending}, payload.Scopes)
+
+	store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/invitations?page_size=5", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, rsp.MFAToken))
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestLoginMFAAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomTOTPUser(t, company.ID)
+	recoveryCode := "abcde-fghjk"
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T, tokenMaker token.Maker) gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				store.EXPECT().
+					UseUserTotpCounter(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseUserTotpCounterParams) (int64, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.Equal(t, otp.TOTPCounter(time.Now()), arg.Counter)
+						return 1, nil
+					})
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": "ABCDE-FGHJK"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				arg := db.UseRecoveryCodeParams{
+					UserID:   user.ID,
+					CodeHash: util.HashSecret(otp.NormalizeRecoveryCode(recoveryCode)),
+				}
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.RecoveryCode{}, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(company, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": wrongTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTotpCounter(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{FailedCount: 1}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().UseUserTotpCounter(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{FailedCount: 1}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": recoveryCode}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().RecordFailedLogin(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{FailedCount: 1}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AccessTokenInsteadOfMFAToken",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				accessToken, _, err := tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, service.RoleScopes(user.Role), time.Minute)
+				require.NoError(t, err)
+				return gin.H{"mfa_token": accessToken, "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TOTPDisabledSince",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := user
+				disabled.TotpEnabledAt = sql.NullTime{}
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(disabled, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: func(t *testing.T, tokenMaker token.Maker) gin.H {
+				return gin.H{"mfa_token": newMFAToken(t, tokenMaker, user), "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().
+					GetLoginAttempt(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).
+					Times(1).
+					Return(db.LoginAttempt{LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}}, nil)
+				store.EXPECT().UseUserTotpCounter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).AnyTimes().Return(db.LoginAttempt{}, sql.ErrNoRows)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(t, server.tokenMaker))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, password := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+	enabled, _ := randomTOTPUser(t, user.CompanyID)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).AnyTimes().Return(user, nil)
+				store.EXPECT().
+					SetUserTotpSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.SetUserTotpSecretParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.True(t, arg.TotpSecret.Valid)
+						return user, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp enrollTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.Secret)
+				require.Equal(t, otp.TOTPURI(totpIssuer, user.Phone, rsp.Secret), rsp.URI)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"password": "wrong password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).AnyTimes().Return(user, nil)
+				store.EXPECT().SetUserTotpSecret(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).AnyTimes().Return(user, nil)
+				store.EXPECT().SetUserTotpSecret(gomock.Any(), gomock.Any()).Times(1).Return(enabled, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuditLog(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/totp/enroll", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestEnableTOTPAPI(t *testing.T) {
+	pending, _ := randomTOTPUser(t, util.RandInt(1, 1000))
+	pending.TotpEnabledAt = sql.NullTime{}
+
+	testCases := []struct {
+		name          string
+		user          func() db.User
+		code          func(t *testing.T) string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: func() db.User { return pending },
+			code: func(t *testing.T) string { return currentTOTPCode(t, pending) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.EnableTOTPTxParams) (db.User, error) {
+						require.Equal(t, pending.ID, arg.UserID)
+						require.Equal(t, otp.TOTPCounter(time.Now()), arg.Counter)
+						require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+						return pending, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp enableTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			user: func() db.User { return pending },
+			code: func(t *testing.T) string { return wrongTOTPCode(t, pending) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotEnrolled",
+			user: func() db.User {
+				user := pending
+				user.TotpSecret = sql.NullString{}
+				return user
+			},
+			code: func(t *testing.T) string { return currentTOTPCode(t, pending) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			user: func() db.User {
+				user := pending
+				user.TotpEnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				return user
+			},
+			code: func(t *testing.T) string { return currentTOTPCode(t, pending) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user := tc.user()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuditLog(store)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).AnyTimes().Return(user, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code(t)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/totp/enable", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDisableTOTPAPI(t *testing.T) {
+	user, password := randomTOTPUser(t, util.RandInt(1, 1000))
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T) gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password, "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseUserTotpCounter(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": "wrong password", "code": currentTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UseUserTotpCounter(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password, "code": wrongTOTPCode(t, user)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowAuditLog(store)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).AnyTimes().Return(user, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/totp/disable", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Phone, user.ID, user.Name, user.CompanyID, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
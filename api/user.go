@@ -1,28 +1,22 @@
 package api
 
 import (
-	"database/sql"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	db "github.com/lenimbugua/bot/db/sqlc"
-	"github.com/lenimbugua/bot/util"
-	"github.com/lib/pq"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
 )
 
-type createUserRequest struct {
-	Name      string `json:"name" binding:"required"`
-	Password  string `json:"password" binding:"required,min=6"`
-	Phone     string `json:"phone" binding:"required,e164"`
-	CompanyID int64  `json:"company_id" binding:"required,min=1"`
-}
-
 type userResponse struct {
+	Role              string     `json:"role"`
 	Name              string     `json:"name"`
 	Phone             string     `json:"phone"`
+	Email             string     `json:"email,omitempty"`
 	PasswordChangedAt time.Time  `json:"password_changed_at"`
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
@@ -31,8 +25,10 @@ type userResponse struct {
 
 func newUserResponse(user db.User, company db.Company) userResponse {
 	return userResponse{
+		Role:              user.Role,
 		Name:              user.Name,
 		Phone:             user.Phone,
+		Email:             user.Email.String,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 		UpdatedAt:         user.UpdatedAt,
@@ -40,55 +36,6 @@ func newUserResponse(user db.User, company db.Company) userResponse {
 	}
 }
 
-func (server *Server) createUser(ctx *gin.Context) {
-	var req createUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	company, err := server.dbStore.GetCompanyByID(ctx, req.CompanyID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, descriptiveError("Company not found"))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	hashedPassword, err := util.HashPassword(req.Password)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	arg := db.CreateUserParams{
-		Name:         req.Name,
-		PasswordHash: hashedPassword,
-		Phone:        req.Phone,
-		CompanyID:    company.ID,
-	}
-
-	user, err := server.dbStore.CreateUser(ctx, arg)
-	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			log.Print("pq", pqErr.Constraint)
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, descriptiveError(pqErr.Detail))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	rsp := newUserResponse(user, company)
-	ctx.JSON(http.StatusOK, rsp)
-}
-
 type loginUserRequest struct {
 	Phone    string `json:"phone" binding:"required,e164"`
 	Password string `json:"password" binding:"required,min=6"`
@@ -106,84 +53,55 @@ type loginUserResponse struct {
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	user, err := server.dbStore.GetUser(ctx, req.Phone)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err = util.CheckPassword(req.Password, user.PasswordHash)
+	login, err := server.service.Login(ctx, loginClient(ctx), req.Phone, req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		respondLoginError(ctx, err)
 		return
 	}
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		user.Phone,
-		user.ID,
-		user.Name,
-		user.CompanyID,
-		server.config.AccessTokenDuration,
-	)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
-		user.Phone,
-		user.ID,
-		user.Name,
-		user.CompanyID,
-		server.config.RefreshTokenDuration,
-	)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if login.MFARequired() {
+		ctx.JSON(http.StatusOK, newMFARequiredResponse(login))
 		return
 	}
 
-	session, err := server.dbStore.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		UserID:       user.ID,
-		RefreshToken: refreshToken,
-		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.ClientIP(),
-		IsBlocked:    false,
-		ChannelID:    util.DefaultID,
-		QuestionID:   util.DefaultID,
-		ResponseID:   util.DefaultID,
-		ExpiresAt:    refreshPayload.ExpiredAt,
-	})
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
+	rsp := newLoginUserResponse(login.Session)
+	rsp.User = newUserResponse(login.User, login.Company)
+	ctx.JSON(http.StatusOK, rsp)
+}
 
-	company, err := server.dbStore.GetCompanyByID(ctx, user.CompanyID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+// loginClient describes the client making the request to the service
+func loginClient(ctx *gin.Context) service.Client {
+	return service.Client{IP: ctx.ClientIP(), UserAgent: ctx.Request.UserAgent()}
+}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+// respondLoginError answers a login refused by the service
+func respondLoginError(ctx *gin.Context, err error) {
+	var lockedErr *service.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		abortLocked(ctx, lockedErr.Until)
+	case errors.Is(err, service.ErrWrongPassword),
+		errors.Is(err, service.ErrInvalidSecondFactor),
+		errors.Is(err, service.ErrUserGone),
+		errors.Is(err, token.ErrInvalidToken),
+		errors.Is(err, token.ErrExpiredToken):
+		respondError(ctx, http.StatusUnauthorized, err)
+	case errors.Is(err, service.ErrUserDeactivated), errors.Is(err, service.ErrPhoneNotVerified):
+		respondError(ctx, http.StatusForbidden, err)
+	default:
+		respondError(ctx, http.StatusInternalServerError, err)
 	}
+}
 
-	rsp := loginUserResponse{
+func newLoginUserResponse(session service.Session) loginUserResponse {
+	return loginUserResponse{
 		SessionID:             session.ID,
-		AccessToken:           accessToken,
-		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
-		RefreshToken:          refreshToken,
-		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
-		User:                  newUserResponse(user, company),
+		AccessToken:           session.AccessToken,
+		AccessTokenExpiresAt:  session.AccessTokenExpiresAt,
+		RefreshToken:          session.RefreshToken,
+		RefreshTokenExpiresAt: session.RefreshTokenExpiresAt,
 	}
-	ctx.JSON(http.StatusOK, rsp)
 }
@@ -4,227 +4,20 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
-	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/lenimbugua/bot/db/mock"
 	db "github.com/lenimbugua/bot/db/sqlc"
 	"github.com/lenimbugua/bot/util"
-	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
-type eqCreateUserParamsMatcher struct {
-	arg      db.CreateUserParams
-	password string
-}
-
-func (e eqCreateUserParamsMatcher) Matches(x interface{}) bool {
-	arg, ok := x.(db.CreateUserParams)
-	if !ok {
-		return false
-	}
-
-	err := util.CheckPassword(e.password, arg.PasswordHash)
-	if err != nil {
-		return false
-	}
-
-	e.arg.PasswordHash = arg.PasswordHash
-	return reflect.DeepEqual(e.arg, arg)
-}
-
-func (e eqCreateUserParamsMatcher) String() string {
-	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
-}
-
-func EqCreateUserParams(arg db.CreateUserParams, password string) gomock.Matcher {
-	return eqCreateUserParamsMatcher{arg, password}
-}
-
-func TestCreateUserAPI(t *testing.T) {
-	company := randomCompany()
-	user, password := randomUser(t, company.ID)
-	testCases := []struct {
-		name          string
-		body          gin.H
-		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(recoder *httptest.ResponseRecorder)
-	}{
-		{
-			name: "OK",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.CreateUserParams{
-					Name:         user.Name,
-					Phone:        user.Phone,
-					PasswordHash: password,
-					CompanyID:    company.ID,
-				}
-				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
-				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
-					Times(1).
-					Return(user, nil)
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchUser(t, recorder.Body, user)
-			},
-		},
-		{
-			name: "CompanyNotFound",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrNoRows)
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(0)
-
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
-			},
-		},
-		{
-			name: "GetCompanyInternalError",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Any()).Times(1).Return(db.Company{}, sql.ErrConnDone)
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(0)
-
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
-			},
-		},
-
-		
-		{
-			name: "InternalError",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(1).
-					Return(db.User{}, sql.ErrConnDone)
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
-			},
-		},
-		{
-			name: "DuplicatePhone",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(1).
-					Return(db.User{}, &pq.Error{Code: "23505"})
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
-			},
-		},
-		{
-			name: "InvalidPhone",
-			body: gin.H{
-				"password":   password,
-				"name":       user.Name,
-				"phone":      "5%uyr6464",
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(0)
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
-			},
-		},
-
-		{
-			name: "TooShortPassword",
-			body: gin.H{
-				"password":   "123",
-				"name":       user.Name,
-				"phone":      user.Phone,
-				"company_id": company.ID,
-			},
-			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
-					Times(0)
-			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
-			},
-		},
-	}
-
-	for i := range testCases {
-		tc := testCases[i]
-
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			store := mockdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
-
-			server := newTestServer(t, store)
-			recorder := httptest.NewRecorder()
-
-			// Marshal body data to JSON
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
-			url := "/users"
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-			require.NoError(t, err)
-
-			server.router.ServeHTTP(recorder, request)
-			tc.checkResponse(recorder)
-		})
-	}
-}
-
 func TestLoginUserAPI(t *testing.T) {
 	company := randomCompany()
 	user, password := randomUser(t, company.ID)
@@ -268,7 +61,7 @@ func TestLoginUserAPI(t *testing.T) {
 					Return(db.User{}, sql.ErrNoRows)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
@@ -287,6 +80,27 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "PhoneNotVerified",
+			body: gin.H{
+				"password": password,
+				"phone":    user.Phone,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				unverified := user
+				unverified.VerifiedAt = sql.NullTime{}
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Phone)).
+					Times(1).
+					Return(unverified, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -376,7 +190,7 @@ func TestLoginUserAPI(t *testing.T) {
 
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 	}
@@ -390,6 +204,7 @@ func TestLoginUserAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			allowLoginAttempts(store)
 
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -418,6 +233,8 @@ func randomUser(t *testing.T, companyID int64) (user db.User, password string) {
 		PasswordHash: hashedPassword,
 		Phone:        util.RandomPhoneNumber(),
 		CompanyID:    companyID,
+		Role:         util.MemberRole,
+		VerifiedAt:   sql.NullTime{Time: time.Now(), Valid: true},
 	}
 	return
 }
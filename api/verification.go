@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+)
+
+// otpErrorStatus maps the errors of the otp manager that are caused by the client
+func otpErrorStatus(err error) (int, bool) {
+	switch err {
+	case otp.ErrInvalidCode:
+		return http.StatusBadRequest, true
+	case otp.ErrTooManyAttempts, otp.ErrResendCooldown:
+		return http.StatusTooManyRequests, true
+	}
+	return 0, false
+}
+
+type pendingVerificationResponse struct {
+	User                 userResponse `json:"user"`
+	VerificationRequired bool         `json:"verification_required"`
+}
+
+// startVerification sends a verification code to a newly registered user.
+// A failed delivery is only logged because the user already exists and can ask for a new code.
+func (server *Server) startVerification(ctx *gin.Context, user db.User, company db.Company) {
+	err := server.otp.Issue(ctx, user, otp.PurposePhoneVerification)
+	if err != nil {
+		log.Printf("cannot send verification code: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, pendingVerificationResponse{
+		User:                 newUserResponse(user, company),
+		VerificationRequired: true,
+	})
+}
+
+type sendVerificationCodeRequest struct {
+	Phone string `json:"phone" binding:"required,e164"`
+}
+
+// sendVerificationCode sends a new verification code to an unverified phone.
+// It answers the same way whether or not the phone is registered, so a request made
+// during the resend cooldown is dropped silently rather than refused.
+func (server *Server) sendVerificationCode(ctx *gin.Context) {
+	var req sendVerificationCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := server.dbStore.GetUser(ctx, req.Phone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, nil)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.VerifiedAt.Valid {
+		ctx.JSON(http.StatusOK, nil)
+		return
+	}
+
+	err = server.otp.Issue(ctx, user, otp.PurposePhoneVerification)
+	if err != nil && err != otp.ErrResendCooldown {
+		log.Printf("cannot send verification code: %v", err)
+		respondError(ctx, http.StatusInternalServerError, errors.New("cannot send verification code"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
+
+type verifyPhoneRequest struct {
+	Phone string `json:"phone" binding:"required,e164"`
+	Code  string `json:"code" binding:"required,numeric"`
+}
+
+// verifyPhone marks the phone of the user as verified. It does not log the user in: the
+// code only proves the phone, and the user logs in with the password and second factor.
+func (server *Server) verifyPhone(ctx *gin.Context) {
+	var req verifyPhoneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := server.dbStore.GetUser(ctx, req.Phone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, otp.ErrInvalidCode)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.VerifiedAt.Valid {
+		respondError(ctx, http.StatusBadRequest, errors.New("phone number is already verified"))
+		return
+	}
+
+	otpCode, err := server.otp.Verify(ctx, user.ID, otp.PurposePhoneVerification, req.Code)
+	if err != nil {
+		if code, ok := otpErrorStatus(err); ok {
+			respondError(ctx, code, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err = server.dbStore.VerifyPhoneTx(ctx, db.VerifyPhoneTxParams{
+		UserID:    user.ID,
+		OtpCodeID: otpCode.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			respondError(ctx, http.StatusBadRequest, otp.ErrInvalidCode)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	company, err := server.dbStore.GetCompanyByID(ctx, user.CompanyID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user, company))
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendVerificationCodeAPI(t *testing.T) {
+	user, _ := randomUser(t, util.RandInt(1, 1000))
+	user.ID = util.RandInt(1, 1000)
+	user.VerifiedAt = sql.NullTime{}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder, sender *fakeOTPSender)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(db.OtpCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateOtpCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOtpCodeParams) (db.OtpCode, error) {
+						require.Equal(t, otp.PurposePhoneVerification, arg.Purpose)
+						return db.OtpCode{CodeHash: arg.CodeHash}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, user.Phone, sender.phone)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(verified, nil)
+				store.EXPECT().CreateOtpCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+		{
+			name: "UnknownPhone",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateOtpCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+		{
+			name: "ResendCooldown",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				otpCode, _ := randomOtpCode(t, user.ID, otp.PurposePhoneVerification)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().CreateOtpCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, sender.message)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"phone": user.Phone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *fakeOTPSender) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			sender := &fakeOTPSender{}
+			server.otp = otp.NewManager(store, sender, otp.Config{})
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/verify/send"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, sender)
+		})
+	}
+}
+
+func TestVerifyPhoneAPI(t *testing.T) {
+	company := randomCompany()
+	user, _ := randomUser(t, company.ID)
+	user.ID = util.RandInt(1, 1000)
+	user.VerifiedAt = sql.NullTime{}
+	otpCode, code := randomOtpCode(t, user.ID, otp.PurposePhoneVerification)
+
+	verified := user
+	verified.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	body := gin.H{
+		"phone": user.Phone,
+		"code":  code,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().
+					GetLatestOtpCode(gomock.Any(), gomock.Eq(db.GetLatestOtpCodeParams{UserID: user.ID, Purpose: otp.PurposePhoneVerification})).
+					Times(1).
+					Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Eq(otpCode.ID)).Times(1).Return(otpCode, nil)
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Eq(db.VerifyPhoneTxParams{UserID: user.ID, OtpCodeID: otpCode.ID})).
+					Times(1).
+					Return(verified, nil)
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				// the code proves the phone, not the password: no session is started
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+
+				var rsp userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, user.Phone, rsp.Phone)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{
+				"phone": user.Phone,
+				"code":  "0000000",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				exhausted := otpCode
+				exhausted.Attempts = otp.DefaultMaxAttempts
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(exhausted, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(verified, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CodeAlreadyConsumed",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(otpCode, nil)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{
+				"phone": user.Phone,
+				"code":  "abc",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/users/verify"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'member';
+
+-- the first user of every existing company becomes its owner
+UPDATE "users" SET "role" = 'owner'
+WHERE "id" IN (SELECT min("id") FROM "users" GROUP BY "company_id");
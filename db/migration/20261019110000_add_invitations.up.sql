@@ -0,0 +1,23 @@
+CREATE TABLE "invitations" (
+  "id" bigserial PRIMARY KEY,
+  "company_id" bigint NOT NULL,
+  "invited_by" bigint NOT NULL,
+  "role" varchar NOT NULL,
+  "phone" varchar,
+  "email" varchar,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "accepted_at" timestamptz,
+  "accepted_by" bigint,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "invitations_contact_check" CHECK ("phone" IS NOT NULL OR "email" IS NOT NULL)
+);
+
+CREATE INDEX ON "invitations" ("company_id");
+
+ALTER TABLE "invitations" ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "invitations" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "invitations" ADD FOREIGN KEY ("accepted_by") REFERENCES "users" ("id") ON DELETE SET NULL ON UPDATE NO ACTION;
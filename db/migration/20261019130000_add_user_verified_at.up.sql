@@ -0,0 +1,4 @@
+ALTER TABLE "users" ADD COLUMN "verified_at" timestamptz;
+
+-- users that already exist keep being able to log in
+UPDATE "users" SET "verified_at" = now();
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "sso_login_states";
+
+DROP TABLE IF EXISTS "sso_connections";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email";
@@ -0,0 +1,29 @@
+ALTER TABLE "users" ADD COLUMN "email" varchar;
+
+CREATE UNIQUE INDEX ON "users" (lower("email"));
+
+CREATE TABLE "sso_connections" (
+  "id" bigserial PRIMARY KEY,
+  "company_id" bigint NOT NULL,
+  "email_domain" varchar UNIQUE NOT NULL,
+  "issuer" varchar NOT NULL,
+  "client_id" varchar NOT NULL,
+  "client_secret" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sso_connections" ("company_id");
+
+ALTER TABLE "sso_connections" ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+-- a login in progress, kept between the redirect to the identity provider and its callback
+CREATE TABLE "sso_login_states" (
+  "state" varchar PRIMARY KEY,
+  "connection_id" bigint NOT NULL,
+  "code_verifier" varchar NOT NULL,
+  "nonce" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "sso_login_states" ADD FOREIGN KEY ("connection_id") REFERENCES "sso_connections" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
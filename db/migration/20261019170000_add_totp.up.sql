@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "totp_secret" varchar;
+
+-- two factor authentication is on once the first code has been confirmed
+ALTER TABLE "users" ADD COLUMN "totp_enabled_at" timestamptz;
+
+-- the period of the last accepted code, so that a code cannot be used twice
+ALTER TABLE "users" ADD COLUMN "totp_last_counter" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS "companies_name_idx";
+
+DROP INDEX IF EXISTS "companies_updated_at_idx";
+
+DROP INDEX IF EXISTS "bots_company_id_title_idx";
+
+DROP INDEX IF EXISTS "bots_company_id_updated_at_idx";
+
+DROP INDEX IF EXISTS "companies_email_trgm_idx";
+
+DROP INDEX IF EXISTS "companies_name_trgm_idx";
+
+DROP INDEX IF EXISTS "bots_title_trgm_idx";
+
+DROP EXTENSION IF EXISTS pg_trgm;
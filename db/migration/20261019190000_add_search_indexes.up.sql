@@ -0,0 +1,16 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram indexes serve the substring searches (ILIKE '%...%') of the list endpoints
+CREATE INDEX "bots_title_trgm_idx" ON "bots" USING gin ("title" gin_trgm_ops);
+
+CREATE INDEX "companies_name_trgm_idx" ON "companies" USING gin ("name" gin_trgm_ops);
+
+CREATE INDEX "companies_email_trgm_idx" ON "companies" USING gin ("email" gin_trgm_ops);
+
+CREATE INDEX "bots_company_id_updated_at_idx" ON "bots" ("company_id", "updated_at", "id");
+
+CREATE INDEX "bots_company_id_title_idx" ON "bots" ("company_id", "title", "id");
+
+CREATE INDEX "companies_updated_at_idx" ON "companies" ("updated_at", "id");
+
+CREATE INDEX "companies_name_idx" ON "companies" ("name", "id");
@@ -0,0 +1,17 @@
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_question_id_fkey";
+
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_response_id_fkey";
+
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_user_id_fkey";
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("response_id") REFERENCES "responses" ("id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+ALTER TABLE IF EXISTS "questions" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE IF EXISTS "bots" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE IF EXISTS "companies" DROP COLUMN IF EXISTS "deleted_at";
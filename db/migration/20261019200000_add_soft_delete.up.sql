@@ -0,0 +1,25 @@
+ALTER TABLE "companies" ADD COLUMN "deleted_at" timestamptz;
+
+ALTER TABLE "bots" ADD COLUMN "deleted_at" timestamptz;
+
+ALTER TABLE "questions" ADD COLUMN "deleted_at" timestamptz;
+
+-- the purge looks for the rows deleted before the retention period
+CREATE INDEX ON "companies" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX ON "bots" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX ON "questions" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+-- rows are only hard deleted by the purge, which takes the answers with them
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_user_id_fkey";
+
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_response_id_fkey";
+
+ALTER TABLE "user_responses" DROP CONSTRAINT "user_responses_question_id_fkey";
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("response_id") REFERENCES "responses" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+ALTER TABLE "user_responses" ADD FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
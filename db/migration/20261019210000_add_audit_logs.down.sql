@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "audit_logs";
+DROP FUNCTION IF EXISTS reject_audit_log_change();
@@ -0,0 +1,31 @@
+CREATE TABLE "audit_logs" (
+  "id" bigserial PRIMARY KEY,
+  "company_id" bigint NOT NULL,
+  "actor_user_id" bigint,
+  "actor_api_key_id" bigint,
+  "actor_name" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" bigint,
+  "changes" jsonb NOT NULL DEFAULT '{}',
+  "ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- no foreign keys: the log outlives the companies, users and keys it mentions
+CREATE INDEX ON "audit_logs" ("company_id", "id");
+
+CREATE INDEX ON "audit_logs" ("company_id", "entity_type", "entity_id");
+
+-- the log is append only, rows can be neither changed nor removed
+CREATE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_append_only
+BEFORE UPDATE OR DELETE OR TRUNCATE ON "audit_logs"
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
@@ -0,0 +1,22 @@
+-- an email domain claimed by a company for single sign-on, verified once the company
+-- published the verification token in a TXT record of the domain
+CREATE TABLE "sso_domains" (
+  "id" bigserial PRIMARY KEY,
+  "company_id" bigint NOT NULL,
+  "domain" varchar NOT NULL,
+  "verification_token" varchar NOT NULL,
+  "verified_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "sso_domains" ("company_id", "domain");
+
+-- any company may claim a domain, only one can prove it owns it
+CREATE UNIQUE INDEX "sso_domains_verified_domain_idx" ON "sso_domains" ("domain") WHERE "verified_at" IS NOT NULL;
+
+ALTER TABLE "sso_domains" ADD FOREIGN KEY ("company_id") REFERENCES "companies" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+-- the connections made before domains were verified stay unused until their domain is verified
+INSERT INTO "sso_domains" ("company_id", "domain", "verification_token")
+SELECT "company_id", "email_domain", md5(random()::text || "id"::text)
+FROM "sso_connections";
@@ -0,0 +1,110 @@
+package migrator
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+// lockTimeout is how long to wait for another instance that is migrating the same
+// database, a long migration holds the lock until it is done
+const lockTimeout = 5 * time.Minute
+
+// Migrator applies the migrations found at MIGRATION_URL to the database at DB_SOURCE.
+// The versions applied are recorded in the schema_migrations table, the same one the
+// migrate CLI uses. Every change runs under a postgres advisory lock, so replicas
+// starting together wait for each other instead of racing.
+type Migrator struct {
+	migrate *migrate.Migrate
+}
+
+// New creates a migrator reading the migrations from sourceURL, as in file://db/migration
+func New(sourceURL string, databaseURL string) (*Migrator, error) {
+	m, err := migrate.New(sourceURL, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = logger{}
+	m.LockTimeout = lockTimeout
+
+	return &Migrator{migrate: m}, nil
+}
+
+// Up applies all the pending migrations, it is not an error when there are none
+func (migrator *Migrator) Up() error {
+	return ignoreNoChange(migrator.migrate.Up())
+}
+
+// Steps applies the next n migrations, or rolls back the last -n when n is negative
+func (migrator *Migrator) Steps(n int) error {
+	return ignoreNoChange(migrator.migrate.Steps(n))
+}
+
+// Version returns the version of the last migration applied, zero when there is none.
+// The database is dirty when that migration failed halfway and must be fixed by hand.
+func (migrator *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = migrator.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return
+}
+
+// LatestVersion returns the version of the newest migration at sourceURL, the version
+// the database is at once they are all applied. It is zero when there are none.
+func LatestVersion(sourceURL string) (uint, error) {
+	driver, err := source.Open(sourceURL)
+	if err != nil {
+		return 0, err
+	}
+	defer driver.Close()
+
+	version, err := driver.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	for err == nil {
+		var next uint
+		next, err = driver.Next(version)
+		if err == nil {
+			version = next
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Close closes the connections to the migrations and to the database
+func (migrator *Migrator) Close() error {
+	sourceErr, databaseErr := migrator.migrate.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return databaseErr
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// logger prints the migrations as they are applied
+type logger struct{}
+
+func (logger) Printf(format string, v ...any) {
+	log.Printf("migrate: "+format, v...)
+}
+
+func (logger) Verbose() bool {
+	return false
+}
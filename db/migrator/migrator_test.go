@@ -0,0 +1,89 @@
+package migrator
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testSourceURL = "file://../migration"
+
+// latestVersion is the version of the newest migration in db/migration
+func latestVersion(t *testing.T) uint {
+	entries, err := os.ReadDir("../migration")
+	require.NoError(t, err)
+
+	var versions []uint64
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		require.NoError(t, err)
+		versions = append(versions, version)
+	}
+	require.NotEmpty(t, versions)
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return uint(versions[len(versions)-1])
+}
+
+func TestUpConcurrently(t *testing.T) {
+	config, err := util.LoadConfig("../..")
+	require.NoError(t, err)
+
+	n := 3
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m, err := New(testSourceURL, config.DBSource)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer m.Close()
+			errs <- m.Up()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	m, err := New(testSourceURL, config.DBSource)
+	require.NoError(t, err)
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, latestVersion(t), version)
+
+	require.NoError(t, m.Up())
+}
+
+func TestNewInvalidSource(t *testing.T) {
+	m, err := New("file://does-not-exist", "postgresql://localhost:5432/bot")
+	require.Error(t, err)
+	require.Nil(t, m)
+}
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion(testSourceURL)
+	require.NoError(t, err)
+	require.Equal(t, latestVersion(t), version)
+
+	version, err = LatestVersion("file://" + t.TempDir())
+	require.NoError(t, err)
+	require.Zero(t, version)
+}
@@ -7,6 +7,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,286 +37,1701 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockStore) AcceptInvitation(arg0 context.Context, arg1 db.AcceptInvitationParams) (db.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockStoreMockRecorder) AcceptInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockStore)(nil).AcceptInvitation), arg0, arg1)
+}
+
+// AcceptInvitationTx mocks base method.
+func (m *MockStore) AcceptInvitationTx(arg0 context.Context, arg1 db.AcceptInvitationTxParams) (db.AcceptInvitationTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitationTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AcceptInvitationTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitationTx indicates an expected call of AcceptInvitationTx.
+func (mr *MockStoreMockRecorder) AcceptInvitationTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitationTx", reflect.TypeOf((*MockStore)(nil).AcceptInvitationTx), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// ChangePasswordTx mocks base method.
+func (m *MockStore) ChangePasswordTx(arg0 context.Context, arg1 db.ChangePasswordTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePasswordTx indicates an expected call of ChangePasswordTx.
+func (mr *MockStoreMockRecorder) ChangePasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePasswordTx", reflect.TypeOf((*MockStore)(nil).ChangePasswordTx), arg0, arg1)
+}
+
+// ConsumeOtpCode mocks base method.
+func (m *MockStore) ConsumeOtpCode(arg0 context.Context, arg1 int64) (db.OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOtpCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOtpCode indicates an expected call of ConsumeOtpCode.
+func (mr *MockStoreMockRecorder) ConsumeOtpCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOtpCode", reflect.TypeOf((*MockStore)(nil).ConsumeOtpCode), arg0, arg1)
+}
+
+// ConsumeSsoLoginState mocks base method.
+func (m *MockStore) ConsumeSsoLoginState(arg0 context.Context, arg1 string) (db.SsoLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSsoLoginState", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeSsoLoginState indicates an expected call of ConsumeSsoLoginState.
+func (mr *MockStoreMockRecorder) ConsumeSsoLoginState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSsoLoginState", reflect.TypeOf((*MockStore)(nil).ConsumeSsoLoginState), arg0, arg1)
+}
+
+// CountActiveConversations mocks base method.
+func (m *MockStore) CountActiveConversations(arg0 context.Context) ([]db.CountActiveConversationsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveConversations", arg0)
+	ret0, _ := ret[0].([]db.CountActiveConversationsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveConversations indicates an expected call of CountActiveConversations.
+func (mr *MockStoreMockRecorder) CountActiveConversations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveConversations", reflect.TypeOf((*MockStore)(nil).CountActiveConversations), arg0)
+}
+
+// CountAuditLogs mocks base method.
+func (m *MockStore) CountAuditLogs(arg0 context.Context, arg1 db.CountAuditLogsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAuditLogs indicates an expected call of CountAuditLogs.
+func (mr *MockStoreMockRecorder) CountAuditLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAuditLogs", reflect.TypeOf((*MockStore)(nil).CountAuditLogs), arg0, arg1)
+}
+
+// CountBots mocks base method.
+func (m *MockStore) CountBots(arg0 context.Context, arg1 db.CountBotsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBots", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBots indicates an expected call of CountBots.
+func (mr *MockStoreMockRecorder) CountBots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBots", reflect.TypeOf((*MockStore)(nil).CountBots), arg0, arg1)
+}
+
+// CountChannels mocks base method.
+func (m *MockStore) CountChannels(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountChannels", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountChannels indicates an expected call of CountChannels.
+func (mr *MockStoreMockRecorder) CountChannels(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountChannels", reflect.TypeOf((*MockStore)(nil).CountChannels), arg0)
+}
+
+// CountCompanies mocks base method.
+func (m *MockStore) CountCompanies(arg0 context.Context, arg1 db.CountCompaniesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanies", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanies indicates an expected call of CountCompanies.
+func (mr *MockStoreMockRecorder) CountCompanies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanies", reflect.TypeOf((*MockStore)(nil).CountCompanies), arg0, arg1)
+}
+
+// CountCompanyApiKeys mocks base method.
+func (m *MockStore) CountCompanyApiKeys(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanyApiKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanyApiKeys indicates an expected call of CountCompanyApiKeys.
+func (mr *MockStoreMockRecorder) CountCompanyApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanyApiKeys", reflect.TypeOf((*MockStore)(nil).CountCompanyApiKeys), arg0, arg1)
+}
+
+// CountCompanyInvitations mocks base method.
+func (m *MockStore) CountCompanyInvitations(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanyInvitations", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanyInvitations indicates an expected call of CountCompanyInvitations.
+func (mr *MockStoreMockRecorder) CountCompanyInvitations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanyInvitations", reflect.TypeOf((*MockStore)(nil).CountCompanyInvitations), arg0, arg1)
+}
+
+// CountCompanySsoConnections mocks base method.
+func (m *MockStore) CountCompanySsoConnections(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanySsoConnections", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanySsoConnections indicates an expected call of CountCompanySsoConnections.
+func (mr *MockStoreMockRecorder) CountCompanySsoConnections(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanySsoConnections", reflect.TypeOf((*MockStore)(nil).CountCompanySsoConnections), arg0, arg1)
+}
+
+// CountCompanySsoDomains mocks base method.
+func (m *MockStore) CountCompanySsoDomains(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanySsoDomains", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanySsoDomains indicates an expected call of CountCompanySsoDomains.
+func (mr *MockStoreMockRecorder) CountCompanySsoDomains(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanySsoDomains", reflect.TypeOf((*MockStore)(nil).CountCompanySsoDomains), arg0, arg1)
+}
+
+// CountCompanyUsers mocks base method.
+func (m *MockStore) CountCompanyUsers(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCompanyUsers", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCompanyUsers indicates an expected call of CountCompanyUsers.
+func (mr *MockStoreMockRecorder) CountCompanyUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCompanyUsers", reflect.TypeOf((*MockStore)(nil).CountCompanyUsers), arg0, arg1)
+}
+
+// CountUnusedRecoveryCodes mocks base method.
+func (m *MockStore) CountUnusedRecoveryCodes(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnusedRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnusedRecoveryCodes indicates an expected call of CountUnusedRecoveryCodes.
+func (mr *MockStoreMockRecorder) CountUnusedRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnusedRecoveryCodes", reflect.TypeOf((*MockStore)(nil).CountUnusedRecoveryCodes), arg0, arg1)
+}
+
+// CreateApiKey mocks base method.
+func (m *MockStore) CreateApiKey(arg0 context.Context, arg1 db.CreateApiKeyParams) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApiKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApiKey indicates an expected call of CreateApiKey.
+func (mr *MockStoreMockRecorder) CreateApiKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApiKey", reflect.TypeOf((*MockStore)(nil).CreateApiKey), arg0, arg1)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockStore) CreateAuditLog(arg0 context.Context, arg1 db.CreateAuditLogParams) (db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockStoreMockRecorder) CreateAuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockStore)(nil).CreateAuditLog), arg0, arg1)
+}
+
 // CreateBot mocks base method.
 func (m *MockStore) CreateBot(arg0 context.Context, arg1 db.CreateBotParams) (db.Bot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBot", arg0, arg1)
-	ret0, _ := ret[0].(db.Bot)
+	ret := m.ctrl.Call(m, "CreateBot", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBot indicates an expected call of CreateBot.
+func (mr *MockStoreMockRecorder) CreateBot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBot", reflect.TypeOf((*MockStore)(nil).CreateBot), arg0, arg1)
+}
+
+// CreateChannel mocks base method.
+func (m *MockStore) CreateChannel(arg0 context.Context, arg1 string) (db.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChannel", arg0, arg1)
+	ret0, _ := ret[0].(db.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateChannel indicates an expected call of CreateChannel.
+func (mr *MockStoreMockRecorder) CreateChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockStore)(nil).CreateChannel), arg0, arg1)
+}
+
+// CreateCompany mocks base method.
+func (m *MockStore) CreateCompany(arg0 context.Context, arg1 db.CreateCompanyParams) (db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCompany", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCompany indicates an expected call of CreateCompany.
+func (mr *MockStoreMockRecorder) CreateCompany(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockStore)(nil).CreateCompany), arg0, arg1)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockStore) CreateInvitation(arg0 context.Context, arg1 db.CreateInvitationParams) (db.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockStoreMockRecorder) CreateInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockStore)(nil).CreateInvitation), arg0, arg1)
+}
+
+// CreateOtpCode mocks base method.
+func (m *MockStore) CreateOtpCode(arg0 context.Context, arg1 db.CreateOtpCodeParams) (db.OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOtpCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOtpCode indicates an expected call of CreateOtpCode.
+func (mr *MockStoreMockRecorder) CreateOtpCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOtpCode", reflect.TypeOf((*MockStore)(nil).CreateOtpCode), arg0, arg1)
+}
+
+// CreateQuestion mocks base method.
+func (m *MockStore) CreateQuestion(arg0 context.Context, arg1 db.CreateQuestionParams) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuestion indicates an expected call of CreateQuestion.
+func (mr *MockStoreMockRecorder) CreateQuestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuestion", reflect.TypeOf((*MockStore)(nil).CreateQuestion), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateResponse mocks base method.
+func (m *MockStore) CreateResponse(arg0 context.Context, arg1 db.CreateResponseParams) (db.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateResponse", arg0, arg1)
+	ret0, _ := ret[0].(db.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateResponse indicates an expected call of CreateResponse.
+func (mr *MockStoreMockRecorder) CreateResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResponse", reflect.TypeOf((*MockStore)(nil).CreateResponse), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateSsoConnection mocks base method.
+func (m *MockStore) CreateSsoConnection(arg0 context.Context, arg1 db.CreateSsoConnectionParams) (db.SsoConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSsoConnection", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSsoConnection indicates an expected call of CreateSsoConnection.
+func (mr *MockStoreMockRecorder) CreateSsoConnection(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSsoConnection", reflect.TypeOf((*MockStore)(nil).CreateSsoConnection), arg0, arg1)
+}
+
+// CreateSsoDomain mocks base method.
+func (m *MockStore) CreateSsoDomain(arg0 context.Context, arg1 db.CreateSsoDomainParams) (db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSsoDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSsoDomain indicates an expected call of CreateSsoDomain.
+func (mr *MockStoreMockRecorder) CreateSsoDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSsoDomain", reflect.TypeOf((*MockStore)(nil).CreateSsoDomain), arg0, arg1)
+}
+
+// CreateSsoLoginState mocks base method.
+func (m *MockStore) CreateSsoLoginState(arg0 context.Context, arg1 db.CreateSsoLoginStateParams) (db.SsoLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSsoLoginState", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSsoLoginState indicates an expected call of CreateSsoLoginState.
+func (mr *MockStoreMockRecorder) CreateSsoLoginState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSsoLoginState", reflect.TypeOf((*MockStore)(nil).CreateSsoLoginState), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateUserResponse mocks base method.
+func (m *MockStore) CreateUserResponse(arg0 context.Context, arg1 db.CreateUserResponseParams) (db.UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserResponse", arg0, arg1)
+	ret0, _ := ret[0].(db.UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserResponse indicates an expected call of CreateUserResponse.
+func (mr *MockStoreMockRecorder) CreateUserResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserResponse", reflect.TypeOf((*MockStore)(nil).CreateUserResponse), arg0, arg1)
+}
+
+// DeactivateUser mocks base method.
+func (m *MockStore) DeactivateUser(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockStoreMockRecorder) DeactivateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockStore)(nil).DeactivateUser), arg0, arg1)
+}
+
+// DeactivateUserTx mocks base method.
+func (m *MockStore) DeactivateUserTx(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateUserTx indicates an expected call of DeactivateUserTx.
+func (mr *MockStoreMockRecorder) DeactivateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUserTx", reflect.TypeOf((*MockStore)(nil).DeactivateUserTx), arg0, arg1)
+}
+
+// DeleteBotTx mocks base method.
+func (m *MockStore) DeleteBotTx(arg0 context.Context, arg1 db.SoftDeleteBotParams) (db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBotTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBotTx indicates an expected call of DeleteBotTx.
+func (mr *MockStoreMockRecorder) DeleteBotTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBotTx", reflect.TypeOf((*MockStore)(nil).DeleteBotTx), arg0, arg1)
+}
+
+// DeleteChannel mocks base method.
+func (m *MockStore) DeleteChannel(arg0 context.Context, arg1 int32) (db.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChannel", arg0, arg1)
+	ret0, _ := ret[0].(db.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteChannel indicates an expected call of DeleteChannel.
+func (mr *MockStoreMockRecorder) DeleteChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannel", reflect.TypeOf((*MockStore)(nil).DeleteChannel), arg0, arg1)
+}
+
+// DeleteCompanyTx mocks base method.
+func (m *MockStore) DeleteCompanyTx(arg0 context.Context, arg1 db.SoftDeleteCompanyParams) (db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompanyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCompanyTx indicates an expected call of DeleteCompanyTx.
+func (mr *MockStoreMockRecorder) DeleteCompanyTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompanyTx", reflect.TypeOf((*MockStore)(nil).DeleteCompanyTx), arg0, arg1)
+}
+
+// DeleteSsoConnection mocks base method.
+func (m *MockStore) DeleteSsoConnection(arg0 context.Context, arg1 db.DeleteSsoConnectionParams) (db.SsoConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSsoConnection", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSsoConnection indicates an expected call of DeleteSsoConnection.
+func (mr *MockStoreMockRecorder) DeleteSsoConnection(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSsoConnection", reflect.TypeOf((*MockStore)(nil).DeleteSsoConnection), arg0, arg1)
+}
+
+// DeleteSsoDomain mocks base method.
+func (m *MockStore) DeleteSsoDomain(arg0 context.Context, arg1 db.DeleteSsoDomainParams) (db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSsoDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSsoDomain indicates an expected call of DeleteSsoDomain.
+func (mr *MockStoreMockRecorder) DeleteSsoDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSsoDomain", reflect.TypeOf((*MockStore)(nil).DeleteSsoDomain), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserRecoveryCodes mocks base method.
+func (m *MockStore) DeleteUserRecoveryCodes(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserRecoveryCodes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserRecoveryCodes indicates an expected call of DeleteUserRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteUserRecoveryCodes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteUserRecoveryCodes), arg0, arg1)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), arg0, arg1)
+}
+
+// DisableUserTotp mocks base method.
+func (m *MockStore) DisableUserTotp(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUserTotp", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUserTotp indicates an expected call of DisableUserTotp.
+func (mr *MockStoreMockRecorder) DisableUserTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUserTotp", reflect.TypeOf((*MockStore)(nil).DisableUserTotp), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 db.EnableTOTPTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1)
+}
+
+// EnableUserTotp mocks base method.
+func (m *MockStore) EnableUserTotp(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTotp", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTotp indicates an expected call of EnableUserTotp.
+func (mr *MockStoreMockRecorder) EnableUserTotp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTotp", reflect.TypeOf((*MockStore)(nil).EnableUserTotp), arg0, arg1)
+}
+
+// ExecTx mocks base method.
+func (m *MockStore) ExecTx(arg0 context.Context, arg1 func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTx indicates an expected call of ExecTx.
+func (mr *MockStoreMockRecorder) ExecTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTx", reflect.TypeOf((*MockStore)(nil).ExecTx), arg0, arg1)
+}
+
+// GetApiKeyByPrefix mocks base method.
+func (m *MockStore) GetApiKeyByPrefix(arg0 context.Context, arg1 string) (db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetApiKeyByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetApiKeyByPrefix indicates an expected call of GetApiKeyByPrefix.
+func (mr *MockStoreMockRecorder) GetApiKeyByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApiKeyByPrefix", reflect.TypeOf((*MockStore)(nil).GetApiKeyByPrefix), arg0, arg1)
+}
+
+// GetBot mocks base method.
+func (m *MockStore) GetBot(arg0 context.Context, arg1 int64) (db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBot", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBot indicates an expected call of GetBot.
+func (mr *MockStoreMockRecorder) GetBot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBot", reflect.TypeOf((*MockStore)(nil).GetBot), arg0, arg1)
+}
+
+// GetChannel mocks base method.
+func (m *MockStore) GetChannel(arg0 context.Context, arg1 string) (db.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannel", arg0, arg1)
+	ret0, _ := ret[0].(db.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannel indicates an expected call of GetChannel.
+func (mr *MockStoreMockRecorder) GetChannel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockStore)(nil).GetChannel), arg0, arg1)
+}
+
+// GetChannelByID mocks base method.
+func (m *MockStore) GetChannelByID(arg0 context.Context, arg1 int32) (db.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannelByID", arg0, arg1)
+	ret0, _ := ret[0].(db.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannelByID indicates an expected call of GetChannelByID.
+func (mr *MockStoreMockRecorder) GetChannelByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannelByID", reflect.TypeOf((*MockStore)(nil).GetChannelByID), arg0, arg1)
+}
+
+// GetCompanyByEmail mocks base method.
+func (m *MockStore) GetCompanyByEmail(arg0 context.Context, arg1 string) (db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanyByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanyByEmail indicates an expected call of GetCompanyByEmail.
+func (mr *MockStoreMockRecorder) GetCompanyByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByEmail", reflect.TypeOf((*MockStore)(nil).GetCompanyByEmail), arg0, arg1)
+}
+
+// GetCompanyByID mocks base method.
+func (m *MockStore) GetCompanyByID(arg0 context.Context, arg1 int64) (db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanyByID", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanyByID indicates an expected call of GetCompanyByID.
+func (mr *MockStoreMockRecorder) GetCompanyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByID", reflect.TypeOf((*MockStore)(nil).GetCompanyByID), arg0, arg1)
+}
+
+// GetCompanySsoDomain mocks base method.
+func (m *MockStore) GetCompanySsoDomain(arg0 context.Context, arg1 db.GetCompanySsoDomainParams) (db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompanySsoDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompanySsoDomain indicates an expected call of GetCompanySsoDomain.
+func (mr *MockStoreMockRecorder) GetCompanySsoDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanySsoDomain", reflect.TypeOf((*MockStore)(nil).GetCompanySsoDomain), arg0, arg1)
+}
+
+// GetDeletedBot mocks base method.
+func (m *MockStore) GetDeletedBot(arg0 context.Context, arg1 int64) (db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedBot", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedBot indicates an expected call of GetDeletedBot.
+func (mr *MockStoreMockRecorder) GetDeletedBot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedBot", reflect.TypeOf((*MockStore)(nil).GetDeletedBot), arg0, arg1)
+}
+
+// GetDeletedQuestion mocks base method.
+func (m *MockStore) GetDeletedQuestion(arg0 context.Context, arg1 int64) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedQuestion indicates an expected call of GetDeletedQuestion.
+func (mr *MockStoreMockRecorder) GetDeletedQuestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedQuestion", reflect.TypeOf((*MockStore)(nil).GetDeletedQuestion), arg0, arg1)
+}
+
+// GetFirstQuestion mocks base method.
+func (m *MockStore) GetFirstQuestion(arg0 context.Context, arg1 int64) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstQuestion indicates an expected call of GetFirstQuestion.
+func (mr *MockStoreMockRecorder) GetFirstQuestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstQuestion", reflect.TypeOf((*MockStore)(nil).GetFirstQuestion), arg0, arg1)
+}
+
+// GetInvitation mocks base method.
+func (m *MockStore) GetInvitation(arg0 context.Context, arg1 int64) (db.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitation indicates an expected call of GetInvitation.
+func (mr *MockStoreMockRecorder) GetInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitation", reflect.TypeOf((*MockStore)(nil).GetInvitation), arg0, arg1)
+}
+
+// GetInvitationByTokenHashForUpdate mocks base method.
+func (m *MockStore) GetInvitationByTokenHashForUpdate(arg0 context.Context, arg1 string) (db.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitationByTokenHashForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitationByTokenHashForUpdate indicates an expected call of GetInvitationByTokenHashForUpdate.
+func (mr *MockStoreMockRecorder) GetInvitationByTokenHashForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitationByTokenHashForUpdate", reflect.TypeOf((*MockStore)(nil).GetInvitationByTokenHashForUpdate), arg0, arg1)
+}
+
+// GetLatestOtpCode mocks base method.
+func (m *MockStore) GetLatestOtpCode(arg0 context.Context, arg1 db.GetLatestOtpCodeParams) (db.OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestOtpCode", arg0, arg1)
+	ret0, _ := ret[0].(db.OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestOtpCode indicates an expected call of GetLatestOtpCode.
+func (mr *MockStoreMockRecorder) GetLatestOtpCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestOtpCode", reflect.TypeOf((*MockStore)(nil).GetLatestOtpCode), arg0, arg1)
+}
+
+// GetLoginAttempt mocks base method.
+func (m *MockStore) GetLoginAttempt(arg0 context.Context, arg1 string) (db.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempt indicates an expected call of GetLoginAttempt.
+func (mr *MockStoreMockRecorder) GetLoginAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempt", reflect.TypeOf((*MockStore)(nil).GetLoginAttempt), arg0, arg1)
+}
+
+// GetQuestion mocks base method.
+func (m *MockStore) GetQuestion(arg0 context.Context, arg1 int64) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuestion indicates an expected call of GetQuestion.
+func (mr *MockStoreMockRecorder) GetQuestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuestion", reflect.TypeOf((*MockStore)(nil).GetQuestion), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSsoConnection mocks base method.
+func (m *MockStore) GetSsoConnection(arg0 context.Context, arg1 int64) (db.SsoConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSsoConnection", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSsoConnection indicates an expected call of GetSsoConnection.
+func (mr *MockStoreMockRecorder) GetSsoConnection(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSsoConnection", reflect.TypeOf((*MockStore)(nil).GetSsoConnection), arg0, arg1)
+}
+
+// GetSsoConnectionByDomain mocks base method.
+func (m *MockStore) GetSsoConnectionByDomain(arg0 context.Context, arg1 string) (db.SsoConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSsoConnectionByDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSsoConnectionByDomain indicates an expected call of GetSsoConnectionByDomain.
+func (mr *MockStoreMockRecorder) GetSsoConnectionByDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSsoConnectionByDomain", reflect.TypeOf((*MockStore)(nil).GetSsoConnectionByDomain), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), arg0, arg1)
+}
+
+// GetVerifiedSsoDomain mocks base method.
+func (m *MockStore) GetVerifiedSsoDomain(arg0 context.Context, arg1 db.GetVerifiedSsoDomainParams) (db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVerifiedSsoDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVerifiedSsoDomain indicates an expected call of GetVerifiedSsoDomain.
+func (mr *MockStoreMockRecorder) GetVerifiedSsoDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerifiedSsoDomain", reflect.TypeOf((*MockStore)(nil).GetVerifiedSsoDomain), arg0, arg1)
+}
+
+// IncrementOtpCodeAttempts mocks base method.
+func (m *MockStore) IncrementOtpCodeAttempts(arg0 context.Context, arg1 int64) (db.OtpCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementOtpCodeAttempts", arg0, arg1)
+	ret0, _ := ret[0].(db.OtpCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementOtpCodeAttempts indicates an expected call of IncrementOtpCodeAttempts.
+func (mr *MockStoreMockRecorder) IncrementOtpCodeAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementOtpCodeAttempts", reflect.TypeOf((*MockStore)(nil).IncrementOtpCodeAttempts), arg0, arg1)
+}
+
+// ListAllBots mocks base method.
+func (m *MockStore) ListAllBots(arg0 context.Context, arg1 db.ListAllBotsParams) ([]db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllBots", arg0, arg1)
+	ret0, _ := ret[0].([]db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllBots indicates an expected call of ListAllBots.
+func (mr *MockStoreMockRecorder) ListAllBots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllBots", reflect.TypeOf((*MockStore)(nil).ListAllBots), arg0, arg1)
+}
+
+// ListAuditLogs mocks base method.
+func (m *MockStore) ListAuditLogs(arg0 context.Context, arg1 db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogs", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogs indicates an expected call of ListAuditLogs.
+func (mr *MockStoreMockRecorder) ListAuditLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogs", reflect.TypeOf((*MockStore)(nil).ListAuditLogs), arg0, arg1)
+}
+
+// ListChannels mocks base method.
+func (m *MockStore) ListChannels(arg0 context.Context, arg1 db.ListChannelsParams) ([]db.Channel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChannels", arg0, arg1)
+	ret0, _ := ret[0].([]db.Channel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChannels indicates an expected call of ListChannels.
+func (mr *MockStoreMockRecorder) ListChannels(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannels", reflect.TypeOf((*MockStore)(nil).ListChannels), arg0, arg1)
+}
+
+// ListCompanies mocks base method.
+func (m *MockStore) ListCompanies(arg0 context.Context, arg1 db.ListCompaniesParams) ([]db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanies", arg0, arg1)
+	ret0, _ := ret[0].([]db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanies indicates an expected call of ListCompanies.
+func (mr *MockStoreMockRecorder) ListCompanies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanies", reflect.TypeOf((*MockStore)(nil).ListCompanies), arg0, arg1)
+}
+
+// ListCompanyApiKeys mocks base method.
+func (m *MockStore) ListCompanyApiKeys(arg0 context.Context, arg1 db.ListCompanyApiKeysParams) ([]db.ApiKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanyApiKeys", arg0, arg1)
+	ret0, _ := ret[0].([]db.ApiKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanyApiKeys indicates an expected call of ListCompanyApiKeys.
+func (mr *MockStoreMockRecorder) ListCompanyApiKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyApiKeys", reflect.TypeOf((*MockStore)(nil).ListCompanyApiKeys), arg0, arg1)
+}
+
+// ListCompanyBots mocks base method.
+func (m *MockStore) ListCompanyBots(arg0 context.Context, arg1 db.ListCompanyBotsParams) ([]db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanyBots", arg0, arg1)
+	ret0, _ := ret[0].([]db.Bot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanyBots indicates an expected call of ListCompanyBots.
+func (mr *MockStoreMockRecorder) ListCompanyBots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyBots", reflect.TypeOf((*MockStore)(nil).ListCompanyBots), arg0, arg1)
+}
+
+// ListCompanyInvitations mocks base method.
+func (m *MockStore) ListCompanyInvitations(arg0 context.Context, arg1 db.ListCompanyInvitationsParams) ([]db.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanyInvitations", arg0, arg1)
+	ret0, _ := ret[0].([]db.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanyInvitations indicates an expected call of ListCompanyInvitations.
+func (mr *MockStoreMockRecorder) ListCompanyInvitations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyInvitations", reflect.TypeOf((*MockStore)(nil).ListCompanyInvitations), arg0, arg1)
+}
+
+// ListCompanySsoConnections mocks base method.
+func (m *MockStore) ListCompanySsoConnections(arg0 context.Context, arg1 db.ListCompanySsoConnectionsParams) ([]db.SsoConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanySsoConnections", arg0, arg1)
+	ret0, _ := ret[0].([]db.SsoConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanySsoConnections indicates an expected call of ListCompanySsoConnections.
+func (mr *MockStoreMockRecorder) ListCompanySsoConnections(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanySsoConnections", reflect.TypeOf((*MockStore)(nil).ListCompanySsoConnections), arg0, arg1)
+}
+
+// ListCompanySsoDomains mocks base method.
+func (m *MockStore) ListCompanySsoDomains(arg0 context.Context, arg1 db.ListCompanySsoDomainsParams) ([]db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanySsoDomains", arg0, arg1)
+	ret0, _ := ret[0].([]db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanySsoDomains indicates an expected call of ListCompanySsoDomains.
+func (mr *MockStoreMockRecorder) ListCompanySsoDomains(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanySsoDomains", reflect.TypeOf((*MockStore)(nil).ListCompanySsoDomains), arg0, arg1)
+}
+
+// ListCompanyUsers mocks base method.
+func (m *MockStore) ListCompanyUsers(arg0 context.Context, arg1 db.ListCompanyUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCompanyUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCompanyUsers indicates an expected call of ListCompanyUsers.
+func (mr *MockStoreMockRecorder) ListCompanyUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyUsers", reflect.TypeOf((*MockStore)(nil).ListCompanyUsers), arg0, arg1)
+}
+
+// LockCompanyOwners mocks base method.
+func (m *MockStore) LockCompanyOwners(arg0 context.Context, arg1 int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockCompanyOwners", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockCompanyOwners indicates an expected call of LockCompanyOwners.
+func (mr *MockStoreMockRecorder) LockCompanyOwners(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockCompanyOwners", reflect.TypeOf((*MockStore)(nil).LockCompanyOwners), arg0, arg1)
+}
+
+// LockLoginAttempt mocks base method.
+func (m *MockStore) LockLoginAttempt(arg0 context.Context, arg1 db.LockLoginAttemptParams) (db.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockLoginAttempt indicates an expected call of LockLoginAttempt.
+func (mr *MockStoreMockRecorder) LockLoginAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginAttempt", reflect.TypeOf((*MockStore)(nil).LockLoginAttempt), arg0, arg1)
+}
+
+// MarkUserVerified mocks base method.
+func (m *MockStore) MarkUserVerified(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUserVerified", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUserVerified indicates an expected call of MarkUserVerified.
+func (mr *MockStoreMockRecorder) MarkUserVerified(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserVerified", reflect.TypeOf((*MockStore)(nil).MarkUserVerified), arg0, arg1)
+}
+
+// MatchResponse mocks base method.
+func (m *MockStore) MatchResponse(arg0 context.Context, arg1 db.MatchResponseParams) (db.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchResponse", arg0, arg1)
+	ret0, _ := ret[0].(db.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MatchResponse indicates an expected call of MatchResponse.
+func (mr *MockStoreMockRecorder) MatchResponse(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchResponse", reflect.TypeOf((*MockStore)(nil).MatchResponse), arg0, arg1)
+}
+
+// MigrationVersion mocks base method.
+func (m *MockStore) MigrationVersion(arg0 context.Context) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockStoreMockRecorder) MigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockStore)(nil).MigrationVersion), arg0)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// PurgeDeletedBots mocks base method.
+func (m *MockStore) PurgeDeletedBots(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedBots", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedBots indicates an expected call of PurgeDeletedBots.
+func (mr *MockStoreMockRecorder) PurgeDeletedBots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedBots", reflect.TypeOf((*MockStore)(nil).PurgeDeletedBots), arg0, arg1)
+}
+
+// PurgeDeletedCompanies mocks base method.
+func (m *MockStore) PurgeDeletedCompanies(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedCompanies", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedCompanies indicates an expected call of PurgeDeletedCompanies.
+func (mr *MockStoreMockRecorder) PurgeDeletedCompanies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedCompanies", reflect.TypeOf((*MockStore)(nil).PurgeDeletedCompanies), arg0, arg1)
+}
+
+// PurgeDeletedQuestions mocks base method.
+func (m *MockStore) PurgeDeletedQuestions(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedQuestions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateBot indicates an expected call of CreateBot.
-func (mr *MockStoreMockRecorder) CreateBot(arg0, arg1 interface{}) *gomock.Call {
+// PurgeDeletedQuestions indicates an expected call of PurgeDeletedQuestions.
+func (mr *MockStoreMockRecorder) PurgeDeletedQuestions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBot", reflect.TypeOf((*MockStore)(nil).CreateBot), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedQuestions", reflect.TypeOf((*MockStore)(nil).PurgeDeletedQuestions), arg0, arg1)
 }
 
-// CreateChannel mocks base method.
-func (m *MockStore) CreateChannel(arg0 context.Context, arg1 string) (db.Channel, error) {
+// ReactivateUser mocks base method.
+func (m *MockStore) ReactivateUser(arg0 context.Context, arg1 int64) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateChannel", arg0, arg1)
-	ret0, _ := ret[0].(db.Channel)
+	ret := m.ctrl.Call(m, "ReactivateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateChannel indicates an expected call of CreateChannel.
-func (mr *MockStoreMockRecorder) CreateChannel(arg0, arg1 interface{}) *gomock.Call {
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockStoreMockRecorder) ReactivateUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChannel", reflect.TypeOf((*MockStore)(nil).CreateChannel), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockStore)(nil).ReactivateUser), arg0, arg1)
 }
 
-// CreateCompany mocks base method.
-func (m *MockStore) CreateCompany(arg0 context.Context, arg1 db.CreateCompanyParams) (db.Company, error) {
+// RecordFailedLogin mocks base method.
+func (m *MockStore) RecordFailedLogin(arg0 context.Context, arg1 db.RecordFailedLoginParams) (db.LoginAttempt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCompany", arg0, arg1)
-	ret0, _ := ret[0].(db.Company)
+	ret := m.ctrl.Call(m, "RecordFailedLogin", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginAttempt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateCompany indicates an expected call of CreateCompany.
-func (mr *MockStoreMockRecorder) CreateCompany(arg0, arg1 interface{}) *gomock.Call {
+// RecordFailedLogin indicates an expected call of RecordFailedLogin.
+func (mr *MockStoreMockRecorder) RecordFailedLogin(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockStore)(nil).CreateCompany), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedLogin", reflect.TypeOf((*MockStore)(nil).RecordFailedLogin), arg0, arg1)
 }
 
-// CreateQuestion mocks base method.
-func (m *MockStore) CreateQuestion(arg0 context.Context, arg1 db.CreateQuestionParams) (db.Question, error) {
+// ResetLoginAttempts mocks base method.
+func (m *MockStore) ResetLoginAttempts(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateQuestion", arg0, arg1)
-	ret0, _ := ret[0].(db.Question)
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockStoreMockRecorder) ResetLoginAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockStore)(nil).ResetLoginAttempts), arg0, arg1)
+}
+
+// RestoreBot mocks base method.
+func (m *MockStore) RestoreBot(arg0 context.Context, arg1 int64) (db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBot", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateQuestion indicates an expected call of CreateQuestion.
-func (mr *MockStoreMockRecorder) CreateQuestion(arg0, arg1 interface{}) *gomock.Call {
+// RestoreBot indicates an expected call of RestoreBot.
+func (mr *MockStoreMockRecorder) RestoreBot(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuestion", reflect.TypeOf((*MockStore)(nil).CreateQuestion), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBot", reflect.TypeOf((*MockStore)(nil).RestoreBot), arg0, arg1)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+// RestoreBotQuestions mocks base method.
+func (m *MockStore) RestoreBotQuestions(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "RestoreBotQuestions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreBotQuestions indicates an expected call of RestoreBotQuestions.
+func (mr *MockStoreMockRecorder) RestoreBotQuestions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBotQuestions", reflect.TypeOf((*MockStore)(nil).RestoreBotQuestions), arg0, arg1)
+}
+
+// RestoreBotTx mocks base method.
+func (m *MockStore) RestoreBotTx(arg0 context.Context, arg1 int64) (db.Bot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBotTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+// RestoreBotTx indicates an expected call of RestoreBotTx.
+func (mr *MockStoreMockRecorder) RestoreBotTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBotTx", reflect.TypeOf((*MockStore)(nil).RestoreBotTx), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// RestoreCompany mocks base method.
+func (m *MockStore) RestoreCompany(arg0 context.Context, arg1 int64) (db.Company, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "RestoreCompany", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockStoreMockRecorder) RestoreCompany(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockStore)(nil).RestoreCompany), arg0, arg1)
 }
 
-// DeleteBot mocks base method.
-func (m *MockStore) DeleteBot(arg0 context.Context, arg1 int64) error {
+// RestoreCompanyBots mocks base method.
+func (m *MockStore) RestoreCompanyBots(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBot", arg0, arg1)
+	ret := m.ctrl.Call(m, "RestoreCompanyBots", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteBot indicates an expected call of DeleteBot.
-func (mr *MockStoreMockRecorder) DeleteBot(arg0, arg1 interface{}) *gomock.Call {
+// RestoreCompanyBots indicates an expected call of RestoreCompanyBots.
+func (mr *MockStoreMockRecorder) RestoreCompanyBots(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBot", reflect.TypeOf((*MockStore)(nil).DeleteBot), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompanyBots", reflect.TypeOf((*MockStore)(nil).RestoreCompanyBots), arg0, arg1)
 }
 
-// DeleteChannel mocks base method.
-func (m *MockStore) DeleteChannel(arg0 context.Context, arg1 int32) error {
+// RestoreCompanyQuestions mocks base method.
+func (m *MockStore) RestoreCompanyQuestions(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteChannel", arg0, arg1)
+	ret := m.ctrl.Call(m, "RestoreCompanyQuestions", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteChannel indicates an expected call of DeleteChannel.
-func (mr *MockStoreMockRecorder) DeleteChannel(arg0, arg1 interface{}) *gomock.Call {
+// RestoreCompanyQuestions indicates an expected call of RestoreCompanyQuestions.
+func (mr *MockStoreMockRecorder) RestoreCompanyQuestions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannel", reflect.TypeOf((*MockStore)(nil).DeleteChannel), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompanyQuestions", reflect.TypeOf((*MockStore)(nil).RestoreCompanyQuestions), arg0, arg1)
 }
 
-// DeleteCompany mocks base method.
-func (m *MockStore) DeleteCompany(arg0 context.Context, arg1 int64) error {
+// RestoreCompanyTx mocks base method.
+func (m *MockStore) RestoreCompanyTx(arg0 context.Context, arg1 int64) (db.Company, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCompany", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "RestoreCompanyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteCompany indicates an expected call of DeleteCompany.
-func (mr *MockStoreMockRecorder) DeleteCompany(arg0, arg1 interface{}) *gomock.Call {
+// RestoreCompanyTx indicates an expected call of RestoreCompanyTx.
+func (mr *MockStoreMockRecorder) RestoreCompanyTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockStore)(nil).DeleteCompany), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompanyTx", reflect.TypeOf((*MockStore)(nil).RestoreCompanyTx), arg0, arg1)
 }
 
-// GetBot mocks base method.
-func (m *MockStore) GetBot(arg0 context.Context, arg1 int64) (db.Bot, error) {
+// RestoreQuestion mocks base method.
+func (m *MockStore) RestoreQuestion(arg0 context.Context, arg1 int64) (db.Question, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBot", arg0, arg1)
-	ret0, _ := ret[0].(db.Bot)
+	ret := m.ctrl.Call(m, "RestoreQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBot indicates an expected call of GetBot.
-func (mr *MockStoreMockRecorder) GetBot(arg0, arg1 interface{}) *gomock.Call {
+// RestoreQuestion indicates an expected call of RestoreQuestion.
+func (mr *MockStoreMockRecorder) RestoreQuestion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBot", reflect.TypeOf((*MockStore)(nil).GetBot), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreQuestion", reflect.TypeOf((*MockStore)(nil).RestoreQuestion), arg0, arg1)
 }
 
-// GetChannel mocks base method.
-func (m *MockStore) GetChannel(arg0 context.Context, arg1 string) (db.Channel, error) {
+// RevokeApiKey mocks base method.
+func (m *MockStore) RevokeApiKey(arg0 context.Context, arg1 db.RevokeApiKeyParams) (db.ApiKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetChannel", arg0, arg1)
-	ret0, _ := ret[0].(db.Channel)
+	ret := m.ctrl.Call(m, "RevokeApiKey", arg0, arg1)
+	ret0, _ := ret[0].(db.ApiKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetChannel indicates an expected call of GetChannel.
-func (mr *MockStoreMockRecorder) GetChannel(arg0, arg1 interface{}) *gomock.Call {
+// RevokeApiKey indicates an expected call of RevokeApiKey.
+func (mr *MockStoreMockRecorder) RevokeApiKey(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockStore)(nil).GetChannel), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeApiKey", reflect.TypeOf((*MockStore)(nil).RevokeApiKey), arg0, arg1)
 }
 
-// GetCompanyByEmail mocks base method.
-func (m *MockStore) GetCompanyByEmail(arg0 context.Context, arg1 string) (db.Company, error) {
+// RevokeInvitation mocks base method.
+func (m *MockStore) RevokeInvitation(arg0 context.Context, arg1 db.RevokeInvitationParams) (db.Invitation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCompanyByEmail", arg0, arg1)
-	ret0, _ := ret[0].(db.Company)
+	ret := m.ctrl.Call(m, "RevokeInvitation", arg0, arg1)
+	ret0, _ := ret[0].(db.Invitation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCompanyByEmail indicates an expected call of GetCompanyByEmail.
-func (mr *MockStoreMockRecorder) GetCompanyByEmail(arg0, arg1 interface{}) *gomock.Call {
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockStoreMockRecorder) RevokeInvitation(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByEmail", reflect.TypeOf((*MockStore)(nil).GetCompanyByEmail), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockStore)(nil).RevokeInvitation), arg0, arg1)
 }
 
-// GetCompanyByID mocks base method.
-func (m *MockStore) GetCompanyByID(arg0 context.Context, arg1 int64) (db.Company, error) {
+// SearchBots mocks base method.
+func (m *MockStore) SearchBots(arg0 context.Context, arg1 db.SearchBotsParams) ([]db.Bot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCompanyByID", arg0, arg1)
-	ret0, _ := ret[0].(db.Company)
+	ret := m.ctrl.Call(m, "SearchBots", arg0, arg1)
+	ret0, _ := ret[0].([]db.Bot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCompanyByID indicates an expected call of GetCompanyByID.
-func (mr *MockStoreMockRecorder) GetCompanyByID(arg0, arg1 interface{}) *gomock.Call {
+// SearchBots indicates an expected call of SearchBots.
+func (mr *MockStoreMockRecorder) SearchBots(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByID", reflect.TypeOf((*MockStore)(nil).GetCompanyByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBots", reflect.TypeOf((*MockStore)(nil).SearchBots), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// SearchCompanies mocks base method.
+func (m *MockStore) SearchCompanies(arg0 context.Context, arg1 db.SearchCompaniesParams) ([]db.Company, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "SearchCompanies", arg0, arg1)
+	ret0, _ := ret[0].([]db.Company)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// SearchCompanies indicates an expected call of SearchCompanies.
+func (mr *MockStoreMockRecorder) SearchCompanies(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCompanies", reflect.TypeOf((*MockStore)(nil).SearchCompanies), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// SetUserTotpSecret mocks base method.
+func (m *MockStore) SetUserTotpSecret(arg0 context.Context, arg1 db.SetUserTotpSecretParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetUserTotpSecret", arg0, arg1)
 	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// SetUserTotpSecret indicates an expected call of SetUserTotpSecret.
+func (mr *MockStoreMockRecorder) SetUserTotpSecret(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserTotpSecret", reflect.TypeOf((*MockStore)(nil).SetUserTotpSecret), arg0, arg1)
 }
 
-// ListAllBots mocks base method.
-func (m *MockStore) ListAllBots(arg0 context.Context, arg1 db.ListAllBotsParams) ([]db.Bot, error) {
+// SignupTx mocks base method.
+func (m *MockStore) SignupTx(arg0 context.Context, arg1 db.SignupTxParams) (db.SignupTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAllBots", arg0, arg1)
-	ret0, _ := ret[0].([]db.Bot)
+	ret := m.ctrl.Call(m, "SignupTx", arg0, arg1)
+	ret0, _ := ret[0].(db.SignupTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAllBots indicates an expected call of ListAllBots.
-func (mr *MockStoreMockRecorder) ListAllBots(arg0, arg1 interface{}) *gomock.Call {
+// SignupTx indicates an expected call of SignupTx.
+func (mr *MockStoreMockRecorder) SignupTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllBots", reflect.TypeOf((*MockStore)(nil).ListAllBots), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignupTx", reflect.TypeOf((*MockStore)(nil).SignupTx), arg0, arg1)
 }
 
-// ListChannels mocks base method.
-func (m *MockStore) ListChannels(arg0 context.Context, arg1 db.ListChannelsParams) ([]db.Channel, error) {
+// SoftDeleteBot mocks base method.
+func (m *MockStore) SoftDeleteBot(arg0 context.Context, arg1 db.SoftDeleteBotParams) (db.Bot, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListChannels", arg0, arg1)
-	ret0, _ := ret[0].([]db.Channel)
+	ret := m.ctrl.Call(m, "SoftDeleteBot", arg0, arg1)
+	ret0, _ := ret[0].(db.Bot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListChannels indicates an expected call of ListChannels.
-func (mr *MockStoreMockRecorder) ListChannels(arg0, arg1 interface{}) *gomock.Call {
+// SoftDeleteBot indicates an expected call of SoftDeleteBot.
+func (mr *MockStoreMockRecorder) SoftDeleteBot(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChannels", reflect.TypeOf((*MockStore)(nil).ListChannels), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteBot", reflect.TypeOf((*MockStore)(nil).SoftDeleteBot), arg0, arg1)
 }
 
-// ListCompanies mocks base method.
-func (m *MockStore) ListCompanies(arg0 context.Context, arg1 db.ListCompaniesParams) ([]db.Company, error) {
+// SoftDeleteBotQuestions mocks base method.
+func (m *MockStore) SoftDeleteBotQuestions(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCompanies", arg0, arg1)
-	ret0, _ := ret[0].([]db.Company)
+	ret := m.ctrl.Call(m, "SoftDeleteBotQuestions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteBotQuestions indicates an expected call of SoftDeleteBotQuestions.
+func (mr *MockStoreMockRecorder) SoftDeleteBotQuestions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteBotQuestions", reflect.TypeOf((*MockStore)(nil).SoftDeleteBotQuestions), arg0, arg1)
+}
+
+// SoftDeleteCompany mocks base method.
+func (m *MockStore) SoftDeleteCompany(arg0 context.Context, arg1 db.SoftDeleteCompanyParams) (db.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteCompany", arg0, arg1)
+	ret0, _ := ret[0].(db.Company)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListCompanies indicates an expected call of ListCompanies.
-func (mr *MockStoreMockRecorder) ListCompanies(arg0, arg1 interface{}) *gomock.Call {
+// SoftDeleteCompany indicates an expected call of SoftDeleteCompany.
+func (mr *MockStoreMockRecorder) SoftDeleteCompany(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanies", reflect.TypeOf((*MockStore)(nil).ListCompanies), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteCompany", reflect.TypeOf((*MockStore)(nil).SoftDeleteCompany), arg0, arg1)
 }
 
-// ListCompanyBots mocks base method.
-func (m *MockStore) ListCompanyBots(arg0 context.Context, arg1 db.ListCompanyBotsParams) ([]db.Bot, error) {
+// SoftDeleteCompanyBots mocks base method.
+func (m *MockStore) SoftDeleteCompanyBots(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCompanyBots", arg0, arg1)
-	ret0, _ := ret[0].([]db.Bot)
+	ret := m.ctrl.Call(m, "SoftDeleteCompanyBots", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteCompanyBots indicates an expected call of SoftDeleteCompanyBots.
+func (mr *MockStoreMockRecorder) SoftDeleteCompanyBots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteCompanyBots", reflect.TypeOf((*MockStore)(nil).SoftDeleteCompanyBots), arg0, arg1)
+}
+
+// SoftDeleteCompanyQuestions mocks base method.
+func (m *MockStore) SoftDeleteCompanyQuestions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteCompanyQuestions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteCompanyQuestions indicates an expected call of SoftDeleteCompanyQuestions.
+func (mr *MockStoreMockRecorder) SoftDeleteCompanyQuestions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteCompanyQuestions", reflect.TypeOf((*MockStore)(nil).SoftDeleteCompanyQuestions), arg0, arg1)
+}
+
+// SoftDeleteQuestion mocks base method.
+func (m *MockStore) SoftDeleteQuestion(arg0 context.Context, arg1 db.SoftDeleteQuestionParams) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListCompanyBots indicates an expected call of ListCompanyBots.
-func (mr *MockStoreMockRecorder) ListCompanyBots(arg0, arg1 interface{}) *gomock.Call {
+// SoftDeleteQuestion indicates an expected call of SoftDeleteQuestion.
+func (mr *MockStoreMockRecorder) SoftDeleteQuestion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyBots", reflect.TypeOf((*MockStore)(nil).ListCompanyBots), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteQuestion", reflect.TypeOf((*MockStore)(nil).SoftDeleteQuestion), arg0, arg1)
+}
+
+// TouchApiKey mocks base method.
+func (m *MockStore) TouchApiKey(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchApiKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchApiKey indicates an expected call of TouchApiKey.
+func (mr *MockStoreMockRecorder) TouchApiKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchApiKey", reflect.TypeOf((*MockStore)(nil).TouchApiKey), arg0, arg1)
 }
 
 // UpdateBot mocks base method.
@@ -362,3 +1778,138 @@ func (mr *MockStoreMockRecorder) UpdateCompany(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockStore)(nil).UpdateCompany), arg0, arg1)
 }
+
+// UpdateQuestion mocks base method.
+func (m *MockStore) UpdateQuestion(arg0 context.Context, arg1 db.UpdateQuestionParams) (db.Question, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuestion", arg0, arg1)
+	ret0, _ := ret[0].(db.Question)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateQuestion indicates an expected call of UpdateQuestion.
+func (mr *MockStoreMockRecorder) UpdateQuestion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuestion", reflect.TypeOf((*MockStore)(nil).UpdateQuestion), arg0, arg1)
+}
+
+// UpdateSessionConversation mocks base method.
+func (m *MockStore) UpdateSessionConversation(arg0 context.Context, arg1 db.UpdateSessionConversationParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSessionConversation", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSessionConversation indicates an expected call of UpdateSessionConversation.
+func (mr *MockStoreMockRecorder) UpdateSessionConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSessionConversation", reflect.TypeOf((*MockStore)(nil).UpdateSessionConversation), arg0, arg1)
+}
+
+// UpdateUser mocks base method.
+func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 db.UpdateUserPasswordParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpdateUserTx mocks base method.
+func (m *MockStore) UpdateUserTx(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTx indicates an expected call of UpdateUserTx.
+func (mr *MockStoreMockRecorder) UpdateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTx", reflect.TypeOf((*MockStore)(nil).UpdateUserTx), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseUserTotpCounter mocks base method.
+func (m *MockStore) UseUserTotpCounter(arg0 context.Context, arg1 db.UseUserTotpCounterParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseUserTotpCounter", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseUserTotpCounter indicates an expected call of UseUserTotpCounter.
+func (mr *MockStoreMockRecorder) UseUserTotpCounter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseUserTotpCounter", reflect.TypeOf((*MockStore)(nil).UseUserTotpCounter), arg0, arg1)
+}
+
+// VerifyPhoneTx mocks base method.
+func (m *MockStore) VerifyPhoneTx(arg0 context.Context, arg1 db.VerifyPhoneTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPhoneTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPhoneTx indicates an expected call of VerifyPhoneTx.
+func (mr *MockStoreMockRecorder) VerifyPhoneTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPhoneTx", reflect.TypeOf((*MockStore)(nil).VerifyPhoneTx), arg0, arg1)
+}
+
+// VerifySsoDomain mocks base method.
+func (m *MockStore) VerifySsoDomain(arg0 context.Context, arg1 int64) (db.SsoDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySsoDomain", arg0, arg1)
+	ret0, _ := ret[0].(db.SsoDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySsoDomain indicates an expected call of VerifySsoDomain.
+func (mr *MockStoreMockRecorder) VerifySsoDomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySsoDomain", reflect.TypeOf((*MockStore)(nil).VerifySsoDomain), arg0, arg1)
+}
@@ -0,0 +1,43 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (
+  company_id,
+  created_by,
+  name,
+  prefix,
+  secret_hash,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetApiKeyByPrefix :one
+-- the keys of a deleted company stop working with it
+SELECT api_keys.* FROM api_keys
+JOIN companies ON companies.id = api_keys.company_id AND companies.deleted_at IS NULL
+WHERE api_keys.prefix = $1 LIMIT 1;
+
+-- name: ListCompanyApiKeys :many
+SELECT * FROM api_keys
+WHERE company_id = sqlc.arg('company_id')
+ AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanyApiKeys :one
+SELECT count(*) FROM api_keys
+WHERE company_id = $1;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys
+SET
+ revoked_at = now()
+WHERE id = sqlc.arg('id')
+ AND company_id = sqlc.arg('company_id')
+ AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1;
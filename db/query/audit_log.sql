@@ -0,0 +1,40 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+  company_id,
+  actor_user_id,
+  actor_api_key_id,
+  actor_name,
+  action,
+  entity_type,
+  entity_id,
+  changes,
+  ip,
+  user_agent,
+  request_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+-- newest first, a page starts before after_id
+SELECT * FROM audit_logs
+WHERE company_id = sqlc.arg('company_id')
+ AND (sqlc.narg('actor_user_id')::bigint IS NULL OR actor_user_id = sqlc.narg('actor_user_id'))
+ AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+ AND (sqlc.narg('entity_type')::text IS NULL OR entity_type = sqlc.narg('entity_type'))
+ AND (sqlc.narg('entity_id')::bigint IS NULL OR entity_id = sqlc.narg('entity_id'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ AND (sqlc.narg('after_id')::bigint IS NULL OR id < sqlc.narg('after_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountAuditLogs :one
+SELECT count(*) FROM audit_logs
+WHERE company_id = sqlc.arg('company_id')
+ AND (sqlc.narg('actor_user_id')::bigint IS NULL OR actor_user_id = sqlc.narg('actor_user_id'))
+ AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+ AND (sqlc.narg('entity_type')::text IS NULL OR entity_type = sqlc.narg('entity_type'))
+ AND (sqlc.narg('entity_id')::bigint IS NULL OR entity_id = sqlc.narg('entity_id'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'));
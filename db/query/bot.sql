@@ -8,32 +8,102 @@ INSERT INTO bots (
 
 -- name: GetBot :one
 SELECT * FROM bots
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 
 -- name: ListAllBots :many
 SELECT * FROM bots
+WHERE deleted_at IS NULL
+ AND id > sqlc.arg('after_id')
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit');
 
 -- name: ListCompanyBots :many
 SELECT * FROM bots
-WHERE company_id = $1
+WHERE company_id = sqlc.arg('company_id')
+ AND deleted_at IS NULL
+ AND id > sqlc.arg('after_id')
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit');
+
+-- name: SearchBots :many
+-- sort is one of title, updated_at or the same prefixed with - for descending order, and id otherwise.
+-- after_text and after_time are the sort key of the row after_id.
+SELECT * FROM bots
+WHERE deleted_at IS NULL
+ AND (sqlc.narg('company_id')::bigint IS NULL OR company_id = sqlc.narg('company_id'))
+ AND (sqlc.narg('search')::text IS NULL OR title ILIKE sqlc.narg('search'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+ AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+ AND (sqlc.narg('after_id')::bigint IS NULL OR CASE sqlc.arg('sort')::text
+  WHEN 'title' THEN (title, id) > (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN '-title' THEN (title, id) < (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN 'updated_at' THEN (updated_at, id) > (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+  WHEN '-updated_at' THEN (updated_at, id) < (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+  ELSE id > sqlc.narg('after_id')
+ END)
+ORDER BY
+ CASE WHEN sqlc.arg('sort')::text = 'title' THEN title END,
+ CASE WHEN sqlc.arg('sort')::text = '-title' THEN title END DESC,
+ CASE WHEN sqlc.arg('sort')::text = 'updated_at' THEN updated_at END,
+ CASE WHEN sqlc.arg('sort')::text = '-updated_at' THEN updated_at END DESC,
+ CASE WHEN sqlc.arg('sort')::text LIKE '-%' THEN id END DESC,
+ id
+LIMIT sqlc.arg('limit');
+
+-- name: CountBots :one
+SELECT count(*) FROM bots
+WHERE deleted_at IS NULL
+ AND (sqlc.narg('company_id')::bigint IS NULL OR company_id = sqlc.narg('company_id'))
+ AND (sqlc.narg('search')::text IS NULL OR title ILIKE sqlc.narg('search'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+ AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'));
 
 -- name: UpdateBot :one
+-- a valid updated_at only updates the bot if it was not changed since
 UPDATE bots
 SET
  title = coalesce(sqlc.narg('title'), title),
  company_id = coalesce(sqlc.narg('company_id'), company_id),
  updated_at = now()
-WHERE id = sqlc.arg('id') 
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
+RETURNING *;
+
+
+-- name: SoftDeleteBot :one
+-- a valid updated_at only deletes the bot if it was not changed since
+UPDATE bots
+SET deleted_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
 RETURNING *;
 
+-- name: GetDeletedBot :one
+SELECT * FROM bots
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: RestoreBot :one
+UPDATE bots
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: SoftDeleteCompanyBots :exec
+UPDATE bots
+SET deleted_at = (SELECT c.deleted_at FROM companies c WHERE c.id = $1)
+WHERE company_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreCompanyBots :exec
+-- restores the bots deleted with the company, not the ones deleted before it
+UPDATE bots
+SET deleted_at = NULL
+WHERE company_id = $1 AND deleted_at = (SELECT c.deleted_at FROM companies c WHERE c.id = $1);
 
--- name: DeleteBot :exec
+-- name: PurgeDeletedBots :execrows
 DELETE FROM bots
-WHERE id = $1;
+WHERE deleted_at < sqlc.arg('before')::timestamptz;
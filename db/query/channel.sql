@@ -9,11 +9,18 @@ INSERT INTO channels (
 SELECT * FROM channels
 WHERE name = $1 LIMIT 1;
 
+-- name: GetChannelByID :one
+SELECT * FROM channels
+WHERE id = $1 LIMIT 1;
+
 -- name: ListChannels :many
 SELECT * FROM channels
+WHERE id > sqlc.arg('after_id')
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit');
+
+-- name: CountChannels :one
+SELECT count(*) FROM channels;
 
 
 -- name: UpdateChannel :one
@@ -25,7 +32,8 @@ WHERE id = sqlc.arg('id')
 RETURNING *;
 
 
--- name: DeleteChannel :exec
+-- name: DeleteChannel :one
 DELETE FROM channels
-WHERE id = $1;
+WHERE id = $1
+RETURNING *;
 
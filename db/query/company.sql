@@ -9,32 +9,91 @@ INSERT INTO companies (
 
 -- name: GetCompanyByEmail :one
 SELECT * FROM companies
-WHERE email = $1 LIMIT 1;
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetCompanyByID :one
 SELECT * FROM companies
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 
 -- name: UpdateCompany :one
+-- a valid updated_at only updates the company if it was not changed since
 UPDATE companies
 SET
  email = coalesce(sqlc.narg('email'), email),
  phone = coalesce(sqlc.narg('phone'), phone),
  name = coalesce(sqlc.narg('name'), name),
  updated_at = now()
-WHERE id = sqlc.arg('id') 
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
 RETURNING *;
 
 
 -- name: ListCompanies :many
 SELECT * FROM companies
+WHERE deleted_at IS NULL
+ AND (sqlc.narg('id')::bigint IS NULL OR id = sqlc.narg('id'))
+ AND id > sqlc.arg('after_id')
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT sqlc.arg('limit');
 
--- name: DeleteCompany :exec
+-- name: SearchCompanies :many
+-- sort is one of name, email, updated_at or the same prefixed with - for descending order, and id otherwise.
+-- after_text and after_time are the sort key of the row after_id.
+SELECT * FROM companies
+WHERE deleted_at IS NULL
+ AND (sqlc.narg('id')::bigint IS NULL OR id = sqlc.narg('id'))
+ AND (sqlc.narg('search')::text IS NULL OR name ILIKE sqlc.narg('search') OR email ILIKE sqlc.narg('search'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+ AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+ AND (sqlc.narg('after_id')::bigint IS NULL OR CASE sqlc.arg('sort')::text
+  WHEN 'name' THEN (name, id) > (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN '-name' THEN (name, id) < (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN 'email' THEN (email, id) > (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN '-email' THEN (email, id) < (sqlc.arg('after_text')::text, sqlc.narg('after_id'))
+  WHEN 'updated_at' THEN (updated_at, id) > (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+  WHEN '-updated_at' THEN (updated_at, id) < (sqlc.arg('after_time')::timestamptz, sqlc.narg('after_id'))
+  ELSE id > sqlc.narg('after_id')
+ END)
+ORDER BY
+ CASE WHEN sqlc.arg('sort')::text = 'name' THEN name END,
+ CASE WHEN sqlc.arg('sort')::text = '-name' THEN name END DESC,
+ CASE WHEN sqlc.arg('sort')::text = 'email' THEN email END,
+ CASE WHEN sqlc.arg('sort')::text = '-email' THEN email END DESC,
+ CASE WHEN sqlc.arg('sort')::text = 'updated_at' THEN updated_at END,
+ CASE WHEN sqlc.arg('sort')::text = '-updated_at' THEN updated_at END DESC,
+ CASE WHEN sqlc.arg('sort')::text LIKE '-%' THEN id END DESC,
+ id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanies :one
+SELECT count(*) FROM companies
+WHERE deleted_at IS NULL
+ AND (sqlc.narg('id')::bigint IS NULL OR id = sqlc.narg('id'))
+ AND (sqlc.narg('search')::text IS NULL OR name ILIKE sqlc.narg('search') OR email ILIKE sqlc.narg('search'))
+ AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+ AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+ AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'));
+
+-- name: SoftDeleteCompany :one
+-- a valid updated_at only deletes the company if it was not changed since
+UPDATE companies
+SET deleted_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
+RETURNING *;
+
+-- name: RestoreCompany :one
+UPDATE companies
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedCompanies :execrows
 DELETE FROM companies
-WHERE id = $1;
+WHERE deleted_at < sqlc.arg('before')::timestamptz;
 
 
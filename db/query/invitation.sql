@@ -0,0 +1,50 @@
+-- name: CreateInvitation :one
+INSERT INTO invitations (
+  company_id,
+  invited_by,
+  role,
+  phone,
+  email,
+  token_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetInvitation :one
+SELECT * FROM invitations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetInvitationByTokenHashForUpdate :one
+SELECT * FROM invitations
+WHERE token_hash = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListCompanyInvitations :many
+SELECT * FROM invitations
+WHERE company_id = sqlc.arg('company_id')
+ AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanyInvitations :one
+SELECT count(*) FROM invitations
+WHERE company_id = $1;
+
+-- name: AcceptInvitation :one
+UPDATE invitations
+SET
+ accepted_at = now(),
+ accepted_by = sqlc.arg('accepted_by')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: RevokeInvitation :one
+UPDATE invitations
+SET
+ revoked_at = now()
+WHERE id = sqlc.arg('id')
+ AND company_id = sqlc.arg('company_id')
+ AND accepted_at IS NULL
+ AND revoked_at IS NULL
+RETURNING *;
@@ -0,0 +1,31 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE key = $1 LIMIT 1;
+
+-- name: RecordFailedLogin :one
+-- the count starts over when the previous failure is older than reset_before
+INSERT INTO login_attempts (
+  key,
+  failed_count,
+  last_failed_at
+) VALUES (
+  sqlc.arg(key), 1, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+  failed_count = CASE
+    WHEN login_attempts.last_failed_at < sqlc.arg(reset_before) THEN 1
+    ELSE login_attempts.failed_count + 1
+  END,
+  last_failed_at = now()
+RETURNING *;
+
+-- name: LockLoginAttempt :one
+UPDATE login_attempts
+SET locked_until = $2
+WHERE key = $1
+RETURNING *;
+
+-- name: ResetLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = $1;
@@ -0,0 +1,27 @@
+-- name: CreateOtpCode :one
+INSERT INTO otp_codes (
+  user_id,
+  purpose,
+  code_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetLatestOtpCode :one
+SELECT * FROM otp_codes
+WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: IncrementOtpCodeAttempts :one
+UPDATE otp_codes
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: ConsumeOtpCode :one
+UPDATE otp_codes
+SET consumed_at = now()
+WHERE id = $1 AND consumed_at IS NULL
+RETURNING *;
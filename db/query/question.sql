@@ -8,3 +8,75 @@ INSERT INTO questions (
 ) VALUES (
     $1,$2,$3,$4,$5
 ) RETURNING *;
+
+-- name: SoftDeleteBotQuestions :exec
+UPDATE questions
+SET deleted_at = (SELECT b.deleted_at FROM bots b WHERE b.id = $1)
+WHERE bot_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreBotQuestions :exec
+-- restores the questions deleted with the bot, not the ones deleted before it
+UPDATE questions
+SET deleted_at = NULL
+WHERE bot_id = $1 AND deleted_at = (SELECT b.deleted_at FROM bots b WHERE b.id = $1);
+
+-- name: SoftDeleteCompanyQuestions :exec
+UPDATE questions q
+SET deleted_at = c.deleted_at
+FROM bots b, companies c
+WHERE q.bot_id = b.id AND b.company_id = c.id
+ AND c.id = $1 AND b.deleted_at IS NULL AND q.deleted_at IS NULL;
+
+-- name: RestoreCompanyQuestions :exec
+-- restores the questions deleted with the company, not the ones deleted before it
+UPDATE questions q
+SET deleted_at = NULL
+FROM bots b, companies c
+WHERE q.bot_id = b.id AND b.company_id = c.id
+ AND c.id = $1 AND b.deleted_at = c.deleted_at AND q.deleted_at = c.deleted_at;
+
+-- name: PurgeDeletedQuestions :execrows
+DELETE FROM questions
+WHERE deleted_at < sqlc.arg('before')::timestamptz;
+
+-- name: GetQuestion :one
+SELECT * FROM questions
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetFirstQuestion :one
+-- a conversation with a bot starts at the first of its questions without a parent
+SELECT * FROM questions
+WHERE bot_id = $1 AND parent_id = 0 AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1;
+
+-- name: UpdateQuestion :one
+-- a valid updated_at only updates the question if it was not changed since
+UPDATE questions
+SET
+ question = coalesce(sqlc.narg('question'), question),
+ type = coalesce(sqlc.narg('type'), type),
+ parent_id = coalesce(sqlc.narg('parent_id'), parent_id),
+ next_question_id = coalesce(sqlc.narg('next_question_id'), next_question_id),
+ updated_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
+RETURNING *;
+
+-- name: SoftDeleteQuestion :one
+-- a valid updated_at only deletes the question if it was not changed since
+UPDATE questions
+SET deleted_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+ AND (sqlc.narg('updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('updated_at'))
+RETURNING *;
+
+-- name: GetDeletedQuestion :one
+SELECT * FROM questions
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: RestoreQuestion :one
+UPDATE questions
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: CreateResponse :one
+INSERT INTO responses (
+    question_id,
+    response,
+    next_question_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: MatchResponse :one
+-- an answer matches a response of the question whatever its case and surrounding spaces
+SELECT * FROM responses
+WHERE question_id = sqlc.arg('question_id')
+ AND lower(trim(response)) = lower(trim(sqlc.arg('answer')::text))
+ORDER BY id
+LIMIT 1;
+
+-- name: CreateUserResponse :one
+INSERT INTO user_responses (
+    response_id,
+    user_id,
+    question_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
@@ -17,3 +17,32 @@ INSERT INTO sessions (
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: BlockUserSessions :exec
+UPDATE sessions
+SET
+ is_blocked = true,
+ updated_at = now()
+WHERE user_id = $1;
+
+-- name: CountActiveConversations :many
+-- a session is in a conversation while it waits for the answer to a question on a channel
+SELECT channels.name AS channel, count(*) AS conversations
+FROM sessions
+JOIN channels ON channels.id = sessions.channel_id
+WHERE sessions.question_id <> 0
+ AND NOT sessions.is_blocked
+ AND sessions.expires_at > now()
+GROUP BY channels.name
+ORDER BY channels.name;
+
+-- name: UpdateSessionConversation :one
+-- question_id is the question the session waits for the answer to, 0 once the conversation is over
+UPDATE sessions
+SET
+ channel_id = $2,
+ question_id = $3,
+ response_id = $4,
+ updated_at = now()
+WHERE id = $1
+RETURNING *;
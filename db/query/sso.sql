@@ -0,0 +1,101 @@
+-- name: CreateSsoConnection :one
+INSERT INTO sso_connections (
+  company_id,
+  email_domain,
+  issuer,
+  client_id,
+  client_secret
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetSsoConnection :one
+SELECT * FROM sso_connections
+WHERE id = $1 LIMIT 1;
+
+-- name: GetSsoConnectionByDomain :one
+-- a connection is only used once its company has verified the email domain
+SELECT sso_connections.* FROM sso_connections
+JOIN sso_domains ON sso_domains.company_id = sso_connections.company_id
+ AND sso_domains.domain = sso_connections.email_domain
+WHERE sso_connections.email_domain = lower(sqlc.arg('email_domain'))
+ AND sso_domains.verified_at IS NOT NULL
+LIMIT 1;
+
+-- name: ListCompanySsoConnections :many
+SELECT * FROM sso_connections
+WHERE company_id = sqlc.arg('company_id')
+ AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanySsoConnections :one
+SELECT count(*) FROM sso_connections
+WHERE company_id = $1;
+
+-- name: DeleteSsoConnection :one
+DELETE FROM sso_connections
+WHERE id = sqlc.arg('id')
+ AND company_id = sqlc.arg('company_id')
+RETURNING *;
+
+-- name: CreateSsoLoginState :one
+INSERT INTO sso_login_states (
+  state,
+  connection_id,
+  code_verifier,
+  nonce,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ConsumeSsoLoginState :one
+DELETE FROM sso_login_states
+WHERE state = $1
+RETURNING *;
+
+-- name: CreateSsoDomain :one
+INSERT INTO sso_domains (
+  company_id,
+  domain,
+  verification_token
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetCompanySsoDomain :one
+SELECT * FROM sso_domains
+WHERE id = sqlc.arg('id')
+ AND company_id = sqlc.arg('company_id')
+LIMIT 1;
+
+-- name: GetVerifiedSsoDomain :one
+SELECT * FROM sso_domains
+WHERE company_id = sqlc.arg('company_id')
+ AND domain = lower(sqlc.arg('domain'))
+ AND verified_at IS NOT NULL
+LIMIT 1;
+
+-- name: VerifySsoDomain :one
+UPDATE sso_domains
+SET verified_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListCompanySsoDomains :many
+SELECT * FROM sso_domains
+WHERE company_id = sqlc.arg('company_id')
+ AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanySsoDomains :one
+SELECT count(*) FROM sso_domains
+WHERE company_id = $1;
+
+-- name: DeleteSsoDomain :one
+DELETE FROM sso_domains
+WHERE id = sqlc.arg('id')
+ AND company_id = sqlc.arg('company_id')
+RETURNING *;
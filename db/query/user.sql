@@ -3,11 +3,128 @@ INSERT INTO users (
   name,
   password_hash,
   phone,
-  company_id
+  company_id,
+  role,
+  email
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetUser :one
+-- the users of a deleted company are gone with it
+SELECT users.* FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE users.phone = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT users.* FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE lower(users.email) = lower(sqlc.arg('email')) LIMIT 1;
+
+-- name: GetUserByID :one
+SELECT users.* FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE users.id = $1 LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+ password_hash = sqlc.arg('password_hash'),
+ password_changed_at = now(),
+ updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: MarkUserVerified :one
+UPDATE users
+SET
+ verified_at = coalesce(verified_at, now()),
+ updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserTotpSecret :one
+UPDATE users
+SET
+ totp_secret = sqlc.arg('totp_secret'),
+ updated_at = now()
+WHERE id = sqlc.arg('id')
+ AND totp_enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserTotp :one
+UPDATE users
+SET
+ totp_enabled_at = now(),
+ updated_at = now()
+WHERE id = $1
+ AND totp_secret IS NOT NULL
+ AND totp_enabled_at IS NULL
+RETURNING *;
+
+-- name: DisableUserTotp :one
+UPDATE users
+SET
+ totp_secret = NULL,
+ totp_enabled_at = NULL,
+ totp_last_counter = 0,
+ updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UseUserTotpCounter :execrows
+UPDATE users
+SET totp_last_counter = sqlc.arg('counter')
+WHERE id = sqlc.arg('id')
+ AND totp_last_counter < sqlc.arg('counter');
+
+-- name: ListCompanyUsers :many
 SELECT * FROM users
-WHERE phone = $1 LIMIT 1;
+WHERE company_id = sqlc.arg('company_id')
+ AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountCompanyUsers :one
+SELECT count(*) FROM users
+WHERE company_id = $1;
+
+-- name: LockCompanyOwners :many
+SELECT id FROM users
+WHERE company_id = $1
+ AND role = 'owner'
+ AND deactivated_at IS NULL
+ORDER BY id
+FOR UPDATE;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+ name = coalesce(sqlc.narg('name'), name),
+ phone = coalesce(sqlc.narg('phone'), phone),
+ role = coalesce(sqlc.narg('role'), role),
+ -- a new phone has to be verified again
+ verified_at = CASE WHEN sqlc.narg('phone')::varchar <> phone THEN NULL ELSE verified_at END,
+ updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeactivateUser :one
+UPDATE users
+SET
+ deactivated_at = coalesce(deactivated_at, now()),
+ updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ReactivateUser :one
+UPDATE users
+SET
+ deactivated_at = NULL,
+ updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
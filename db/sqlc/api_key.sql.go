@@ -0,0 +1,196 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: api_key.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const countCompanyApiKeys = `-- name: CountCompanyApiKeys :one
+SELECT count(*) FROM api_keys
+WHERE company_id = $1
+`
+
+func (q *Queries) CountCompanyApiKeys(ctx context.Context, companyID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanyApiKeys, companyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createApiKey = `-- name: CreateApiKey :one
+INSERT INTO api_keys (
+  company_id,
+  created_by,
+  name,
+  prefix,
+  secret_hash,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, company_id, created_by, name, prefix, secret_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+`
+
+type CreateApiKeyParams struct {
+	CompanyID  int64         `json:"company_id"`
+	CreatedBy  sql.NullInt64 `json:"created_by"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	SecretHash string        `json:"secret_hash"`
+	Scopes     []string      `json:"scopes"`
+	ExpiresAt  sql.NullTime  `json:"expires_at"`
+}
+
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createApiKey,
+		arg.CompanyID,
+		arg.CreatedBy,
+		arg.Name,
+		arg.Prefix,
+		arg.SecretHash,
+		pq.Array(arg.Scopes),
+		arg.ExpiresAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.CreatedBy,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApiKeyByPrefix = `-- name: GetApiKeyByPrefix :one
+SELECT api_keys.id, api_keys.company_id, api_keys.created_by, api_keys.name, api_keys.prefix, api_keys.secret_hash, api_keys.scopes, api_keys.expires_at, api_keys.last_used_at, api_keys.revoked_at, api_keys.created_at FROM api_keys
+JOIN companies ON companies.id = api_keys.company_id AND companies.deleted_at IS NULL
+WHERE api_keys.prefix = $1 LIMIT 1
+`
+
+// the keys of a deleted company stop working with it
+func (q *Queries) GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getApiKeyByPrefix, prefix)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.CreatedBy,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listCompanyApiKeys = `-- name: ListCompanyApiKeys :many
+SELECT id, company_id, created_by, name, prefix, secret_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys
+WHERE company_id = $1
+ AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListCompanyApiKeysParams struct {
+	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListCompanyApiKeys(ctx context.Context, arg ListCompanyApiKeysParams) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanyApiKeys, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.CreatedBy,
+			&i.Name,
+			&i.Prefix,
+			&i.SecretHash,
+			pq.Array(&i.Scopes),
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :one
+UPDATE api_keys
+SET
+ revoked_at = now()
+WHERE id = $1
+ AND company_id = $2
+ AND revoked_at IS NULL
+RETURNING id, company_id, created_by, name, prefix, secret_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+`
+
+type RevokeApiKeyParams struct {
+	ID        int64 `json:"id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, revokeApiKey, arg.ID, arg.CompanyID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.CreatedBy,
+		&i.Name,
+		&i.Prefix,
+		&i.SecretHash,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const touchApiKey = `-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) TouchApiKey(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchApiKey, id)
+	return err
+}
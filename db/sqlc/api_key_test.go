@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomApiKey(t *testing.T, creator User) ApiKey {
+	arg := CreateApiKeyParams{
+		CompanyID:  creator.CompanyID,
+		CreatedBy:  sql.NullInt64{Int64: creator.ID, Valid: true},
+		Name:       util.RandomString(6),
+		Prefix:     util.RandomString(12),
+		SecretHash: util.HashSecret(util.RandomString(32)),
+		Scopes:     []string{"bots:read", "bots:write"},
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err := testQueries.CreateApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, apiKey.ID)
+	require.Equal(t, arg.CompanyID, apiKey.CompanyID)
+	require.Equal(t, arg.CreatedBy, apiKey.CreatedBy)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.Prefix, apiKey.Prefix)
+	require.Equal(t, arg.SecretHash, apiKey.SecretHash)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+	return apiKey
+}
+
+func TestCreateApiKey(t *testing.T) {
+	createRandomApiKey(t, createRandomUser(t))
+}
+
+func TestGetApiKeyByPrefix(t *testing.T) {
+	apiKey1 := createRandomApiKey(t, createRandomUser(t))
+
+	apiKey2, err := testQueries.GetApiKeyByPrefix(context.Background(), apiKey1.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, apiKey1.ID, apiKey2.ID)
+	require.Equal(t, apiKey1.SecretHash, apiKey2.SecretHash)
+	require.Equal(t, apiKey1.Scopes, apiKey2.Scopes)
+}
+
+func TestListCompanyApiKeys(t *testing.T) {
+	creator := createRandomUser(t)
+	for i := 0; i < 5; i++ {
+		createRandomApiKey(t, creator)
+	}
+
+	apiKeys, err := testQueries.ListCompanyApiKeys(context.Background(), ListCompanyApiKeysParams{
+		CompanyID: creator.CompanyID,
+		Limit:     5,
+		AfterID:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 5)
+	for _, apiKey := range apiKeys {
+		require.Equal(t, creator.CompanyID, apiKey.CompanyID)
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	apiKey := createRandomApiKey(t, createRandomUser(t))
+	arg := RevokeApiKeyParams{
+		ID:        apiKey.ID,
+		CompanyID: apiKey.CompanyID,
+	}
+
+	revoked, err := testQueries.RevokeApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	// an already revoked key cannot be revoked again
+	_, err = testQueries.RevokeApiKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTouchApiKey(t *testing.T) {
+	apiKey := createRandomApiKey(t, createRandomUser(t))
+
+	err := testQueries.TouchApiKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+
+	touched, err := testQueries.GetApiKeyByPrefix(context.Background(), apiKey.Prefix)
+	require.NoError(t, err)
+	require.True(t, touched.LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), touched.LastUsedAt.Time, time.Second)
+}
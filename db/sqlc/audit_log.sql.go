@@ -0,0 +1,187 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: audit_log.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const countAuditLogs = `-- name: CountAuditLogs :one
+SELECT count(*) FROM audit_logs
+WHERE company_id = $1
+ AND ($2::bigint IS NULL OR actor_user_id = $2)
+ AND ($3::text IS NULL OR action = $3)
+ AND ($4::text IS NULL OR entity_type = $4)
+ AND ($5::bigint IS NULL OR entity_id = $5)
+ AND ($6::timestamptz IS NULL OR created_at >= $6)
+ AND ($7::timestamptz IS NULL OR created_at < $7)
+`
+
+type CountAuditLogsParams struct {
+	CompanyID     int64          `json:"company_id"`
+	ActorUserID   sql.NullInt64  `json:"actor_user_id"`
+	Action        sql.NullString `json:"action"`
+	EntityType    sql.NullString `json:"entity_type"`
+	EntityID      sql.NullInt64  `json:"entity_id"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+}
+
+func (q *Queries) CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuditLogs,
+		arg.CompanyID,
+		arg.ActorUserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+  company_id,
+  actor_user_id,
+  actor_api_key_id,
+  actor_name,
+  action,
+  entity_type,
+  entity_id,
+  changes,
+  ip,
+  user_agent,
+  request_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, company_id, actor_user_id, actor_api_key_id, actor_name, action, entity_type, entity_id, changes, ip, user_agent, request_id, created_at
+`
+
+type CreateAuditLogParams struct {
+	CompanyID     int64           `json:"company_id"`
+	ActorUserID   sql.NullInt64   `json:"actor_user_id"`
+	ActorApiKeyID sql.NullInt64   `json:"actor_api_key_id"`
+	ActorName     string          `json:"actor_name"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      sql.NullInt64   `json:"entity_id"`
+	Changes       json.RawMessage `json:"changes"`
+	Ip            string          `json:"ip"`
+	UserAgent     string          `json:"user_agent"`
+	RequestID     string          `json:"request_id"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditLog,
+		arg.CompanyID,
+		arg.ActorUserID,
+		arg.ActorApiKeyID,
+		arg.ActorName,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Changes,
+		arg.Ip,
+		arg.UserAgent,
+		arg.RequestID,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.ActorUserID,
+		&i.ActorApiKeyID,
+		&i.ActorName,
+		&i.Action,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Changes,
+		&i.Ip,
+		&i.UserAgent,
+		&i.RequestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, company_id, actor_user_id, actor_api_key_id, actor_name, action, entity_type, entity_id, changes, ip, user_agent, request_id, created_at FROM audit_logs
+WHERE company_id = $1
+ AND ($2::bigint IS NULL OR actor_user_id = $2)
+ AND ($3::text IS NULL OR action = $3)
+ AND ($4::text IS NULL OR entity_type = $4)
+ AND ($5::bigint IS NULL OR entity_id = $5)
+ AND ($6::timestamptz IS NULL OR created_at >= $6)
+ AND ($7::timestamptz IS NULL OR created_at < $7)
+ AND ($8::bigint IS NULL OR id < $8)
+ORDER BY id DESC
+LIMIT $9
+`
+
+type ListAuditLogsParams struct {
+	CompanyID     int64          `json:"company_id"`
+	ActorUserID   sql.NullInt64  `json:"actor_user_id"`
+	Action        sql.NullString `json:"action"`
+	EntityType    sql.NullString `json:"entity_type"`
+	EntityID      sql.NullInt64  `json:"entity_id"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	AfterID       sql.NullInt64  `json:"after_id"`
+	Limit         int32          `json:"limit"`
+}
+
+// newest first, a page starts before after_id
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogs,
+		arg.CompanyID,
+		arg.ActorUserID,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.ActorUserID,
+			&i.ActorApiKeyID,
+			&i.ActorName,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Changes,
+			&i.Ip,
+			&i.UserAgent,
+			&i.RequestID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
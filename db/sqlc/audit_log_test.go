@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditLog(t *testing.T, companyID int64, action string) AuditLog {
+	arg := CreateAuditLogParams{
+		CompanyID:   companyID,
+		ActorUserID: sql.NullInt64{Int64: util.RandInt(1, 1000), Valid: true},
+		ActorName:   util.RandomString(6),
+		Action:      action,
+		EntityType:  "bot",
+		EntityID:    sql.NullInt64{Int64: util.RandInt(1, 1000), Valid: true},
+		Changes:     json.RawMessage(`{"title": {"from": "old", "to": "new"}}`),
+		Ip:          "192.0.2.1",
+		UserAgent:   util.RandomString(6),
+		RequestID:   util.RandomString(6),
+	}
+
+	log, err := testQueries.CreateAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.CompanyID, log.CompanyID)
+	require.Equal(t, arg.Action, log.Action)
+	require.JSONEq(t, string(arg.Changes), string(log.Changes))
+	require.NotZero(t, log.CreatedAt)
+	return log
+}
+
+func TestListAuditLogs(t *testing.T) {
+	company := createRandomCompany(t)
+	first := createRandomAuditLog(t, company.ID, "bot.create")
+	second := createRandomAuditLog(t, company.ID, "bot.update")
+	createRandomAuditLog(t, createRandomCompany(t).ID, "bot.update")
+
+	// newest first
+	logs, err := testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		CompanyID: company.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, second.ID, logs[0].ID)
+	require.Equal(t, first.ID, logs[1].ID)
+
+	logs, err = testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		CompanyID: company.ID,
+		AfterID:   sql.NullInt64{Int64: second.ID, Valid: true},
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, first.ID, logs[0].ID)
+
+	total, err := testQueries.CountAuditLogs(context.Background(), CountAuditLogsParams{
+		CompanyID: company.ID,
+		Action:    sql.NullString{String: "bot.update", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	log := createRandomAuditLog(t, createRandomCompany(t).ID, "bot.delete")
+
+	_, err := testDB.ExecContext(context.Background(), "UPDATE audit_logs SET action = 'edited' WHERE id = $1", log.ID)
+	require.Error(t, err)
+
+	_, err = testDB.ExecContext(context.Background(), "DELETE FROM audit_logs WHERE id = $1", log.ID)
+	require.Error(t, err)
+}
@@ -8,15 +8,50 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const countBots = `-- name: CountBots :one
+SELECT count(*) FROM bots
+WHERE deleted_at IS NULL
+ AND ($1::bigint IS NULL OR company_id = $1)
+ AND ($2::text IS NULL OR title ILIKE $2)
+ AND ($3::timestamptz IS NULL OR created_at >= $3)
+ AND ($4::timestamptz IS NULL OR created_at < $4)
+ AND ($5::timestamptz IS NULL OR updated_at >= $5)
+ AND ($6::timestamptz IS NULL OR updated_at < $6)
+`
+
+type CountBotsParams struct {
+	CompanyID     sql.NullInt64  `json:"company_id"`
+	Search        sql.NullString `json:"search"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	UpdatedAfter  sql.NullTime   `json:"updated_after"`
+	UpdatedBefore sql.NullTime   `json:"updated_before"`
+}
+
+func (q *Queries) CountBots(ctx context.Context, arg CountBotsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBots,
+		arg.CompanyID,
+		arg.Search,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBot = `-- name: CreateBot :one
 INSERT INTO bots (
     title,
     company_id
 ) VALUES (
     $1, $2
-) RETURNING id, title, company_id, created_at, updated_at
+) RETURNING id, title, company_id, created_at, updated_at, deleted_at
 `
 
 type CreateBotParams struct {
@@ -33,27 +68,37 @@ func (q *Queries) CreateBot(ctx context.Context, arg CreateBotParams) (Bot, erro
 		&i.CompanyID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteBot = `-- name: DeleteBot :exec
-DELETE FROM bots
-WHERE id = $1
+const getBot = `-- name: GetBot :one
+SELECT id, title, company_id, created_at, updated_at, deleted_at FROM bots
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
-func (q *Queries) DeleteBot(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteBot, id)
-	return err
+func (q *Queries) GetBot(ctx context.Context, id int64) (Bot, error) {
+	row := q.db.QueryRowContext(ctx, getBot, id)
+	var i Bot
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.CompanyID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
-const getBot = `-- name: GetBot :one
-SELECT id, title, company_id, created_at, updated_at FROM bots
-WHERE id = $1 LIMIT 1
+const getDeletedBot = `-- name: GetDeletedBot :one
+SELECT id, title, company_id, created_at, updated_at, deleted_at FROM bots
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
 `
 
-func (q *Queries) GetBot(ctx context.Context, id int64) (Bot, error) {
-	row := q.db.QueryRowContext(ctx, getBot, id)
+func (q *Queries) GetDeletedBot(ctx context.Context, id int64) (Bot, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedBot, id)
 	var i Bot
 	err := row.Scan(
 		&i.ID,
@@ -61,24 +106,26 @@ func (q *Queries) GetBot(ctx context.Context, id int64) (Bot, error) {
 		&i.CompanyID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listAllBots = `-- name: ListAllBots :many
-SELECT id, title, company_id, created_at, updated_at FROM bots
+SELECT id, title, company_id, created_at, updated_at, deleted_at FROM bots
+WHERE deleted_at IS NULL
+ AND id > $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
 `
 
 type ListAllBotsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	AfterID int64 `json:"after_id"`
+	Limit   int32 `json:"limit"`
 }
 
 func (q *Queries) ListAllBots(ctx context.Context, arg ListAllBotsParams) ([]Bot, error) {
-	rows, err := q.db.QueryContext(ctx, listAllBots, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAllBots, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +139,7 @@ func (q *Queries) ListAllBots(ctx context.Context, arg ListAllBotsParams) ([]Bot
 			&i.CompanyID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -107,21 +155,152 @@ func (q *Queries) ListAllBots(ctx context.Context, arg ListAllBotsParams) ([]Bot
 }
 
 const listCompanyBots = `-- name: ListCompanyBots :many
-SELECT id, title, company_id, created_at, updated_at FROM bots
+SELECT id, title, company_id, created_at, updated_at, deleted_at FROM bots
 WHERE company_id = $1
+ AND deleted_at IS NULL
+ AND id > $2
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $3
 `
 
 type ListCompanyBotsParams struct {
 	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
 	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
 }
 
 func (q *Queries) ListCompanyBots(ctx context.Context, arg ListCompanyBotsParams) ([]Bot, error) {
-	rows, err := q.db.QueryContext(ctx, listCompanyBots, arg.CompanyID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listCompanyBots, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Bot{}
+	for rows.Next() {
+		var i Bot
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.CompanyID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedBots = `-- name: PurgeDeletedBots :execrows
+DELETE FROM bots
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeDeletedBots(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedBots, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreBot = `-- name: RestoreBot :one
+UPDATE bots
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, title, company_id, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) RestoreBot(ctx context.Context, id int64) (Bot, error) {
+	row := q.db.QueryRowContext(ctx, restoreBot, id)
+	var i Bot
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.CompanyID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const restoreCompanyBots = `-- name: RestoreCompanyBots :exec
+UPDATE bots
+SET deleted_at = NULL
+WHERE company_id = $1 AND deleted_at = (SELECT c.deleted_at FROM companies c WHERE c.id = $1)
+`
+
+// restores the bots deleted with the company, not the ones deleted before it
+func (q *Queries) RestoreCompanyBots(ctx context.Context, companyID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreCompanyBots, companyID)
+	return err
+}
+
+const searchBots = `-- name: SearchBots :many
+SELECT id, title, company_id, created_at, updated_at, deleted_at FROM bots
+WHERE deleted_at IS NULL
+ AND ($1::bigint IS NULL OR company_id = $1)
+ AND ($2::text IS NULL OR title ILIKE $2)
+ AND ($3::timestamptz IS NULL OR created_at >= $3)
+ AND ($4::timestamptz IS NULL OR created_at < $4)
+ AND ($5::timestamptz IS NULL OR updated_at >= $5)
+ AND ($6::timestamptz IS NULL OR updated_at < $6)
+ AND ($7::bigint IS NULL OR CASE $8::text
+  WHEN 'title' THEN (title, id) > ($9::text, $7)
+  WHEN '-title' THEN (title, id) < ($9::text, $7)
+  WHEN 'updated_at' THEN (updated_at, id) > ($10::timestamptz, $7)
+  WHEN '-updated_at' THEN (updated_at, id) < ($10::timestamptz, $7)
+  ELSE id > $7
+ END)
+ORDER BY
+ CASE WHEN $8::text = 'title' THEN title END,
+ CASE WHEN $8::text = '-title' THEN title END DESC,
+ CASE WHEN $8::text = 'updated_at' THEN updated_at END,
+ CASE WHEN $8::text = '-updated_at' THEN updated_at END DESC,
+ CASE WHEN $8::text LIKE '-%' THEN id END DESC,
+ id
+LIMIT $11
+`
+
+type SearchBotsParams struct {
+	CompanyID     sql.NullInt64  `json:"company_id"`
+	Search        sql.NullString `json:"search"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	UpdatedAfter  sql.NullTime   `json:"updated_after"`
+	UpdatedBefore sql.NullTime   `json:"updated_before"`
+	AfterID       sql.NullInt64  `json:"after_id"`
+	Sort          string         `json:"sort"`
+	AfterText     string         `json:"after_text"`
+	AfterTime     time.Time      `json:"after_time"`
+	Limit         int32          `json:"limit"`
+}
+
+// sort is one of title, updated_at or the same prefixed with - for descending order, and id otherwise.
+// after_text and after_time are the sort key of the row after_id.
+func (q *Queries) SearchBots(ctx context.Context, arg SearchBotsParams) ([]Bot, error) {
+	rows, err := q.db.QueryContext(ctx, searchBots,
+		arg.CompanyID,
+		arg.Search,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterText,
+		arg.AfterTime,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +314,7 @@ func (q *Queries) ListCompanyBots(ctx context.Context, arg ListCompanyBotsParams
 			&i.CompanyID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -149,24 +329,71 @@ func (q *Queries) ListCompanyBots(ctx context.Context, arg ListCompanyBotsParams
 	return items, nil
 }
 
+const softDeleteBot = `-- name: SoftDeleteBot :one
+UPDATE bots
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+ AND ($2::timestamptz IS NULL OR updated_at = $2)
+RETURNING id, title, company_id, created_at, updated_at, deleted_at
+`
+
+type SoftDeleteBotParams struct {
+	ID        int64        `json:"id"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+// a valid updated_at only deletes the bot if it was not changed since
+func (q *Queries) SoftDeleteBot(ctx context.Context, arg SoftDeleteBotParams) (Bot, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteBot, arg.ID, arg.UpdatedAt)
+	var i Bot
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.CompanyID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const softDeleteCompanyBots = `-- name: SoftDeleteCompanyBots :exec
+UPDATE bots
+SET deleted_at = (SELECT c.deleted_at FROM companies c WHERE c.id = $1)
+WHERE company_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteCompanyBots(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteCompanyBots, id)
+	return err
+}
+
 const updateBot = `-- name: UpdateBot :one
 UPDATE bots
 SET
  title = coalesce($1, title),
  company_id = coalesce($2, company_id),
  updated_at = now()
-WHERE id = $3 
-RETURNING id, title, company_id, created_at, updated_at
+WHERE id = $3 AND deleted_at IS NULL
+ AND ($4::timestamptz IS NULL OR updated_at = $4)
+RETURNING id, title, company_id, created_at, updated_at, deleted_at
 `
 
 type UpdateBotParams struct {
 	Title     sql.NullString `json:"title"`
 	CompanyID sql.NullInt64  `json:"company_id"`
 	ID        int64          `json:"id"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
 }
 
+// a valid updated_at only updates the bot if it was not changed since
 func (q *Queries) UpdateBot(ctx context.Context, arg UpdateBotParams) (Bot, error) {
-	row := q.db.QueryRowContext(ctx, updateBot, arg.Title, arg.CompanyID, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateBot,
+		arg.Title,
+		arg.CompanyID,
+		arg.ID,
+		arg.UpdatedAt,
+	)
 	var i Bot
 	err := row.Scan(
 		&i.ID,
@@ -174,6 +401,7 @@ func (q *Queries) UpdateBot(ctx context.Context, arg UpdateBotParams) (Bot, erro
 		&i.CompanyID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -49,8 +49,8 @@ func TestListAllBots(t *testing.T) {
 		createRandomBot(t)
 	}
 	arg := ListAllBotsParams{
-		Limit:  5,
-		Offset: 5,
+		Limit:   5,
+		AfterID: 0,
 	}
 
 	bots, err := testQueries.ListAllBots(context.Background(), arg)
@@ -90,7 +90,7 @@ func TestListCompanyBots(t *testing.T) {
 	arg := ListCompanyBotsParams{
 		CompanyID: company.ID,
 		Limit:     5,
-		Offset:    5,
+		AfterID:   0,
 	}
 
 	companybots, err := testQueries.ListCompanyBots(context.Background(), arg)
@@ -102,8 +102,8 @@ func TestListCompanyBots(t *testing.T) {
 	}
 
 	allBotsArgs := ListAllBotsParams{
-		Limit:  20,
-		Offset: 20,
+		Limit:   20,
+		AfterID: 0,
 	}
 	allBots, err := testQueries.ListAllBots(context.Background(), allBotsArgs)
 	require.NoError(err)
@@ -179,14 +179,125 @@ func TestUpdateBotCompanyIDOnly(t *testing.T) {
 	require.WithinDuration(bot.CreatedAt, updatedBot.CreatedAt, time.Second)
 }
 
+func TestUpdateBotIfUnchanged(t *testing.T) {
+	require := require.New(t)
+	bot := createRandomBot(t)
+
+	arg := UpdateBotParams{
+		Title:     sql.NullString{String: util.RandomString(6), Valid: true},
+		ID:        bot.ID,
+		UpdatedAt: sql.NullTime{Time: bot.UpdatedAt, Valid: true},
+	}
+	updatedBot, err := testQueries.UpdateBot(context.Background(), arg)
+	require.NoError(err)
+	require.Equal(arg.Title.String, updatedBot.Title)
+
+	// the version read before the first update is stale now
+	arg.Title = sql.NullString{String: util.RandomString(6), Valid: true}
+	_, err = testQueries.UpdateBot(context.Background(), arg)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
 func TestDeleteBot(t *testing.T) {
 	require := require.New(t)
 	bot := createRandomBot(t)
 
-	err := testQueries.DeleteBot(context.Background(), bot.ID)
+	deleted, err := testQueries.SoftDeleteBot(context.Background(), SoftDeleteBotParams{ID: bot.ID})
 	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
 	bot1, err := testQueries.GetBot(context.Background(), bot.ID)
 	require.Error(err)
 	require.EqualError(err, sql.ErrNoRows.Error())
 	require.Empty(bot1)
+
+	_, err = testQueries.SoftDeleteBot(context.Background(), SoftDeleteBotParams{ID: bot.ID})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	bot2, err := testQueries.GetDeletedBot(context.Background(), bot.ID)
+	require.NoError(err)
+	require.Equal(bot.ID, bot2.ID)
+
+	restored, err := testQueries.RestoreBot(context.Background(), bot.ID)
+	require.NoError(err)
+	require.False(restored.DeletedAt.Valid)
+	_, err = testQueries.GetBot(context.Background(), bot.ID)
+	require.NoError(err)
+}
+
+func TestSoftDeleteBotIfUnchanged(t *testing.T) {
+	require := require.New(t)
+	bot := createRandomBot(t)
+
+	stale := sql.NullTime{Time: bot.UpdatedAt.Add(-time.Second), Valid: true}
+	_, err := testQueries.SoftDeleteBot(context.Background(), SoftDeleteBotParams{ID: bot.ID, UpdatedAt: stale})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	current := sql.NullTime{Time: bot.UpdatedAt, Valid: true}
+	deleted, err := testQueries.SoftDeleteBot(context.Background(), SoftDeleteBotParams{ID: bot.ID, UpdatedAt: current})
+	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
+}
+
+func TestPurgeDeletedBots(t *testing.T) {
+	bot := createRandomBot(t)
+	_, err := testQueries.SoftDeleteBot(context.Background(), SoftDeleteBotParams{ID: bot.ID})
+	require.NoError(t, err)
+
+	// the bot is kept during the retention period
+	_, err = testQueries.PurgeDeletedBots(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	_, err = testQueries.GetDeletedBot(context.Background(), bot.ID)
+	require.NoError(t, err)
+
+	purged, err := testQueries.PurgeDeletedBots(context.Background(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.NotZero(t, purged)
+	_, err = testQueries.GetDeletedBot(context.Background(), bot.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSearchBots(t *testing.T) {
+	company := createRandomCompany(t)
+	word := util.RandomString(8)
+	titles := []string{"c " + word, "a " + word, "b " + word}
+	for _, title := range titles {
+		_, err := testQueries.CreateBot(context.Background(), CreateBotParams{Title: title, CompanyID: company.ID})
+		require.NoError(t, err)
+	}
+	createCompanyBot(t, company.ID)
+
+	arg := SearchBotsParams{
+		CompanyID: sql.NullInt64{Int64: company.ID, Valid: true},
+		Search:    sql.NullString{String: "%" + word + "%", Valid: true},
+		Sort:      "title",
+		Limit:     2,
+	}
+	bots, err := testQueries.SearchBots(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, bots, 2)
+	require.Equal(t, titles[1], bots[0].Title)
+	require.Equal(t, titles[2], bots[1].Title)
+
+	// the next page starts after the sort key of the last bot
+	arg.AfterID = sql.NullInt64{Int64: bots[1].ID, Valid: true}
+	arg.AfterText = bots[1].Title
+	bots, err = testQueries.SearchBots(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, bots, 1)
+	require.Equal(t, titles[0], bots[0].Title)
+
+	total, err := testQueries.CountBots(context.Background(), CountBotsParams{
+		CompanyID: arg.CompanyID,
+		Search:    arg.Search,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(titles)), total)
+
+	// no bot was created in the future
+	total, err = testQueries.CountBots(context.Background(), CountBotsParams{
+		CompanyID:    arg.CompanyID,
+		CreatedAfter: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
 }
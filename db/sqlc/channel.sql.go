@@ -10,6 +10,17 @@ import (
 	"database/sql"
 )
 
+const countChannels = `-- name: CountChannels :one
+SELECT count(*) FROM channels
+`
+
+func (q *Queries) CountChannels(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChannels)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChannel = `-- name: CreateChannel :one
 INSERT INTO channels (
     name
@@ -30,14 +41,22 @@ func (q *Queries) CreateChannel(ctx context.Context, name string) (Channel, erro
 	return i, err
 }
 
-const deleteChannel = `-- name: DeleteChannel :exec
+const deleteChannel = `-- name: DeleteChannel :one
 DELETE FROM channels
 WHERE id = $1
+RETURNING id, name, created_at, updated_at
 `
 
-func (q *Queries) DeleteChannel(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteChannel, id)
-	return err
+func (q *Queries) DeleteChannel(ctx context.Context, id int32) (Channel, error) {
+	row := q.db.QueryRowContext(ctx, deleteChannel, id)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const getChannel = `-- name: GetChannel :one
@@ -57,20 +76,37 @@ func (q *Queries) GetChannel(ctx context.Context, name string) (Channel, error)
 	return i, err
 }
 
+const getChannelByID = `-- name: GetChannelByID :one
+SELECT id, name, created_at, updated_at FROM channels
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetChannelByID(ctx context.Context, id int32) (Channel, error) {
+	row := q.db.QueryRowContext(ctx, getChannelByID, id)
+	var i Channel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const listChannels = `-- name: ListChannels :many
 SELECT id, name, created_at, updated_at FROM channels
+WHERE id > $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
 `
 
 type ListChannelsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	AfterID int32 `json:"after_id"`
+	Limit   int32 `json:"limit"`
 }
 
 func (q *Queries) ListChannels(ctx context.Context, arg ListChannelsParams) ([]Channel, error) {
-	rows, err := q.db.QueryContext(ctx, listChannels, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listChannels, arg.AfterID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -61,22 +61,32 @@ func TestDeleteChannel(t *testing.T) {
 	require := require.New(t)
 	channel := createRandomChannel(t)
 
-	err := testQueries.DeleteChannel(context.Background(), channel.ID)
+	deleted, err := testQueries.DeleteChannel(context.Background(), channel.ID)
 	require.NoError(err)
+	require.Equal(channel.ID, deleted.ID)
 	channel1, err := testQueries.GetChannel(context.Background(), channel.Name)
 	require.Error(err)
 	require.EqualError(err, sql.ErrNoRows.Error())
 	require.Empty(channel1)
 }
 
+func TestGetChannelByID(t *testing.T) {
+	require := require.New(t)
+	channel1 := createRandomChannel(t)
+
+	channel2, err := testQueries.GetChannelByID(context.Background(), channel1.ID)
+	require.NoError(err)
+	require.Equal(channel1.Name, channel2.Name)
+}
+
 func TestListChannels(t *testing.T) {
 	require := require.New(t)
 	for i := 0; i < 10; i++ {
 		createRandomChannel(t)
 	}
 	arg := ListChannelsParams{
-		Limit:  5,
-		Offset: 5,
+		Limit:   5,
+		AfterID: 0,
 	}
 
 	channels, err := testQueries.ListChannels(context.Background(), arg)
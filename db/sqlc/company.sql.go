@@ -8,8 +8,43 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const countCompanies = `-- name: CountCompanies :one
+SELECT count(*) FROM companies
+WHERE deleted_at IS NULL
+ AND ($1::bigint IS NULL OR id = $1)
+ AND ($2::text IS NULL OR name ILIKE $2 OR email ILIKE $2)
+ AND ($3::timestamptz IS NULL OR created_at >= $3)
+ AND ($4::timestamptz IS NULL OR created_at < $4)
+ AND ($5::timestamptz IS NULL OR updated_at >= $5)
+ AND ($6::timestamptz IS NULL OR updated_at < $6)
+`
+
+type CountCompaniesParams struct {
+	ID            sql.NullInt64  `json:"id"`
+	Search        sql.NullString `json:"search"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	UpdatedAfter  sql.NullTime   `json:"updated_after"`
+	UpdatedBefore sql.NullTime   `json:"updated_before"`
+}
+
+func (q *Queries) CountCompanies(ctx context.Context, arg CountCompaniesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanies,
+		arg.ID,
+		arg.Search,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCompany = `-- name: CreateCompany :one
 INSERT INTO companies (
     phone,
@@ -17,7 +52,7 @@ INSERT INTO companies (
     email
 ) VALUES (
     $1, $2, $3
-) RETURNING id, email, phone, name, created_at, updated_at
+) RETURNING id, email, phone, name, created_at, updated_at, deleted_at
 `
 
 type CreateCompanyParams struct {
@@ -36,23 +71,14 @@ func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (C
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteCompany = `-- name: DeleteCompany :exec
-DELETE FROM companies
-WHERE id = $1
-`
-
-func (q *Queries) DeleteCompany(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteCompany, id)
-	return err
-}
-
 const getCompanyByEmail = `-- name: GetCompanyByEmail :one
-SELECT id, email, phone, name, created_at, updated_at FROM companies
-WHERE email = $1 LIMIT 1
+SELECT id, email, phone, name, created_at, updated_at, deleted_at FROM companies
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetCompanyByEmail(ctx context.Context, email string) (Company, error) {
@@ -65,13 +91,14 @@ func (q *Queries) GetCompanyByEmail(ctx context.Context, email string) (Company,
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getCompanyByID = `-- name: GetCompanyByID :one
-SELECT id, email, phone, name, created_at, updated_at FROM companies
-WHERE id = $1 LIMIT 1
+SELECT id, email, phone, name, created_at, updated_at, deleted_at FROM companies
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetCompanyByID(ctx context.Context, id int64) (Company, error) {
@@ -84,24 +111,152 @@ func (q *Queries) GetCompanyByID(ctx context.Context, id int64) (Company, error)
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listCompanies = `-- name: ListCompanies :many
-SELECT id, email, phone, name, created_at, updated_at FROM companies
+SELECT id, email, phone, name, created_at, updated_at, deleted_at FROM companies
+WHERE deleted_at IS NULL
+ AND ($1::bigint IS NULL OR id = $1)
+ AND id > $2
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $3
 `
 
 type ListCompaniesParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	ID      sql.NullInt64 `json:"id"`
+	AfterID int64         `json:"after_id"`
+	Limit   int32         `json:"limit"`
 }
 
 func (q *Queries) ListCompanies(ctx context.Context, arg ListCompaniesParams) ([]Company, error) {
-	rows, err := q.db.QueryContext(ctx, listCompanies, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listCompanies, arg.ID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Company{}
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Phone,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedCompanies = `-- name: PurgeDeletedCompanies :execrows
+DELETE FROM companies
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeDeletedCompanies(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedCompanies, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreCompany = `-- name: RestoreCompany :one
+UPDATE companies
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, email, phone, name, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) RestoreCompany(ctx context.Context, id int64) (Company, error) {
+	row := q.db.QueryRowContext(ctx, restoreCompany, id)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Phone,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const searchCompanies = `-- name: SearchCompanies :many
+SELECT id, email, phone, name, created_at, updated_at, deleted_at FROM companies
+WHERE deleted_at IS NULL
+ AND ($1::bigint IS NULL OR id = $1)
+ AND ($2::text IS NULL OR name ILIKE $2 OR email ILIKE $2)
+ AND ($3::timestamptz IS NULL OR created_at >= $3)
+ AND ($4::timestamptz IS NULL OR created_at < $4)
+ AND ($5::timestamptz IS NULL OR updated_at >= $5)
+ AND ($6::timestamptz IS NULL OR updated_at < $6)
+ AND ($7::bigint IS NULL OR CASE $8::text
+  WHEN 'name' THEN (name, id) > ($9::text, $7)
+  WHEN '-name' THEN (name, id) < ($9::text, $7)
+  WHEN 'email' THEN (email, id) > ($9::text, $7)
+  WHEN '-email' THEN (email, id) < ($9::text, $7)
+  WHEN 'updated_at' THEN (updated_at, id) > ($10::timestamptz, $7)
+  WHEN '-updated_at' THEN (updated_at, id) < ($10::timestamptz, $7)
+  ELSE id > $7
+ END)
+ORDER BY
+ CASE WHEN $8::text = 'name' THEN name END,
+ CASE WHEN $8::text = '-name' THEN name END DESC,
+ CASE WHEN $8::text = 'email' THEN email END,
+ CASE WHEN $8::text = '-email' THEN email END DESC,
+ CASE WHEN $8::text = 'updated_at' THEN updated_at END,
+ CASE WHEN $8::text = '-updated_at' THEN updated_at END DESC,
+ CASE WHEN $8::text LIKE '-%' THEN id END DESC,
+ id
+LIMIT $11
+`
+
+type SearchCompaniesParams struct {
+	ID            sql.NullInt64  `json:"id"`
+	Search        sql.NullString `json:"search"`
+	CreatedAfter  sql.NullTime   `json:"created_after"`
+	CreatedBefore sql.NullTime   `json:"created_before"`
+	UpdatedAfter  sql.NullTime   `json:"updated_after"`
+	UpdatedBefore sql.NullTime   `json:"updated_before"`
+	AfterID       sql.NullInt64  `json:"after_id"`
+	Sort          string         `json:"sort"`
+	AfterText     string         `json:"after_text"`
+	AfterTime     time.Time      `json:"after_time"`
+	Limit         int32          `json:"limit"`
+}
+
+// sort is one of name, email, updated_at or the same prefixed with - for descending order, and id otherwise.
+// after_text and after_time are the sort key of the row after_id.
+func (q *Queries) SearchCompanies(ctx context.Context, arg SearchCompaniesParams) ([]Company, error) {
+	rows, err := q.db.QueryContext(ctx, searchCompanies,
+		arg.ID,
+		arg.Search,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterText,
+		arg.AfterTime,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +271,7 @@ func (q *Queries) ListCompanies(ctx context.Context, arg ListCompaniesParams) ([
 			&i.Name,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -130,6 +286,35 @@ func (q *Queries) ListCompanies(ctx context.Context, arg ListCompaniesParams) ([
 	return items, nil
 }
 
+const softDeleteCompany = `-- name: SoftDeleteCompany :one
+UPDATE companies
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+ AND ($2::timestamptz IS NULL OR updated_at = $2)
+RETURNING id, email, phone, name, created_at, updated_at, deleted_at
+`
+
+type SoftDeleteCompanyParams struct {
+	ID        int64        `json:"id"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+// a valid updated_at only deletes the company if it was not changed since
+func (q *Queries) SoftDeleteCompany(ctx context.Context, arg SoftDeleteCompanyParams) (Company, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteCompany, arg.ID, arg.UpdatedAt)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Phone,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateCompany = `-- name: UpdateCompany :one
 UPDATE companies
 SET
@@ -137,23 +322,27 @@ SET
  phone = coalesce($2, phone),
  name = coalesce($3, name),
  updated_at = now()
-WHERE id = $4 
-RETURNING id, email, phone, name, created_at, updated_at
+WHERE id = $4 AND deleted_at IS NULL
+ AND ($5::timestamptz IS NULL OR updated_at = $5)
+RETURNING id, email, phone, name, created_at, updated_at, deleted_at
 `
 
 type UpdateCompanyParams struct {
-	Email sql.NullString `json:"email"`
-	Phone sql.NullString `json:"phone"`
-	Name  sql.NullString `json:"name"`
-	ID    int64          `json:"id"`
+	Email     sql.NullString `json:"email"`
+	Phone     sql.NullString `json:"phone"`
+	Name      sql.NullString `json:"name"`
+	ID        int64          `json:"id"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
 }
 
+// a valid updated_at only updates the company if it was not changed since
 func (q *Queries) UpdateCompany(ctx context.Context, arg UpdateCompanyParams) (Company, error) {
 	row := q.db.QueryRowContext(ctx, updateCompany,
 		arg.Email,
 		arg.Phone,
 		arg.Name,
 		arg.ID,
+		arg.UpdatedAt,
 	)
 	var i Company
 	err := row.Scan(
@@ -163,6 +352,7 @@ func (q *Queries) UpdateCompany(ctx context.Context, arg UpdateCompanyParams) (C
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
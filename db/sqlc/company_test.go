@@ -58,8 +58,8 @@ func TestListCompanies(t *testing.T) {
 		createRandomCompany(t)
 	}
 	arg := ListCompaniesParams{
-		Limit:  5,
-		Offset: 5,
+		Limit:   5,
+		AfterID: 0,
 	}
 
 	companies, err := testQueries.ListCompanies(context.Background(), arg)
@@ -167,10 +167,48 @@ func TestDeleteCompany(t *testing.T) {
 	require := require.New(t)
 
 	company := createRandomCompany(t)
-	err := testQueries.DeleteCompany(context.Background(), company.ID)
+	_, err := testQueries.SoftDeleteCompany(context.Background(), SoftDeleteCompanyParams{ID: company.ID})
 	require.NoError(err)
 	company1, err := testQueries.GetCompanyByEmail(context.Background(), company.Email)
 	require.Error(err)
 	require.EqualError(err, sql.ErrNoRows.Error())
 	require.Empty(company1)
 }
+
+func TestSoftDeleteCompanyIfUnchanged(t *testing.T) {
+	require := require.New(t)
+	company := createRandomCompany(t)
+
+	stale := sql.NullTime{Time: company.UpdatedAt.Add(-time.Second), Valid: true}
+	_, err := testQueries.SoftDeleteCompany(context.Background(), SoftDeleteCompanyParams{ID: company.ID, UpdatedAt: stale})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	current := sql.NullTime{Time: company.UpdatedAt, Valid: true}
+	deleted, err := testQueries.SoftDeleteCompany(context.Background(), SoftDeleteCompanyParams{ID: company.ID, UpdatedAt: current})
+	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
+}
+
+func TestSearchCompanies(t *testing.T) {
+	company := createRandomCompany(t)
+
+	arg := SearchCompaniesParams{
+		Search: sql.NullString{String: "%" + company.Email + "%", Valid: true},
+		Sort:   "-updated_at",
+		Limit:  5,
+	}
+	companies, err := testQueries.SearchCompanies(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, companies, 1)
+	require.Equal(t, company.ID, companies[0].ID)
+
+	arg.AfterID = sql.NullInt64{Int64: company.ID, Valid: true}
+	arg.AfterTime = company.UpdatedAt
+	companies, err = testQueries.SearchCompanies(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, companies)
+
+	total, err := testQueries.CountCompanies(context.Background(), CountCompaniesParams{Search: arg.Search})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+}
@@ -0,0 +1,247 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: invitation.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const acceptInvitation = `-- name: AcceptInvitation :one
+UPDATE invitations
+SET
+ accepted_at = now(),
+ accepted_by = $1
+WHERE id = $2
+RETURNING id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at
+`
+
+type AcceptInvitationParams struct {
+	AcceptedBy sql.NullInt64 `json:"accepted_by"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) AcceptInvitation(ctx context.Context, arg AcceptInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, acceptInvitation, arg.AcceptedBy, arg.ID)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.InvitedBy,
+		&i.Role,
+		&i.Phone,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countCompanyInvitations = `-- name: CountCompanyInvitations :one
+SELECT count(*) FROM invitations
+WHERE company_id = $1
+`
+
+func (q *Queries) CountCompanyInvitations(ctx context.Context, companyID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanyInvitations, companyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createInvitation = `-- name: CreateInvitation :one
+INSERT INTO invitations (
+  company_id,
+  invited_by,
+  role,
+  phone,
+  email,
+  token_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at
+`
+
+type CreateInvitationParams struct {
+	CompanyID int64          `json:"company_id"`
+	InvitedBy int64          `json:"invited_by"`
+	Role      string         `json:"role"`
+	Phone     sql.NullString `json:"phone"`
+	Email     sql.NullString `json:"email"`
+	TokenHash string         `json:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, createInvitation,
+		arg.CompanyID,
+		arg.InvitedBy,
+		arg.Role,
+		arg.Phone,
+		arg.Email,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.InvitedBy,
+		&i.Role,
+		&i.Phone,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInvitation = `-- name: GetInvitation :one
+SELECT id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at FROM invitations
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetInvitation(ctx context.Context, id int64) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, getInvitation, id)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.InvitedBy,
+		&i.Role,
+		&i.Phone,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInvitationByTokenHashForUpdate = `-- name: GetInvitationByTokenHashForUpdate :one
+SELECT id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at FROM invitations
+WHERE token_hash = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetInvitationByTokenHashForUpdate(ctx context.Context, tokenHash string) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, getInvitationByTokenHashForUpdate, tokenHash)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.InvitedBy,
+		&i.Role,
+		&i.Phone,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listCompanyInvitations = `-- name: ListCompanyInvitations :many
+SELECT id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at FROM invitations
+WHERE company_id = $1
+ AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListCompanyInvitationsParams struct {
+	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListCompanyInvitations(ctx context.Context, arg ListCompanyInvitationsParams) ([]Invitation, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanyInvitations, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Invitation{}
+	for rows.Next() {
+		var i Invitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.InvitedBy,
+			&i.Role,
+			&i.Phone,
+			&i.Email,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.AcceptedBy,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeInvitation = `-- name: RevokeInvitation :one
+UPDATE invitations
+SET
+ revoked_at = now()
+WHERE id = $1
+ AND company_id = $2
+ AND accepted_at IS NULL
+ AND revoked_at IS NULL
+RETURNING id, company_id, invited_by, role, phone, email, token_hash, expires_at, accepted_at, accepted_by, revoked_at, created_at
+`
+
+type RevokeInvitationParams struct {
+	ID        int64 `json:"id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, revokeInvitation, arg.ID, arg.CompanyID)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.InvitedBy,
+		&i.Role,
+		&i.Phone,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
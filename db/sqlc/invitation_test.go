@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInvitation(t *testing.T, inviter User) (Invitation, string) {
+	token := util.RandomString(32)
+	arg := CreateInvitationParams{
+		CompanyID: inviter.CompanyID,
+		InvitedBy: inviter.ID,
+		Role:      util.MemberRole,
+		Phone:     sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+		TokenHash: util.HashSecret(token),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	invitation, err := testQueries.CreateInvitation(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, invitation.ID)
+	require.Equal(t, arg.CompanyID, invitation.CompanyID)
+	require.Equal(t, arg.InvitedBy, invitation.InvitedBy)
+	require.Equal(t, arg.Role, invitation.Role)
+	require.Equal(t, arg.Phone, invitation.Phone)
+	require.False(t, invitation.Email.Valid)
+	require.Equal(t, arg.TokenHash, invitation.TokenHash)
+	require.WithinDuration(t, arg.ExpiresAt, invitation.ExpiresAt, time.Second)
+	require.False(t, invitation.AcceptedAt.Valid)
+	require.False(t, invitation.RevokedAt.Valid)
+	return invitation, token
+}
+
+func TestCreateInvitation(t *testing.T) {
+	createRandomInvitation(t, createRandomUser(t))
+}
+
+func TestListCompanyInvitations(t *testing.T) {
+	inviter := createRandomUser(t)
+	for i := 0; i < 5; i++ {
+		createRandomInvitation(t, inviter)
+	}
+
+	invitations, err := testQueries.ListCompanyInvitations(context.Background(), ListCompanyInvitationsParams{
+		CompanyID: inviter.CompanyID,
+		Limit:     5,
+		AfterID:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, invitations, 5)
+	for _, invitation := range invitations {
+		require.Equal(t, inviter.CompanyID, invitation.CompanyID)
+	}
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	inviter := createRandomUser(t)
+	invitation, _ := createRandomInvitation(t, inviter)
+
+	_, err := testQueries.RevokeInvitation(context.Background(), RevokeInvitationParams{
+		ID:        invitation.ID,
+		CompanyID: inviter.CompanyID + 1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := testQueries.RevokeInvitation(context.Background(), RevokeInvitationParams{
+		ID:        invitation.ID,
+		CompanyID: inviter.CompanyID,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	_, err = testQueries.RevokeInvitation(context.Background(), RevokeInvitationParams{
+		ID:        invitation.ID,
+		CompanyID: inviter.CompanyID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
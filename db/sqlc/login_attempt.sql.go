@@ -0,0 +1,99 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: login_attempt.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const getLoginAttempt = `-- name: GetLoginAttempt :one
+SELECT key, failed_count, locked_until, last_failed_at FROM login_attempts
+WHERE key = $1 LIMIT 1
+`
+
+func (q *Queries) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAttempt, key)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.FailedCount,
+		&i.LockedUntil,
+		&i.LastFailedAt,
+	)
+	return i, err
+}
+
+const lockLoginAttempt = `-- name: LockLoginAttempt :one
+UPDATE login_attempts
+SET locked_until = $2
+WHERE key = $1
+RETURNING key, failed_count, locked_until, last_failed_at
+`
+
+type LockLoginAttemptParams struct {
+	Key         string       `json:"key"`
+	LockedUntil sql.NullTime `json:"locked_until"`
+}
+
+func (q *Queries) LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, lockLoginAttempt, arg.Key, arg.LockedUntil)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.FailedCount,
+		&i.LockedUntil,
+		&i.LastFailedAt,
+	)
+	return i, err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+INSERT INTO login_attempts (
+  key,
+  failed_count,
+  last_failed_at
+) VALUES (
+  $1, 1, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+  failed_count = CASE
+    WHEN login_attempts.last_failed_at < $2 THEN 1
+    ELSE login_attempts.failed_count + 1
+  END,
+  last_failed_at = now()
+RETURNING key, failed_count, locked_until, last_failed_at
+`
+
+type RecordFailedLoginParams struct {
+	Key         string    `json:"key"`
+	ResetBefore time.Time `json:"reset_before"`
+}
+
+// the count starts over when the previous failure is older than reset_before
+func (q *Queries) RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, recordFailedLogin, arg.Key, arg.ResetBefore)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.Key,
+		&i.FailedCount,
+		&i.LockedUntil,
+		&i.LastFailedAt,
+	)
+	return i, err
+}
+
+const resetLoginAttempts = `-- name: ResetLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE key = $1
+`
+
+func (q *Queries) ResetLoginAttempts(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, resetLoginAttempts, key)
+	return err
+}
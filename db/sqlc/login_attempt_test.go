@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createFailedLogin(t *testing.T, key string, resetBefore time.Time) LoginAttempt {
+	attempt, err := testQueries.RecordFailedLogin(context.Background(), RecordFailedLoginParams{
+		Key:         key,
+		ResetBefore: resetBefore,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, attempt.Key)
+	require.WithinDuration(t, time.Now(), attempt.LastFailedAt, time.Second)
+	return attempt
+}
+
+func TestRecordFailedLogin(t *testing.T) {
+	key := "phone:" + util.RandomPhoneNumber()
+	window := time.Now().Add(-time.Hour)
+
+	for i := 1; i <= 3; i++ {
+		attempt := createFailedLogin(t, key, window)
+		require.Equal(t, int32(i), attempt.FailedCount)
+	}
+
+	// failures older than reset_before are forgotten
+	attempt := createFailedLogin(t, key, time.Now().Add(time.Minute))
+	require.Equal(t, int32(1), attempt.FailedCount)
+}
+
+func TestLockLoginAttempt(t *testing.T) {
+	key := "ip:" + util.RandomString(8)
+	createFailedLogin(t, key, time.Now().Add(-time.Hour))
+
+	lockedUntil := time.Now().Add(time.Minute)
+	locked, err := testQueries.LockLoginAttempt(context.Background(), LockLoginAttemptParams{
+		Key:         key,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, locked.LockedUntil.Valid)
+	require.WithinDuration(t, lockedUntil, locked.LockedUntil.Time, time.Second)
+
+	got, err := testQueries.GetLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, locked.LockedUntil.Time.Unix(), got.LockedUntil.Time.Unix())
+}
+
+func TestResetLoginAttempts(t *testing.T) {
+	key := "phone:" + util.RandomPhoneNumber()
+	createFailedLogin(t, key, time.Now().Add(-time.Hour))
+
+	err := testQueries.ResetLoginAttempts(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginAttempt(context.Background(), key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
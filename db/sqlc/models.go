@@ -5,17 +5,50 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         int64         `json:"id"`
+	CompanyID  int64         `json:"company_id"`
+	CreatedBy  sql.NullInt64 `json:"created_by"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	SecretHash string        `json:"secret_hash"`
+	Scopes     []string      `json:"scopes"`
+	ExpiresAt  sql.NullTime  `json:"expires_at"`
+	LastUsedAt sql.NullTime  `json:"last_used_at"`
+	RevokedAt  sql.NullTime  `json:"revoked_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID            int64           `json:"id"`
+	CompanyID     int64           `json:"company_id"`
+	ActorUserID   sql.NullInt64   `json:"actor_user_id"`
+	ActorApiKeyID sql.NullInt64   `json:"actor_api_key_id"`
+	ActorName     string          `json:"actor_name"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      sql.NullInt64   `json:"entity_id"`
+	Changes       json.RawMessage `json:"changes"`
+	Ip            string          `json:"ip"`
+	UserAgent     string          `json:"user_agent"`
+	RequestID     string          `json:"request_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
 type Bot struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	CompanyID int64     `json:"company_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64        `json:"id"`
+	Title     string       `json:"title"`
+	CompanyID int64        `json:"company_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	DeletedAt sql.NullTime `json:"-"`
 }
 
 type Channel struct {
@@ -26,23 +59,66 @@ type Channel struct {
 }
 
 type Company struct {
-	ID        int64     `json:"id"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64        `json:"id"`
+	Email     string       `json:"email"`
+	Phone     string       `json:"phone"`
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	DeletedAt sql.NullTime `json:"-"`
+}
+
+type Invitation struct {
+	ID         int64          `json:"id"`
+	CompanyID  int64          `json:"company_id"`
+	InvitedBy  int64          `json:"invited_by"`
+	Role       string         `json:"role"`
+	Phone      sql.NullString `json:"phone"`
+	Email      sql.NullString `json:"email"`
+	TokenHash  string         `json:"token_hash"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	AcceptedAt sql.NullTime   `json:"accepted_at"`
+	AcceptedBy sql.NullInt64  `json:"accepted_by"`
+	RevokedAt  sql.NullTime   `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	Key          string       `json:"key"`
+	FailedCount  int32        `json:"failed_count"`
+	LockedUntil  sql.NullTime `json:"locked_until"`
+	LastFailedAt time.Time    `json:"last_failed_at"`
+}
+
+type OtpCode struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Purpose    string       `json:"purpose"`
+	CodeHash   string       `json:"code_hash"`
+	Attempts   int32        `json:"attempts"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `json:"created_at"`
 }
 
 type Question struct {
-	ID             int64     `json:"id"`
-	Question       string    `json:"question"`
-	BotID          int64     `json:"bot_id"`
-	Type           string    `json:"type"`
-	ParentID       int64     `json:"parent_id"`
-	NextQuestionID int64     `json:"next_question_id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             int64        `json:"id"`
+	Question       string       `json:"question"`
+	BotID          int64        `json:"bot_id"`
+	Type           string       `json:"type"`
+	ParentID       int64        `json:"parent_id"`
+	NextQuestionID int64        `json:"next_question_id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	DeletedAt      sql.NullTime `json:"-"`
+}
+
+type RecoveryCode struct {
+	ID        int64        `json:"id"`
+	UserID    int64        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
 
 type Response struct {
@@ -69,15 +145,50 @@ type Session struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+type SsoConnection struct {
+	ID           int64     `json:"id"`
+	CompanyID    int64     `json:"company_id"`
+	EmailDomain  string    `json:"email_domain"`
+	Issuer       string    `json:"issuer"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type SsoDomain struct {
+	ID                int64        `json:"id"`
+	CompanyID         int64        `json:"company_id"`
+	Domain            string       `json:"domain"`
+	VerificationToken string       `json:"verification_token"`
+	VerifiedAt        sql.NullTime `json:"verified_at"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+type SsoLoginState struct {
+	State        string    `json:"state"`
+	ConnectionID int64     `json:"connection_id"`
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type User struct {
-	ID                int64     `json:"id"`
-	Phone             string    `json:"phone"`
-	CompanyID         int64     `json:"company_id"`
-	PasswordHash      string    `json:"password_hash"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	Name              string    `json:"name"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                int64          `json:"id"`
+	Phone             string         `json:"phone"`
+	CompanyID         int64          `json:"company_id"`
+	PasswordHash      string         `json:"password_hash"`
+	PasswordChangedAt time.Time      `json:"password_changed_at"`
+	Name              string         `json:"name"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	Role              string         `json:"role"`
+	VerifiedAt        sql.NullTime   `json:"verified_at"`
+	Email             sql.NullString `json:"email"`
+	TotpSecret        sql.NullString `json:"totp_secret"`
+	TotpEnabledAt     sql.NullTime   `json:"totp_enabled_at"`
+	TotpLastCounter   int64          `json:"totp_last_counter"`
+	DeactivatedAt     sql.NullTime   `json:"deactivated_at"`
 }
 
 type UserResponse struct {
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryObserver is told the name of every query the store runs, as in its
+// "-- name:" comment, and how long the database took to answer it
+type QueryObserver func(name string, duration time.Duration)
+
+// observedDBTX times the queries run on db and reports them to observe.
+// For queries returning rows the time is the one taken to get the first rows.
+type observedDBTX struct {
+	db      DBTX
+	observe QueryObserver
+}
+
+func (o observedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer o.since(query, time.Now())
+	return o.db.ExecContext(ctx, query, args...)
+}
+
+func (o observedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return o.db.PrepareContext(ctx, query)
+}
+
+func (o observedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer o.since(query, time.Now())
+	return o.db.QueryContext(ctx, query, args...)
+}
+
+func (o observedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer o.since(query, time.Now())
+	return o.db.QueryRowContext(ctx, query, args...)
+}
+
+func (o observedDBTX) since(query string, start time.Time) {
+	o.observe(queryName(query), time.Since(start))
+}
+
+// queryName returns the name sqlc gives a query in its first line, "-- name: GetBot :one"
+func queryName(query string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(query, prefix) {
+		return "unnamed"
+	}
+	name, _, _ := strings.Cut(query[len(prefix):], " ")
+	return name
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetBot", queryName(getBot))
+	require.Equal(t, "CountActiveConversations", queryName(countActiveConversations))
+	require.Equal(t, "unnamed", queryName("SELECT version, dirty FROM schema_migrations LIMIT 1"))
+}
@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: otp.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const consumeOtpCode = `-- name: ConsumeOtpCode :one
+UPDATE otp_codes
+SET consumed_at = now()
+WHERE id = $1 AND consumed_at IS NULL
+RETURNING id, user_id, purpose, code_hash, attempts, expires_at, consumed_at, created_at
+`
+
+func (q *Queries) ConsumeOtpCode(ctx context.Context, id int64) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, consumeOtpCode, id)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOtpCode = `-- name: CreateOtpCode :one
+INSERT INTO otp_codes (
+  user_id,
+  purpose,
+  code_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, user_id, purpose, code_hash, attempts, expires_at, consumed_at, created_at
+`
+
+type CreateOtpCodeParams struct {
+	UserID    int64     `json:"user_id"`
+	Purpose   string    `json:"purpose"`
+	CodeHash  string    `json:"code_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOtpCode(ctx context.Context, arg CreateOtpCodeParams) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, createOtpCode,
+		arg.UserID,
+		arg.Purpose,
+		arg.CodeHash,
+		arg.ExpiresAt,
+	)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestOtpCode = `-- name: GetLatestOtpCode :one
+SELECT id, user_id, purpose, code_hash, attempts, expires_at, consumed_at, created_at FROM otp_codes
+WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetLatestOtpCodeParams struct {
+	UserID  int64  `json:"user_id"`
+	Purpose string `json:"purpose"`
+}
+
+func (q *Queries) GetLatestOtpCode(ctx context.Context, arg GetLatestOtpCodeParams) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, getLatestOtpCode, arg.UserID, arg.Purpose)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementOtpCodeAttempts = `-- name: IncrementOtpCodeAttempts :one
+UPDATE otp_codes
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING id, user_id, purpose, code_hash, attempts, expires_at, consumed_at, created_at
+`
+
+func (q *Queries) IncrementOtpCodeAttempts(ctx context.Context, id int64) (OtpCode, error) {
+	row := q.db.QueryRowContext(ctx, incrementOtpCodeAttempts, id)
+	var i OtpCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.CodeHash,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOtpCode(t *testing.T, user User, purpose string) OtpCode {
+	arg := CreateOtpCodeParams{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		CodeHash:  util.RandomString(32),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	otpCode, err := testQueries.CreateOtpCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, otpCode.ID)
+	require.Equal(t, arg.UserID, otpCode.UserID)
+	require.Equal(t, arg.Purpose, otpCode.Purpose)
+	require.Equal(t, arg.CodeHash, otpCode.CodeHash)
+	require.Zero(t, otpCode.Attempts)
+	require.False(t, otpCode.ConsumedAt.Valid)
+	require.WithinDuration(t, arg.ExpiresAt, otpCode.ExpiresAt, time.Second)
+	return otpCode
+}
+
+func TestCreateOtpCode(t *testing.T) {
+	createRandomOtpCode(t, createRandomUser(t), "password_reset")
+}
+
+func TestGetLatestOtpCode(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomOtpCode(t, user, "password_reset")
+	latest := createRandomOtpCode(t, user, "password_reset")
+
+	otpCode, err := testQueries.GetLatestOtpCode(context.Background(), GetLatestOtpCodeParams{
+		UserID:  user.ID,
+		Purpose: "password_reset",
+	})
+	require.NoError(t, err)
+	require.Equal(t, latest.ID, otpCode.ID)
+
+	_, err = testQueries.GetLatestOtpCode(context.Background(), GetLatestOtpCodeParams{
+		UserID:  user.ID,
+		Purpose: util.RandomString(6),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestIncrementOtpCodeAttempts(t *testing.T) {
+	otpCode := createRandomOtpCode(t, createRandomUser(t), "password_reset")
+
+	for i := 1; i <= 3; i++ {
+		updated, err := testQueries.IncrementOtpCodeAttempts(context.Background(), otpCode.ID)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), updated.Attempts)
+	}
+}
+
+func TestConsumeOtpCode(t *testing.T) {
+	user := createRandomUser(t)
+	otpCode := createRandomOtpCode(t, user, "password_reset")
+
+	consumed, err := testQueries.ConsumeOtpCode(context.Background(), otpCode.ID)
+	require.NoError(t, err)
+	require.True(t, consumed.ConsumedAt.Valid)
+
+	_, err = testQueries.ConsumeOtpCode(context.Background(), otpCode.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.GetLatestOtpCode(context.Background(), GetLatestOtpCodeParams{
+		UserID:  user.ID,
+		Purpose: "password_reset",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,33 +6,144 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcceptInvitation(ctx context.Context, arg AcceptInvitationParams) (Invitation, error)
+	BlockUserSessions(ctx context.Context, userID int64) error
+	ConsumeOtpCode(ctx context.Context, id int64) (OtpCode, error)
+	ConsumeSsoLoginState(ctx context.Context, state string) (SsoLoginState, error)
+	// a session is in a conversation while it waits for the answer to a question on a channel
+	CountActiveConversations(ctx context.Context) ([]CountActiveConversationsRow, error)
+	CountAuditLogs(ctx context.Context, arg CountAuditLogsParams) (int64, error)
+	CountBots(ctx context.Context, arg CountBotsParams) (int64, error)
+	CountChannels(ctx context.Context) (int64, error)
+	CountCompanies(ctx context.Context, arg CountCompaniesParams) (int64, error)
+	CountCompanyApiKeys(ctx context.Context, companyID int64) (int64, error)
+	CountCompanyInvitations(ctx context.Context, companyID int64) (int64, error)
+	CountCompanySsoConnections(ctx context.Context, companyID int64) (int64, error)
+	CountCompanySsoDomains(ctx context.Context, companyID int64) (int64, error)
+	CountCompanyUsers(ctx context.Context, companyID int64) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateBot(ctx context.Context, arg CreateBotParams) (Bot, error)
 	CreateChannel(ctx context.Context, name string) (Channel, error)
 	CreateCompany(ctx context.Context, arg CreateCompanyParams) (Company, error)
+	CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error)
+	CreateOtpCode(ctx context.Context, arg CreateOtpCodeParams) (OtpCode, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateResponse(ctx context.Context, arg CreateResponseParams) (Response, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSsoConnection(ctx context.Context, arg CreateSsoConnectionParams) (SsoConnection, error)
+	CreateSsoDomain(ctx context.Context, arg CreateSsoDomainParams) (SsoDomain, error)
+	CreateSsoLoginState(ctx context.Context, arg CreateSsoLoginStateParams) (SsoLoginState, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteBot(ctx context.Context, id int64) error
-	DeleteChannel(ctx context.Context, id int32) error
-	DeleteCompany(ctx context.Context, id int64) error
+	CreateUserResponse(ctx context.Context, arg CreateUserResponseParams) (UserResponse, error)
+	DeactivateUser(ctx context.Context, id int64) (User, error)
+	DeleteChannel(ctx context.Context, id int32) (Channel, error)
+	DeleteSsoConnection(ctx context.Context, arg DeleteSsoConnectionParams) (SsoConnection, error)
+	DeleteSsoDomain(ctx context.Context, arg DeleteSsoDomainParams) (SsoDomain, error)
+	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserRecoveryCodes(ctx context.Context, userID int64) error
+	DisableUserTotp(ctx context.Context, id int64) (User, error)
+	EnableUserTotp(ctx context.Context, id int64) (User, error)
+	// the keys of a deleted company stop working with it
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
 	GetBot(ctx context.Context, id int64) (Bot, error)
 	GetChannel(ctx context.Context, name string) (Channel, error)
+	GetChannelByID(ctx context.Context, id int32) (Channel, error)
 	GetCompanyByEmail(ctx context.Context, email string) (Company, error)
 	GetCompanyByID(ctx context.Context, id int64) (Company, error)
+	GetCompanySsoDomain(ctx context.Context, arg GetCompanySsoDomainParams) (SsoDomain, error)
+	GetDeletedBot(ctx context.Context, id int64) (Bot, error)
+	GetDeletedQuestion(ctx context.Context, id int64) (Question, error)
+	// a conversation with a bot starts at the first of its questions without a parent
+	GetFirstQuestion(ctx context.Context, botID int64) (Question, error)
+	GetInvitation(ctx context.Context, id int64) (Invitation, error)
+	GetInvitationByTokenHashForUpdate(ctx context.Context, tokenHash string) (Invitation, error)
+	GetLatestOtpCode(ctx context.Context, arg GetLatestOtpCodeParams) (OtpCode, error)
+	GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	GetQuestion(ctx context.Context, id int64) (Question, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSsoConnection(ctx context.Context, id int64) (SsoConnection, error)
+	// a connection is only used once its company has verified the email domain
+	GetSsoConnectionByDomain(ctx context.Context, emailDomain string) (SsoConnection, error)
+	// the users of a deleted company are gone with it
 	GetUser(ctx context.Context, phone string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int64) (User, error)
+	GetVerifiedSsoDomain(ctx context.Context, arg GetVerifiedSsoDomainParams) (SsoDomain, error)
+	IncrementOtpCodeAttempts(ctx context.Context, id int64) (OtpCode, error)
 	ListAllBots(ctx context.Context, arg ListAllBotsParams) ([]Bot, error)
+	// newest first, a page starts before after_id
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
 	ListChannels(ctx context.Context, arg ListChannelsParams) ([]Channel, error)
 	ListCompanies(ctx context.Context, arg ListCompaniesParams) ([]Company, error)
+	ListCompanyApiKeys(ctx context.Context, arg ListCompanyApiKeysParams) ([]ApiKey, error)
 	ListCompanyBots(ctx context.Context, arg ListCompanyBotsParams) ([]Bot, error)
+	ListCompanyInvitations(ctx context.Context, arg ListCompanyInvitationsParams) ([]Invitation, error)
+	ListCompanySsoConnections(ctx context.Context, arg ListCompanySsoConnectionsParams) ([]SsoConnection, error)
+	ListCompanySsoDomains(ctx context.Context, arg ListCompanySsoDomainsParams) ([]SsoDomain, error)
+	ListCompanyUsers(ctx context.Context, arg ListCompanyUsersParams) ([]User, error)
+	LockCompanyOwners(ctx context.Context, companyID int64) ([]int64, error)
+	LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) (LoginAttempt, error)
+	MarkUserVerified(ctx context.Context, id int64) (User, error)
+	// an answer matches a response of the question whatever its case and surrounding spaces
+	MatchResponse(ctx context.Context, arg MatchResponseParams) (Response, error)
+	PurgeDeletedBots(ctx context.Context, before time.Time) (int64, error)
+	PurgeDeletedCompanies(ctx context.Context, before time.Time) (int64, error)
+	PurgeDeletedQuestions(ctx context.Context, before time.Time) (int64, error)
+	ReactivateUser(ctx context.Context, id int64) (User, error)
+	// the count starts over when the previous failure is older than reset_before
+	RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (LoginAttempt, error)
+	ResetLoginAttempts(ctx context.Context, key string) error
+	RestoreBot(ctx context.Context, id int64) (Bot, error)
+	// restores the questions deleted with the bot, not the ones deleted before it
+	RestoreBotQuestions(ctx context.Context, botID int64) error
+	RestoreCompany(ctx context.Context, id int64) (Company, error)
+	// restores the bots deleted with the company, not the ones deleted before it
+	RestoreCompanyBots(ctx context.Context, companyID int64) error
+	// restores the questions deleted with the company, not the ones deleted before it
+	RestoreCompanyQuestions(ctx context.Context, id int64) error
+	RestoreQuestion(ctx context.Context, id int64) (Question, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (ApiKey, error)
+	RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (Invitation, error)
+	// sort is one of title, updated_at or the same prefixed with - for descending order, and id otherwise.
+	// after_text and after_time are the sort key of the row after_id.
+	SearchBots(ctx context.Context, arg SearchBotsParams) ([]Bot, error)
+	// sort is one of name, email, updated_at or the same prefixed with - for descending order, and id otherwise.
+	// after_text and after_time are the sort key of the row after_id.
+	SearchCompanies(ctx context.Context, arg SearchCompaniesParams) ([]Company, error)
+	SetUserTotpSecret(ctx context.Context, arg SetUserTotpSecretParams) (User, error)
+	// a valid updated_at only deletes the bot if it was not changed since
+	SoftDeleteBot(ctx context.Context, arg SoftDeleteBotParams) (Bot, error)
+	SoftDeleteBotQuestions(ctx context.Context, id int64) error
+	// a valid updated_at only deletes the company if it was not changed since
+	SoftDeleteCompany(ctx context.Context, arg SoftDeleteCompanyParams) (Company, error)
+	SoftDeleteCompanyBots(ctx context.Context, id int64) error
+	SoftDeleteCompanyQuestions(ctx context.Context, id int64) error
+	// a valid updated_at only deletes the question if it was not changed since
+	SoftDeleteQuestion(ctx context.Context, arg SoftDeleteQuestionParams) (Question, error)
+	TouchApiKey(ctx context.Context, id int64) error
+	// a valid updated_at only updates the bot if it was not changed since
 	UpdateBot(ctx context.Context, arg UpdateBotParams) (Bot, error)
 	UpdateChannel(ctx context.Context, arg UpdateChannelParams) (Channel, error)
+	// a valid updated_at only updates the company if it was not changed since
 	UpdateCompany(ctx context.Context, arg UpdateCompanyParams) (Company, error)
+	// a valid updated_at only updates the question if it was not changed since
+	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
+	// question_id is the question the session waits for the answer to, 0 once the conversation is over
+	UpdateSessionConversation(ctx context.Context, arg UpdateSessionConversationParams) (Session, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseUserTotpCounter(ctx context.Context, arg UseUserTotpCounterParams) (int64, error)
+	VerifySsoDomain(ctx context.Context, id int64) (SsoDomain, error)
 }
 
 var _ Querier = (*Queries)(nil)
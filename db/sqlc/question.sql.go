@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createQuestion = `-- name: CreateQuestion :one
@@ -18,7 +20,7 @@ INSERT INTO questions (
     next_question_id
 ) VALUES (
     $1,$2,$3,$4,$5
-) RETURNING id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at
+) RETURNING id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at
 `
 
 type CreateQuestionParams struct {
@@ -47,6 +49,241 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.NextQuestionID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getDeletedQuestion = `-- name: GetDeletedQuestion :one
+SELECT id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at FROM questions
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
+`
+
+func (q *Queries) GetDeletedQuestion(ctx context.Context, id int64) (Question, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getFirstQuestion = `-- name: GetFirstQuestion :one
+SELECT id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at FROM questions
+WHERE bot_id = $1 AND parent_id = 0 AND deleted_at IS NULL
+ORDER BY id
+LIMIT 1
+`
+
+// a conversation with a bot starts at the first of its questions without a parent
+func (q *Queries) GetFirstQuestion(ctx context.Context, botID int64) (Question, error) {
+	row := q.db.QueryRowContext(ctx, getFirstQuestion, botID)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getQuestion = `-- name: GetQuestion :one
+SELECT id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at FROM questions
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetQuestion(ctx context.Context, id int64) (Question, error) {
+	row := q.db.QueryRowContext(ctx, getQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const purgeDeletedQuestions = `-- name: PurgeDeletedQuestions :execrows
+DELETE FROM questions
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeDeletedQuestions(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedQuestions, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreBotQuestions = `-- name: RestoreBotQuestions :exec
+UPDATE questions
+SET deleted_at = NULL
+WHERE bot_id = $1 AND deleted_at = (SELECT b.deleted_at FROM bots b WHERE b.id = $1)
+`
+
+// restores the questions deleted with the bot, not the ones deleted before it
+func (q *Queries) RestoreBotQuestions(ctx context.Context, botID int64) error {
+	_, err := q.db.ExecContext(ctx, restoreBotQuestions, botID)
+	return err
+}
+
+const restoreCompanyQuestions = `-- name: RestoreCompanyQuestions :exec
+UPDATE questions q
+SET deleted_at = NULL
+FROM bots b, companies c
+WHERE q.bot_id = b.id AND b.company_id = c.id
+ AND c.id = $1 AND b.deleted_at = c.deleted_at AND q.deleted_at = c.deleted_at
+`
+
+// restores the questions deleted with the company, not the ones deleted before it
+func (q *Queries) RestoreCompanyQuestions(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, restoreCompanyQuestions, id)
+	return err
+}
+
+const restoreQuestion = `-- name: RestoreQuestion :one
+UPDATE questions
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) RestoreQuestion(ctx context.Context, id int64) (Question, error) {
+	row := q.db.QueryRowContext(ctx, restoreQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const softDeleteBotQuestions = `-- name: SoftDeleteBotQuestions :exec
+UPDATE questions
+SET deleted_at = (SELECT b.deleted_at FROM bots b WHERE b.id = $1)
+WHERE bot_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteBotQuestions(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteBotQuestions, id)
+	return err
+}
+
+const softDeleteCompanyQuestions = `-- name: SoftDeleteCompanyQuestions :exec
+UPDATE questions q
+SET deleted_at = c.deleted_at
+FROM bots b, companies c
+WHERE q.bot_id = b.id AND b.company_id = c.id
+ AND c.id = $1 AND b.deleted_at IS NULL AND q.deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteCompanyQuestions(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, softDeleteCompanyQuestions, id)
+	return err
+}
+
+const softDeleteQuestion = `-- name: SoftDeleteQuestion :one
+UPDATE questions
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+ AND ($2::timestamptz IS NULL OR updated_at = $2)
+RETURNING id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at
+`
+
+type SoftDeleteQuestionParams struct {
+	ID        int64        `json:"id"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+// a valid updated_at only deletes the question if it was not changed since
+func (q *Queries) SoftDeleteQuestion(ctx context.Context, arg SoftDeleteQuestionParams) (Question, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteQuestion, arg.ID, arg.UpdatedAt)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateQuestion = `-- name: UpdateQuestion :one
+UPDATE questions
+SET
+ question = coalesce($1, question),
+ type = coalesce($2, type),
+ parent_id = coalesce($3, parent_id),
+ next_question_id = coalesce($4, next_question_id),
+ updated_at = now()
+WHERE id = $5 AND deleted_at IS NULL
+ AND ($6::timestamptz IS NULL OR updated_at = $6)
+RETURNING id, question, bot_id, type, parent_id, next_question_id, created_at, updated_at, deleted_at
+`
+
+type UpdateQuestionParams struct {
+	Question       sql.NullString `json:"question"`
+	Type           sql.NullString `json:"type"`
+	ParentID       sql.NullInt64  `json:"parent_id"`
+	NextQuestionID sql.NullInt64  `json:"next_question_id"`
+	ID             int64          `json:"id"`
+	UpdatedAt      sql.NullTime   `json:"updated_at"`
+}
+
+// a valid updated_at only updates the question if it was not changed since
+func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
+	row := q.db.QueryRowContext(ctx, updateQuestion,
+		arg.Question,
+		arg.Type,
+		arg.ParentID,
+		arg.NextQuestionID,
+		arg.ID,
+		arg.UpdatedAt,
+	)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Question,
+		&i.BotID,
+		&i.Type,
+		&i.ParentID,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/lenimbugua/bot/util"
 	"github.com/stretchr/testify/require"
@@ -34,3 +36,80 @@ func createRandomQuestion(t *testing.T) Question {
 func TestCreateQuestion(t *testing.T) {
 	createRandomQuestion(t)
 }
+
+func TestRestoreQuestion(t *testing.T) {
+	require := require.New(t)
+	question := createRandomQuestion(t)
+
+	_, err := testQueries.GetDeletedQuestion(context.Background(), question.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	_, err = testQueries.SoftDeleteQuestion(context.Background(), SoftDeleteQuestionParams{ID: question.ID})
+	require.NoError(err)
+	_, err = testQueries.GetQuestion(context.Background(), question.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+	deleted, err := testQueries.GetDeletedQuestion(context.Background(), question.ID)
+	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
+
+	restored, err := testQueries.RestoreQuestion(context.Background(), question.ID)
+	require.NoError(err)
+	require.False(restored.DeletedAt.Valid)
+	_, err = testQueries.GetQuestion(context.Background(), question.ID)
+	require.NoError(err)
+
+	_, err = testQueries.RestoreQuestion(context.Background(), question.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestSoftDeleteQuestionIfUnchanged(t *testing.T) {
+	require := require.New(t)
+	question := createRandomQuestion(t)
+
+	stale := sql.NullTime{Time: question.UpdatedAt.Add(-time.Second), Valid: true}
+	_, err := testQueries.SoftDeleteQuestion(context.Background(), SoftDeleteQuestionParams{ID: question.ID, UpdatedAt: stale})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	current := sql.NullTime{Time: question.UpdatedAt, Valid: true}
+	deleted, err := testQueries.SoftDeleteQuestion(context.Background(), SoftDeleteQuestionParams{ID: question.ID, UpdatedAt: current})
+	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
+}
+
+func TestUpdateQuestionIfUnchanged(t *testing.T) {
+	require := require.New(t)
+	question := createRandomQuestion(t)
+	text := sql.NullString{String: util.RandomString(6), Valid: true}
+
+	stale := sql.NullTime{Time: question.UpdatedAt.Add(-time.Second), Valid: true}
+	_, err := testQueries.UpdateQuestion(context.Background(), UpdateQuestionParams{ID: question.ID, Question: text, UpdatedAt: stale})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	current := sql.NullTime{Time: question.UpdatedAt, Valid: true}
+	updated, err := testQueries.UpdateQuestion(context.Background(), UpdateQuestionParams{ID: question.ID, Question: text, UpdatedAt: current})
+	require.NoError(err)
+	require.Equal(text.String, updated.Question)
+	require.Equal(question.Type, updated.Type)
+	require.True(updated.UpdatedAt.After(question.UpdatedAt))
+}
+
+func TestGetFirstQuestion(t *testing.T) {
+	require := require.New(t)
+	first := createRandomQuestion(t)
+
+	child, err := testQueries.CreateQuestion(context.Background(), CreateQuestionParams{
+		Question: util.RandomString(6),
+		Type:     util.RandomString(6),
+		BotID:    first.BotID,
+		ParentID: first.ID,
+	})
+	require.NoError(err)
+	require.Equal(first.ID, child.ParentID)
+
+	got, err := testQueries.GetFirstQuestion(context.Background(), first.BotID)
+	require.NoError(err)
+	require.Equal(first.ID, got.ID)
+
+	_, err = testQueries.GetFirstQuestion(context.Background(), createRandomBot(t).ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomRecoveryCode(t *testing.T, user User) RecoveryCode {
+	arg := CreateRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: util.HashSecret(util.RandomString(10)),
+	}
+
+	code, err := testQueries.CreateRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, code.ID)
+	require.Equal(t, arg.UserID, code.UserID)
+	require.Equal(t, arg.CodeHash, code.CodeHash)
+	require.False(t, code.UsedAt.Valid)
+	return code
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	code := createRandomRecoveryCode(t, user)
+	createRandomRecoveryCode(t, user)
+
+	arg := UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: code.CodeHash,
+	}
+	used, err := testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, code.ID, used.ID)
+	require.True(t, used.UsedAt.Valid)
+
+	// recovery codes work once only
+	_, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	count, err := testQueries.CountUnusedRecoveryCodes(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestUseRecoveryCodeOtherUser(t *testing.T) {
+	code := createRandomRecoveryCode(t, createRandomUser(t))
+	other := createRandomUser(t)
+
+	_, err := testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{
+		UserID:   other.ID,
+		CodeHash: code.CodeHash,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,98 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: response.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createResponse = `-- name: CreateResponse :one
+INSERT INTO responses (
+    question_id,
+    response,
+    next_question_id
+) VALUES (
+    $1, $2, $3
+) RETURNING id, question_id, response, next_question_id, created_at, updated_at
+`
+
+type CreateResponseParams struct {
+	QuestionID     int64  `json:"question_id"`
+	Response       string `json:"response"`
+	NextQuestionID int64  `json:"next_question_id"`
+}
+
+func (q *Queries) CreateResponse(ctx context.Context, arg CreateResponseParams) (Response, error) {
+	row := q.db.QueryRowContext(ctx, createResponse, arg.QuestionID, arg.Response, arg.NextQuestionID)
+	var i Response
+	err := row.Scan(
+		&i.ID,
+		&i.QuestionID,
+		&i.Response,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createUserResponse = `-- name: CreateUserResponse :one
+INSERT INTO user_responses (
+    response_id,
+    user_id,
+    question_id
+) VALUES (
+    $1, $2, $3
+) RETURNING id, response_id, user_id, question_id, created_at, updated_at
+`
+
+type CreateUserResponseParams struct {
+	ResponseID int64 `json:"response_id"`
+	UserID     int64 `json:"user_id"`
+	QuestionID int64 `json:"question_id"`
+}
+
+func (q *Queries) CreateUserResponse(ctx context.Context, arg CreateUserResponseParams) (UserResponse, error) {
+	row := q.db.QueryRowContext(ctx, createUserResponse, arg.ResponseID, arg.UserID, arg.QuestionID)
+	var i UserResponse
+	err := row.Scan(
+		&i.ID,
+		&i.ResponseID,
+		&i.UserID,
+		&i.QuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const matchResponse = `-- name: MatchResponse :one
+SELECT id, question_id, response, next_question_id, created_at, updated_at FROM responses
+WHERE question_id = $1
+ AND lower(trim(response)) = lower(trim($2::text))
+ORDER BY id
+LIMIT 1
+`
+
+type MatchResponseParams struct {
+	QuestionID int64  `json:"question_id"`
+	Answer     string `json:"answer"`
+}
+
+// an answer matches a response of the question whatever its case and surrounding spaces
+func (q *Queries) MatchResponse(ctx context.Context, arg MatchResponseParams) (Response, error) {
+	row := q.db.QueryRowContext(ctx, matchResponse, arg.QuestionID, arg.Answer)
+	var i Response
+	err := row.Scan(
+		&i.ID,
+		&i.QuestionID,
+		&i.Response,
+		&i.NextQuestionID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
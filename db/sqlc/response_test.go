@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomResponse(t *testing.T, question Question) Response {
+	arg := CreateResponseParams{
+		QuestionID:     question.ID,
+		Response:       util.RandomString(6),
+		NextQuestionID: util.DefaultID,
+	}
+
+	response, err := testQueries.CreateResponse(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.QuestionID, response.QuestionID)
+	require.Equal(t, arg.Response, response.Response)
+	require.Equal(t, arg.NextQuestionID, response.NextQuestionID)
+	require.NotZero(t, response.CreatedAt)
+	return response
+}
+
+func TestMatchResponse(t *testing.T) {
+	require := require.New(t)
+	question := createRandomQuestion(t)
+	response := createRandomResponse(t, question)
+	createRandomResponse(t, question)
+
+	got, err := testQueries.MatchResponse(context.Background(), MatchResponseParams{
+		QuestionID: question.ID,
+		Answer:     " " + strings.ToUpper(response.Response) + " ",
+	})
+	require.NoError(err)
+	require.Equal(response.ID, got.ID)
+
+	_, err = testQueries.MatchResponse(context.Background(), MatchResponseParams{
+		QuestionID: createRandomQuestion(t).ID,
+		Answer:     response.Response,
+	})
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestCreateUserResponse(t *testing.T) {
+	user := createRandomUser(t)
+	question := createRandomQuestion(t)
+	response := createRandomResponse(t, question)
+
+	arg := CreateUserResponseParams{
+		ResponseID: response.ID,
+		UserID:     user.ID,
+		QuestionID: question.ID,
+	}
+	userResponse, err := testQueries.CreateUserResponse(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseID, userResponse.ResponseID)
+	require.Equal(t, arg.UserID, userResponse.UserID)
+	require.Equal(t, arg.QuestionID, userResponse.QuestionID)
+}
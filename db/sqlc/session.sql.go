@@ -12,6 +12,59 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockUserSessions = `-- name: BlockUserSessions :exec
+UPDATE sessions
+SET
+ is_blocked = true,
+ updated_at = now()
+WHERE user_id = $1
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, blockUserSessions, userID)
+	return err
+}
+
+const countActiveConversations = `-- name: CountActiveConversations :many
+SELECT channels.name AS channel, count(*) AS conversations
+FROM sessions
+JOIN channels ON channels.id = sessions.channel_id
+WHERE sessions.question_id <> 0
+ AND NOT sessions.is_blocked
+ AND sessions.expires_at > now()
+GROUP BY channels.name
+ORDER BY channels.name
+`
+
+type CountActiveConversationsRow struct {
+	Channel       string `json:"channel"`
+	Conversations int64  `json:"conversations"`
+}
+
+// a session is in a conversation while it waits for the answer to a question on a channel
+func (q *Queries) CountActiveConversations(ctx context.Context) ([]CountActiveConversationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countActiveConversations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountActiveConversationsRow{}
+	for rows.Next() {
+		var i CountActiveConversationsRow
+		if err := rows.Scan(&i.Channel, &i.Conversations); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
   id,
@@ -97,3 +150,47 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	)
 	return i, err
 }
+
+const updateSessionConversation = `-- name: UpdateSessionConversation :one
+UPDATE sessions
+SET
+ channel_id = $2,
+ question_id = $3,
+ response_id = $4,
+ updated_at = now()
+WHERE id = $1
+RETURNING id, user_id, refresh_token, user_agent, client_ip, is_blocked, channel_id, question_id, response_id, expires_at, created_at, updated_at
+`
+
+type UpdateSessionConversationParams struct {
+	ID         uuid.UUID `json:"id"`
+	ChannelID  int64     `json:"channel_id"`
+	QuestionID int64     `json:"question_id"`
+	ResponseID int64     `json:"response_id"`
+}
+
+// question_id is the question the session waits for the answer to, 0 once the conversation is over
+func (q *Queries) UpdateSessionConversation(ctx context.Context, arg UpdateSessionConversationParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, updateSessionConversation,
+		arg.ID,
+		arg.ChannelID,
+		arg.QuestionID,
+		arg.ResponseID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ChannelID,
+		&i.QuestionID,
+		&i.ResponseID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
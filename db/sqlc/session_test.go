@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(6),
+		ClientIp:     "127.0.0.1",
+		ChannelID:    util.DefaultID,
+		QuestionID:   util.DefaultID,
+		ResponseID:   util.DefaultID,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.UserID, session.UserID)
+	require.False(t, session.IsBlocked)
+	return session
+}
+
+func TestGetSession(t *testing.T) {
+	session1 := createRandomSession(t, createRandomUser(t))
+
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.Equal(t, session1.UserID, session2.UserID)
+	require.Equal(t, session1.RefreshToken, session2.RefreshToken)
+	require.WithinDuration(t, session1.ExpiresAt, session2.ExpiresAt, time.Second)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user)
+	session2 := createRandomSession(t, user)
+	other := createRandomSession(t, createRandomUser(t))
+
+	err := testQueries.BlockUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	for _, session := range []Session{session1, session2} {
+		blocked, err := testQueries.GetSession(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.True(t, blocked.IsBlocked)
+	}
+
+	untouched, err := testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, untouched.IsBlocked)
+}
+
+func TestUpdateSessionConversation(t *testing.T) {
+	require := require.New(t)
+	session := createRandomSession(t, createRandomUser(t))
+	channel := createRandomChannel(t)
+	question := createRandomQuestion(t)
+	response := createRandomResponse(t, question)
+
+	arg := UpdateSessionConversationParams{
+		ID:         session.ID,
+		ChannelID:  int64(channel.ID),
+		QuestionID: question.ID,
+		ResponseID: response.ID,
+	}
+	updated, err := testQueries.UpdateSessionConversation(context.Background(), arg)
+	require.NoError(err)
+	require.Equal(arg.ChannelID, updated.ChannelID)
+	require.Equal(arg.QuestionID, updated.QuestionID)
+	require.Equal(arg.ResponseID, updated.ResponseID)
+
+	// the conversation is over
+	updated, err = testQueries.UpdateSessionConversation(context.Background(), UpdateSessionConversationParams{
+		ID:        session.ID,
+		ChannelID: int64(channel.ID),
+	})
+	require.NoError(err)
+	require.Equal(int64(util.DefaultID), updated.QuestionID)
+}
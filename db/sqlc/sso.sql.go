@@ -0,0 +1,427 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: sso.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const consumeSsoLoginState = `-- name: ConsumeSsoLoginState :one
+DELETE FROM sso_login_states
+WHERE state = $1
+RETURNING state, connection_id, code_verifier, nonce, expires_at, created_at
+`
+
+func (q *Queries) ConsumeSsoLoginState(ctx context.Context, state string) (SsoLoginState, error) {
+	row := q.db.QueryRowContext(ctx, consumeSsoLoginState, state)
+	var i SsoLoginState
+	err := row.Scan(
+		&i.State,
+		&i.ConnectionID,
+		&i.CodeVerifier,
+		&i.Nonce,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countCompanySsoConnections = `-- name: CountCompanySsoConnections :one
+SELECT count(*) FROM sso_connections
+WHERE company_id = $1
+`
+
+func (q *Queries) CountCompanySsoConnections(ctx context.Context, companyID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanySsoConnections, companyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCompanySsoDomains = `-- name: CountCompanySsoDomains :one
+SELECT count(*) FROM sso_domains
+WHERE company_id = $1
+`
+
+func (q *Queries) CountCompanySsoDomains(ctx context.Context, companyID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanySsoDomains, companyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSsoConnection = `-- name: CreateSsoConnection :one
+INSERT INTO sso_connections (
+  company_id,
+  email_domain,
+  issuer,
+  client_id,
+  client_secret
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, company_id, email_domain, issuer, client_id, client_secret, created_at
+`
+
+type CreateSsoConnectionParams struct {
+	CompanyID    int64  `json:"company_id"`
+	EmailDomain  string `json:"email_domain"`
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+func (q *Queries) CreateSsoConnection(ctx context.Context, arg CreateSsoConnectionParams) (SsoConnection, error) {
+	row := q.db.QueryRowContext(ctx, createSsoConnection,
+		arg.CompanyID,
+		arg.EmailDomain,
+		arg.Issuer,
+		arg.ClientID,
+		arg.ClientSecret,
+	)
+	var i SsoConnection
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.EmailDomain,
+		&i.Issuer,
+		&i.ClientID,
+		&i.ClientSecret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSsoDomain = `-- name: CreateSsoDomain :one
+INSERT INTO sso_domains (
+  company_id,
+  domain,
+  verification_token
+) VALUES (
+  $1, $2, $3
+) RETURNING id, company_id, domain, verification_token, verified_at, created_at
+`
+
+type CreateSsoDomainParams struct {
+	CompanyID         int64  `json:"company_id"`
+	Domain            string `json:"domain"`
+	VerificationToken string `json:"verification_token"`
+}
+
+func (q *Queries) CreateSsoDomain(ctx context.Context, arg CreateSsoDomainParams) (SsoDomain, error) {
+	row := q.db.QueryRowContext(ctx, createSsoDomain, arg.CompanyID, arg.Domain, arg.VerificationToken)
+	var i SsoDomain
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Domain,
+		&i.VerificationToken,
+		&i.VerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSsoLoginState = `-- name: CreateSsoLoginState :one
+INSERT INTO sso_login_states (
+  state,
+  connection_id,
+  code_verifier,
+  nonce,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING state, connection_id, code_verifier, nonce, expires_at, created_at
+`
+
+type CreateSsoLoginStateParams struct {
+	State        string    `json:"state"`
+	ConnectionID int64     `json:"connection_id"`
+	CodeVerifier string    `json:"code_verifier"`
+	Nonce        string    `json:"nonce"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateSsoLoginState(ctx context.Context, arg CreateSsoLoginStateParams) (SsoLoginState, error) {
+	row := q.db.QueryRowContext(ctx, createSsoLoginState,
+		arg.State,
+		arg.ConnectionID,
+		arg.CodeVerifier,
+		arg.Nonce,
+		arg.ExpiresAt,
+	)
+	var i SsoLoginState
+	err := row.Scan(
+		&i.State,
+		&i.ConnectionID,
+		&i.CodeVerifier,
+		&i.Nonce,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteSsoConnection = `-- name: DeleteSsoConnection :one
+DELETE FROM sso_connections
+WHERE id = $1
+ AND company_id = $2
+RETURNING id, company_id, email_domain, issuer, client_id, client_secret, created_at
+`
+
+type DeleteSsoConnectionParams struct {
+	ID        int64 `json:"id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) DeleteSsoConnection(ctx context.Context, arg DeleteSsoConnectionParams) (SsoConnection, error) {
+	row := q.db.QueryRowContext(ctx, deleteSsoConnection, arg.ID, arg.CompanyID)
+	var i SsoConnection
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.EmailDomain,
+		&i.Issuer,
+		&i.ClientID,
+		&i.ClientSecret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteSsoDomain = `-- name: DeleteSsoDomain :one
+DELETE FROM sso_domains
+WHERE id = $1
+ AND company_id = $2
+RETURNING id, company_id, domain, verification_token, verified_at, created_at
+`
+
+type DeleteSsoDomainParams struct {
+	ID        int64 `json:"id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) DeleteSsoDomain(ctx context.Context, arg DeleteSsoDomainParams) (SsoDomain, error) {
+	row := q.db.QueryRowContext(ctx, deleteSsoDomain, arg.ID, arg.CompanyID)
+	var i SsoDomain
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Domain,
+		&i.VerificationToken,
+		&i.VerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCompanySsoDomain = `-- name: GetCompanySsoDomain :one
+SELECT id, company_id, domain, verification_token, verified_at, created_at FROM sso_domains
+WHERE id = $1
+ AND company_id = $2
+LIMIT 1
+`
+
+type GetCompanySsoDomainParams struct {
+	ID        int64 `json:"id"`
+	CompanyID int64 `json:"company_id"`
+}
+
+func (q *Queries) GetCompanySsoDomain(ctx context.Context, arg GetCompanySsoDomainParams) (SsoDomain, error) {
+	row := q.db.QueryRowContext(ctx, getCompanySsoDomain, arg.ID, arg.CompanyID)
+	var i SsoDomain
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Domain,
+		&i.VerificationToken,
+		&i.VerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSsoConnection = `-- name: GetSsoConnection :one
+SELECT id, company_id, email_domain, issuer, client_id, client_secret, created_at FROM sso_connections
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetSsoConnection(ctx context.Context, id int64) (SsoConnection, error) {
+	row := q.db.QueryRowContext(ctx, getSsoConnection, id)
+	var i SsoConnection
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.EmailDomain,
+		&i.Issuer,
+		&i.ClientID,
+		&i.ClientSecret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSsoConnectionByDomain = `-- name: GetSsoConnectionByDomain :one
+SELECT sso_connections.id, sso_connections.company_id, sso_connections.email_domain, sso_connections.issuer, sso_connections.client_id, sso_connections.client_secret, sso_connections.created_at FROM sso_connections
+JOIN sso_domains ON sso_domains.company_id = sso_connections.company_id
+ AND sso_domains.domain = sso_connections.email_domain
+WHERE sso_connections.email_domain = lower($1)
+ AND sso_domains.verified_at IS NOT NULL
+LIMIT 1
+`
+
+// a connection is only used once its company has verified the email domain
+func (q *Queries) GetSsoConnectionByDomain(ctx context.Context, emailDomain string) (SsoConnection, error) {
+	row := q.db.QueryRowContext(ctx, getSsoConnectionByDomain, emailDomain)
+	var i SsoConnection
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.EmailDomain,
+		&i.Issuer,
+		&i.ClientID,
+		&i.ClientSecret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getVerifiedSsoDomain = `-- name: GetVerifiedSsoDomain :one
+SELECT id, company_id, domain, verification_token, verified_at, created_at FROM sso_domains
+WHERE company_id = $1
+ AND domain = lower($2)
+ AND verified_at IS NOT NULL
+LIMIT 1
+`
+
+type GetVerifiedSsoDomainParams struct {
+	CompanyID int64  `json:"company_id"`
+	Domain    string `json:"domain"`
+}
+
+func (q *Queries) GetVerifiedSsoDomain(ctx context.Context, arg GetVerifiedSsoDomainParams) (SsoDomain, error) {
+	row := q.db.QueryRowContext(ctx, getVerifiedSsoDomain, arg.CompanyID, arg.Domain)
+	var i SsoDomain
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Domain,
+		&i.VerificationToken,
+		&i.VerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listCompanySsoConnections = `-- name: ListCompanySsoConnections :many
+SELECT id, company_id, email_domain, issuer, client_id, client_secret, created_at FROM sso_connections
+WHERE company_id = $1
+ AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListCompanySsoConnectionsParams struct {
+	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListCompanySsoConnections(ctx context.Context, arg ListCompanySsoConnectionsParams) ([]SsoConnection, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanySsoConnections, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SsoConnection{}
+	for rows.Next() {
+		var i SsoConnection
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.EmailDomain,
+			&i.Issuer,
+			&i.ClientID,
+			&i.ClientSecret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompanySsoDomains = `-- name: ListCompanySsoDomains :many
+SELECT id, company_id, domain, verification_token, verified_at, created_at FROM sso_domains
+WHERE company_id = $1
+ AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListCompanySsoDomainsParams struct {
+	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListCompanySsoDomains(ctx context.Context, arg ListCompanySsoDomainsParams) ([]SsoDomain, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanySsoDomains, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SsoDomain{}
+	for rows.Next() {
+		var i SsoDomain
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Domain,
+			&i.VerificationToken,
+			&i.VerifiedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const verifySsoDomain = `-- name: VerifySsoDomain :one
+UPDATE sso_domains
+SET verified_at = now()
+WHERE id = $1
+RETURNING id, company_id, domain, verification_token, verified_at, created_at
+`
+
+func (q *Queries) VerifySsoDomain(ctx context.Context, id int64) (SsoDomain, error) {
+	row := q.db.QueryRowContext(ctx, verifySsoDomain, id)
+	var i SsoDomain
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Domain,
+		&i.VerificationToken,
+		&i.VerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
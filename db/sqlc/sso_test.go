@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSsoConnection(t *testing.T, company Company) SsoConnection {
+	arg := CreateSsoConnectionParams{
+		CompanyID:    company.ID,
+		EmailDomain:  util.RandomString(10) + ".com",
+		Issuer:       "https://accounts.example.com",
+		ClientID:     util.RandomString(12),
+		ClientSecret: util.RandomString(32),
+	}
+
+	connection, err := testQueries.CreateSsoConnection(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, connection.ID)
+	require.Equal(t, arg.CompanyID, connection.CompanyID)
+	require.Equal(t, arg.EmailDomain, connection.EmailDomain)
+	require.Equal(t, arg.Issuer, connection.Issuer)
+	require.Equal(t, arg.ClientID, connection.ClientID)
+	require.Equal(t, arg.ClientSecret, connection.ClientSecret)
+	return connection
+}
+
+func TestCreateSsoConnection(t *testing.T) {
+	createRandomSsoConnection(t, createRandomCompany(t))
+}
+
+func createRandomSsoDomain(t *testing.T, company Company, domain string) SsoDomain {
+	arg := CreateSsoDomainParams{
+		CompanyID:         company.ID,
+		Domain:            domain,
+		VerificationToken: util.RandomString(32),
+	}
+
+	ssoDomain, err := testQueries.CreateSsoDomain(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, ssoDomain.ID)
+	require.Equal(t, arg.CompanyID, ssoDomain.CompanyID)
+	require.Equal(t, arg.Domain, ssoDomain.Domain)
+	require.Equal(t, arg.VerificationToken, ssoDomain.VerificationToken)
+	require.False(t, ssoDomain.VerifiedAt.Valid)
+	return ssoDomain
+}
+
+func TestGetSsoConnectionByDomain(t *testing.T) {
+	company := createRandomCompany(t)
+	connection1 := createRandomSsoConnection(t, company)
+
+	// the connection is not used before its company verified the domain
+	ssoDomain := createRandomSsoDomain(t, company, connection1.EmailDomain)
+	_, err := testQueries.GetSsoConnectionByDomain(context.Background(), connection1.EmailDomain)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.VerifySsoDomain(context.Background(), ssoDomain.ID)
+	require.NoError(t, err)
+
+	connection2, err := testQueries.GetSsoConnectionByDomain(context.Background(), strings.ToUpper(connection1.EmailDomain))
+	require.NoError(t, err)
+	require.Equal(t, connection1.ID, connection2.ID)
+}
+
+func TestVerifySsoDomain(t *testing.T) {
+	domain := util.RandomString(10) + ".com"
+	company1 := createRandomCompany(t)
+	company2 := createRandomCompany(t)
+
+	// several companies may claim a domain
+	claim1 := createRandomSsoDomain(t, company1, domain)
+	claim2 := createRandomSsoDomain(t, company2, domain)
+
+	_, err := testQueries.GetVerifiedSsoDomain(context.Background(), GetVerifiedSsoDomainParams{CompanyID: company1.ID, Domain: domain})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	verified, err := testQueries.VerifySsoDomain(context.Background(), claim1.ID)
+	require.NoError(t, err)
+	require.True(t, verified.VerifiedAt.Valid)
+
+	got, err := testQueries.GetVerifiedSsoDomain(context.Background(), GetVerifiedSsoDomainParams{CompanyID: company1.ID, Domain: strings.ToUpper(domain)})
+	require.NoError(t, err)
+	require.Equal(t, claim1.ID, got.ID)
+
+	// only one of them can verify it
+	_, err = testQueries.VerifySsoDomain(context.Background(), claim2.ID)
+	require.Error(t, err)
+	_, err = testQueries.GetVerifiedSsoDomain(context.Background(), GetVerifiedSsoDomainParams{CompanyID: company2.ID, Domain: domain})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// nor claim it twice
+	_, err = testQueries.CreateSsoDomain(context.Background(), CreateSsoDomainParams{
+		CompanyID:         company1.ID,
+		Domain:            domain,
+		VerificationToken: util.RandomString(32),
+	})
+	require.Error(t, err)
+}
+
+func TestDeleteSsoDomain(t *testing.T) {
+	company := createRandomCompany(t)
+	ssoDomain := createRandomSsoDomain(t, company, util.RandomString(10)+".com")
+
+	// a domain can only be deleted by its own company
+	_, err := testQueries.DeleteSsoDomain(context.Background(), DeleteSsoDomainParams{
+		ID:        ssoDomain.ID,
+		CompanyID: company.ID + 1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.DeleteSsoDomain(context.Background(), DeleteSsoDomainParams{
+		ID:        ssoDomain.ID,
+		CompanyID: company.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetCompanySsoDomain(context.Background(), GetCompanySsoDomainParams{
+		ID:        ssoDomain.ID,
+		CompanyID: company.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteSsoConnection(t *testing.T) {
+	connection := createRandomSsoConnection(t, createRandomCompany(t))
+
+	// a connection can only be deleted by its own company
+	_, err := testQueries.DeleteSsoConnection(context.Background(), DeleteSsoConnectionParams{
+		ID:        connection.ID,
+		CompanyID: connection.CompanyID + 1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.DeleteSsoConnection(context.Background(), DeleteSsoConnectionParams{
+		ID:        connection.ID,
+		CompanyID: connection.CompanyID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSsoConnection(context.Background(), connection.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConsumeSsoLoginState(t *testing.T) {
+	connection := createRandomSsoConnection(t, createRandomCompany(t))
+	arg := CreateSsoLoginStateParams{
+		State:        util.RandomString(32),
+		ConnectionID: connection.ID,
+		CodeVerifier: util.RandomString(43),
+		Nonce:        util.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	_, err := testQueries.CreateSsoLoginState(context.Background(), arg)
+	require.NoError(t, err)
+
+	loginState, err := testQueries.ConsumeSsoLoginState(context.Background(), arg.State)
+	require.NoError(t, err)
+	require.Equal(t, arg.CodeVerifier, loginState.CodeVerifier)
+	require.Equal(t, arg.Nonce, loginState.Nonce)
+
+	// a state can only be used once
+	_, err = testQueries.ConsumeSsoLoginState(context.Background(), arg.State)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
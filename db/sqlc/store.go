@@ -3,83 +3,466 @@ package db
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
+	"time"
 )
 
+// Different types of error returned when an invitation cannot be accepted
+var (
+	ErrInvitationUnusable      = errors.New("invitation has expired, been revoked or already been used")
+	ErrInvitationPhoneMismatch = errors.New("phone does not match the invited phone")
+	ErrInvitationPhoneRequired = errors.New("phone is required to accept this invitation")
+)
+
+// ErrLastOwner is returned when a change would leave a company without an active owner
+var ErrLastOwner = errors.New("a company must keep at least one active owner")
+
 // Provides all the fuctions to execute database queries as well as transactions
 type Store interface {
 	Querier
-	// UserCompanyTx(ctx context.Context, arg UserCompanyTxParams) (UserCompanyTxResult, error)
+	SignupTx(ctx context.Context, arg SignupTxParams) (SignupTxResult, error)
+	AcceptInvitationTx(ctx context.Context, arg AcceptInvitationTxParams) (AcceptInvitationTxResult, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams) (User, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error)
+	DisableTOTPTx(ctx context.Context, userID int64) (User, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserParams) (User, error)
+	DeactivateUserTx(ctx context.Context, userID int64) (User, error)
+	DeleteUserTx(ctx context.Context, userID int64) error
+	DeleteBotTx(ctx context.Context, arg SoftDeleteBotParams) (Bot, error)
+	RestoreBotTx(ctx context.Context, botID int64) (Bot, error)
+	DeleteCompanyTx(ctx context.Context, arg SoftDeleteCompanyParams) (Company, error)
+	RestoreCompanyTx(ctx context.Context, companyID int64) (Company, error)
+	ExecTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
 }
 
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db      *sql.DB
+	observe QueryObserver
 }
 
 func NewSQLStore(db *sql.DB) Store {
 	return &SQLStore{
 		db:      db,
-		Queries: New(db),
+		Queries: New(contextDBTX{db: db}),
+	}
+}
+
+// NewObservedSQLStore is NewSQLStore reporting every query it runs to observe,
+// those of transactions included
+func NewObservedSQLStore(db *sql.DB, observe QueryObserver) Store {
+	return &SQLStore{
+		db:      db,
+		Queries: New(observedDBTX{db: contextDBTX{db: db}, observe: observe}),
+		observe: observe,
+	}
+}
+
+// Ping checks that the database can be reached
+func (dbStore *SQLStore) Ping(ctx context.Context) error {
+	return dbStore.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version of the last migration applied to the database,
+// zero when there is none. The database is dirty when that migration failed halfway.
+func (dbStore *SQLStore) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	row := dbStore.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	err = row.Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
 	}
+	return
 }
 
-// execTx executes a function within a database transaction
+// execTx executes a function within a database transaction, the one of ExecTx
+// when ctx carries it
 func (dbStore *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := dbStore.db.BeginTx(ctx, nil)
+	return dbStore.ExecTx(ctx, func(ctx context.Context) error {
+		tx, _ := txFromContext(ctx)
+		if dbStore.observe != nil {
+			return fn(New(observedDBTX{db: tx, observe: dbStore.observe}))
+		}
+		return fn(New(tx))
+	})
+}
+
+// SignupTxParams contains the input parameters of the signup transaction
+type SignupTxParams struct {
+	Company CreateCompanyParams `json:"company"`
+	Owner   CreateUserParams    `json:"owner"`
+}
+
+// SignupTxResult is the result of the signup transaction
+type SignupTxResult struct {
+	Company Company `json:"company"`
+	Owner   User    `json:"owner"`
+}
+
+// SignupTx creates a company together with the user that owns it
+func (dbStore *SQLStore) SignupTx(ctx context.Context, arg SignupTxParams) (SignupTxResult, error) {
+	var result SignupTxResult
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Company, err = q.CreateCompany(ctx, arg.Company)
+		if err != nil {
+			return err
+		}
+
+		owner := arg.Owner
+		owner.CompanyID = result.Company.ID
+		result.Owner, err = q.CreateUser(ctx, owner)
+		return err
+	})
+
+	return result, err
+}
+
+// AcceptInvitationTxParams contains the input parameters of the accept invitation transaction
+type AcceptInvitationTxParams struct {
+	TokenHash    string `json:"token_hash"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// AcceptInvitationTxResult is the result of the accept invitation transaction
+type AcceptInvitationTxResult struct {
+	Invitation Invitation `json:"invitation"`
+	User       User       `json:"user"`
+}
+
+// AcceptInvitationTx creates the invited user and marks the invitation as used.
+// The invitation row is locked so that a token can only ever be redeemed once.
+func (dbStore *SQLStore) AcceptInvitationTx(ctx context.Context, arg AcceptInvitationTxParams) (AcceptInvitationTxResult, error) {
+	var result AcceptInvitationTxResult
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		invitation, err := q.GetInvitationByTokenHashForUpdate(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		if invitation.AcceptedAt.Valid || invitation.RevokedAt.Valid || time.Now().After(invitation.ExpiresAt) {
+			return ErrInvitationUnusable
+		}
+
+		phone := arg.Phone
+		if invitation.Phone.Valid {
+			if phone != "" && phone != invitation.Phone.String {
+				return ErrInvitationPhoneMismatch
+			}
+			phone = invitation.Phone.String
+		}
+		if phone == "" {
+			return ErrInvitationPhoneRequired
+		}
+
+		result.User, err = q.CreateUser(ctx, CreateUserParams{
+			Name:         arg.Name,
+			PasswordHash: arg.PasswordHash,
+			Phone:        phone,
+			CompanyID:    invitation.CompanyID,
+			Role:         invitation.Role,
+			Email:        invitation.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Invitation, err = q.AcceptInvitation(ctx, AcceptInvitationParams{
+			AcceptedBy: sql.NullInt64{Int64: result.User.ID, Valid: true},
+			ID:         invitation.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ChangePasswordTxParams contains the input parameters of the change password transaction
+type ChangePasswordTxParams struct {
+	UserID       int64  `json:"user_id"`
+	PasswordHash string `json:"password_hash"`
+	// OtpCodeID is the code that authorised the change, if any. It is consumed in the same transaction.
+	OtpCodeID int64 `json:"otp_code_id"`
+}
+
+// ChangePasswordTx stores a new password hash and blocks every existing session of the user
+func (dbStore *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.OtpCodeID != 0 {
+			_, err = q.ConsumeOtpCode(ctx, arg.OtpCodeID)
+			if err != nil {
+				return err
+			}
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			PasswordHash: arg.PasswordHash,
+			ID:           arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, arg.UserID)
+	})
+
+	return user, err
+}
+
+// VerifyPhoneTxParams contains the input parameters of the verify phone transaction
+type VerifyPhoneTxParams struct {
+	UserID    int64 `json:"user_id"`
+	OtpCodeID int64 `json:"otp_code_id"`
+}
+
+// VerifyPhoneTx consumes the verification code and marks the phone of the user as verified
+func (dbStore *SQLStore) VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		_, err := q.ConsumeOtpCode(ctx, arg.OtpCodeID)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkUserVerified(ctx, arg.UserID)
+		return err
+	})
+
+	return user, err
+}
+
+// EnableTOTPTxParams contains the input parameters of the enable totp transaction
+type EnableTOTPTxParams struct {
+	UserID int64 `json:"user_id"`
+	// Counter is the period of the code that confirmed the enrollment
+	Counter            int64    `json:"counter"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+// EnableTOTPTx turns two factor authentication on for the user and replaces the recovery codes.
+// It fails with sql.ErrNoRows unless the user has a pending, not yet enabled, secret.
+func (dbStore *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.EnableUserTotp(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UseUserTotpCounter(ctx, UseUserTotpCounterParams{
+			Counter: arg.Counter,
+			ID:      arg.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		user.TotpLastCounter = arg.Counter
+
+		err = q.DeleteUserRecoveryCodes(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				UserID:   arg.UserID,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return user, err
+}
+
+// DisableTOTPTx turns two factor authentication off and deletes the recovery codes of the user
+func (dbStore *SQLStore) DisableTOTPTx(ctx context.Context, userID int64) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.DisableUserTotp(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserRecoveryCodes(ctx, userID)
+	})
+
+	return user, err
+}
+
+// ensureNotLastOwner fails with ErrLastOwner when the user is the only active owner of their company.
+// The owners stay locked until the transaction ends so that two owners cannot remove each other at once.
+func ensureNotLastOwner(ctx context.Context, q *Queries, userID int64) error {
+	user, err := q.GetUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
-	q := New(tx)
-	err = fn(q)
+
+	owners, err := q.LockCompanyOwners(ctx, user.CompanyID)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
-		}
 		return err
 	}
-	return tx.Commit()
-}
-
-// UserCompanyParams contains the input parameters of the company user transaction
-// type UserCompanyTxParams struct {
-// 	UserID       int64  `json:"user_id"`
-// 	CompanyPhone string `json:"phone"`
-// 	CompanyName  string `json:"name"`
-// 	CompanyEmail string `json:"email"`
-// }
-
-// // UserCompanyTxResult is the result of the  user company transaction
-// type UserCompanyTxResult struct {
-// 	Company     Company     `json:"company"`
-// 	UserCompany UserCompany `json:"user_company"`
-// }
-
-// // UserCompanyTx creates a company and adds user to company user
-// func (store *SQLStore) UserCompanyTx(ctx context.Context, arg UserCompanyTxParams) (UserCompanyTxResult, error) {
-// 	var result UserCompanyTxResult
-
-// 	err := store.execTx(ctx, func(q *Queries) error {
-// 		var err error
-
-// 		result.Company, err = q.CreateCompany(ctx, CreateCompanyParams{
-// 			Phone: arg.CompanyPhone,
-// 			Name:  arg.CompanyName,
-// 			Email: arg.CompanyEmail,
-// 		})
-// 		if err != nil {
-// 			return err
-// 		}
-
-// 		result.UserCompany, err = q.CreateUserCompany(ctx, CreateUserCompanyParams{
-// 			CompanyID: result.Company.ID,
-// 			UserID:    arg.UserID,
-// 		})
-// 		if err != nil {
-// 			return err
-// 		}
-// 		return err
-// 	})
-
-// 	return result, err
-// }
+
+	if len(owners) == 1 && owners[0] == userID {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// UpdateUserTx updates the user, refusing to demote the last active owner of the company
+func (dbStore *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.Role.Valid && arg.Role.String != "owner" {
+			err = ensureNotLastOwner(ctx, q, arg.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		user, err = q.UpdateUser(ctx, arg)
+		return err
+	})
+
+	return user, err
+}
+
+// DeactivateUserTx stops the user from logging in and blocks their sessions.
+// The last active owner of a company cannot be deactivated.
+func (dbStore *SQLStore) DeactivateUserTx(ctx context.Context, userID int64) (User, error) {
+	var user User
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		err := ensureNotLastOwner(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.DeactivateUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, userID)
+	})
+
+	return user, err
+}
+
+// DeleteUserTx deletes the user unless they are the last active owner of their company
+func (dbStore *SQLStore) DeleteUserTx(ctx context.Context, userID int64) error {
+	return dbStore.execTx(ctx, func(q *Queries) error {
+		err := ensureNotLastOwner(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUser(ctx, userID)
+	})
+}
+
+// DeleteBotTx soft deletes the bot together with its questions, only the version of the bot
+// updated at arg.UpdatedAt when it is valid.
+// They keep the same deleted_at so that RestoreBotTx can tell them apart from the questions deleted earlier.
+func (dbStore *SQLStore) DeleteBotTx(ctx context.Context, arg SoftDeleteBotParams) (Bot, error) {
+	var bot Bot
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+		bot, err = q.SoftDeleteBot(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.SoftDeleteBotQuestions(ctx, arg.ID)
+	})
+
+	return bot, err
+}
+
+// RestoreBotTx restores a soft deleted bot and the questions deleted with it
+func (dbStore *SQLStore) RestoreBotTx(ctx context.Context, botID int64) (Bot, error) {
+	var bot Bot
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		err := q.RestoreBotQuestions(ctx, botID)
+		if err != nil {
+			return err
+		}
+
+		bot, err = q.RestoreBot(ctx, botID)
+		return err
+	})
+
+	return bot, err
+}
+
+// DeleteCompanyTx soft deletes the company together with its bots and their questions, only
+// the version of the company updated at arg.UpdatedAt when it is valid.
+// The users of the company cannot log in until it is restored.
+func (dbStore *SQLStore) DeleteCompanyTx(ctx context.Context, arg SoftDeleteCompanyParams) (Company, error) {
+	var company Company
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		var err error
+		company, err = q.SoftDeleteCompany(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = q.SoftDeleteCompanyQuestions(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.SoftDeleteCompanyBots(ctx, arg.ID)
+	})
+
+	return company, err
+}
+
+// RestoreCompanyTx restores a soft deleted company and the bots and questions deleted with it
+func (dbStore *SQLStore) RestoreCompanyTx(ctx context.Context, companyID int64) (Company, error) {
+	var company Company
+
+	err := dbStore.execTx(ctx, func(q *Queries) error {
+		err := q.RestoreCompanyQuestions(ctx, companyID)
+		if err != nil {
+			return err
+		}
+
+		err = q.RestoreCompanyBots(ctx, companyID)
+		if err != nil {
+			return err
+		}
+
+		company, err = q.RestoreCompany(ctx, companyID)
+		return err
+	})
+
+	return company, err
+}
@@ -1,49 +1,376 @@
 package db
 
-// import (
-// 	"context"
-// 	"testing"
-
-// 	"github.com/lenimbugua/bot/util"
-// 	"github.com/stretchr/testify/require"
-// )
-
-// func TestUserCompanyTx(t *testing.T) {
-// 	store := NewSQLStore(testDB)
-
-// 	user := createRandomUser(t)
-
-// 	//run n concurrent company user transactions
-// 	n := 5
-// 	errs := make(chan error)
-// 	results := make(chan UserCompanyTxResult)
-
-// 	for i := 0; i < n; i++ {
-// 		go func() {
-// 			result, err := store.UserCompanyTx(context.Background(), UserCompanyTxParams{
-// 				UserID:       user.ID,
-// 				CompanyPhone: util.RandomPhoneNumber(),
-// 				CompanyName:  util.RandomString(6),
-// 				CompanyEmail: util.RandomEmail(),
-// 			})
-
-// 			errs <- err
-// 			results <- result
-// 		}()
-// 	}
-
-// 	for i := 0; i < n; i++ {
-// 		require := require.New(t)
-// 		err := <-errs
-// 		require.NoError(err)
-
-// 		result := <-results
-// 		require.NotEmpty(result)
-
-// 		userCompany := result.UserCompany
-// 		company := result.Company
-
-// 		require.Equal(userCompany.UserID, user.ID)
-// 		require.Equal(userCompany.CompanyID, company.ID)
-// 	}
-// }
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignupTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(err)
+
+	arg := SignupTxParams{
+		Company: CreateCompanyParams{
+			Phone: util.RandomPhoneNumber(),
+			Name:  util.RandomString(6),
+			Email: util.RandomEmail(),
+		},
+		Owner: CreateUserParams{
+			Name:         util.RandomString(6),
+			PasswordHash: hashedPassword,
+			Phone:        util.RandomPhoneNumber(),
+			Role:         util.OwnerRole,
+		},
+	}
+
+	result, err := store.SignupTx(context.Background(), arg)
+	require.NoError(err)
+	require.NotZero(result.Company.ID)
+	require.Equal(arg.Company.Email, result.Company.Email)
+	require.Equal(result.Company.ID, result.Owner.CompanyID)
+	require.Equal(arg.Owner.Phone, result.Owner.Phone)
+	require.Equal(util.OwnerRole, result.Owner.Role)
+}
+
+func TestSignupTxDuplicatePhoneRollsBack(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+
+	arg := SignupTxParams{
+		Company: CreateCompanyParams{
+			Phone: util.RandomPhoneNumber(),
+			Name:  util.RandomString(6),
+			Email: util.RandomEmail(),
+		},
+		Owner: CreateUserParams{
+			Name:         util.RandomString(6),
+			PasswordHash: user.PasswordHash,
+			Phone:        user.Phone,
+			Role:         util.OwnerRole,
+		},
+	}
+
+	_, err := store.SignupTx(context.Background(), arg)
+	require.Error(err)
+
+	_, err = testQueries.GetCompanyByEmail(context.Background(), arg.Company.Email)
+	require.Error(err)
+}
+
+func TestAcceptInvitationTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	inviter := createRandomUser(t)
+	invitation, token := createRandomInvitation(t, inviter)
+
+	arg := AcceptInvitationTxParams{
+		TokenHash:    util.HashSecret(token),
+		Name:         util.RandomString(6),
+		PasswordHash: inviter.PasswordHash,
+	}
+
+	// run n concurrent accepts, only one of them may succeed
+	n := 5
+	errs := make(chan error)
+	results := make(chan AcceptInvitationTxResult)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.AcceptInvitationTx(context.Background(), arg)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	accepted := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		result := <-results
+		if err != nil {
+			require.ErrorIs(err, ErrInvitationUnusable)
+			continue
+		}
+		accepted++
+		require.Equal(invitation.Phone.String, result.User.Phone)
+		require.Equal(invitation.CompanyID, result.User.CompanyID)
+		require.Equal(invitation.Role, result.User.Role)
+		require.True(result.Invitation.AcceptedAt.Valid)
+		require.Equal(result.User.ID, result.Invitation.AcceptedBy.Int64)
+	}
+	require.Equal(1, accepted)
+}
+
+func TestAcceptInvitationTxPhoneMismatch(t *testing.T) {
+	store := NewSQLStore(testDB)
+	inviter := createRandomUser(t)
+	_, token := createRandomInvitation(t, inviter)
+
+	_, err := store.AcceptInvitationTx(context.Background(), AcceptInvitationTxParams{
+		TokenHash:    util.HashSecret(token),
+		Name:         util.RandomString(6),
+		Phone:        util.RandomPhoneNumber(),
+		PasswordHash: inviter.PasswordHash,
+	})
+	require.ErrorIs(t, err, ErrInvitationPhoneMismatch)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+	otpCode := createRandomOtpCode(t, user, "password_reset")
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(err)
+
+	arg := ChangePasswordTxParams{
+		UserID:       user.ID,
+		PasswordHash: hashedPassword,
+		OtpCodeID:    otpCode.ID,
+	}
+	updated, err := store.ChangePasswordTx(context.Background(), arg)
+	require.NoError(err)
+	require.Equal(hashedPassword, updated.PasswordHash)
+	require.True(updated.PasswordChangedAt.After(user.PasswordChangedAt))
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(err)
+	require.True(blocked.IsBlocked)
+
+	// the code was consumed so it cannot authorise a second change
+	_, err = store.ChangePasswordTx(context.Background(), arg)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestVerifyPhoneTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	require.False(user.VerifiedAt.Valid)
+	otpCode := createRandomOtpCode(t, user, "phone_verification")
+
+	arg := VerifyPhoneTxParams{
+		UserID:    user.ID,
+		OtpCodeID: otpCode.ID,
+	}
+	verified, err := store.VerifyPhoneTx(context.Background(), arg)
+	require.NoError(err)
+	require.True(verified.VerifiedAt.Valid)
+	require.WithinDuration(time.Now(), verified.VerifiedAt.Time, time.Second)
+
+	// the code was consumed so it cannot be used a second time
+	_, err = store.VerifyPhoneTx(context.Background(), arg)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestEnableTOTPTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	old := createRandomRecoveryCode(t, user)
+
+	_, err := testQueries.SetUserTotpSecret(context.Background(), SetUserTotpSecretParams{
+		TotpSecret: sql.NullString{String: util.RandomString(32), Valid: true},
+		ID:         user.ID,
+	})
+	require.NoError(err)
+
+	arg := EnableTOTPTxParams{
+		UserID:             user.ID,
+		Counter:            100,
+		RecoveryCodeHashes: []string{util.HashSecret(util.RandomString(10)), util.HashSecret(util.RandomString(10))},
+	}
+	enabled, err := store.EnableTOTPTx(context.Background(), arg)
+	require.NoError(err)
+	require.True(enabled.TotpEnabledAt.Valid)
+	require.Equal(int64(100), enabled.TotpLastCounter)
+
+	// codes from an earlier enrollment are replaced
+	count, err := testQueries.CountUnusedRecoveryCodes(context.Background(), user.ID)
+	require.NoError(err)
+	require.Equal(int64(len(arg.RecoveryCodeHashes)), count)
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{UserID: user.ID, CodeHash: old.CodeHash})
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	_, err = store.EnableTOTPTx(context.Background(), arg)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	disabled, err := store.DisableTOTPTx(context.Background(), user.ID)
+	require.NoError(err)
+	require.False(disabled.TotpEnabledAt.Valid)
+
+	count, err = testQueries.CountUnusedRecoveryCodes(context.Background(), user.ID)
+	require.NoError(err)
+	require.Zero(count)
+}
+
+func TestLastOwnerProtected(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	company := createRandomCompany(t)
+	owner := createRandomCompanyUser(t, company.ID, util.OwnerRole)
+	member := createRandomCompanyUser(t, company.ID, util.MemberRole)
+
+	demote := UpdateUserParams{
+		Role: sql.NullString{String: util.AdminRole, Valid: true},
+		ID:   owner.ID,
+	}
+	_, err := store.UpdateUserTx(context.Background(), demote)
+	require.ErrorIs(err, ErrLastOwner)
+	_, err = store.DeactivateUserTx(context.Background(), owner.ID)
+	require.ErrorIs(err, ErrLastOwner)
+	err = store.DeleteUserTx(context.Background(), owner.ID)
+	require.ErrorIs(err, ErrLastOwner)
+
+	// renaming the last owner is fine
+	_, err = store.UpdateUserTx(context.Background(), UpdateUserParams{
+		Name: sql.NullString{String: util.RandomString(6), Valid: true},
+		ID:   owner.ID,
+	})
+	require.NoError(err)
+
+	// once there is a second owner the first one can step down
+	_, err = store.UpdateUserTx(context.Background(), UpdateUserParams{
+		Role: sql.NullString{String: util.OwnerRole, Valid: true},
+		ID:   member.ID,
+	})
+	require.NoError(err)
+
+	demoted, err := store.UpdateUserTx(context.Background(), demote)
+	require.NoError(err)
+	require.Equal(util.AdminRole, demoted.Role)
+
+	// the new owner is now the last one
+	_, err = store.DeactivateUserTx(context.Background(), member.ID)
+	require.ErrorIs(err, ErrLastOwner)
+}
+
+func TestDeactivateUserTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	deactivated, err := store.DeactivateUserTx(context.Background(), user.ID)
+	require.NoError(err)
+	require.True(deactivated.DeactivatedAt.Valid)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(err)
+	require.True(blocked.IsBlocked)
+
+	err = store.DeleteUserTx(context.Background(), user.ID)
+	require.NoError(err)
+	_, err = testQueries.GetUserByID(context.Background(), user.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestDeleteBotTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	question := createRandomQuestion(t)
+	other, err := testQueries.CreateQuestion(context.Background(), CreateQuestionParams{
+		Question: util.RandomString(6),
+		Type:     util.RandomString(6),
+		BotID:    question.BotID,
+	})
+	require.NoError(err)
+
+	// a question deleted before the bot stays deleted when the bot is restored
+	_, err = testQueries.SoftDeleteQuestion(context.Background(), SoftDeleteQuestionParams{ID: other.ID})
+	require.NoError(err)
+
+	deleted, err := store.DeleteBotTx(context.Background(), SoftDeleteBotParams{ID: question.BotID})
+	require.NoError(err)
+	require.True(deleted.DeletedAt.Valid)
+	_, err = testQueries.GetQuestion(context.Background(), question.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	restored, err := store.RestoreBotTx(context.Background(), question.BotID)
+	require.NoError(err)
+	require.False(restored.DeletedAt.Valid)
+	_, err = testQueries.GetQuestion(context.Background(), question.ID)
+	require.NoError(err)
+	_, err = testQueries.GetQuestion(context.Background(), other.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	_, err = store.RestoreBotTx(context.Background(), question.BotID)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
+
+func TestDeleteCompanyTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	bot := createCompanyBot(t, user.CompanyID)
+	deletedBot := createCompanyBot(t, user.CompanyID)
+	apiKey := createRandomApiKey(t, user)
+	_, err := store.DeleteBotTx(context.Background(), SoftDeleteBotParams{ID: deletedBot.ID})
+	require.NoError(err)
+
+	_, err = store.DeleteCompanyTx(context.Background(), SoftDeleteCompanyParams{ID: user.CompanyID})
+	require.NoError(err)
+	_, err = testQueries.GetCompanyByID(context.Background(), user.CompanyID)
+	require.ErrorIs(err, sql.ErrNoRows)
+	_, err = testQueries.GetBot(context.Background(), bot.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	// the users of a deleted company cannot log in
+	_, err = testQueries.GetUser(context.Background(), user.Phone)
+	require.ErrorIs(err, sql.ErrNoRows)
+	_, err = testQueries.GetUserByID(context.Background(), user.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+	// nor can its API keys be used
+	_, err = testQueries.GetApiKeyByPrefix(context.Background(), apiKey.Prefix)
+	require.ErrorIs(err, sql.ErrNoRows)
+
+	restored, err := store.RestoreCompanyTx(context.Background(), user.CompanyID)
+	require.NoError(err)
+	require.Equal(user.CompanyID, restored.ID)
+	_, err = testQueries.GetBot(context.Background(), bot.ID)
+	require.NoError(err)
+	_, err = testQueries.GetBot(context.Background(), deletedBot.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+	_, err = testQueries.GetUserByID(context.Background(), user.ID)
+	require.NoError(err)
+	_, err = testQueries.GetApiKeyByPrefix(context.Background(), apiKey.Prefix)
+	require.NoError(err)
+}
+
+func TestExecTx(t *testing.T) {
+	require := require.New(t)
+	store := NewSQLStore(testDB)
+	user := createRandomUser(t)
+	bot := createCompanyBot(t, user.CompanyID)
+	errAbort := errors.New("abort")
+
+	// the queries and transactions run with the context of the transaction roll back with it
+	err := store.ExecTx(context.Background(), func(ctx context.Context) error {
+		_, err := store.DeleteBotTx(ctx, SoftDeleteBotParams{ID: bot.ID})
+		require.NoError(err)
+		_, err = store.GetBot(ctx, bot.ID)
+		require.ErrorIs(err, sql.ErrNoRows)
+		return errAbort
+	})
+	require.ErrorIs(err, errAbort)
+	_, err = store.GetBot(context.Background(), bot.ID)
+	require.NoError(err)
+
+	err = store.ExecTx(context.Background(), func(ctx context.Context) error {
+		_, err := store.DeleteBotTx(ctx, SoftDeleteBotParams{ID: bot.ID})
+		return err
+	})
+	require.NoError(err)
+	_, err = store.GetBot(context.Background(), bot.ID)
+	require.ErrorIs(err, sql.ErrNoRows)
+}
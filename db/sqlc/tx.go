@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txKey carries the transaction of ExecTx in a context
+type txKey struct{}
+
+// txFromContext returns the transaction of ExecTx the context carries, if any
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// contextDBTX runs the queries in the transaction of the context they are run with,
+// and on db when the context carries none
+type contextDBTX struct {
+	db *sql.DB
+}
+
+func (c contextDBTX) conn(ctx context.Context) DBTX {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return c.db
+}
+
+func (c contextDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.conn(ctx).ExecContext(ctx, query, args...)
+}
+
+func (c contextDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.conn(ctx).PrepareContext(ctx, query)
+}
+
+func (c contextDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn(ctx).QueryContext(ctx, query, args...)
+}
+
+func (c contextDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.conn(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// ExecTx runs fn in a transaction, committed when fn returns nil and rolled back otherwise.
+// The calls fn makes to the store with the context it is given join the transaction, the
+// transactions of the store included, so that several of them commit or fail together.
+// Called with such a context, ExecTx joins the transaction already running.
+func (dbStore *SQLStore) ExecTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := dbStore.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -7,24 +7,41 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countCompanyUsers = `-- name: CountCompanyUsers :one
+SELECT count(*) FROM users
+WHERE company_id = $1
+`
+
+func (q *Queries) CountCompanyUsers(ctx context.Context, companyID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCompanyUsers, companyID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   name,
   password_hash,
   phone,
-  company_id
+  company_id,
+  role,
+  email
 ) VALUES (
-  $1, $2, $3, $4
-) RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
 `
 
 type CreateUserParams struct {
-	Name         string `json:"name"`
-	PasswordHash string `json:"password_hash"`
-	Phone        string `json:"phone"`
-	CompanyID    int64  `json:"company_id"`
+	Name         string         `json:"name"`
+	PasswordHash string         `json:"password_hash"`
+	Phone        string         `json:"phone"`
+	CompanyID    int64          `json:"company_id"`
+	Role         string         `json:"role"`
+	Email        sql.NullString `json:"email"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -33,7 +50,119 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.PasswordHash,
 		arg.Phone,
 		arg.CompanyID,
+		arg.Role,
+		arg.Email,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :one
+UPDATE users
+SET
+ deactivated_at = coalesce(deactivated_at, now()),
+ updated_at = now()
+WHERE id = $1
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, deactivateUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const disableUserTotp = `-- name: DisableUserTotp :one
+UPDATE users
+SET
+ totp_secret = NULL,
+ totp_enabled_at = NULL,
+ totp_last_counter = 0,
+ updated_at = now()
+WHERE id = $1
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+func (q *Queries) DisableUserTotp(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, disableUserTotp, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
 	)
+	return i, err
+}
+
+const enableUserTotp = `-- name: EnableUserTotp :one
+UPDATE users
+SET
+ totp_enabled_at = now(),
+ updated_at = now()
+WHERE id = $1
+ AND totp_secret IS NOT NULL
+ AND totp_enabled_at IS NULL
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+func (q *Queries) EnableUserTotp(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTotp, id)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -44,15 +173,24 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at FROM users
-WHERE phone = $1 LIMIT 1
+SELECT users.id, users.phone, users.company_id, users.password_hash, users.password_changed_at, users.name, users.created_at, users.updated_at, users.role, users.verified_at, users.email, users.totp_secret, users.totp_enabled_at, users.totp_last_counter, users.deactivated_at FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE users.phone = $1 LIMIT 1
 `
 
+// the users of a deleted company are gone with it
 func (q *Queries) GetUser(ctx context.Context, phone string) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUser, phone)
 	var i User
@@ -65,6 +203,364 @@ func (q *Queries) GetUser(ctx context.Context, phone string) (User, error) {
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
 	)
 	return i, err
 }
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT users.id, users.phone, users.company_id, users.password_hash, users.password_changed_at, users.name, users.created_at, users.updated_at, users.role, users.verified_at, users.email, users.totp_secret, users.totp_enabled_at, users.totp_last_counter, users.deactivated_at FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE lower(users.email) = lower($1) LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT users.id, users.phone, users.company_id, users.password_hash, users.password_changed_at, users.name, users.created_at, users.updated_at, users.role, users.verified_at, users.email, users.totp_secret, users.totp_enabled_at, users.totp_last_counter, users.deactivated_at FROM users
+JOIN companies ON companies.id = users.company_id AND companies.deleted_at IS NULL
+WHERE users.id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const listCompanyUsers = `-- name: ListCompanyUsers :many
+SELECT id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at FROM users
+WHERE company_id = $1
+ AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListCompanyUsersParams struct {
+	CompanyID int64 `json:"company_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListCompanyUsers(ctx context.Context, arg ListCompanyUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listCompanyUsers, arg.CompanyID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Phone,
+			&i.CompanyID,
+			&i.PasswordHash,
+			&i.PasswordChangedAt,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+			&i.VerifiedAt,
+			&i.Email,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastCounter,
+			&i.DeactivatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockCompanyOwners = `-- name: LockCompanyOwners :many
+SELECT id FROM users
+WHERE company_id = $1
+ AND role = 'owner'
+ AND deactivated_at IS NULL
+ORDER BY id
+FOR UPDATE
+`
+
+func (q *Queries) LockCompanyOwners(ctx context.Context, companyID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, lockCompanyOwners, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markUserVerified = `-- name: MarkUserVerified :one
+UPDATE users
+SET
+ verified_at = coalesce(verified_at, now()),
+ updated_at = now()
+WHERE id = $1
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+func (q *Queries) MarkUserVerified(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, markUserVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const reactivateUser = `-- name: ReactivateUser :one
+UPDATE users
+SET
+ deactivated_at = NULL,
+ updated_at = now()
+WHERE id = $1
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, reactivateUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const setUserTotpSecret = `-- name: SetUserTotpSecret :one
+UPDATE users
+SET
+ totp_secret = $1,
+ updated_at = now()
+WHERE id = $2
+ AND totp_enabled_at IS NULL
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+type SetUserTotpSecretParams struct {
+	TotpSecret sql.NullString `json:"totp_secret"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) SetUserTotpSecret(ctx context.Context, arg SetUserTotpSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserTotpSecret, arg.TotpSecret, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users
+SET
+ name = coalesce($1, name),
+ phone = coalesce($2, phone),
+ role = coalesce($3, role),
+ -- a new phone has to be verified again
+ verified_at = CASE WHEN $2::varchar <> phone THEN NULL ELSE verified_at END,
+ updated_at = now()
+WHERE id = $4
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+type UpdateUserParams struct {
+	Name  sql.NullString `json:"name"`
+	Phone sql.NullString `json:"phone"`
+	Role  sql.NullString `json:"role"`
+	ID    int64          `json:"id"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Name,
+		arg.Phone,
+		arg.Role,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET
+ password_hash = $1,
+ password_changed_at = now(),
+ updated_at = now()
+WHERE id = $2
+RETURNING id, phone, company_id, password_hash, password_changed_at, name, created_at, updated_at, role, verified_at, email, totp_secret, totp_enabled_at, totp_last_counter, deactivated_at
+`
+
+type UpdateUserPasswordParams struct {
+	PasswordHash string `json:"password_hash"`
+	ID           int64  `json:"id"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.PasswordHash, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.CompanyID,
+		&i.PasswordHash,
+		&i.PasswordChangedAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.VerifiedAt,
+		&i.Email,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const useUserTotpCounter = `-- name: UseUserTotpCounter :execrows
+UPDATE users
+SET totp_last_counter = $1
+WHERE id = $2
+ AND totp_last_counter < $1
+`
+
+type UseUserTotpCounterParams struct {
+	Counter int64 `json:"counter"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) UseUserTotpCounter(ctx context.Context, arg UseUserTotpCounterParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useUserTotpCounter, arg.Counter, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,6 +20,8 @@ func createRandomUser(t *testing.T) User {
 		PasswordHash: hashedPassword,
 		Phone:        util.RandomPhoneNumber(),
 		CompanyID:    company.ID,
+		Role:         util.MemberRole,
+		Email:        sql.NullString{String: util.RandomEmail(), Valid: true},
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), arg)
@@ -28,6 +32,8 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.PasswordHash, user.PasswordHash)
 	require.Equal(t, arg.Phone, user.Phone)
 	require.Equal(t, arg.CompanyID, user.CompanyID)
+	require.Equal(t, arg.Role, user.Role)
+	require.Equal(t, arg.Email, user.Email)
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.UpdatedAt)
@@ -35,6 +41,14 @@ func createRandomUser(t *testing.T) User {
 	return user
 }
 
+func TestGetUserByEmail(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.GetUserByEmail(context.Background(), strings.ToUpper(user1.Email.String))
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+}
+
 func TestCreateUser(t *testing.T) {
 	createRandomUser(t)
 }
@@ -52,3 +66,156 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 	require.WithinDuration(t, user1.UpdatedAt, user2.UpdatedAt, time.Second)
 }
+
+func TestGetUserByID(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2, err := testQueries.GetUserByID(context.Background(), user1.ID)
+	require.NoError(t, err)
+	require.Equal(t, user1.Phone, user2.Phone)
+	require.Equal(t, user1.CompanyID, user2.CompanyID)
+	require.Equal(t, user1.Role, user2.Role)
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	updated, err := testQueries.UpdateUserPassword(context.Background(), UpdateUserPasswordParams{
+		PasswordHash: hashedPassword,
+		ID:           user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updated.PasswordHash)
+	require.WithinDuration(t, time.Now(), updated.PasswordChangedAt, time.Second)
+}
+
+func TestUserTotp(t *testing.T) {
+	user := createRandomUser(t)
+	secret := sql.NullString{String: util.RandomString(32), Valid: true}
+
+	enrolled, err := testQueries.SetUserTotpSecret(context.Background(), SetUserTotpSecretParams{
+		TotpSecret: secret,
+		ID:         user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret, enrolled.TotpSecret)
+	require.False(t, enrolled.TotpEnabledAt.Valid)
+
+	enabled, err := testQueries.EnableUserTotp(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, enabled.TotpEnabledAt.Valid)
+
+	// the secret cannot be swapped while two factor authentication is on
+	_, err = testQueries.SetUserTotpSecret(context.Background(), SetUserTotpSecretParams{
+		TotpSecret: sql.NullString{String: util.RandomString(32), Valid: true},
+		ID:         user.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	rows, err := testQueries.UseUserTotpCounter(context.Background(), UseUserTotpCounterParams{Counter: 100, ID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// a counter already used, or an older one, is a replay
+	rows, err = testQueries.UseUserTotpCounter(context.Background(), UseUserTotpCounterParams{Counter: 100, ID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+	rows, err = testQueries.UseUserTotpCounter(context.Background(), UseUserTotpCounterParams{Counter: 99, ID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	disabled, err := testQueries.DisableUserTotp(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.False(t, disabled.TotpSecret.Valid)
+	require.False(t, disabled.TotpEnabledAt.Valid)
+	require.Zero(t, disabled.TotpLastCounter)
+}
+
+func createRandomCompanyUser(t *testing.T, companyID int64, role string) User {
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	user, err := testQueries.CreateUser(context.Background(), CreateUserParams{
+		Name:         util.RandomString(6),
+		PasswordHash: hashedPassword,
+		Phone:        util.RandomPhoneNumber(),
+		CompanyID:    companyID,
+		Role:         role,
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func TestListCompanyUsers(t *testing.T) {
+	company := createRandomCompany(t)
+	for i := 0; i < 3; i++ {
+		createRandomCompanyUser(t, company.ID, util.MemberRole)
+	}
+	createRandomUser(t)
+
+	users, err := testQueries.ListCompanyUsers(context.Background(), ListCompanyUsersParams{
+		CompanyID: company.ID,
+		Limit:     5,
+		AfterID:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	for _, user := range users {
+		require.Equal(t, company.ID, user.CompanyID)
+	}
+
+	// the next page starts after the last user of the previous one
+	next, err := testQueries.ListCompanyUsers(context.Background(), ListCompanyUsersParams{
+		CompanyID: company.ID,
+		AfterID:   users[1].ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	require.Equal(t, users[2].ID, next[0].ID)
+
+	total, err := testQueries.CountCompanyUsers(context.Background(), company.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+}
+
+func TestUpdateUser(t *testing.T) {
+	user, err := testQueries.MarkUserVerified(context.Background(), createRandomUser(t).ID)
+	require.NoError(t, err)
+
+	// fields left out keep their value
+	renamed, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Name: sql.NullString{String: util.RandomString(6), Valid: true},
+		ID:   user.ID,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, user.Name, renamed.Name)
+	require.Equal(t, user.Phone, renamed.Phone)
+	require.Equal(t, user.Role, renamed.Role)
+	require.True(t, renamed.VerifiedAt.Valid)
+
+	// a new phone has to be verified again
+	moved, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Phone: sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+		Role:  sql.NullString{String: util.AdminRole, Valid: true},
+		ID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, user.Phone, moved.Phone)
+	require.Equal(t, util.AdminRole, moved.Role)
+	require.False(t, moved.VerifiedAt.Valid)
+}
+
+func TestDeactivateUser(t *testing.T) {
+	user := createRandomUser(t)
+
+	deactivated, err := testQueries.DeactivateUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, deactivated.DeactivatedAt.Valid)
+	require.WithinDuration(t, time.Now(), deactivated.DeactivatedAt.Time, time.Second)
+
+	reactivated, err := testQueries.ReactivateUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.False(t, reactivated.DeactivatedAt.Valid)
+}
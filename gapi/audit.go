@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"log"
+
+	"github.com/lenimbugua/bot/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodActions names the changes made by the methods in the audit log, the same
+// as the routes of the HTTP API
+var methodActions = map[string]string{
+	"/pb.BotService/CreateBot":  "bot.create",
+	"/pb.BotService/UpdateBot":  "bot.update",
+	"/pb.BotService/DeleteBot":  "bot.delete",
+	"/pb.BotService/RestoreBot": "bot.restore",
+
+	"/pb.CompanyService/CreateCompany": "company.create",
+	"/pb.CompanyService/UpdateCompany": "company.update",
+	"/pb.CompanyService/DeleteCompany": "company.delete",
+
+	"/pb.ChannelService/CreateChannel": "channel.create",
+	"/pb.ChannelService/UpdateChannel": "channel.update",
+	"/pb.ChannelService/DeleteChannel": "channel.delete",
+
+	"/pb.QuestionService/CreateQuestion":  "question.create",
+	"/pb.QuestionService/UpdateQuestion":  "question.update",
+	"/pb.QuestionService/DeleteQuestion":  "question.delete",
+	"/pb.QuestionService/RestoreQuestion": "question.restore",
+}
+
+type auditEntityKey struct{}
+
+// setAuditEntity records the entity changed by the call for the audit interceptor
+func setAuditEntity(ctx context.Context, id int64, before, after any) {
+	if entity, ok := ctx.Value(auditEntityKey{}).(*service.AuditEntity); ok {
+		*entity = service.AuditEntity{ID: id, Before: before, After: after}
+	}
+}
+
+// auditInterceptor appends the changes made by the methods to the audit log of the
+// company of the principal, in the transaction of the change. A method failing rolls
+// its changes back, and a change that cannot be logged is rolled back and fails the
+// call. It must run after authInterceptor.
+func (server *Server) auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	action, ok := methodActions[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	var rsp interface{}
+	var handlerErr error
+	err := server.service.RecordChange(ctx, principal(ctx), func(ctx context.Context) (service.AuditEntry, error) {
+		entity := &service.AuditEntity{}
+		rsp, handlerErr = handler(context.WithValue(ctx, auditEntityKey{}, entity), req)
+		if handlerErr != nil {
+			return service.AuditEntry{}, handlerErr
+		}
+
+		mtdt := extractMetadata(ctx)
+		return service.AuditEntry{
+			Action:    action,
+			Entity:    entity,
+			IP:        mtdt.ClientIP,
+			UserAgent: mtdt.UserAgent,
+			RequestID: mtdt.RequestID,
+		}, nil
+	})
+	if handlerErr != nil {
+		return nil, handlerErr
+	}
+	if err != nil {
+		log.Printf("cannot record %s in the audit log: %v", action, err)
+		return nil, status.Error(codes.Internal, "cannot record the change in the audit log")
+	}
+	return rsp, nil
+}
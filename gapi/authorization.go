@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/lenimbugua/bot/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without credentials
+var publicMethods = map[string]bool{
+	"/pb.AuthService/LoginUser": true,
+	"/pb.AuthService/LoginMFA":  true,
+}
+
+// methodScopes lists the scopes each method needs, the same as its route in the HTTP API.
+// Methods missing from it are refused, so that a new method cannot be left open by mistake.
+var methodScopes = map[string][]string{
+	"/pb.BotService/CreateBot":  {token.ScopeBotsWrite},
+	"/pb.BotService/GetBot":     {token.ScopeBotsRead},
+	"/pb.BotService/UpdateBot":  {token.ScopeBotsWrite},
+	"/pb.BotService/DeleteBot":  {token.ScopeBotsWrite},
+	"/pb.BotService/RestoreBot": {token.ScopeBotsWrite},
+	"/pb.BotService/ListBots":   {token.ScopeBotsRead},
+
+	"/pb.CompanyService/CreateCompany": {token.ScopeCompaniesWrite},
+	"/pb.CompanyService/GetCompany":    {token.ScopeCompaniesRead},
+	"/pb.CompanyService/UpdateCompany": {token.ScopeCompaniesWrite},
+	"/pb.CompanyService/DeleteCompany": {token.ScopeCompaniesWrite},
+	"/pb.CompanyService/ListCompanies": {token.ScopeCompaniesRead},
+
+	"/pb.ChannelService/CreateChannel": {token.ScopeChannelsWrite},
+	"/pb.ChannelService/GetChannel":    {token.ScopeChannelsRead},
+	"/pb.ChannelService/UpdateChannel": {token.ScopeChannelsWrite},
+	"/pb.ChannelService/DeleteChannel": {token.ScopeChannelsWrite},
+	"/pb.ChannelService/ListChannels":  {token.ScopeChannelsRead},
+
+	"/pb.QuestionService/CreateQuestion":  {token.ScopeQuestionsWrite},
+	"/pb.QuestionService/GetQuestion":     {token.ScopeQuestionsRead},
+	"/pb.QuestionService/UpdateQuestion":  {token.ScopeQuestionsWrite},
+	"/pb.QuestionService/DeleteQuestion":  {token.ScopeQuestionsWrite},
+	"/pb.QuestionService/RestoreQuestion": {token.ScopeQuestionsWrite},
+}
+
+type principalKey struct{}
+
+// authInterceptor authenticates the calls to every method but the public ones, with the
+// same credentials as the authorization header of the HTTP API, and checks their scopes.
+// The principal is passed on to the method in the context.
+func (server *Server) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	scopes, ok := methodScopes[info.FullMethod]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "method %s has no scopes", info.FullMethod)
+	}
+
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		authorization = firstValue(md, authorizationHeader)
+	}
+
+	payload, err := server.service.Authenticate(ctx, authorization)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	for _, scope := range scopes {
+		if !payload.HasScope(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "missing required scope %s", scope)
+		}
+	}
+
+	return handler(context.WithValue(ctx, principalKey{}, payload), req)
+}
+
+// principal returns the principal authenticated by authInterceptor
+func principal(ctx context.Context) *token.Payload {
+	return ctx.Value(principalKey{}).(*token.Payload)
+}
@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+
+	testCases := []struct {
+		name       string
+		buildCtx   func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return contextWithBearer(t, tokenMaker, user, token.ReadScopes)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidToken",
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, "bearer invalid")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UserGone",
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return contextWithBearer(t, tokenMaker, user, token.ReadScopes)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "MissingScope",
+			buildCtx: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return contextWithBearer(t, tokenMaker, user, []string{token.ScopeCompaniesRead})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			_, err := client.GetBot(tc.buildCtx(t, server.tokenMaker), &pb.GetBotRequest{Id: bot.ID})
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertBot(bot db.Bot) *pb.Bot {
+	return &pb.Bot{
+		Id:        bot.ID,
+		Title:     bot.Title,
+		CompanyId: bot.CompanyID,
+		CreatedAt: timestamppb.New(bot.CreatedAt),
+		UpdatedAt: timestamppb.New(bot.UpdatedAt),
+		Etag:      service.ETag(bot.UpdatedAt),
+	}
+}
+
+func convertCompany(company db.Company) *pb.Company {
+	return &pb.Company{
+		Id:        company.ID,
+		Email:     company.Email,
+		Phone:     company.Phone,
+		Name:      company.Name,
+		CreatedAt: timestamppb.New(company.CreatedAt),
+		UpdatedAt: timestamppb.New(company.UpdatedAt),
+		Etag:      service.ETag(company.UpdatedAt),
+	}
+}
+
+func convertChannel(channel db.Channel) *pb.Channel {
+	return &pb.Channel{
+		Id:        channel.ID,
+		Name:      channel.Name,
+		CreatedAt: timestamppb.New(channel.CreatedAt),
+		UpdatedAt: timestamppb.New(channel.UpdatedAt),
+	}
+}
+
+func convertQuestion(question db.Question) *pb.Question {
+	return &pb.Question{
+		Id:             question.ID,
+		Question:       question.Question,
+		BotId:          question.BotID,
+		Type:           question.Type,
+		ParentId:       question.ParentID,
+		NextQuestionId: question.NextQuestionID,
+		CreatedAt:      timestamppb.New(question.CreatedAt),
+		UpdatedAt:      timestamppb.New(question.UpdatedAt),
+		Etag:           service.ETag(question.UpdatedAt),
+	}
+}
+
+func convertUser(user db.User, company db.Company) *pb.User {
+	return &pb.User{
+		Role:              user.Role,
+		Name:              user.Name,
+		Phone:             user.Phone,
+		Email:             user.Email.String,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+		UpdatedAt:         timestamppb.New(user.UpdatedAt),
+		Company:           convertCompany(company),
+	}
+}
+
+// convertLogin answers a login with its session, or with the token to complete it when a second factor is needed
+func convertLogin(login service.Login) *pb.LoginUserResponse {
+	if login.MFARequired() {
+		return &pb.LoginUserResponse{
+			MfaRequired:       true,
+			MfaToken:          login.MFAToken,
+			MfaTokenExpiresAt: timestamppb.New(login.MFATokenExpiresAt),
+		}
+	}
+
+	return &pb.LoginUserResponse{
+		SessionId:             login.Session.ID.String(),
+		AccessToken:           login.Session.AccessToken,
+		AccessTokenExpiresAt:  timestamppb.New(login.Session.AccessTokenExpiresAt),
+		RefreshToken:          login.Session.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(login.Session.RefreshTokenExpiresAt),
+		User:                  convertUser(login.User, login.Company),
+	}
+}
+
+// ifMatch makes a change conditional on the version with the etag, unconditional without one
+func ifMatch(etag string) service.IfMatch {
+	if etag == "" {
+		return nil
+	}
+	return service.IfMatch{etag}
+}
@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError translates an error of the service layer into the status the method fails with.
+// The details of a server failure are logged, not sent.
+func toStatusError(err error) error {
+	var lockedErr *service.LockedError
+	var pqErr *pq.Error
+
+	switch {
+	case service.IsUnauthenticated(err),
+		errors.Is(err, service.ErrWrongPassword),
+		errors.Is(err, service.ErrInvalidSecondFactor),
+		errors.Is(err, service.ErrUserGone),
+		errors.Is(err, token.ErrInvalidToken),
+		errors.Is(err, token.ErrExpiredToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrNotOwner),
+		errors.Is(err, service.ErrNotAdmin),
+		errors.Is(err, service.ErrOwnerOnly),
+		errors.Is(err, service.ErrUserDeactivated),
+		errors.Is(err, service.ErrPhoneNotVerified):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrVersionMismatch),
+		errors.Is(err, service.ErrVersionRequired),
+		errors.Is(err, db.ErrLastOwner):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "resource not found")
+	case errors.As(err, &lockedErr):
+		retryAfter := int64(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		return status.Errorf(codes.ResourceExhausted, "%s, try again in %d seconds", lockedErr, retryAfter)
+	case errors.As(err, &pqErr):
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return status.Error(codes.AlreadyExists, "resource already exists")
+		case "foreign_key_violation":
+			return status.Error(codes.FailedPrecondition, "referenced resource does not exist or is still referenced")
+		case "check_violation", "not_null_violation", "string_data_right_truncation":
+			return status.Error(codes.InvalidArgument, "a value is not allowed")
+		}
+	}
+
+	log.Printf("internal error: %v", err)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// fieldViolation describes why one field of a request is invalid
+type fieldViolation struct {
+	field   string
+	message string
+}
+
+// invalidArgumentError fails a request with invalid fields
+func invalidArgumentError(violations []fieldViolation) error {
+	message := "request is invalid:"
+	for _, violation := range violations {
+		message += fmt.Sprintf(" %s %s;", violation.field, violation.message)
+	}
+	return status.Error(codes.InvalidArgument, message[:len(message)-1])
+}
@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testClient calls a test server through its interceptors over an in-memory connection
+type testClient struct {
+	pb.BotServiceClient
+	pb.CompanyServiceClient
+	pb.QuestionServiceClient
+}
+
+// newTestServer creates a new test server
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+	return server
+}
+
+// newTestClient serves the server until the end of the test and connects a client to it
+func newTestClient(t *testing.T, server *Server) testClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.newGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return testClient{
+		BotServiceClient:      pb.NewBotServiceClient(conn),
+		CompanyServiceClient:  pb.NewCompanyServiceClient(conn),
+		QuestionServiceClient: pb.NewQuestionServiceClient(conn),
+	}
+}
+
+// contextWithBearer authorizes a call with an access token for the user
+func contextWithBearer(t *testing.T, tokenMaker token.Maker, user db.User, scopes []string) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, scopes, time.Minute)
+	require.NoError(t, err)
+
+	authorization := fmt.Sprintf("%s %s", service.AuthorizationTypeBearer, accessToken)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, authorization)
+}
+
+func randomUser(companyID int64) db.User {
+	return db.User{
+		ID:        util.RandInt(1, 1000),
+		Name:      util.RandomString(6),
+		Phone:     util.RandomPhoneNumber(),
+		CompanyID: companyID,
+		Role:      util.MemberRole,
+	}
+}
+
+func randomBot(companyID int64) db.Bot {
+	return db.Bot{
+		ID:        util.RandInt(1, 1000),
+		Title:     util.RandomString(6),
+		CompanyID: companyID,
+		UpdatedAt: time.Now().Truncate(time.Microsecond),
+	}
+}
+
+// allowTx runs the transactions of a test, which the mock store cannot roll back
+func allowTx(store *mockdb.MockStore) {
+	store.EXPECT().
+		ExecTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+}
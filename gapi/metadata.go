@@ -0,0 +1,61 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/lenimbugua/bot/service"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	authorizationHeader = "authorization"
+	userAgentHeader     = "user-agent"
+	requestIDHeader     = "x-request-id"
+)
+
+// requestIDPattern limits the request ids accepted from clients to something safe to log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Metadata is what the server knows about the client making a call
+type Metadata struct {
+	ClientIP  string
+	UserAgent string
+	RequestID string
+}
+
+// firstValue returns the first value of the metadata key, empty when it is missing
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// extractMetadata returns the metadata of the call. Every call gets a request id,
+// the one sent by the client when it looks sane.
+func extractMetadata(ctx context.Context) Metadata {
+	var mtdt Metadata
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		mtdt.UserAgent = firstValue(md, userAgentHeader)
+		mtdt.RequestID = firstValue(md, requestIDHeader)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		mtdt.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(mtdt.ClientIP); err == nil {
+			mtdt.ClientIP = host
+		}
+	}
+	if !requestIDPattern.MatchString(mtdt.RequestID) {
+		mtdt.RequestID = uuid.NewString()
+	}
+	return mtdt
+}
+
+// client describes the caller to the service
+func (mtdt Metadata) client() service.Client {
+	return service.Client{IP: mtdt.ClientIP, UserAgent: mtdt.UserAgent}
+}
@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// Page sizes used when DEFAULT_PAGE_SIZE and MAX_PAGE_SIZE are not set
+const (
+	defaultPageSize    = 10
+	defaultMaxPageSize = 100
+)
+
+// page is the position and size of a page requested with page_size and page_token.
+// The first page is requested without a token; the next ones with the next_page_token of the previous page.
+type page struct {
+	afterID int64
+	size    int32
+}
+
+// limit is the number of rows to query: one more than the page size tells whether another page follows
+func (p page) limit() int32 {
+	return p.size + 1
+}
+
+// parsePage validates the pagination fields of a list request against the configured page sizes
+func (server *Server) parsePage(pageSize int32, pageToken string) (page, error) {
+	size := server.config.DefaultPageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	maxSize := server.config.MaxPageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxPageSize
+	}
+
+	var violations []fieldViolation
+	violations = validateField(violations, "page_size", pageSize, "min=0,max="+strconv.Itoa(int(maxSize)))
+	afterID, err := decodePageToken(pageToken)
+	if err != nil {
+		violations = append(violations, fieldViolation{field: "page_token", message: "is invalid, use the next_page_token of a previous page"})
+	}
+	if violations != nil {
+		return page{}, invalidArgumentError(violations)
+	}
+
+	if pageSize > 0 {
+		size = pageSize
+	}
+	return page{afterID: afterID, size: size}, nil
+}
+
+// encodePageToken encodes the position after which the next page starts opaquely,
+// so that clients do not depend on its content
+func encodePageToken(afterID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(afterID, 10)))
+}
+
+func decodePageToken(pageToken string) (int64, error) {
+	if pageToken == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return 0, err
+	}
+	afterID, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || afterID < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return afterID, nil
+}
+
+// nextPage trims the extra row queried by page.limit and returns the token of the next page,
+// empty on the last page
+func nextPage[T any](p page, items []T, id func(T) int64) ([]T, string) {
+	if int32(len(items)) <= p.size {
+		return items, ""
+	}
+	items = items[:p.size]
+	return items, encodePageToken(id(items[p.size-1]))
+}
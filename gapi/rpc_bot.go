@@ -0,0 +1,126 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+)
+
+func (server *Server) CreateBot(ctx context.Context, req *pb.CreateBotRequest) (*pb.Bot, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "title", req.GetTitle(), "required")
+	violations = validateField(violations, "company_id", req.GetCompanyId(), "required,min=1")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	bot, err := server.service.CreateBot(ctx, principal(ctx), db.CreateBotParams{
+		Title:     req.GetTitle(),
+		CompanyID: req.GetCompanyId(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, bot.ID, nil, bot)
+	return convertBot(bot), nil
+}
+
+func (server *Server) GetBot(ctx context.Context, req *pb.GetBotRequest) (*pb.Bot, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	bot, err := server.service.GetBot(ctx, principal(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertBot(bot), nil
+}
+
+// UpdateBot changes the fields set in the request, only on the version with the etag when one is given
+func (server *Server) UpdateBot(ctx context.Context, req *pb.UpdateBotRequest) (*pb.Bot, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "id", req.GetId(), "min=1")
+	if req.Title != nil {
+		violations = validateField(violations, "title", req.GetTitle(), "min=1")
+	}
+	if req.CompanyId != nil {
+		violations = validateField(violations, "company_id", req.GetCompanyId(), "min=1")
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.UpdateBotParams{ID: req.GetId()}
+	if req.Title != nil {
+		arg.Title = sql.NullString{String: req.GetTitle(), Valid: true}
+	}
+	if req.CompanyId != nil {
+		arg.CompanyID = sql.NullInt64{Int64: req.GetCompanyId(), Valid: true}
+	}
+
+	before, bot, err := server.service.UpdateBot(ctx, principal(ctx), arg, ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, bot.ID, before, bot)
+	return convertBot(bot), nil
+}
+
+// DeleteBot soft deletes a bot and its questions and returns the bot as it was
+func (server *Server) DeleteBot(ctx context.Context, req *pb.DeleteBotRequest) (*pb.Bot, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	bot, err := server.service.DeleteBot(ctx, principal(ctx), req.GetId(), ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, bot.ID, bot, nil)
+	return convertBot(bot), nil
+}
+
+func (server *Server) RestoreBot(ctx context.Context, req *pb.RestoreBotRequest) (*pb.Bot, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	bot, err := server.service.RestoreBot(ctx, principal(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, bot.ID, nil, bot)
+	return convertBot(bot), nil
+}
+
+// ListBots lists the bots in order of id, those of the company of the user unless it is
+// an operator, who lists those of one company when company_id is set
+func (server *Server) ListBots(ctx context.Context, req *pb.ListBotsRequest) (*pb.ListBotsResponse, error) {
+	violations := validateField(nil, "company_id", req.GetCompanyId(), "min=0")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+	p, err := server.parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	bots, err := server.service.ListBots(ctx, principal(ctx), req.GetCompanyId(), p.afterID, p.limit())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	bots, nextPageToken := nextPage(p, bots, func(bot db.Bot) int64 { return bot.ID })
+	rsp := &pb.ListBotsResponse{NextPageToken: nextPageToken}
+	for _, bot := range bots {
+		rsp.Bots = append(rsp.Bots, convertBot(bot))
+	}
+	return rsp, nil
+}
@@ -0,0 +1,321 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func requireMatchBot(t *testing.T, rsp *pb.Bot, bot db.Bot) {
+	require.Equal(t, bot.ID, rsp.GetId())
+	require.Equal(t, bot.Title, rsp.GetTitle())
+	require.Equal(t, bot.CompanyID, rsp.GetCompanyId())
+	require.Equal(t, service.ETag(bot.UpdatedAt), rsp.GetEtag())
+}
+
+func TestCreateBotRPC(t *testing.T) {
+	user := randomUser(1)
+	other := randomUser(user.CompanyID + 1)
+	company := db.Company{ID: user.CompanyID}
+	bot := randomBot(user.CompanyID)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Bot, err error)
+	}{
+		{
+			name: "OK",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateBotParams{Title: bot.Title, CompanyID: bot.CompanyID}
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().CreateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(bot, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.NoError(t, err)
+				requireMatchBot(t, rsp, bot)
+			},
+		},
+		{
+			name: "OtherCompany",
+			user: other,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().CreateBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(tc.user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, tc.user, token.AllScopes)
+			rsp, err := client.CreateBot(ctx, &pb.CreateBotRequest{Title: bot.Title, CompanyId: company.ID})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestGetBotRPC(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+
+	testCases := []struct {
+		name          string
+		req           *pb.GetBotRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Bot, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.GetBotRequest{Id: bot.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.NoError(t, err)
+				requireMatchBot(t, rsp, bot)
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  &pb.GetBotRequest{Id: bot.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := bot
+				other.CompanyID = user.CompanyID + 1
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(other, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.GetBotRequest{Id: bot.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.GetBotRequest{Id: bot.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(db.Bot{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			req:  &pb.GetBotRequest{Id: -20},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+			rsp, err := client.GetBot(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestUpdateBotRPC(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+	title := "renamed"
+
+	updated := bot
+	updated.Title = title
+	updated.UpdatedAt = bot.UpdatedAt.Add(1000)
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateBotRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Bot, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.UpdateBotRequest{Id: bot.ID, Title: &title, Etag: service.ETag(bot.UpdatedAt)},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateBotParams{
+					ID:        bot.ID,
+					Title:     sql.NullString{String: title, Valid: true},
+					UpdatedAt: sql.NullTime{Time: bot.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, "bot.update", arg.Action)
+						require.Equal(t, "bot", arg.EntityType)
+						require.Equal(t, sql.NullInt64{Int64: bot.ID, Valid: true}, arg.EntityID)
+						require.Equal(t, user.CompanyID, arg.CompanyID)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.NoError(t, err)
+				requireMatchBot(t, rsp, updated)
+			},
+		},
+		{
+			name: "VersionMismatch",
+			req:  &pb.UpdateBotRequest{Id: bot.ID, Title: &title, Etag: `"stale"`},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "AuditLogError",
+			req:  &pb.UpdateBotRequest{Id: bot.ID, Title: &title},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(1).Return(updated, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				// the update is rolled back with the entry
+				require.Equal(t, codes.Internal, status.Code(err))
+				require.Nil(t, rsp)
+			},
+		},
+		{
+			name: "EmptyTitle",
+			req:  &pb.UpdateBotRequest{Id: bot.ID, Title: new(string)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Bot, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+			rsp, err := client.UpdateBot(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestListBotsRPC(t *testing.T) {
+	user := randomUser(1)
+	bots := make([]db.Bot, 3)
+	for i := range bots {
+		bots[i] = randomBot(user.CompanyID)
+		bots[i].ID = int64(i + 1)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	companyID := sql.NullInt64{Int64: user.CompanyID, Valid: true}
+	store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+	store.EXPECT().
+		SearchBots(gomock.Any(), gomock.Eq(db.SearchBotsParams{CompanyID: companyID, Limit: 3})).
+		Times(1).
+		Return(bots, nil)
+	store.EXPECT().
+		SearchBots(gomock.Any(), gomock.Eq(db.SearchBotsParams{CompanyID: companyID, AfterID: sql.NullInt64{Int64: 2, Valid: true}, Limit: 3})).
+		Times(1).
+		Return(bots[2:], nil)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+	ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+
+	rsp, err := client.ListBots(ctx, &pb.ListBotsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetBots(), 2)
+	require.NotEmpty(t, rsp.GetNextPageToken())
+
+	rsp, err = client.ListBots(ctx, &pb.ListBotsRequest{PageSize: 2, PageToken: rsp.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetBots(), 1)
+	requireMatchBot(t, rsp.GetBots()[0], bots[2])
+	require.Empty(t, rsp.GetNextPageToken())
+
+	_, err = client.ListBots(ctx, &pb.ListBotsRequest{PageToken: "invalid!"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the bots of other companies are only listed for operators
+	_, err = client.ListBots(ctx, &pb.ListBotsRequest{CompanyId: user.CompanyID + 1})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,99 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+)
+
+func (server *Server) CreateChannel(ctx context.Context, req *pb.CreateChannelRequest) (*pb.Channel, error) {
+	if violations := validateField(nil, "name", req.GetName(), "required"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	channel, err := server.service.CreateChannel(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, int64(channel.ID), nil, channel)
+	return convertChannel(channel), nil
+}
+
+func (server *Server) GetChannel(ctx context.Context, req *pb.GetChannelRequest) (*pb.Channel, error) {
+	if violations := validateField(nil, "name", req.GetName(), "required,alpha"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	channel, err := server.service.GetChannel(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertChannel(channel), nil
+}
+
+// UpdateChannel changes the fields set in the request
+func (server *Server) UpdateChannel(ctx context.Context, req *pb.UpdateChannelRequest) (*pb.Channel, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "id", req.GetId(), "min=1")
+	if req.Name != nil {
+		violations = validateField(violations, "name", req.GetName(), "min=1")
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.UpdateChannelParams{ID: req.GetId()}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: req.GetName(), Valid: true}
+	}
+
+	before, channel, err := server.service.UpdateChannel(ctx, arg)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, int64(channel.ID), before, channel)
+	return convertChannel(channel), nil
+}
+
+// DeleteChannel deletes a channel and returns it as it was
+func (server *Server) DeleteChannel(ctx context.Context, req *pb.DeleteChannelRequest) (*pb.Channel, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	channel, err := server.service.DeleteChannel(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, int64(channel.ID), channel, nil)
+	return convertChannel(channel), nil
+}
+
+// ListChannels lists the channels in order of id
+func (server *Server) ListChannels(ctx context.Context, req *pb.ListChannelsRequest) (*pb.ListChannelsResponse, error) {
+	p, err := server.parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	if p.afterID > math.MaxInt32 {
+		return nil, invalidArgumentError([]fieldViolation{{field: "page_token", message: "is invalid, use the next_page_token of a previous page"}})
+	}
+
+	channels, err := server.service.ListChannels(ctx, int32(p.afterID), p.limit())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	channels, nextPageToken := nextPage(p, channels, func(channel db.Channel) int64 { return int64(channel.ID) })
+	rsp := &pb.ListChannelsResponse{NextPageToken: nextPageToken}
+	for _, channel := range channels {
+		rsp.Channels = append(rsp.Channels, convertChannel(channel))
+	}
+	return rsp, nil
+}
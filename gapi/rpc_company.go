@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+)
+
+func (server *Server) CreateCompany(ctx context.Context, req *pb.CreateCompanyRequest) (*pb.Company, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "phone", req.GetPhone(), "required,e164")
+	violations = validateField(violations, "name", req.GetName(), "required")
+	violations = validateField(violations, "email", req.GetEmail(), "required,email")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	company, err := server.service.CreateCompany(ctx, db.CreateCompanyParams{
+		Phone: req.GetPhone(),
+		Name:  req.GetName(),
+		Email: req.GetEmail(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, company.ID, nil, company)
+	return convertCompany(company), nil
+}
+
+func (server *Server) GetCompany(ctx context.Context, req *pb.GetCompanyRequest) (*pb.Company, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	company, err := server.service.GetCompany(ctx, principal(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertCompany(company), nil
+}
+
+// UpdateCompany changes the fields set in the request, only on the version with the etag when one is given
+func (server *Server) UpdateCompany(ctx context.Context, req *pb.UpdateCompanyRequest) (*pb.Company, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "id", req.GetId(), "min=1")
+	if req.Phone != nil {
+		violations = validateField(violations, "phone", req.GetPhone(), "e164")
+	}
+	if req.Name != nil {
+		violations = validateField(violations, "name", req.GetName(), "min=1")
+	}
+	if req.Email != nil {
+		violations = validateField(violations, "email", req.GetEmail(), "email")
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.UpdateCompanyParams{ID: req.GetId()}
+	if req.Phone != nil {
+		arg.Phone = sql.NullString{String: req.GetPhone(), Valid: true}
+	}
+	if req.Name != nil {
+		arg.Name = sql.NullString{String: req.GetName(), Valid: true}
+	}
+	if req.Email != nil {
+		arg.Email = sql.NullString{String: req.GetEmail(), Valid: true}
+	}
+
+	before, company, err := server.service.UpdateCompany(ctx, principal(ctx), arg, ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, company.ID, before, company)
+	return convertCompany(company), nil
+}
+
+// DeleteCompany soft deletes a company with its bots and returns the company as it was
+func (server *Server) DeleteCompany(ctx context.Context, req *pb.DeleteCompanyRequest) (*pb.Company, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	company, err := server.service.DeleteCompany(ctx, principal(ctx), req.GetId(), ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, company.ID, company, nil)
+	return convertCompany(company), nil
+}
+
+// ListCompanies lists the companies in order of id, only the company of the user
+// unless it is an operator
+func (server *Server) ListCompanies(ctx context.Context, req *pb.ListCompaniesRequest) (*pb.ListCompaniesResponse, error) {
+	p, err := server.parsePage(req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	companies, err := server.service.ListCompanies(ctx, principal(ctx), p.afterID, p.limit())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	companies, nextPageToken := nextPage(p, companies, func(company db.Company) int64 { return company.ID })
+	rsp := &pb.ListCompaniesResponse{NextPageToken: nextPageToken}
+	for _, company := range companies {
+		rsp.Companies = append(rsp.Companies, convertCompany(company))
+	}
+	return rsp, nil
+}
@@ -0,0 +1,180 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func randomCompany() db.Company {
+	return db.Company{
+		ID:        util.RandInt(1, 1000),
+		Name:      util.RandomString(6),
+		Phone:     util.RandomPhoneNumber(),
+		Email:     util.RandomEmail(),
+		UpdatedAt: time.Now().Truncate(time.Microsecond),
+	}
+}
+
+func TestUpdateCompanyRPC(t *testing.T) {
+	company := randomCompany()
+	owner := randomUser(company.ID)
+	owner.Role = util.OwnerRole
+	member := randomUser(company.ID)
+	other := randomUser(company.ID + 1)
+	other.Role = util.OwnerRole
+	name := "renamed"
+
+	updated := company
+	updated.Name = name
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Company, err error)
+	}{
+		{
+			name: "OK",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).Times(1).Return(updated, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.NoError(t, err)
+				require.Equal(t, name, rsp.GetName())
+			},
+		},
+		{
+			name: "OtherCompany",
+			user: other,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: member,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().UpdateCompany(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(tc.user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, tc.user, token.AllScopes)
+			rsp, err := client.UpdateCompany(ctx, &pb.UpdateCompanyRequest{Id: company.ID, Name: &name})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestDeleteCompanyRPC(t *testing.T) {
+	company := randomCompany()
+	owner := randomUser(company.ID)
+	owner.Role = util.OwnerRole
+	member := randomUser(company.ID)
+	other := randomUser(company.ID + 1)
+	other.Role = util.OwnerRole
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Company, err error)
+	}{
+		{
+			name: "OK",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Eq(db.SoftDeleteCompanyParams{ID: company.ID})).Times(1).Return(company, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.NoError(t, err)
+				require.Equal(t, company.ID, rsp.GetId())
+			},
+		},
+		{
+			name: "OtherCompany",
+			user: other,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: member,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().DeleteCompanyTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Company, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(tc.user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, tc.user, token.AllScopes)
+			rsp, err := client.DeleteCompany(ctx, &pb.DeleteCompanyRequest{Id: company.ID})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
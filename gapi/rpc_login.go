@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/lenimbugua/bot/pb"
+)
+
+// LoginUser logs a user in with a phone and password, as POST /users/login does
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "phone", req.GetPhone(), "required,e164")
+	violations = validateField(violations, "password", req.GetPassword(), "required,min=6")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	login, err := server.service.Login(ctx, extractMetadata(ctx).client(), req.GetPhone(), req.GetPassword())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertLogin(login), nil
+}
+
+// LoginMFA completes a login needing a second factor, as POST /users/login/mfa does
+func (server *Server) LoginMFA(ctx context.Context, req *pb.LoginMFARequest) (*pb.LoginUserResponse, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "mfa_token", req.GetMfaToken(), "required")
+	violations = validateField(violations, "code", req.GetCode(), "required")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	login, err := server.service.LoginMFA(ctx, extractMetadata(ctx).client(), req.GetMfaToken(), req.GetCode())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertLogin(login), nil
+}
@@ -0,0 +1,122 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+)
+
+// CreateQuestion adds a question to a bot of the company of the principal
+func (server *Server) CreateQuestion(ctx context.Context, req *pb.CreateQuestionRequest) (*pb.Question, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "question", req.GetQuestion(), "required")
+	violations = validateField(violations, "bot_id", req.GetBotId(), "required,min=1")
+	violations = validateField(violations, "type", req.GetType(), "required")
+	violations = validateField(violations, "parent_id", req.GetParentId(), "min=0")
+	violations = validateField(violations, "next_question_id", req.GetNextQuestionId(), "min=0")
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	question, err := server.service.CreateQuestion(ctx, principal(ctx), db.CreateQuestionParams{
+		Question:       req.GetQuestion(),
+		Type:           req.GetType(),
+		ParentID:       req.GetParentId(),
+		BotID:          req.GetBotId(),
+		NextQuestionID: req.GetNextQuestionId(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, question.ID, nil, question)
+	return convertQuestion(question), nil
+}
+
+func (server *Server) GetQuestion(ctx context.Context, req *pb.GetQuestionRequest) (*pb.Question, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	question, err := server.service.GetQuestion(ctx, principal(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return convertQuestion(question), nil
+}
+
+// UpdateQuestion changes the fields set in the request, in the version of the question
+// given by the etag
+func (server *Server) UpdateQuestion(ctx context.Context, req *pb.UpdateQuestionRequest) (*pb.Question, error) {
+	var violations []fieldViolation
+	violations = validateField(violations, "id", req.GetId(), "min=1")
+	if req.Question != nil {
+		violations = validateField(violations, "question", req.GetQuestion(), "min=1")
+	}
+	if req.Type != nil {
+		violations = validateField(violations, "type", req.GetType(), "min=1")
+	}
+	if req.ParentId != nil {
+		violations = validateField(violations, "parent_id", req.GetParentId(), "min=0")
+	}
+	if req.NextQuestionId != nil {
+		violations = validateField(violations, "next_question_id", req.GetNextQuestionId(), "min=0")
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.UpdateQuestionParams{ID: req.GetId()}
+	if req.Question != nil {
+		arg.Question = sql.NullString{String: req.GetQuestion(), Valid: true}
+	}
+	if req.Type != nil {
+		arg.Type = sql.NullString{String: req.GetType(), Valid: true}
+	}
+	if req.ParentId != nil {
+		arg.ParentID = sql.NullInt64{Int64: req.GetParentId(), Valid: true}
+	}
+	if req.NextQuestionId != nil {
+		arg.NextQuestionID = sql.NullInt64{Int64: req.GetNextQuestionId(), Valid: true}
+	}
+
+	before, question, err := server.service.UpdateQuestion(ctx, principal(ctx), arg, ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, question.ID, before, question)
+	return convertQuestion(question), nil
+}
+
+// DeleteQuestion soft deletes a question and returns it as it was
+func (server *Server) DeleteQuestion(ctx context.Context, req *pb.DeleteQuestionRequest) (*pb.Question, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	question, err := server.service.DeleteQuestion(ctx, principal(ctx), req.GetId(), ifMatch(req.GetEtag()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, question.ID, question, nil)
+	return convertQuestion(question), nil
+}
+
+// RestoreQuestion brings back a deleted question of a bot that is not deleted itself
+func (server *Server) RestoreQuestion(ctx context.Context, req *pb.RestoreQuestionRequest) (*pb.Question, error) {
+	if violations := validateField(nil, "id", req.GetId(), "min=1"); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	question, err := server.service.RestoreQuestion(ctx, principal(ctx), req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	setAuditEntity(ctx, question.ID, nil, question)
+	return convertQuestion(question), nil
+}
@@ -0,0 +1,275 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRestoreQuestionRPC(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+	question := db.Question{
+		ID:       util.RandInt(1, 1000),
+		Question: util.RandomString(12),
+		BotID:    bot.ID,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.RestoreQuestionRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Question, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.RestoreQuestionRequest{Id: question.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, "question.restore", arg.Action)
+						require.Equal(t, sql.NullInt64{Int64: question.ID, Valid: true}, arg.EntityID)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.NoError(t, err)
+				require.Equal(t, question.ID, rsp.GetId())
+				require.Equal(t, question.Question, rsp.GetQuestion())
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  &pb.RestoreQuestionRequest{Id: question.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := bot
+				other.CompanyID = user.CompanyID + 1
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(other, nil)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotDeleted",
+			req:  &pb.RestoreQuestionRequest{Id: question.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(db.Question{}, sql.ErrNoRows)
+				store.EXPECT().RestoreQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidID",
+			req:  &pb.RestoreQuestionRequest{Id: -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDeletedQuestion(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+			rsp, err := client.RestoreQuestion(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestDeleteQuestionRPC(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+	question := db.Question{
+		ID:        util.RandInt(1, 1000),
+		Question:  util.RandomString(12),
+		BotID:     bot.ID,
+		UpdatedAt: time.Now().Truncate(time.Microsecond),
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.DeleteQuestionRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Question, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.DeleteQuestionRequest{Id: question.ID, Etag: service.ETag(question.UpdatedAt)},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SoftDeleteQuestionParams{
+					ID:        question.ID,
+					UpdatedAt: sql.NullTime{Time: question.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(question, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.NoError(t, err)
+				require.Equal(t, question.ID, rsp.GetId())
+				require.Equal(t, service.ETag(question.UpdatedAt), rsp.GetEtag())
+			},
+		},
+		{
+			name: "VersionMismatch",
+			req:  &pb.DeleteQuestionRequest{Id: question.ID, Etag: `"stale"`},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().SoftDeleteQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+			rsp, err := client.DeleteQuestion(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestUpdateQuestionRPC(t *testing.T) {
+	user := randomUser(1)
+	bot := randomBot(user.CompanyID)
+	question := db.Question{
+		ID:        util.RandInt(1, 1000),
+		Question:  util.RandomString(12),
+		BotID:     bot.ID,
+		UpdatedAt: time.Now().Truncate(time.Microsecond),
+	}
+	renamed := question
+	renamed.Question = "renamed"
+	renamed.UpdatedAt = question.UpdatedAt.Add(time.Second)
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateQuestionRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.Question, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.UpdateQuestionRequest{Id: question.ID, Question: &renamed.Question, Etag: service.ETag(question.UpdatedAt)},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateQuestionParams{
+					Question:  sql.NullString{String: renamed.Question, Valid: true},
+					ID:        question.ID,
+					UpdatedAt: sql.NullTime{Time: question.UpdatedAt, Valid: true},
+				}
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Eq(arg)).Times(1).Return(renamed, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(1).Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.NoError(t, err)
+				require.Equal(t, renamed.Question, rsp.GetQuestion())
+				require.Equal(t, service.ETag(renamed.UpdatedAt), rsp.GetEtag())
+			},
+		},
+		{
+			name: "VersionMismatch",
+			req:  &pb.UpdateQuestionRequest{Id: question.ID, Question: &renamed.Question, Etag: `"stale"`},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "MissingEtag",
+			req:  &pb.UpdateQuestionRequest{Id: question.ID, Question: &renamed.Question},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateQuestion(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.Question, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).AnyTimes().Return(user, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := contextWithBearer(t, server.tokenMaker, user, token.AllScopes)
+			rsp, err := client.UpdateQuestion(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/pb"
+	"github.com/lenimbugua/bot/service"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves gRPC requests for bot. It applies the rules of the HTTP API
+// through the same service layer.
+type Server struct {
+	pb.UnimplementedAuthServiceServer
+	pb.UnimplementedBotServiceServer
+	pb.UnimplementedCompanyServiceServer
+	pb.UnimplementedChannelServiceServer
+	pb.UnimplementedQuestionServiceServer
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	service    *service.Service
+	grpcServer *grpc.Server
+}
+
+// NewServer creates a new gRPC server
+func NewServer(config util.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewMakerFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		service:    service.New(config, store, tokenMaker),
+	}
+	server.grpcServer = server.newGRPCServer()
+	return server, nil
+}
+
+// newGRPCServer creates a grpc.Server running the interceptors of the server and serving all its services
+func (server *Server) newGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.authInterceptor, server.auditInterceptor),
+	)
+	pb.RegisterAuthServiceServer(grpcServer, server)
+	pb.RegisterBotServiceServer(grpcServer, server)
+	pb.RegisterCompanyServiceServer(grpcServer, server)
+	pb.RegisterChannelServiceServer(grpcServer, server)
+	pb.RegisterQuestionServiceServer(grpcServer, server)
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+// Start runs the server on a specific address until Shutdown is called
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", address, err)
+	}
+	err = server.grpcServer.Serve(listener)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		// Shutdown came first
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops the server from accepting calls and waits for the calls in flight
+// to complete until ctx is done, when it cancels those left
+func (server *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
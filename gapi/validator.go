@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// validateField checks the value of a field against rules written as the binding
+// tags of the HTTP API, so that both APIs accept the same values
+func validateField(violations []fieldViolation, field string, value interface{}, rules string) []fieldViolation {
+	err := validate.Var(value, rules)
+	if err == nil {
+		return violations
+	}
+
+	message := "is invalid"
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) && len(validationErrs) > 0 {
+		message = fmt.Sprintf("does not pass %s", validationErrs[0].Tag())
+	}
+	return append(violations, fieldViolation{field: field, message: message})
+}
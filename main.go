@@ -1,31 +1,102 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/lenimbugua/bot/api"
+	"github.com/lenimbugua/bot/db/migrator"
 	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/gapi"
+	"github.com/lenimbugua/bot/metrics"
+	"github.com/lenimbugua/bot/service"
 	"github.com/lenimbugua/bot/util"
+	"github.com/lenimbugua/bot/worker"
 	_ "github.com/lib/pq"
 )
 
 func main() {
+	skipMigrations := flag.Bool("skip-migrations", false, "start without applying the pending migrations")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-skip-migrations]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s migrate up [n] | down [n] | version\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s restore-company <id>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	config, err := util.LoadConfig(".")
 	if err != nil {
 		log.Fatal("Cannot load config", err)
 	}
+
+	if flag.Arg(0) == "migrate" {
+		err = runMigrate(config, flag.Args()[1:])
+		if err != nil {
+			log.Fatal("Cannot migrate database ", err)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "restore-company" {
+		err = runRestoreCompany(config, flag.Args()[1:])
+		if err != nil {
+			log.Fatal("Cannot restore company ", err)
+		}
+		return
+	}
+
+	if !*skipMigrations {
+		err = runMigrate(config, []string{"up"})
+		if err != nil {
+			log.Fatal("Cannot migrate database ", err)
+		}
+	}
+
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("Cannot connect to database ", err)
 	}
 
-	store := db.NewSQLStore(conn)
-	server, err := api.NewServer(config, store)
+	store := db.NewObservedSQLStore(conn, metrics.ObserveQuery)
+	err = metrics.RegisterDB(conn, store)
+	if err != nil {
+		log.Fatal("cannot register database metrics ", err)
+	}
+
+	httpServer, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("cannot create server", err)
 	}
+	grpcServer, err := gapi.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create gRPC server ", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+	var workers sync.WaitGroup
+	purger := worker.NewPurger(store, config.SoftDeleteRetention, config.PurgeInterval)
+	httpServer.AddReadinessCheck("purger", purger.Check)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		purger.Run(workerCtx)
+	}()
+
 	var serverAddress string
 	if config.Env == "PRODUCTION" {
 		port := os.Getenv("PORT")
@@ -33,8 +104,183 @@ func main() {
 	} else {
 		serverAddress = config.HTTPServerAddress
 	}
-	err = server.Start(serverAddress)
+
+	serverErrs := make(chan error, 3)
+	go func() {
+		log.Printf("start HTTP server at %s", serverAddress)
+		serverErrs <- httpServer.Start(serverAddress)
+	}()
+	go func() {
+		log.Printf("start gRPC server at %s", config.GRPCServerAddress)
+		serverErrs <- grpcServer.Start(config.GRPCServerAddress)
+	}()
+	var metricsServer *metrics.Server
+	if config.MetricsServerAddress != "" {
+		metricsServer = metrics.NewServer()
+		go func() {
+			log.Printf("start metrics server at %s", config.MetricsServerAddress)
+			serverErrs <- metricsServer.Start(config.MetricsServerAddress)
+		}()
+	}
+
+	failed := false
+	select {
+	case <-ctx.Done():
+		log.Print("shutting down")
+	case err := <-serverErrs:
+		log.Print("server stopped, shutting down: ", err)
+		failed = true
+	}
+	stop()
+
+	shutdown(config, httpServer, grpcServer, metricsServer, purger, cancelWorkers, &workers)
+	if err := conn.Close(); err != nil {
+		log.Print("cannot close database: ", err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Time used when SHUTDOWN_TIMEOUT is not set
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdown first reports the HTTP server not ready for SHUTDOWN_DELAY, so that load balancers
+// stop sending requests, then stops the servers and the background workers. The requests in
+// flight and the work in progress have SHUTDOWN_TIMEOUT to finish before they are cancelled.
+// A purge cancelled this way is rolled back and runs again at the next start.
+// The metrics server, nil when it is off, keeps serving until the other servers have stopped.
+func shutdown(config util.Config, httpServer *api.Server, grpcServer *gapi.Server, metricsServer *metrics.Server, purger *worker.Purger, cancelWorkers context.CancelFunc, workers *sync.WaitGroup) {
+	httpServer.Drain()
+	time.Sleep(config.ShutdownDelay)
+
+	timeout := config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var servers sync.WaitGroup
+	servers.Add(2)
+	go func() {
+		defer servers.Done()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Print("cannot shut down HTTP server gracefully: ", err)
+		}
+	}()
+	go func() {
+		defer servers.Done()
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			log.Print("cannot shut down gRPC server gracefully: ", err)
+		}
+	}()
+
+	purger.Stop()
+	servers.Wait()
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Print("cannot shut down metrics server gracefully: ", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Print("background workers did not stop in time, cancelling them")
+		cancelWorkers()
+		<-done
+	}
+}
+
+// runMigrate runs the migrate subcommand: up applies all the pending migrations or the
+// next n, down rolls back the last one or the last n, and version prints the current one
+func runMigrate(config util.Config, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		flag.Usage()
+		return fmt.Errorf("expected up, down or version")
+	}
+
+	steps := 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 || args[0] == "version" {
+			flag.Usage()
+			return fmt.Errorf("invalid number of migrations %q", args[1])
+		}
+		steps = n
+	}
+
+	m, err := migrator.New(config.MigrationURL, config.DBSource)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		if steps == 0 {
+			err = m.Up()
+		} else {
+			err = m.Steps(steps)
+		}
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		err = m.Steps(-steps)
+	case "version":
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database is dirty at version %d, fix it and force the version with the migrate CLI", version)
+	}
+	log.Printf("database is at version %d", version)
+	return nil
+}
+
+// runRestoreCompany runs the restore-company subcommand, bringing back a deleted company
+// with the bots deleted with it. Its users cannot authenticate while it is deleted, so
+// the APIs leave restoring it to operators.
+func runRestoreCompany(config util.Config, args []string) error {
+	if len(args) != 1 {
+		flag.Usage()
+		return fmt.Errorf("expected the id of the company")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		flag.Usage()
+		return fmt.Errorf("invalid company id %q", args[0])
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	company, err := service.New(config, db.NewSQLStore(conn), nil).RestoreCompany(context.Background(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no deleted company %d", id)
+	}
 	if err != nil {
-		log.Fatal("Cannot start server ", err)
+		return err
 	}
+	log.Printf("restored company %d %q", company.ID, company.Name)
+	return nil
 }
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// collectTimeout bounds the queries run when the metrics are scraped
+const collectTimeout = 5 * time.Second
+
+var activeConversationsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_conversations"),
+	"Sessions waiting for the answer to a question, by channel.",
+	[]string{"channel"}, nil,
+)
+
+// storeCollector reads the metrics kept in the database when they are scraped
+type storeCollector struct {
+	store db.Store
+}
+
+func (collector storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeConversationsDesc
+}
+
+func (collector storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	rows, err := collector.store.CountActiveConversations(ctx)
+	if err != nil {
+		log.Printf("cannot count active conversations: %v", err)
+		ch <- prometheus.NewInvalidMetric(activeConversationsDesc, err)
+		return
+	}
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(activeConversationsDesc, prometheus.GaugeValue, float64(row.Conversations), row.Channel)
+	}
+}
+
+// RegisterDB registers the metrics read from the database: the statistics of the
+// connection pool of conn and the metrics kept by the store
+func RegisterDB(conn *sql.DB, store db.Store) error {
+	err := prometheus.Register(collectors.NewDBStatsCollector(conn, namespace))
+	if err != nil {
+		return err
+	}
+	return prometheus.Register(storeCollector{store: store})
+}
@@ -0,0 +1,86 @@
+// Package metrics holds the Prometheus metrics of the application, served at /metrics by Server.
+// They are registered with the default registry once, however many servers are created.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "bot"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by the database to answer the queries of the store, by query.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query"})
+
+	messages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_total",
+		Help:      "Conversation messages, by channel and direction: in from users, out from bots.",
+	}, []string{"channel", "direction"})
+
+	conversationsStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "conversations_started_total",
+		Help:      "Conversations started, by bot.",
+	}, []string{"bot_id"})
+
+	// the completion rate of a bot is the rate of this counter over the rate of conversations_started_total
+	conversationsCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "conversations_completed_total",
+		Help:      "Conversations that reached their last question, by bot.",
+	}, []string{"bot_id"})
+)
+
+// ObserveHTTPRequest records a request served on a route, the path pattern it matched
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a query run by the store, it is a db.QueryObserver
+func ObserveQuery(name string, duration time.Duration) {
+	queryDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+// MessageReceived records a message from a user on a channel
+func MessageReceived(channel string) {
+	messages.WithLabelValues(channel, "in").Inc()
+}
+
+// MessageSent records a message from a bot on a channel
+func MessageSent(channel string) {
+	messages.WithLabelValues(channel, "out").Inc()
+}
+
+// ConversationStarted records a user starting a conversation with a bot
+func ConversationStarted(botID int64) {
+	conversationsStarted.WithLabelValues(strconv.FormatInt(botID, 10)).Inc()
+}
+
+// ConversationCompleted records a conversation with a bot reaching its last question
+func ConversationCompleted(botID int64) {
+	conversationsCompleted.WithLabelValues(strconv.FormatInt(botID, 10)).Inc()
+}
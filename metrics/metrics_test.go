@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHTTPRequest(t *testing.T) {
+	requests := httpRequests.WithLabelValues(http.MethodGet, "/bots/:id", "404")
+	before := testutil.ToFloat64(requests)
+
+	ObserveHTTPRequest(http.MethodGet, "/bots/:id", http.StatusNotFound, time.Millisecond)
+	require.Equal(t, before+1, testutil.ToFloat64(requests))
+}
+
+func TestConversationMetrics(t *testing.T) {
+	received := messages.WithLabelValues("sms", "in")
+	sent := messages.WithLabelValues("sms", "out")
+	started := conversationsStarted.WithLabelValues("7")
+	completed := conversationsCompleted.WithLabelValues("7")
+	receivedBefore, sentBefore := testutil.ToFloat64(received), testutil.ToFloat64(sent)
+	startedBefore, completedBefore := testutil.ToFloat64(started), testutil.ToFloat64(completed)
+
+	MessageReceived("sms")
+	MessageSent("sms")
+	MessageSent("sms")
+	require.Equal(t, receivedBefore+1, testutil.ToFloat64(received))
+	require.Equal(t, sentBefore+2, testutil.ToFloat64(sent))
+
+	ConversationStarted(7)
+	ConversationStarted(7)
+	ConversationCompleted(7)
+	require.Equal(t, startedBefore+2, testutil.ToFloat64(started))
+	require.Equal(t, completedBefore+1, testutil.ToFloat64(completed))
+}
+
+func TestStoreCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CountActiveConversations(gomock.Any()).
+		Times(1).
+		Return([]db.CountActiveConversationsRow{
+			{Channel: "sms", Conversations: 3},
+			{Channel: "whatsapp", Conversations: 5},
+		}, nil)
+
+	expected := `
+# HELP bot_active_conversations Sessions waiting for the answer to a question, by channel.
+# TYPE bot_active_conversations gauge
+bot_active_conversations{channel="sms"} 3
+bot_active_conversations{channel="whatsapp"} 5
+`
+	err := testutil.CollectAndCompare(storeCollector{store: store}, strings.NewReader(expected))
+	require.NoError(t, err)
+}
+
+func TestStoreCollectorError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CountActiveConversations(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(storeCollector{store: store}))
+
+	_, err := registry.Gather()
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,29 @@
+package otp
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Purposes a one time code can be issued for
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposePhoneVerification = "phone_verification"
+)
+
+const digits = "0123456789"
+
+// GenerateCode returns a random numeric code of the given length
+func GenerateCode(length int) (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(digits)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(digits[n.Int64()])
+	}
+	return sb.String(), nil
+}
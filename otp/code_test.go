@@ -0,0 +1,26 @@
+package otp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	require := require.New(t)
+
+	code, err := GenerateCode(6)
+	require.NoError(err)
+	require.Len(code, 6)
+	for _, c := range code {
+		require.True(c >= '0' && c <= '9')
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		code, err := GenerateCode(8)
+		require.NoError(err)
+		seen[code] = true
+	}
+	require.Greater(len(seen), 1)
+}
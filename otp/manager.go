@@ -0,0 +1,159 @@
+package otp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+)
+
+// Default values used when a Config field is left empty
+const (
+	DefaultLength         = 6
+	DefaultDuration       = 10 * time.Minute
+	DefaultMaxAttempts    = 5
+	DefaultResendCooldown = time.Minute
+)
+
+var (
+	ErrInvalidCode     = errors.New("code is invalid or has expired")
+	ErrTooManyAttempts = errors.New("too many attempts, request a new code")
+	ErrResendCooldown  = errors.New("a code was sent recently, try again later")
+)
+
+// Store is the part of db.Store the manager needs
+type Store interface {
+	CreateOtpCode(ctx context.Context, arg db.CreateOtpCodeParams) (db.OtpCode, error)
+	GetLatestOtpCode(ctx context.Context, arg db.GetLatestOtpCodeParams) (db.OtpCode, error)
+	IncrementOtpCodeAttempts(ctx context.Context, id int64) (db.OtpCode, error)
+}
+
+// Config controls how codes are issued and checked
+type Config struct {
+	Length         int
+	Duration       time.Duration
+	MaxAttempts    int32
+	ResendCooldown time.Duration
+}
+
+// Manager issues, delivers and checks one time codes.
+// Only the bcrypt hash of a code is stored.
+type Manager struct {
+	store  Store
+	sender Sender
+	config Config
+}
+
+// NewManager creates a new Manager, filling empty config fields with defaults
+func NewManager(store Store, sender Sender, config Config) *Manager {
+	if config.Length <= 0 {
+		config.Length = DefaultLength
+	}
+	if config.Duration <= 0 {
+		config.Duration = DefaultDuration
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.ResendCooldown <= 0 {
+		config.ResendCooldown = DefaultResendCooldown
+	}
+	return &Manager{
+		store:  store,
+		sender: sender,
+		config: config,
+	}
+}
+
+// Issue creates a new code for the user and sends it to the phone of the user.
+// It returns ErrResendCooldown when the previous code was issued too recently.
+func (manager *Manager) Issue(ctx context.Context, user db.User, purpose string) error {
+	latest, err := manager.store.GetLatestOtpCode(ctx, db.GetLatestOtpCodeParams{
+		UserID:  user.ID,
+		Purpose: purpose,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && time.Since(latest.CreatedAt) < manager.config.ResendCooldown {
+		return ErrResendCooldown
+	}
+
+	code, err := GenerateCode(manager.config.Length)
+	if err != nil {
+		return err
+	}
+
+	codeHash, err := util.HashPassword(code)
+	if err != nil {
+		return err
+	}
+
+	_, err = manager.store.CreateOtpCode(ctx, db.CreateOtpCodeParams{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(manager.config.Duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = manager.sender.Send(ctx, user.Phone, message(purpose, code))
+	if err != nil {
+		return fmt.Errorf("cannot send code: %w", err)
+	}
+	return nil
+}
+
+// Verify checks the code against the latest pending code of the user.
+// The matching code is returned so that the caller can consume it
+// in the same transaction as the change it authorizes.
+func (manager *Manager) Verify(ctx context.Context, userID int64, purpose string, code string) (db.OtpCode, error) {
+	otpCode, err := manager.store.GetLatestOtpCode(ctx, db.GetLatestOtpCodeParams{
+		UserID:  userID,
+		Purpose: purpose,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.OtpCode{}, ErrInvalidCode
+		}
+		return db.OtpCode{}, err
+	}
+
+	if time.Now().After(otpCode.ExpiresAt) {
+		return db.OtpCode{}, ErrInvalidCode
+	}
+	if otpCode.Attempts >= manager.config.MaxAttempts {
+		return db.OtpCode{}, ErrTooManyAttempts
+	}
+
+	// count the attempt before checking the code so that parallel guesses are limited too
+	otpCode, err = manager.store.IncrementOtpCodeAttempts(ctx, otpCode.ID)
+	if err != nil {
+		return db.OtpCode{}, err
+	}
+	if otpCode.Attempts > manager.config.MaxAttempts {
+		return db.OtpCode{}, ErrTooManyAttempts
+	}
+
+	err = util.CheckPassword(code, otpCode.CodeHash)
+	if err != nil {
+		return db.OtpCode{}, ErrInvalidCode
+	}
+	return otpCode, nil
+}
+
+func message(purpose string, code string) string {
+	switch purpose {
+	case PurposePasswordReset:
+		return fmt.Sprintf("Your password reset code is %s", code)
+	case PurposePhoneVerification:
+		return fmt.Sprintf("Your verification code is %s", code)
+	}
+	return fmt.Sprintf("Your code is %s", code)
+}
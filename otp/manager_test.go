@@ -0,0 +1,130 @@
+package otp
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	messages []string
+}
+
+func (sender *recordingSender) Send(ctx context.Context, phone string, message string) error {
+	sender.messages = append(sender.messages, message)
+	return nil
+}
+
+func TestManagerIssueAndVerify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingSender{}
+	manager := NewManager(store, sender, Config{Length: 4})
+	user := db.User{ID: 1, Phone: "+254700000000"}
+
+	var stored db.OtpCode
+	store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(db.OtpCode{}, sql.ErrNoRows)
+	store.EXPECT().
+		CreateOtpCode(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.CreateOtpCodeParams) (db.OtpCode, error) {
+			require.WithinDuration(t, time.Now().Add(DefaultDuration), arg.ExpiresAt, time.Second)
+			stored = db.OtpCode{ID: 7, UserID: arg.UserID, Purpose: arg.Purpose, CodeHash: arg.CodeHash, ExpiresAt: arg.ExpiresAt}
+			return stored, nil
+		})
+
+	require.NoError(t, manager.Issue(context.Background(), user, PurposePhoneVerification))
+	require.Len(t, sender.messages, 1)
+
+	code := sender.messages[0][len(sender.messages[0])-4:]
+	require.NotContains(t, stored.CodeHash, code)
+
+	store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(stored, nil)
+	store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Eq(stored.ID)).Times(1).Return(stored, nil)
+
+	otpCode, err := manager.Verify(context.Background(), user.ID, PurposePhoneVerification, code)
+	require.NoError(t, err)
+	require.Equal(t, stored.ID, otpCode.ID)
+}
+
+func TestManagerIssueCooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingSender{}
+	manager := NewManager(store, sender, Config{ResendCooldown: time.Minute})
+
+	store.EXPECT().
+		GetLatestOtpCode(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.OtpCode{CreatedAt: time.Now().Add(-30 * time.Second)}, nil)
+	store.EXPECT().CreateOtpCode(gomock.Any(), gomock.Any()).Times(0)
+
+	err := manager.Issue(context.Background(), db.User{ID: 1}, PurposePasswordReset)
+	require.ErrorIs(t, err, ErrResendCooldown)
+	require.Empty(t, sender.messages)
+}
+
+func TestManagerVerifyRejects(t *testing.T) {
+	codeHash, err := util.HashPassword("123456")
+	require.NoError(t, err)
+	pending := db.OtpCode{ID: 1, CodeHash: codeHash, ExpiresAt: time.Now().Add(time.Minute)}
+
+	expired := pending
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	exhausted := pending
+	exhausted.Attempts = DefaultMaxAttempts
+
+	testCases := []struct {
+		name    string
+		latest  db.OtpCode
+		findErr error
+		code    string
+		err     error
+	}{
+		{name: "NoCode", findErr: sql.ErrNoRows, code: "123456", err: ErrInvalidCode},
+		{name: "Expired", latest: expired, code: "123456", err: ErrInvalidCode},
+		{name: "TooManyAttempts", latest: exhausted, code: "123456", err: ErrTooManyAttempts},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(tc.latest, tc.findErr)
+			store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+
+			manager := NewManager(store, &recordingSender{}, Config{})
+			_, err := manager.Verify(context.Background(), 1, PurposePasswordReset, tc.code)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("WrongCode", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		store.EXPECT().GetLatestOtpCode(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+		store.EXPECT().IncrementOtpCodeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(pending, nil)
+
+		manager := NewManager(store, &recordingSender{}, Config{})
+		_, err := manager.Verify(context.Background(), 1, PurposePasswordReset, "654321")
+		require.ErrorIs(t, err, ErrInvalidCode)
+	})
+}
@@ -0,0 +1,107 @@
+package otp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+)
+
+// Sender delivers one time codes to a phone number
+type Sender interface {
+	// Send delivers the message to the phone number
+	Send(ctx context.Context, phone string, message string) error
+}
+
+// LogSender writes messages to the standard logger instead of delivering them.
+// It is only meant for local development.
+type LogSender struct{}
+
+// NewLogSender creates a new LogSender
+func NewLogSender() Sender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (sender *LogSender) Send(ctx context.Context, phone string, message string) error {
+	log.Printf("otp message to %s: %s", phone, message)
+	return nil
+}
+
+// SMSSender delivers messages through an HTTP SMS gateway.
+// The gateway receives a JSON body with the recipient, sender ID and message
+// and authenticates the request with a bearer API key.
+type SMSSender struct {
+	gatewayURL string
+	apiKey     string
+	senderID   string
+	client     *http.Client
+}
+
+// NewSMSSender creates a new SMSSender
+func NewSMSSender(gatewayURL string, apiKey string, senderID string) (Sender, error) {
+	if gatewayURL == "" {
+		return nil, fmt.Errorf("sms gateway url is required")
+	}
+	return &SMSSender{
+		gatewayURL: gatewayURL,
+		apiKey:     apiKey,
+		senderID:   senderID,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type smsRequest struct {
+	To      string `json:"to"`
+	From    string `json:"from,omitempty"`
+	Message string `json:"message"`
+}
+
+// Send posts the message to the gateway
+func (sender *SMSSender) Send(ctx context.Context, phone string, message string) error {
+	body, err := json.Marshal(smsRequest{
+		To:      phone,
+		From:    sender.senderID,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sender.gatewayURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if sender.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+sender.apiKey)
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// NewSenderFromConfig returns the sender selected by OTP_SENDER.
+// An empty value selects the log sender.
+func NewSenderFromConfig(config util.Config) (Sender, error) {
+	switch config.OTPSender {
+	case "", "log":
+		return NewLogSender(), nil
+	case "sms":
+		return NewSMSSender(config.SMSGatewayURL, config.SMSAPIKey, config.SMSSenderID)
+	}
+	return nil, fmt.Errorf("unknown otp sender %q", config.OTPSender)
+}
@@ -0,0 +1,58 @@
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMSSender(t *testing.T) {
+	var got smsRequest
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer gateway.Close()
+
+	sender, err := NewSMSSender(gateway.URL, "secret", "BOT")
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), "+254700000000", "Your code is 123456")
+	require.NoError(t, err)
+	require.Equal(t, smsRequest{To: "+254700000000", From: "BOT", Message: "Your code is 123456"}, got)
+}
+
+func TestSMSSenderGatewayError(t *testing.T) {
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer gateway.Close()
+
+	sender, err := NewSMSSender(gateway.URL, "", "")
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), "+254700000000", "Your code is 123456")
+	require.Error(t, err)
+}
+
+func TestNewSenderFromConfig(t *testing.T) {
+	sender, err := NewSenderFromConfig(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &LogSender{}, sender)
+
+	sender, err = NewSenderFromConfig(util.Config{OTPSender: "sms", SMSGatewayURL: "http://localhost"})
+	require.NoError(t, err)
+	require.IsType(t, &SMSSender{}, sender)
+
+	_, err = NewSenderFromConfig(util.Config{OTPSender: "sms"})
+	require.Error(t, err)
+
+	_, err = NewSenderFromConfig(util.Config{OTPSender: "pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package otp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// the RFC lists 8 digit codes, the 6 digit code is their last 6 digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		code, err := TOTPCode(rfc6238Secret, TOTPCounter(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, want, code, unix)
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret1, err := NewTOTPSecret()
+	require.NoError(t, err)
+	require.Len(t, secret1, 32)
+
+	secret2, err := NewTOTPSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret1, secret2)
+
+	_, err = TOTPCode(secret1, 1)
+	require.NoError(t, err)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	counter := TOTPCounter(now)
+
+	code, err := TOTPCode(secret, counter)
+	require.NoError(t, err)
+	got, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, counter, got)
+
+	// codes of the neighbouring periods are accepted for clock drift
+	previous, err := TOTPCode(secret, counter-1)
+	require.NoError(t, err)
+	got, ok = ValidateTOTP(secret, previous, now)
+	require.True(t, ok)
+	require.Equal(t, counter-1, got)
+
+	old, err := TOTPCode(secret, counter-3)
+	require.NoError(t, err)
+	if old != code && old != previous {
+		_, ok = ValidateTOTP(secret, old, now)
+		require.False(t, ok)
+	}
+
+	_, ok = ValidateTOTP(secret, "12345", now)
+	require.False(t, ok)
+	_, ok = ValidateTOTP("not base32!", code, now)
+	require.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(TOTPURI("Bot", "+254700000000", "SECRET"))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Bot:+254700000000", uri.Path)
+	require.Equal(t, "SECRET", uri.Query().Get("secret"))
+	require.Equal(t, "Bot", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, recoveryCodeLength+1)
+		require.Equal(t, byte('-'), code[recoveryCodeLength/2])
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	require.Equal(t, "abcdefghjk", NormalizeRecoveryCode("ABCDE-fghjk"))
+	require.Equal(t, "abcdefghjk", NormalizeRecoveryCode(" abcde fghjk "))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+)
+
+// AuditEntity is what an operation tells the audit log about the entity it changed.
+// Before and after are the API representations of the entity, nil when it did not
+// exist; they must never carry secrets.
+type AuditEntity struct {
+	ID     int64
+	Before any
+	After  any
+}
+
+// AuditEntry is a change made by a principal. The entity type is the part of the
+// action before the dot, as in bot.update.
+type AuditEntry struct {
+	Action    string
+	Entity    *AuditEntity
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// FieldChange is the value of a field before and after a change
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// RecordAudit appends the entry to the audit log of the company of the principal
+func (service *Service) RecordAudit(ctx context.Context, principal *token.Payload, entry AuditEntry) error {
+	entityType, _, _ := strings.Cut(entry.Action, ".")
+	arg := db.CreateAuditLogParams{
+		CompanyID:     principal.CompanyID,
+		ActorUserID:   sql.NullInt64{Int64: principal.UserID, Valid: principal.UserID != 0},
+		ActorApiKeyID: sql.NullInt64{Int64: principal.APIKeyID, Valid: principal.APIKeyID != 0},
+		ActorName:     principal.Name,
+		Action:        entry.Action,
+		EntityType:    entityType,
+		Changes:       json.RawMessage(`{}`),
+		Ip:            entry.IP,
+		UserAgent:     entry.UserAgent,
+		RequestID:     entry.RequestID,
+	}
+
+	if entry.Entity != nil {
+		arg.EntityID = sql.NullInt64{Int64: entry.Entity.ID, Valid: true}
+
+		changes, err := auditChanges(entry.Entity.Before, entry.Entity.After)
+		if err != nil {
+			return err
+		}
+		arg.Changes = changes
+	}
+
+	_, err := service.store.CreateAuditLog(ctx, arg)
+	return err
+}
+
+// RecordChange runs change and appends the entry it returns to the audit log of the company
+// of the principal in one transaction, so that every change committed is in the log. The
+// calls change makes to the store with the context it is given join the transaction. An
+// error of change, or of the log, rolls the change back and is returned as it is.
+func (service *Service) RecordChange(ctx context.Context, principal *token.Payload, change func(ctx context.Context) (AuditEntry, error)) error {
+	return service.store.ExecTx(ctx, func(ctx context.Context) error {
+		entry, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return service.RecordAudit(ctx, principal, entry)
+	})
+}
+
+// auditChanges is the diff of two API representations of an entity, keyed by the fields that changed
+func auditChanges(before, after any) (json.RawMessage, error) {
+	from, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for field, value := range from {
+		if !reflect.DeepEqual(value, to[field]) {
+			changes[field] = FieldChange{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, ok := from[field]; !ok && value != nil {
+			changes[field] = FieldChange{To: value}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// auditFields decodes the JSON representation of an entity into its fields
+func auditFields(entity any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if entity == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditChanges(t *testing.T) {
+	company := db.Company{
+		ID:    util.RandInt(1, 1000),
+		Name:  util.RandomString(6),
+		Email: util.RandomEmail(),
+		Phone: util.RandomPhoneNumber(),
+	}
+	renamed := company
+	renamed.Name = company.Name + "x"
+
+	changes, err := auditChanges(company, renamed)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": {"from": "`+company.Name+`", "to": "`+renamed.Name+`"}}`, string(changes))
+
+	changes, err = auditChanges(company, company)
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(changes))
+
+	changes, err = auditChanges(nil, company)
+	require.NoError(t, err)
+	var created map[string]FieldChange
+	require.NoError(t, json.Unmarshal(changes, &created))
+	require.Equal(t, company.Email, created["email"].To)
+	require.Nil(t, created["email"].From)
+
+	changes, err = auditChanges(company, nil)
+	require.NoError(t, err)
+	var deleted map[string]FieldChange
+	require.NoError(t, json.Unmarshal(changes, &deleted))
+	require.Equal(t, company.Email, deleted["email"].From)
+	require.Nil(t, deleted["email"].To)
+}
+
+func TestRecordAudit(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	principal := randomPrincipal(companyID)
+	principal.Name = util.RandomString(6)
+	bot := db.Bot{ID: util.RandInt(1, 1000), Title: util.RandomTitle(), CompanyID: companyID}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Eq(db.CreateAuditLogParams{
+			CompanyID:   companyID,
+			ActorUserID: sql.NullInt64{Int64: principal.UserID, Valid: true},
+			ActorName:   principal.Name,
+			Action:      "bot.delete",
+			EntityType:  "bot",
+			EntityID:    sql.NullInt64{Int64: bot.ID, Valid: true},
+			Changes:     mustAuditChanges(t, bot, nil),
+			Ip:          "192.0.2.1",
+			RequestID:   "audit-request",
+		})).
+		Times(1).
+		Return(db.AuditLog{}, nil)
+
+	service := newTestService(t, store)
+	err := service.RecordAudit(context.Background(), principal, AuditEntry{
+		Action:    "bot.delete",
+		Entity:    &AuditEntity{ID: bot.ID, Before: bot},
+		IP:        "192.0.2.1",
+		RequestID: "audit-request",
+	})
+	require.NoError(t, err)
+}
+
+func mustAuditChanges(t *testing.T, before, after any) json.RawMessage {
+	changes, err := auditChanges(before, after)
+	require.NoError(t, err)
+	return changes
+}
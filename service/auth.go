@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// Types of credentials accepted by Authenticate
+const (
+	AuthorizationTypeBearer = "bearer"
+	AuthorizationTypeAPIKey = "apikey"
+)
+
+const (
+	apiKeyMarker     = "bot_"
+	apiKeyPrefixSize = 6
+	apiKeySecretSize = 32
+)
+
+// Errors refusing the credentials of a request
+var (
+	ErrInvalidAPIKey   = errors.New("api key is invalid")
+	ErrRevokedAPIKey   = errors.New("api key has been revoked")
+	ErrExpiredAPIKey   = errors.New("api key has expired")
+	ErrMFAPending      = errors.New("two factor authentication has not been completed")
+	ErrUserGone        = errors.New("user no longer exists")
+	ErrPasswordChanged = errors.New("token was issued before the password was changed")
+	ErrUserDeactivated = errors.New("user has been deactivated")
+)
+
+// authError refuses the credentials of a request, as opposed to a failure looking them up
+type authError struct {
+	err error
+}
+
+func (authErr authError) Error() string {
+	return authErr.err.Error()
+}
+
+func (authErr authError) Unwrap() error {
+	return authErr.err
+}
+
+// IsUnauthenticated reports whether err was returned by Authenticate refusing the credentials
+func IsUnauthenticated(err error) bool {
+	var authErr authError
+	return errors.As(err, &authErr)
+}
+
+// RoleScopes returns the scopes a user holding the role is issued at login.
+// Viewers get read only access for dashboards plus their own password and second
+// factor, every other role gets everything.
+func RoleScopes(role string) []string {
+	switch role {
+	case util.OwnerRole, util.AdminRole, util.MemberRole, util.OperatorRole:
+		return token.AllScopes
+	case util.ViewerRole:
+		return append(append([]string{}, token.ReadScopes...), token.ScopeAccountWrite)
+	}
+	return nil
+}
+
+// Authenticate returns the principal of the credentials of a request, given as
+// "bearer <access token>" or "apikey <key>"
+func (service *Service) Authenticate(ctx context.Context, authorization string) (*token.Payload, error) {
+	if len(authorization) == 0 {
+		return nil, authError{errors.New("authorization is not provided")}
+	}
+
+	fields := strings.Fields(authorization)
+	if len(fields) < 2 {
+		return nil, authError{errors.New("invalid authorization format")}
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	switch authorizationType {
+	case AuthorizationTypeAPIKey:
+		return service.verifyAPIKey(ctx, fields[1])
+	case AuthorizationTypeBearer:
+		return service.verifyAccessToken(ctx, fields[1])
+	}
+	return nil, authError{fmt.Errorf("unsupported authorization type %s", authorizationType)}
+}
+
+// verifyAccessToken checks the token and that its user may still use it
+func (service *Service) verifyAccessToken(ctx context.Context, accessToken string) (*token.Payload, error) {
+	payload, err := service.tokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		return nil, authError{err}
+	}
+
+	if payload.HasScope(token.ScopeMFAPending) {
+		return nil, authError{ErrMFAPending}
+	}
+
+	user, err := service.store.GetUserByID(ctx, payload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, authError{ErrUserGone}
+		}
+		return nil, err
+	}
+
+	if payload.IssuedAt.Before(user.PasswordChangedAt) {
+		return nil, authError{ErrPasswordChanged}
+	}
+
+	if user.DeactivatedAt.Valid {
+		return nil, authError{ErrUserDeactivated}
+	}
+
+	// the role is the current one of the user rather than the one the token was issued
+	// with, so that demoting a user takes effect at once. Tokens issued before scopes
+	// existed carry none and get every scope of the role, other tokens keep to their own.
+	scopes := RoleScopes(user.Role)
+	if payload.Scopes != nil {
+		scopes = intersectScopes(payload.Scopes, scopes)
+	}
+	payload.Role = user.Role
+	payload.Scopes = scopes
+	return payload, nil
+}
+
+// intersectScopes returns the scopes found in both lists, in the order of the first
+func intersectScopes(scopes []string, allowed []string) []string {
+	intersection := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		for _, other := range allowed {
+			if scope == other {
+				intersection = append(intersection, scope)
+				break
+			}
+		}
+	}
+	return intersection
+}
+
+// NewAPIKey generates a key formatted as bot_<prefix>_<secret>.
+// The prefix is stored in clear text to find the key, the secret only as a hash.
+func NewAPIKey() (key string, prefix string, secret string, err error) {
+	b := make([]byte, apiKeyPrefixSize)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err = util.NewSecret(apiKeySecretSize)
+	if err != nil {
+		return
+	}
+
+	key = apiKeyMarker + prefix + "_" + secret
+	return
+}
+
+// SplitAPIKey returns the prefix and the secret of a key made by NewAPIKey
+func SplitAPIKey(key string) (prefix string, secret string, ok bool) {
+	if !strings.HasPrefix(key, apiKeyMarker) {
+		return "", "", false
+	}
+	key = key[len(apiKeyMarker):]
+
+	prefixLength := hex.EncodedLen(apiKeyPrefixSize)
+	if len(key) <= prefixLength+1 || key[prefixLength] != '_' {
+		return "", "", false
+	}
+	return key[:prefixLength], key[prefixLength+1:], true
+}
+
+// verifyAPIKey looks up the key and returns the principal it stands for
+func (service *Service) verifyAPIKey(ctx context.Context, key string) (*token.Payload, error) {
+	prefix, secret, ok := SplitAPIKey(key)
+	if !ok {
+		return nil, authError{ErrInvalidAPIKey}
+	}
+
+	apiKey, err := service.store.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, authError{ErrInvalidAPIKey}
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(util.HashSecret(secret)), []byte(apiKey.SecretHash)) != 1 {
+		return nil, authError{ErrInvalidAPIKey}
+	}
+	if apiKey.RevokedAt.Valid {
+		return nil, authError{ErrRevokedAPIKey}
+	}
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		return nil, authError{ErrExpiredAPIKey}
+	}
+
+	// last use is informative only, so failing to record it does not fail the request
+	if err := service.store.TouchApiKey(ctx, apiKey.ID); err != nil {
+		log.Printf("cannot record use of api key %d: %v", apiKey.ID, err)
+	}
+
+	return newAPIKeyPayload(apiKey), nil
+}
+
+// newAPIKeyPayload creates the principal of a request authenticated with the key
+func newAPIKeyPayload(apiKey db.ApiKey) *token.Payload {
+	payload := &token.Payload{
+		Name:      apiKey.Name,
+		CompanyID: apiKey.CompanyID,
+		Role:      util.APIKeyRole,
+		Scopes:    apiKey.Scopes,
+		APIKeyID:  apiKey.ID,
+		IssuedAt:  apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt.Valid {
+		payload.ExpiredAt = apiKey.ExpiresAt.Time
+	}
+	return payload
+}
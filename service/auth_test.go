@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAPIKey(t *testing.T) {
+	key, prefix, secret, err := NewAPIKey()
+	require.NoError(t, err)
+
+	gotPrefix, gotSecret, ok := SplitAPIKey(key)
+	require.True(t, ok)
+	require.Equal(t, prefix, gotPrefix)
+	require.Equal(t, secret, gotSecret)
+
+	for _, invalid := range []string{"", "bot_", "bot_" + prefix, "bot_" + prefix + "x" + secret, "xyz_" + prefix + "_" + secret} {
+		_, _, ok := SplitAPIKey(invalid)
+		require.False(t, ok, invalid)
+	}
+}
+
+func TestRoleScopes(t *testing.T) {
+	for _, role := range []string{util.OwnerRole, util.AdminRole, util.MemberRole} {
+		require.Equal(t, token.AllScopes, RoleScopes(role), role)
+	}
+	require.Equal(t, append(token.ReadScopes, token.ScopeAccountWrite), RoleScopes(util.ViewerRole))
+	require.Empty(t, RoleScopes(util.APIKeyRole))
+}
+
+func TestAuthenticate(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	user := db.User{
+		ID:        util.RandInt(1, 1000),
+		Name:      util.RandomString(6),
+		Phone:     util.RandomPhoneNumber(),
+		CompanyID: companyID,
+		Role:      util.MemberRole,
+	}
+
+	key, prefix, secret, err := NewAPIKey()
+	require.NoError(t, err)
+	apiKey := db.ApiKey{
+		ID:         util.RandInt(1, 1000),
+		CompanyID:  companyID,
+		Prefix:     prefix,
+		SecretHash: util.HashSecret(secret),
+		Scopes:     []string{token.ScopeBotsRead},
+		CreatedAt:  time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		authorization func(t *testing.T, service *Service) string
+		buildStubs    func(store *mockdb.MockStore)
+		check         func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name: "Bearer",
+			authorization: func(t *testing.T, service *Service) string {
+				accessToken, _, err := service.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, nil, time.Minute)
+				require.NoError(t, err)
+				return "Bearer " + accessToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(user, nil)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.ID, payload.UserID)
+				require.Equal(t, RoleScopes(user.Role), payload.Scopes)
+			},
+		},
+		{
+			name: "DemotedUser",
+			authorization: func(t *testing.T, service *Service) string {
+				accessToken, _, err := service.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, util.OwnerRole, token.AllScopes, time.Minute)
+				require.NoError(t, err)
+				return "Bearer " + accessToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				viewer := user
+				viewer.Role = util.ViewerRole
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(viewer, nil)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.ViewerRole, payload.Role)
+				require.ElementsMatch(t, RoleScopes(util.ViewerRole), payload.Scopes)
+				require.False(t, payload.HasScope(token.ScopeBotsWrite))
+			},
+		},
+		{
+			name: "NarrowedToken",
+			authorization: func(t *testing.T, service *Service) string {
+				scopes := []string{token.ScopeBotsRead, token.ScopeBotsWrite}
+				accessToken, _, err := service.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, scopes, time.Minute)
+				require.NoError(t, err)
+				return "Bearer " + accessToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				viewer := user
+				viewer.Role = util.ViewerRole
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(viewer, nil)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{token.ScopeBotsRead}, payload.Scopes)
+			},
+		},
+		{
+			name: "APIKey",
+			authorization: func(t *testing.T, service *Service) string {
+				return fmt.Sprintf("%s %s", AuthorizationTypeAPIKey, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(apiKey, nil)
+				store.EXPECT().TouchApiKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, apiKey.ID, payload.APIKeyID)
+				require.Equal(t, companyID, payload.CompanyID)
+				require.Equal(t, apiKey.Scopes, payload.Scopes)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			authorization: func(t *testing.T, service *Service) string {
+				return ""
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.True(t, IsUnauthenticated(err))
+			},
+		},
+		{
+			name: "UnsupportedType",
+			authorization: func(t *testing.T, service *Service) string {
+				return "Basic dXNlcjpwYXNz"
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.True(t, IsUnauthenticated(err))
+			},
+		},
+		{
+			name: "MFAPending",
+			authorization: func(t *testing.T, service *Service) string {
+				mfaToken, _, err := service.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, []string{token.ScopeMFAPending}, time.Minute)
+				require.NoError(t, err)
+				return "Bearer " + mfaToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.True(t, IsUnauthenticated(err))
+				require.ErrorIs(t, err, ErrMFAPending)
+			},
+		},
+		{
+			name: "RevokedAPIKey",
+			authorization: func(t *testing.T, service *Service) string {
+				return fmt.Sprintf("%s %s", AuthorizationTypeAPIKey, key)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetApiKeyByPrefix(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(revoked, nil)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.True(t, IsUnauthenticated(err))
+				require.ErrorIs(t, err, ErrRevokedAPIKey)
+			},
+		},
+		{
+			name: "LookupFailure",
+			authorization: func(t *testing.T, service *Service) string {
+				accessToken, _, err := service.tokenMaker.CreateToken(user.Phone, user.ID, user.Name, user.CompanyID, user.Role, nil, time.Minute)
+				require.NoError(t, err)
+				return "Bearer " + accessToken
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.False(t, IsUnauthenticated(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			service := newTestService(t, store)
+			payload, err := service.Authenticate(context.Background(), tc.authorization(t, service))
+			tc.check(t, payload, err)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+)
+
+// CreateBot creates a bot for the company of the principal
+func (service *Service) CreateBot(ctx context.Context, principal *token.Payload, arg db.CreateBotParams) (db.Bot, error) {
+	company, err := service.GetCompany(ctx, principal, arg.CompanyID)
+	if err != nil {
+		return db.Bot{}, err
+	}
+
+	arg.CompanyID = company.ID
+	return service.store.CreateBot(ctx, arg)
+}
+
+// GetBot returns a bot of the company of the principal
+func (service *Service) GetBot(ctx context.Context, principal *token.Payload, id int64) (db.Bot, error) {
+	bot, err := service.store.GetBot(ctx, id)
+	if err != nil {
+		return db.Bot{}, err
+	}
+	if bot.CompanyID != principal.CompanyID {
+		return db.Bot{}, ErrNotOwner
+	}
+	return bot, nil
+}
+
+// UpdateBot changes the fields set in arg on a bot of the company of the principal,
+// which cannot move it to another company. It returns the bot before and after the change.
+func (service *Service) UpdateBot(ctx context.Context, principal *token.Payload, arg db.UpdateBotParams, ifMatch IfMatch) (before db.Bot, after db.Bot, err error) {
+	if arg.CompanyID.Valid && arg.CompanyID.Int64 != principal.CompanyID {
+		return before, after, ErrNotOwner
+	}
+
+	before, err = service.GetBot(ctx, principal, arg.ID)
+	if err != nil {
+		return
+	}
+	if !ifMatch.Matches(before.UpdatedAt) {
+		return before, after, ErrVersionMismatch
+	}
+	arg.UpdatedAt = ifMatch.version(before.UpdatedAt)
+
+	after, err = service.store.UpdateBot(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the bot existed a moment ago, a conditional update missing it lost a race
+		err = ErrVersionMismatch
+	}
+	return
+}
+
+// DeleteBot soft deletes a bot of the company of the principal with its questions.
+// They can be restored until they are purged. It returns the bot as it was.
+func (service *Service) DeleteBot(ctx context.Context, principal *token.Payload, id int64, ifMatch IfMatch) (db.Bot, error) {
+	bot, err := service.GetBot(ctx, principal, id)
+	if err != nil {
+		return db.Bot{}, err
+	}
+	if !ifMatch.Matches(bot.UpdatedAt) {
+		return db.Bot{}, ErrVersionMismatch
+	}
+
+	arg := db.SoftDeleteBotParams{
+		ID:        id,
+		UpdatedAt: ifMatch.version(bot.UpdatedAt),
+	}
+	_, err = service.store.DeleteBotTx(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the bot existed a moment ago, a conditional deletion missing it lost a race
+		return db.Bot{}, ErrVersionMismatch
+	}
+	if err != nil {
+		return db.Bot{}, err
+	}
+	return bot, nil
+}
+
+// RestoreBot brings back a deleted bot of the company of the principal with its questions
+func (service *Service) RestoreBot(ctx context.Context, principal *token.Payload, id int64) (db.Bot, error) {
+	bot, err := service.store.GetDeletedBot(ctx, id)
+	if err != nil {
+		return db.Bot{}, err
+	}
+	if bot.CompanyID != principal.CompanyID {
+		return db.Bot{}, ErrNotOwner
+	}
+
+	return service.store.RestoreBotTx(ctx, id)
+}
+
+// ListBots lists the bots in order of id, starting after afterID. A companyID
+// other than 0 lists the bots of that company only, which must be the company of
+// the principal unless it is an operator.
+func (service *Service) ListBots(ctx context.Context, principal *token.Payload, companyID int64, afterID int64, limit int32) ([]db.Bot, error) {
+	return service.SearchBots(ctx, principal, db.SearchBotsParams{
+		CompanyID: sql.NullInt64{Int64: companyID, Valid: companyID != 0},
+		AfterID:   sql.NullInt64{Int64: afterID, Valid: afterID != 0},
+		Limit:     limit,
+	})
+}
+
+// SearchBots lists the bots matching the filters of arg in its sort order, those of
+// the company of the principal unless it is an operator
+func (service *Service) SearchBots(ctx context.Context, principal *token.Payload, arg db.SearchBotsParams) ([]db.Bot, error) {
+	var err error
+	arg.CompanyID, err = tenantScope(principal, arg.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	return service.store.SearchBots(ctx, arg)
+}
+
+// CountBots counts the bots matching the filters of arg, as SearchBots lists them
+func (service *Service) CountBots(ctx context.Context, principal *token.Payload, arg db.CountBotsParams) (int64, error) {
+	var err error
+	arg.CompanyID, err = tenantScope(principal, arg.CompanyID)
+	if err != nil {
+		return 0, err
+	}
+	return service.store.CountBots(ctx, arg)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateBot(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	principal := randomPrincipal(companyID)
+	bot := db.Bot{
+		ID:        util.RandInt(1, 1000),
+		Title:     util.RandomTitle(),
+		CompanyID: companyID,
+		UpdatedAt: time.Date(2026, 10, 19, 10, 0, 0, 123456000, time.UTC),
+	}
+	title := sql.NullString{String: util.RandomTitle(), Valid: true}
+
+	testCases := []struct {
+		name       string
+		arg        db.UpdateBotParams
+		ifMatch    IfMatch
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, before db.Bot, after db.Bot, err error)
+	}{
+		{
+			name: "OK",
+			arg:  db.UpdateBotParams{ID: bot.ID, Title: title},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().
+					UpdateBot(gomock.Any(), gomock.Eq(db.UpdateBotParams{ID: bot.ID, Title: title})).
+					Times(1).
+					Return(db.Bot{ID: bot.ID, Title: title.String, CompanyID: companyID}, nil)
+			},
+			check: func(t *testing.T, before db.Bot, after db.Bot, err error) {
+				require.NoError(t, err)
+				require.Equal(t, bot, before)
+				require.Equal(t, title.String, after.Title)
+			},
+		},
+		{
+			name:    "IfMatch",
+			arg:     db.UpdateBotParams{ID: bot.ID, Title: title},
+			ifMatch: IfMatch{ETag(bot.UpdatedAt)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().
+					UpdateBot(gomock.Any(), gomock.Eq(db.UpdateBotParams{
+						ID:        bot.ID,
+						Title:     title,
+						UpdatedAt: sql.NullTime{Time: bot.UpdatedAt, Valid: true},
+					})).
+					Times(1).
+					Return(db.Bot{}, sql.ErrNoRows)
+			},
+			check: func(t *testing.T, before db.Bot, after db.Bot, err error) {
+				require.ErrorIs(t, err, ErrVersionMismatch)
+			},
+		},
+		{
+			name:    "StaleIfMatch",
+			arg:     db.UpdateBotParams{ID: bot.ID, Title: title},
+			ifMatch: IfMatch{ETag(bot.UpdatedAt.Add(-time.Second))},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.Bot, after db.Bot, err error) {
+				require.ErrorIs(t, err, ErrVersionMismatch)
+			},
+		},
+		{
+			name: "OtherCompany",
+			arg:  db.UpdateBotParams{ID: bot.ID, Title: title},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := bot
+				other.CompanyID = companyID + 1
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(other, nil)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.Bot, after db.Bot, err error) {
+				require.ErrorIs(t, err, ErrNotOwner)
+			},
+		},
+		{
+			name: "MoveToOtherCompany",
+			arg:  db.UpdateBotParams{ID: bot.ID, CompanyID: sql.NullInt64{Int64: companyID + 1, Valid: true}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateBot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.Bot, after db.Bot, err error) {
+				require.ErrorIs(t, err, ErrNotOwner)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			service := newTestService(t, store)
+			before, after, err := service.UpdateBot(context.Background(), principal, tc.arg, tc.ifMatch)
+			tc.check(t, before, after, err)
+		})
+	}
+}
+
+func TestSearchBotsTenantScope(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	own := sql.NullInt64{Int64: companyID, Valid: true}
+	other := sql.NullInt64{Int64: companyID + 1, Valid: true}
+	operator := randomPrincipal(companyID)
+	operator.Role = util.OperatorRole
+
+	testCases := []struct {
+		name      string
+		principal *token.Payload
+		companyID sql.NullInt64
+		scope     sql.NullInt64
+		err       error
+	}{
+		{name: "AnyCompany", principal: randomPrincipal(companyID), scope: own},
+		{name: "OwnCompany", principal: randomPrincipal(companyID), companyID: own, scope: own},
+		{name: "OtherCompany", principal: randomPrincipal(companyID), companyID: other, err: ErrNotOwner},
+		{name: "OperatorAnyCompany", principal: operator},
+		{name: "OperatorOtherCompany", principal: operator, companyID: other, scope: other},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			times := 1
+			if tc.err != nil {
+				times = 0
+			}
+			store.EXPECT().
+				SearchBots(gomock.Any(), gomock.Eq(db.SearchBotsParams{CompanyID: tc.scope, Limit: 10})).
+				Times(times).
+				Return([]db.Bot{}, nil)
+			store.EXPECT().
+				CountBots(gomock.Any(), gomock.Eq(db.CountBotsParams{CompanyID: tc.scope})).
+				Times(times).
+				Return(int64(0), nil)
+
+			service := newTestService(t, store)
+			_, err := service.SearchBots(context.Background(), tc.principal, db.SearchBotsParams{CompanyID: tc.companyID, Limit: 10})
+			require.ErrorIs(t, err, tc.err)
+			_, err = service.CountBots(context.Background(), tc.principal, db.CountBotsParams{CompanyID: tc.companyID})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
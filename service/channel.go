@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+)
+
+// CreateChannel creates a channel, its name must be free. Channels are shared by
+// every company, so they are managed by any principal holding the channel scopes.
+func (service *Service) CreateChannel(ctx context.Context, name string) (db.Channel, error) {
+	return service.store.CreateChannel(ctx, name)
+}
+
+// GetChannel returns the channel with the name
+func (service *Service) GetChannel(ctx context.Context, name string) (db.Channel, error) {
+	return service.store.GetChannel(ctx, name)
+}
+
+// UpdateChannel changes the fields set in arg on a channel. It returns the channel
+// before and after the change.
+func (service *Service) UpdateChannel(ctx context.Context, arg db.UpdateChannelParams) (before db.Channel, after db.Channel, err error) {
+	before, err = service.store.GetChannelByID(ctx, arg.ID)
+	if err != nil {
+		return
+	}
+
+	after, err = service.store.UpdateChannel(ctx, arg)
+	return
+}
+
+// DeleteChannel deletes a channel and returns it as it was
+func (service *Service) DeleteChannel(ctx context.Context, id int32) (db.Channel, error) {
+	return service.store.DeleteChannel(ctx, id)
+}
+
+// ListChannels lists the channels in order of id, starting after afterID
+func (service *Service) ListChannels(ctx context.Context, afterID int32, limit int32) ([]db.Channel, error) {
+	return service.store.ListChannels(ctx, db.ListChannelsParams{
+		AfterID: afterID,
+		Limit:   limit,
+	})
+}
+
+// CountChannels counts all the channels
+func (service *Service) CountChannels(ctx context.Context) (int64, error) {
+	return service.store.CountChannels(ctx)
+}
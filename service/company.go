@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+)
+
+// CreateCompany creates a company
+func (service *Service) CreateCompany(ctx context.Context, arg db.CreateCompanyParams) (db.Company, error) {
+	return service.store.CreateCompany(ctx, arg)
+}
+
+// GetCompany returns the company of the principal
+func (service *Service) GetCompany(ctx context.Context, principal *token.Payload, id int64) (db.Company, error) {
+	company, err := service.store.GetCompanyByID(ctx, id)
+	if err != nil {
+		return db.Company{}, err
+	}
+	if company.ID != principal.CompanyID {
+		return db.Company{}, ErrNotOwner
+	}
+	return company, nil
+}
+
+// GetCompanyByEmail is GetCompany looking the company up by its email
+func (service *Service) GetCompanyByEmail(ctx context.Context, principal *token.Payload, email string) (db.Company, error) {
+	company, err := service.store.GetCompanyByEmail(ctx, email)
+	if err != nil {
+		return db.Company{}, err
+	}
+	if company.ID != principal.CompanyID {
+		return db.Company{}, ErrNotOwner
+	}
+	return company, nil
+}
+
+// UpdateCompany changes the fields set in arg on the company of the principal, which must
+// be one of its owners or admins. It returns the company before and after the change.
+func (service *Service) UpdateCompany(ctx context.Context, principal *token.Payload, arg db.UpdateCompanyParams, ifMatch IfMatch) (before db.Company, after db.Company, err error) {
+	before, err = service.GetCompany(ctx, principal, arg.ID)
+	if err != nil {
+		return
+	}
+	if !isCompanyAdmin(principal.Role) {
+		return before, after, ErrNotAdmin
+	}
+	if !ifMatch.Matches(before.UpdatedAt) {
+		return before, after, ErrVersionMismatch
+	}
+	arg.UpdatedAt = ifMatch.version(before.UpdatedAt)
+
+	after, err = service.store.UpdateCompany(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the company existed a moment ago, a conditional update missing it lost a race
+		err = ErrVersionMismatch
+	}
+	return
+}
+
+// DeleteCompany soft deletes the company of the principal, which must be one of its owners
+// or admins, with its bots. Its users cannot log in until the company is restored or purged.
+// It returns the company as it was.
+func (service *Service) DeleteCompany(ctx context.Context, principal *token.Payload, id int64, ifMatch IfMatch) (db.Company, error) {
+	company, err := service.GetCompany(ctx, principal, id)
+	if err != nil {
+		return db.Company{}, err
+	}
+	if !isCompanyAdmin(principal.Role) {
+		return db.Company{}, ErrNotAdmin
+	}
+	if !ifMatch.Matches(company.UpdatedAt) {
+		return db.Company{}, ErrVersionMismatch
+	}
+
+	arg := db.SoftDeleteCompanyParams{
+		ID:        id,
+		UpdatedAt: ifMatch.version(company.UpdatedAt),
+	}
+	company, err = service.store.DeleteCompanyTx(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the company existed a moment ago, a conditional deletion missing it lost a race
+		return db.Company{}, ErrVersionMismatch
+	}
+	return company, err
+}
+
+// Name of the actor of the changes made by operators in the audit log
+const operatorName = "operator"
+
+// RestoreCompany brings back a deleted company with the bots deleted with it. The users
+// and API keys of a deleted company cannot authenticate, so restoring it is left to
+// operators: it is not offered by the APIs and the audit log names no user as the actor.
+func (service *Service) RestoreCompany(ctx context.Context, id int64) (db.Company, error) {
+	var company db.Company
+	operator := &token.Payload{CompanyID: id, Name: operatorName}
+	err := service.RecordChange(ctx, operator, func(ctx context.Context) (AuditEntry, error) {
+		var err error
+		company, err = service.store.RestoreCompanyTx(ctx, id)
+		if err != nil {
+			return AuditEntry{}, err
+		}
+		return AuditEntry{
+			Action: "company.restore",
+			Entity: &AuditEntity{ID: company.ID, After: company},
+		}, nil
+	})
+	if err != nil {
+		return db.Company{}, err
+	}
+	return company, nil
+}
+
+// ListCompanies lists the companies in order of id, starting after afterID. Users other
+// than operators only see their own company.
+func (service *Service) ListCompanies(ctx context.Context, principal *token.Payload, afterID int64, limit int32) ([]db.Company, error) {
+	id, err := tenantScope(principal, sql.NullInt64{})
+	if err != nil {
+		return nil, err
+	}
+	return service.store.ListCompanies(ctx, db.ListCompaniesParams{
+		ID:      id,
+		AfterID: afterID,
+		Limit:   limit,
+	})
+}
+
+// SearchCompanies lists the companies matching the filters of arg in its sort order,
+// only the company of the principal unless it is an operator
+func (service *Service) SearchCompanies(ctx context.Context, principal *token.Payload, arg db.SearchCompaniesParams) ([]db.Company, error) {
+	var err error
+	arg.ID, err = tenantScope(principal, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	return service.store.SearchCompanies(ctx, arg)
+}
+
+// CountCompanies counts the companies matching the filters of arg, as SearchCompanies lists them
+func (service *Service) CountCompanies(ctx context.Context, principal *token.Payload, arg db.CountCompaniesParams) (int64, error) {
+	var err error
+	arg.ID, err = tenantScope(principal, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return service.store.CountCompanies(ctx, arg)
+}
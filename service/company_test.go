@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreCompany(t *testing.T) {
+	company := db.Company{ID: util.RandInt(1, 100), Name: util.RandomString(6)}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, restored db.Company, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RestoreCompanyTx(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, company.ID, arg.CompanyID)
+						require.Equal(t, "company.restore", arg.Action)
+						require.Equal(t, operatorName, arg.ActorName)
+						require.False(t, arg.ActorUserID.Valid)
+						require.False(t, arg.ActorApiKeyID.Valid)
+						return db.AuditLog{}, nil
+					})
+			},
+			check: func(t *testing.T, restored db.Company, err error) {
+				require.NoError(t, err)
+				require.Equal(t, company, restored)
+			},
+		},
+		{
+			name: "NotDeleted",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RestoreCompanyTx(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(db.Company{}, sql.ErrNoRows)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, restored db.Company, err error) {
+				require.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			allowTx(store)
+
+			service := newTestService(t, store)
+			restored, err := service.RestoreCompany(context.Background(), company.ID)
+			tc.check(t, restored, err)
+		})
+	}
+}
+
+func TestListCompaniesTenantScope(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	operator := randomPrincipal(companyID)
+	operator.Role = util.OperatorRole
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	own := db.ListCompaniesParams{ID: sql.NullInt64{Int64: companyID, Valid: true}, Limit: 10}
+	store.EXPECT().ListCompanies(gomock.Any(), gomock.Eq(own)).Times(1).Return([]db.Company{{ID: companyID}}, nil)
+	store.EXPECT().ListCompanies(gomock.Any(), gomock.Eq(db.ListCompaniesParams{Limit: 10})).Times(1).Return([]db.Company{}, nil)
+
+	service := newTestService(t, store)
+	companies, err := service.ListCompanies(context.Background(), randomPrincipal(companyID), 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []db.Company{{ID: companyID}}, companies)
+
+	_, err = service.ListCompanies(context.Background(), operator, 0, 10)
+	require.NoError(t, err)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/metrics"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// ConversationStep is where a conversation is after a message: the question sent to the
+// user, or none once the conversation is completed
+type ConversationStep struct {
+	Question  db.Question
+	Completed bool
+}
+
+// conversationSession returns the session of the principal a conversation is held in.
+// Blocked and expired sessions are not found.
+func (service *Service) conversationSession(ctx context.Context, principal *token.Payload, sessionID uuid.UUID) (db.Session, error) {
+	session, err := service.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return db.Session{}, err
+	}
+	if session.UserID != principal.UserID {
+		return db.Session{}, ErrNotOwner
+	}
+	if session.IsBlocked || time.Now().After(session.ExpiresAt) {
+		return db.Session{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+// StartConversation starts a conversation of the session of the principal with a bot of its
+// company on a channel. It returns the first question of the bot, the one sent to the user.
+func (service *Service) StartConversation(ctx context.Context, principal *token.Payload, sessionID uuid.UUID, botID int64, channelName string) (ConversationStep, error) {
+	session, err := service.conversationSession(ctx, principal, sessionID)
+	if err != nil {
+		return ConversationStep{}, err
+	}
+	bot, err := service.GetBot(ctx, principal, botID)
+	if err != nil {
+		return ConversationStep{}, err
+	}
+	channel, err := service.store.GetChannel(ctx, channelName)
+	if err != nil {
+		return ConversationStep{}, err
+	}
+	question, err := service.store.GetFirstQuestion(ctx, bot.ID)
+	if err != nil {
+		return ConversationStep{}, err
+	}
+
+	_, err = service.store.UpdateSessionConversation(ctx, db.UpdateSessionConversationParams{
+		ID:         session.ID,
+		ChannelID:  int64(channel.ID),
+		QuestionID: question.ID,
+	})
+	if err != nil {
+		return ConversationStep{}, err
+	}
+
+	metrics.ConversationStarted(bot.ID)
+	metrics.MessageSent(channel.Name)
+	return ConversationStep{Question: question}, nil
+}
+
+// AnswerConversation records the answer of the user to the question the session of the
+// principal waits for, and moves the conversation on to the next question. An answer
+// matching a response of the question leads to the next question of the response, any
+// other answer to the next question of the question. The conversation is completed when
+// there is no next question.
+func (service *Service) AnswerConversation(ctx context.Context, principal *token.Payload, sessionID uuid.UUID, answer string) (ConversationStep, error) {
+	session, err := service.conversationSession(ctx, principal, sessionID)
+	if err != nil {
+		return ConversationStep{}, err
+	}
+	if session.QuestionID == util.DefaultID {
+		// the session is not in a conversation
+		return ConversationStep{}, sql.ErrNoRows
+	}
+	channel, err := service.store.GetChannelByID(ctx, int32(session.ChannelID))
+	if err != nil {
+		return ConversationStep{}, err
+	}
+	metrics.MessageReceived(channel.Name)
+
+	var question db.Question
+	var step ConversationStep
+	err = service.store.ExecTx(ctx, func(ctx context.Context) error {
+		var err error
+		question, err = service.store.GetQuestion(ctx, session.QuestionID)
+		if err != nil {
+			return err
+		}
+
+		nextQuestionID := question.NextQuestionID
+		response, err := service.store.MatchResponse(ctx, db.MatchResponseParams{
+			QuestionID: question.ID,
+			Answer:     answer,
+		})
+		switch {
+		case err == nil:
+			nextQuestionID = response.NextQuestionID
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		_, err = service.store.CreateUserResponse(ctx, db.CreateUserResponseParams{
+			ResponseID: response.ID,
+			UserID:     principal.UserID,
+			QuestionID: question.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		step.Completed = nextQuestionID == util.DefaultID
+		if !step.Completed {
+			step.Question, err = service.store.GetQuestion(ctx, nextQuestionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = service.store.UpdateSessionConversation(ctx, db.UpdateSessionConversationParams{
+			ID:         session.ID,
+			ChannelID:  session.ChannelID,
+			QuestionID: step.Question.ID,
+			ResponseID: response.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return ConversationStep{}, err
+	}
+
+	if step.Completed {
+		metrics.ConversationCompleted(question.BotID)
+	} else {
+		metrics.MessageSent(channel.Name)
+	}
+	return step, nil
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// counterValue reads a counter of the default registry, 0 when it was never incremented
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestStartConversation(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	principal := randomPrincipal(companyID)
+	bot := db.Bot{ID: util.RandInt(1, 1000), CompanyID: companyID}
+	channel := db.Channel{ID: int32(util.RandInt(1, 100)), Name: util.RandomString(8)}
+	question := db.Question{ID: util.RandInt(1, 1000), BotID: bot.ID, Question: util.RandomString(6)}
+	session := db.Session{ID: uuid.New(), UserID: principal.UserID, ExpiresAt: time.Now().Add(time.Hour)}
+	started := map[string]string{"bot_id": strconv.FormatInt(bot.ID, 10)}
+	sent := map[string]string{"channel": channel.Name, "direction": "out"}
+
+	testCases := []struct {
+		name       string
+		session    db.Session
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, step ConversationStep, err error)
+	}{
+		{
+			name:    "OK",
+			session: session,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(bot, nil)
+				store.EXPECT().GetChannel(gomock.Any(), gomock.Eq(channel.Name)).Times(1).Return(channel, nil)
+				store.EXPECT().GetFirstQuestion(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(question, nil)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: int64(channel.ID), QuestionID: question.ID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.NoError(t, err)
+				require.False(t, step.Completed)
+				require.Equal(t, question, step.Question)
+			},
+		},
+		{
+			name:    "OtherUser",
+			session: db.Session{ID: session.ID, UserID: principal.UserID + 1, ExpiresAt: session.ExpiresAt},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.ErrorIs(t, err, ErrNotOwner)
+			},
+		},
+		{
+			name:    "ExpiredSession",
+			session: db.Session{ID: session.ID, UserID: principal.UserID, ExpiresAt: time.Now().Add(-time.Minute)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name:    "BotOfOtherCompany",
+			session: session,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := bot
+				other.CompanyID = companyID + 1
+				store.EXPECT().GetBot(gomock.Any(), gomock.Eq(bot.ID)).Times(1).Return(other, nil)
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.ErrorIs(t, err, ErrNotOwner)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(tc.session, nil)
+			tc.buildStubs(store)
+
+			startedBefore := counterValue(t, "bot_conversations_started_total", started)
+			sentBefore := counterValue(t, "bot_messages_total", sent)
+
+			step, err := newTestService(t, store).StartConversation(context.Background(), principal, session.ID, bot.ID, channel.Name)
+			tc.check(t, step, err)
+
+			// only the conversations started are counted, with the question sent
+			delta := 0.0
+			if err == nil {
+				delta = 1
+			}
+			require.Equal(t, startedBefore+delta, counterValue(t, "bot_conversations_started_total", started))
+			require.Equal(t, sentBefore+delta, counterValue(t, "bot_messages_total", sent))
+		})
+	}
+}
+
+func TestAnswerConversation(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	principal := randomPrincipal(companyID)
+	botID := util.RandInt(1, 1000)
+	channel := db.Channel{ID: int32(util.RandInt(1, 100)), Name: util.RandomString(8)}
+	question := db.Question{ID: util.RandInt(1, 1000), BotID: botID, NextQuestionID: 0}
+	next := db.Question{ID: question.ID + 1, BotID: botID}
+	response := db.Response{ID: util.RandInt(1, 1000), QuestionID: question.ID, Response: "yes", NextQuestionID: next.ID}
+	session := db.Session{
+		ID:         uuid.New(),
+		UserID:     principal.UserID,
+		ChannelID:  int64(channel.ID),
+		QuestionID: question.ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	received := map[string]string{"channel": channel.Name, "direction": "in"}
+	sent := map[string]string{"channel": channel.Name, "direction": "out"}
+	completed := map[string]string{"bot_id": strconv.FormatInt(botID, 10)}
+
+	testCases := []struct {
+		name           string
+		answer         string
+		buildStubs     func(store *mockdb.MockStore)
+		check          func(t *testing.T, step ConversationStep, err error)
+		sentDelta      float64
+		completedDelta float64
+	}{
+		{
+			name:   "MatchingResponse",
+			answer: " Yes ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().
+					MatchResponse(gomock.Any(), gomock.Eq(db.MatchResponseParams{QuestionID: question.ID, Answer: " Yes "})).
+					Times(1).
+					Return(response, nil)
+				store.EXPECT().
+					CreateUserResponse(gomock.Any(), gomock.Eq(db.CreateUserResponseParams{ResponseID: response.ID, UserID: principal.UserID, QuestionID: question.ID})).
+					Times(1)
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(next.ID)).Times(1).Return(next, nil)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: session.ChannelID, QuestionID: next.ID, ResponseID: response.ID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.NoError(t, err)
+				require.False(t, step.Completed)
+				require.Equal(t, next, step.Question)
+			},
+			sentDelta: 1,
+		},
+		{
+			name:   "LastQuestion",
+			answer: util.RandomString(6),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().MatchResponse(gomock.Any(), gomock.Any()).Times(1).Return(db.Response{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateUserResponse(gomock.Any(), gomock.Eq(db.CreateUserResponseParams{UserID: principal.UserID, QuestionID: question.ID})).
+					Times(1)
+				arg := db.UpdateSessionConversationParams{ID: session.ID, ChannelID: session.ChannelID}
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.NoError(t, err)
+				require.True(t, step.Completed)
+			},
+			completedDelta: 1,
+		},
+		{
+			name:   "InternalError",
+			answer: "yes",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetQuestion(gomock.Any(), gomock.Eq(question.ID)).Times(1).Return(question, nil)
+				store.EXPECT().MatchResponse(gomock.Any(), gomock.Any()).Times(1).Return(db.Response{}, sql.ErrConnDone)
+				store.EXPECT().UpdateSessionConversation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, step ConversationStep, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			store.EXPECT().GetChannelByID(gomock.Any(), gomock.Eq(channel.ID)).Times(1).Return(channel, nil)
+			allowTx(store)
+			tc.buildStubs(store)
+
+			receivedBefore := counterValue(t, "bot_messages_total", received)
+			sentBefore := counterValue(t, "bot_messages_total", sent)
+			completedBefore := counterValue(t, "bot_conversations_completed_total", completed)
+
+			step, err := newTestService(t, store).AnswerConversation(context.Background(), principal, session.ID, tc.answer)
+			tc.check(t, step, err)
+
+			// every message of the user is counted, the replies only once they are committed
+			require.Equal(t, receivedBefore+1, counterValue(t, "bot_messages_total", received))
+			require.Equal(t, sentBefore+tc.sentDelta, counterValue(t, "bot_messages_total", sent))
+			require.Equal(t, completedBefore+tc.completedDelta, counterValue(t, "bot_conversations_completed_total", completed))
+		})
+	}
+
+	t.Run("NotInConversation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		idle := session
+		idle.QuestionID = 0
+		store := mockdb.NewMockStore(ctrl)
+		store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(idle, nil)
+		store.EXPECT().GetChannelByID(gomock.Any(), gomock.Any()).Times(0)
+
+		_, err := newTestService(t, store).AnswerConversation(context.Background(), principal, session.ID, "yes")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/otp"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// failedLoginWindow is how long a failed login is remembered.
+// Counting starts over after a quiet period of this length.
+const failedLoginWindow = 24 * time.Hour
+
+// dummyPasswordHash is checked against the password given for an unknown phone, so that
+// refusing it takes as long as refusing a wrong password and does not reveal the phone is unknown.
+// It has the cost of util.HashPassword.
+const dummyPasswordHash = "$2a$10$75.kCBUbkPr1WmypMx9yKeDGZ2YFLZgAFbyJJwx.HeKKRni0mNhxa"
+
+// mfaTokenDuration is how long the second factor of a login can be given after its password
+const mfaTokenDuration = 5 * time.Minute
+
+// Errors refusing a login
+var (
+	ErrWrongPassword       = errors.New("password is incorrect")
+	ErrPhoneNotVerified    = errors.New("phone number is not verified")
+	ErrInvalidSecondFactor = errors.New("two factor code is invalid or has already been used")
+)
+
+// LockedError refuses a login made while the account or the client IP is locked
+// after too many failures
+type LockedError struct {
+	Until time.Time
+}
+
+func (lockedErr *LockedError) Error() string {
+	return "too many failed login attempts"
+}
+
+// lockoutPolicy decides how long logins are refused after repeated failures
+type lockoutPolicy struct {
+	// freeAttempts is the number of failures allowed before the first lockout
+	freeAttempts int32
+	// baseDelay is the length of the first lockout; every further failure doubles it
+	baseDelay time.Duration
+	// maxDelay caps the length of a lockout
+	maxDelay time.Duration
+}
+
+var (
+	phoneLockoutPolicy = lockoutPolicy{freeAttempts: 5, baseDelay: time.Minute, maxDelay: time.Hour}
+	ipLockoutPolicy    = lockoutPolicy{freeAttempts: 20, baseDelay: time.Minute, maxDelay: time.Hour}
+)
+
+// lockoutDuration returns how long to refuse logins after the given number of consecutive failures
+func (policy lockoutPolicy) lockoutDuration(failures int32) time.Duration {
+	if failures < policy.freeAttempts {
+		return 0
+	}
+
+	exponent := float64(failures - policy.freeAttempts)
+	delay := float64(policy.baseDelay) * math.Pow(2, exponent)
+	if delay > float64(policy.maxDelay) {
+		return policy.maxDelay
+	}
+	return time.Duration(delay)
+}
+
+func phoneLoginKey(phone string) string {
+	return "phone:" + phone
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Client describes where a login comes from. Failed logins are counted against its IP
+// as well as against the phone, and sessions remember both.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is the pair of tokens issued to a user who logged in.
+// The refresh token is stored as the session.
+type Session struct {
+	ID                    uuid.UUID
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// Login is a successful login: a session, or for a user with two factor authentication
+// on, a token to exchange for a session with a second factor code at LoginMFA
+type Login struct {
+	User              db.User
+	Company           db.Company
+	Session           Session
+	MFAToken          string
+	MFATokenExpiresAt time.Time
+}
+
+// MFARequired reports whether the login still needs a second factor
+func (login Login) MFARequired() bool {
+	return login.MFAToken != ""
+}
+
+// Login checks the password of the user with the phone
+func (service *Service) Login(ctx context.Context, client Client, phone string, password string) (Login, error) {
+	err := service.checkLoginLock(ctx, phoneLoginKey(phone), ipLoginKey(client.IP))
+	if err != nil {
+		return Login{}, err
+	}
+
+	user, err := service.store.GetUser(ctx, phone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// unknown phones are refused like wrong passwords, as slowly, and counted too,
+			// so that probing for accounts learns nothing and is throttled
+			_ = util.CheckPassword(password, dummyPasswordHash)
+			if err := service.recordFailedLogins(ctx, client, phone); err != nil {
+				return Login{}, err
+			}
+			return Login{}, ErrWrongPassword
+		}
+		return Login{}, err
+	}
+
+	err = util.CheckPassword(password, user.PasswordHash)
+	if err != nil {
+		if err := service.recordFailedLogins(ctx, client, phone); err != nil {
+			return Login{}, err
+		}
+		return Login{}, ErrWrongPassword
+	}
+
+	// with two factor authentication on, failures only reset once the second factor
+	// passes, so that knowing the password does not allow unlimited guesses of codes
+	if !user.TotpEnabledAt.Valid {
+		err = service.store.ResetLoginAttempts(ctx, phoneLoginKey(user.Phone))
+		if err != nil {
+			return Login{}, err
+		}
+	}
+
+	return service.completeLogin(ctx, client, user)
+}
+
+// LoginSSO logs in a user whose identity was proven by a single sign-on provider instead
+// of a password. The same checks as Login apply, a second factor included.
+func (service *Service) LoginSSO(ctx context.Context, client Client, user db.User) (Login, error) {
+	return service.completeLogin(ctx, client, user)
+}
+
+// completeLogin finishes a login once the user has proven who they are: a session,
+// or a token to give the second factor with when two factor authentication is on
+func (service *Service) completeLogin(ctx context.Context, client Client, user db.User) (Login, error) {
+	if user.DeactivatedAt.Valid {
+		return Login{}, ErrUserDeactivated
+	}
+	if !user.VerifiedAt.Valid {
+		return Login{}, ErrPhoneNotVerified
+	}
+	if user.TotpEnabledAt.Valid {
+		mfaToken, payload, err := service.tokenMaker.CreateToken(
+			user.Phone,
+			user.ID,
+			user.Name,
+			user.CompanyID,
+			user.Role,
+			[]string{token.ScopeMFAPending},
+			mfaTokenDuration,
+		)
+		if err != nil {
+			return Login{}, err
+		}
+		return Login{User: user, MFAToken: mfaToken, MFATokenExpiresAt: payload.ExpiredAt}, nil
+	}
+
+	return service.startSession(ctx, client, user)
+}
+
+// LoginMFA completes a login started with a password by checking the second factor
+func (service *Service) LoginMFA(ctx context.Context, client Client, mfaToken string, code string) (Login, error) {
+	payload, err := service.tokenMaker.VerifyToken(mfaToken)
+	if err != nil {
+		return Login{}, err
+	}
+	if !payload.HasScope(token.ScopeMFAPending) {
+		return Login{}, token.ErrInvalidToken
+	}
+
+	user, err := service.store.GetUserByID(ctx, payload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Login{}, ErrUserGone
+		}
+		return Login{}, err
+	}
+	if payload.IssuedAt.Before(user.PasswordChangedAt) || !user.TotpEnabledAt.Valid {
+		return Login{}, token.ErrInvalidToken
+	}
+	if user.DeactivatedAt.Valid {
+		return Login{}, ErrUserDeactivated
+	}
+
+	err = service.checkLoginLock(ctx, phoneLoginKey(user.Phone), ipLoginKey(client.IP))
+	if err != nil {
+		return Login{}, err
+	}
+
+	ok, err := service.CheckSecondFactor(ctx, user, code)
+	if err != nil {
+		return Login{}, err
+	}
+	if !ok {
+		if err := service.recordFailedLogins(ctx, client, user.Phone); err != nil {
+			return Login{}, err
+		}
+		return Login{}, ErrInvalidSecondFactor
+	}
+
+	err = service.store.ResetLoginAttempts(ctx, phoneLoginKey(user.Phone))
+	if err != nil {
+		return Login{}, err
+	}
+
+	return service.startSession(ctx, client, user)
+}
+
+// startSession completes the login of the user with a new session
+func (service *Service) startSession(ctx context.Context, client Client, user db.User) (Login, error) {
+	session, err := service.CreateSession(ctx, client, user)
+	if err != nil {
+		return Login{}, err
+	}
+
+	company, err := service.store.GetCompanyByID(ctx, user.CompanyID)
+	if err != nil {
+		return Login{}, err
+	}
+
+	return Login{User: user, Company: company, Session: session}, nil
+}
+
+// CreateSession issues an access and refresh token pair for the user and
+// stores the refresh token as a new session
+func (service *Service) CreateSession(ctx context.Context, client Client, user db.User) (Session, error) {
+	accessToken, accessPayload, err := service.tokenMaker.CreateToken(
+		user.Phone,
+		user.ID,
+		user.Name,
+		user.CompanyID,
+		user.Role,
+		RoleScopes(user.Role),
+		service.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return Session{}, err
+	}
+
+	refreshToken, refreshPayload, err := service.tokenMaker.CreateToken(
+		user.Phone,
+		user.ID,
+		user.Name,
+		user.CompanyID,
+		user.Role,
+		RoleScopes(user.Role),
+		service.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		return Session{}, err
+	}
+
+	session, err := service.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		UserID:       user.ID,
+		RefreshToken: refreshToken,
+		UserAgent:    client.UserAgent,
+		ClientIp:     client.IP,
+		IsBlocked:    false,
+		ChannelID:    util.DefaultID,
+		QuestionID:   util.DefaultID,
+		ResponseID:   util.DefaultID,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return Session{}, err
+	}
+
+	return Session{
+		ID:                    session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+	}, nil
+}
+
+// CheckSecondFactor accepts either a current authenticator code or an unused recovery code.
+// Each code is accepted once only.
+func (service *Service) CheckSecondFactor(ctx context.Context, user db.User, code string) (bool, error) {
+	if len(code) == otp.TOTPDigits {
+		counter, ok := otp.ValidateTOTP(user.TotpSecret.String, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		// the counter only moves forward, so a code seen before updates nothing
+		updated, err := service.store.UseUserTotpCounter(ctx, db.UseUserTotpCounterParams{
+			Counter: counter,
+			ID:      user.ID,
+		})
+		return updated == 1, err
+	}
+
+	_, err := service.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: util.HashSecret(otp.NormalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// UnlockUser clears the failed login counter of a user in the company of the principal,
+// who must be an owner or an admin. Only the lock on the phone of the user is lifted: a lock
+// on a client IP counts the failures of every phone tried from it, so it is not the user's
+// to clear and lapses on its own within ipLockoutPolicy.maxDelay.
+func (service *Service) UnlockUser(ctx context.Context, principal *token.Payload, id int64) (db.User, error) {
+	if !isCompanyAdmin(principal.Role) {
+		return db.User{}, ErrNotAdmin
+	}
+
+	user, err := service.store.GetUserByID(ctx, id)
+	if err != nil {
+		return db.User{}, err
+	}
+	if user.CompanyID != principal.CompanyID {
+		return db.User{}, ErrNotOwner
+	}
+
+	err = service.store.ResetLoginAttempts(ctx, phoneLoginKey(user.Phone))
+	if err != nil {
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+// checkLoginLock returns a LockedError while a lock is in force on any of the keys
+func (service *Service) checkLoginLock(ctx context.Context, keys ...string) error {
+	var lockedUntil time.Time
+	for _, key := range keys {
+		attempt, err := service.store.GetLoginAttempt(ctx, key)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return err
+		}
+		if attempt.LockedUntil.Valid && attempt.LockedUntil.Time.After(lockedUntil) {
+			lockedUntil = attempt.LockedUntil.Time
+		}
+	}
+
+	if time.Now().Before(lockedUntil) {
+		return &LockedError{Until: lockedUntil}
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed login against the key and locks it once the policy says so
+func (service *Service) recordFailedLogin(ctx context.Context, key string, policy lockoutPolicy) error {
+	attempt, err := service.store.RecordFailedLogin(ctx, db.RecordFailedLoginParams{
+		Key:         key,
+		ResetBefore: time.Now().Add(-failedLoginWindow),
+	})
+	if err != nil {
+		return err
+	}
+
+	delay := policy.lockoutDuration(attempt.FailedCount)
+	if delay == 0 {
+		return nil
+	}
+
+	_, err = service.store.LockLoginAttempt(ctx, db.LockLoginAttemptParams{
+		Key:         key,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(delay), Valid: true},
+	})
+	return err
+}
+
+// recordFailedLogins counts a failed login against both the phone and the client IP
+func (service *Service) recordFailedLogins(ctx context.Context, client Client, phone string) error {
+	err := service.recordFailedLogin(ctx, phoneLoginKey(phone), phoneLockoutPolicy)
+	if err != nil {
+		return err
+	}
+	return service.recordFailedLogin(ctx, ipLoginKey(client.IP), ipLockoutPolicy)
+}
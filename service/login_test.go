@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	policy := lockoutPolicy{freeAttempts: 3, baseDelay: time.Minute, maxDelay: 10 * time.Minute}
+
+	testCases := []struct {
+		failures int32
+		delay    time.Duration
+	}{
+		{failures: 0, delay: 0},
+		{failures: 2, delay: 0},
+		{failures: 3, delay: time.Minute},
+		{failures: 4, delay: 2 * time.Minute},
+		{failures: 5, delay: 4 * time.Minute},
+		{failures: 6, delay: 8 * time.Minute},
+		{failures: 7, delay: 10 * time.Minute},
+		{failures: 100, delay: 10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d failures", tc.failures), func(t *testing.T) {
+			require.Equal(t, tc.delay, policy.lockoutDuration(tc.failures))
+		})
+	}
+}
+
+func TestDummyPasswordHash(t *testing.T) {
+	// refusing an unknown phone takes as long as refusing a wrong password
+	hash, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	dummyCost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	require.NoError(t, err)
+	require.Equal(t, cost, dummyCost)
+}
+
+func TestLogin(t *testing.T) {
+	company := db.Company{ID: util.RandInt(1, 100), Name: util.RandomString(6)}
+	password := util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user := db.User{
+		ID:           util.RandInt(1, 1000),
+		Name:         util.RandomString(6),
+		Phone:        util.RandomPhoneNumber(),
+		PasswordHash: hashedPassword,
+		CompanyID:    company.ID,
+		Role:         util.MemberRole,
+		VerifiedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	client := Client{IP: "192.0.2.1", UserAgent: "test"}
+
+	testCases := []struct {
+		name       string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, login Login, err error)
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, client.IP, arg.ClientIp)
+						require.Equal(t, client.UserAgent, arg.UserAgent)
+						return db.Session{ID: arg.ID}, nil
+					})
+				store.EXPECT().GetCompanyByID(gomock.Any(), gomock.Eq(company.ID)).Times(1).Return(company, nil)
+			},
+			check: func(t *testing.T, login Login, err error) {
+				require.NoError(t, err)
+				require.False(t, login.MFARequired())
+				require.NotEmpty(t, login.Session.AccessToken)
+				require.NotEmpty(t, login.Session.RefreshToken)
+				require.Equal(t, company, login.Company)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: "incorrect",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(user, nil)
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordFailedLoginParams) (db.LoginAttempt, error) {
+						require.Equal(t, phoneLoginKey(user.Phone), arg.Key)
+						require.WithinDuration(t, time.Now().Add(-failedLoginWindow), arg.ResetBefore, time.Second)
+						return db.LoginAttempt{Key: arg.Key, FailedCount: phoneLockoutPolicy.freeAttempts}, nil
+					})
+				store.EXPECT().
+					LockLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.LockLoginAttemptParams) (db.LoginAttempt, error) {
+						require.Equal(t, phoneLoginKey(user.Phone), arg.Key)
+						require.WithinDuration(t, time.Now().Add(phoneLockoutPolicy.baseDelay), arg.LockedUntil.Time, time.Second)
+						return db.LoginAttempt{}, nil
+					})
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordFailedLoginParams) (db.LoginAttempt, error) {
+						require.Equal(t, ipLoginKey(client.IP), arg.Key)
+						return db.LoginAttempt{Key: arg.Key, FailedCount: 1}, nil
+					})
+			},
+			check: func(t *testing.T, login Login, err error) {
+				require.ErrorIs(t, err, ErrWrongPassword)
+			},
+		},
+		{
+			name:     "UnknownPhone",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordFailedLogin(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginAttempt{FailedCount: 1}, nil)
+			},
+			check: func(t *testing.T, login Login, err error) {
+				require.ErrorIs(t, err, ErrWrongPassword)
+			},
+		},
+		{
+			name:     "Locked",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				lockedUntil := sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Eq(phoneLoginKey(user.Phone))).Times(1).Return(db.LoginAttempt{LockedUntil: lockedUntil}, nil)
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Eq(ipLoginKey(client.IP))).Times(1).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, login Login, err error) {
+				var lockedErr *LockedError
+				require.True(t, errors.As(err, &lockedErr))
+				require.WithinDuration(t, time.Now().Add(time.Minute), lockedErr.Until, time.Second)
+			},
+		},
+		{
+			name:     "SecondFactorRequired",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				withTOTP := user
+				withTOTP.TotpEnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(withTOTP, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, login Login, err error) {
+				require.NoError(t, err)
+				require.True(t, login.MFARequired())
+				require.WithinDuration(t, time.Now().Add(mfaTokenDuration), login.MFATokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name:     "Unverified",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				unverified := user
+				unverified.VerifiedAt = sql.NullTime{}
+				store.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Times(2).Return(db.LoginAttempt{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Phone)).Times(1).Return(unverified, nil)
+				store.EXPECT().ResetLoginAttempts(gomock.Any(), gomock.Any()).Times(1)
+			},
+			check: func(t *testing.T, login Login, err error) {
+				require.ErrorIs(t, err, ErrPhoneNotVerified)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			service := newTestService(t, store)
+			login, err := service.Login(context.Background(), client, user.Phone, tc.password)
+			tc.check(t, login, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, store *mockdb.MockStore) *Service {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+	tokenMaker, err := token.NewMakerFromConfig(config)
+	require.NoError(t, err)
+
+	return New(config, store, tokenMaker)
+}
+
+func randomPrincipal(companyID int64) *token.Payload {
+	return &token.Payload{
+		UserID:    util.RandInt(1, 1000),
+		CompanyID: companyID,
+		Role:      util.OwnerRole,
+		Scopes:    token.AllScopes,
+	}
+}
+
+// allowTx runs the transactions of a test, which the mock store cannot roll back
+func allowTx(store *mockdb.MockStore) {
+	store.EXPECT().
+		ExecTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+)
+
+// CreateQuestion adds a question to a bot of the company of the principal
+func (service *Service) CreateQuestion(ctx context.Context, principal *token.Payload, arg db.CreateQuestionParams) (db.Question, error) {
+	_, err := service.GetBot(ctx, principal, arg.BotID)
+	if err != nil {
+		return db.Question{}, err
+	}
+
+	return service.store.CreateQuestion(ctx, arg)
+}
+
+// GetQuestion returns a question of a bot of the company of the principal
+func (service *Service) GetQuestion(ctx context.Context, principal *token.Payload, id int64) (db.Question, error) {
+	question, err := service.store.GetQuestion(ctx, id)
+	if err != nil {
+		return db.Question{}, err
+	}
+
+	_, err = service.GetBot(ctx, principal, question.BotID)
+	if err != nil {
+		return db.Question{}, err
+	}
+	return question, nil
+}
+
+// UpdateQuestion changes the valid fields of arg in a question of a bot of the company of
+// the principal. Questions are edited by several people while a bot is designed, so the
+// change must name the version it was made to: without one it fails with ErrVersionRequired.
+func (service *Service) UpdateQuestion(ctx context.Context, principal *token.Payload, arg db.UpdateQuestionParams, ifMatch IfMatch) (before db.Question, after db.Question, err error) {
+	if len(ifMatch) == 0 {
+		return before, after, ErrVersionRequired
+	}
+
+	before, err = service.GetQuestion(ctx, principal, arg.ID)
+	if err != nil {
+		return
+	}
+	if !ifMatch.Matches(before.UpdatedAt) {
+		return before, after, ErrVersionMismatch
+	}
+	arg.UpdatedAt = ifMatch.version(before.UpdatedAt)
+
+	after, err = service.store.UpdateQuestion(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the question existed a moment ago, a conditional update missing it lost a race
+		err = ErrVersionMismatch
+	}
+	return
+}
+
+// DeleteQuestion soft deletes a question of a bot of the company of the principal
+// and returns it as it was
+func (service *Service) DeleteQuestion(ctx context.Context, principal *token.Payload, id int64, ifMatch IfMatch) (db.Question, error) {
+	question, err := service.GetQuestion(ctx, principal, id)
+	if err != nil {
+		return db.Question{}, err
+	}
+	if !ifMatch.Matches(question.UpdatedAt) {
+		return db.Question{}, ErrVersionMismatch
+	}
+
+	arg := db.SoftDeleteQuestionParams{
+		ID:        id,
+		UpdatedAt: ifMatch.version(question.UpdatedAt),
+	}
+	_, err = service.store.SoftDeleteQuestion(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) && arg.UpdatedAt.Valid {
+		// the question existed a moment ago, a conditional deletion missing it lost a race
+		return db.Question{}, ErrVersionMismatch
+	}
+	if err != nil {
+		return db.Question{}, err
+	}
+	return question, nil
+}
+
+// RestoreQuestion brings back a deleted question of a bot of the company of the principal.
+// The bot must not be deleted itself, restoring it brings back the questions deleted with it.
+func (service *Service) RestoreQuestion(ctx context.Context, principal *token.Payload, id int64) (db.Question, error) {
+	question, err := service.store.GetDeletedQuestion(ctx, id)
+	if err != nil {
+		return db.Question{}, err
+	}
+
+	_, err = service.GetBot(ctx, principal, question.BotID)
+	if err != nil {
+		return db.Question{}, err
+	}
+
+	return service.store.RestoreQuestion(ctx, id)
+}
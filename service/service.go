@@ -0,0 +1,55 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// Service carries out the operations offered by both the HTTP and the gRPC API, so that
+// the two apply the same rules. Operations restricted to a company take the principal
+// acting, the payload of its access token or API key. Binding requests and rendering
+// results are left to the transports.
+type Service struct {
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+}
+
+// New creates a service over the store, issuing tokens with tokenMaker
+func New(config util.Config, store db.Store, tokenMaker token.Maker) *Service {
+	return &Service{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+	}
+}
+
+// Errors of the operations that the transports answer with a status of their own.
+// Errors of the store are returned as they are, sql.ErrNoRows meaning not found.
+var (
+	ErrNotOwner        = errors.New("resource doesn't belong to the authenticated company")
+	ErrNotAdmin        = errors.New("only company owners and admins are allowed to do this")
+	ErrVersionMismatch = errors.New("the resource was changed since it was read, fetch it again and retry")
+	ErrVersionRequired = errors.New("the version of the resource to change must be given")
+)
+
+// isCompanyAdmin reports whether the role may manage other users of its company
+func isCompanyAdmin(role string) bool {
+	return role == util.OwnerRole || role == util.AdminRole
+}
+
+// tenantScope returns the company the lists of the principal are limited to, given the
+// company asked for, none meaning any. Only operators list the resources of every company.
+func tenantScope(principal *token.Payload, companyID sql.NullInt64) (sql.NullInt64, error) {
+	if principal.Role == util.OperatorRole {
+		return companyID, nil
+	}
+	if companyID.Valid && companyID.Int64 != principal.CompanyID {
+		return sql.NullInt64{}, ErrNotOwner
+	}
+	return sql.NullInt64{Int64: principal.CompanyID, Valid: true}, nil
+}
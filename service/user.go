@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/token"
+	"github.com/lenimbugua/bot/util"
+)
+
+// ErrOwnerOnly refuses an admin changing an owner or making a user an owner
+var ErrOwnerOnly = errors.New("only owners can manage other owners or make users owners")
+
+// GetUser returns a user of the company of the principal
+func (service *Service) GetUser(ctx context.Context, principal *token.Payload, id int64) (db.User, error) {
+	user, err := service.store.GetUserByID(ctx, id)
+	if err != nil {
+		return db.User{}, err
+	}
+	if user.CompanyID != principal.CompanyID {
+		return db.User{}, ErrNotOwner
+	}
+	return user, nil
+}
+
+// getManagedUser returns a user of the company of the principal that the principal may change.
+// Owners manage everyone, admins manage everyone but owners.
+func (service *Service) getManagedUser(ctx context.Context, principal *token.Payload, id int64) (db.User, error) {
+	user, err := service.GetUser(ctx, principal, id)
+	if err != nil {
+		return db.User{}, err
+	}
+	if !isCompanyAdmin(principal.Role) {
+		return db.User{}, ErrNotAdmin
+	}
+	if user.Role == util.OwnerRole && principal.Role != util.OwnerRole {
+		return db.User{}, ErrOwnerOnly
+	}
+	return user, nil
+}
+
+// ListUsers lists the users of the company of the principal in order of id, starting after afterID
+func (service *Service) ListUsers(ctx context.Context, principal *token.Payload, afterID int64, limit int32) ([]db.User, error) {
+	return service.store.ListCompanyUsers(ctx, db.ListCompanyUsersParams{
+		CompanyID: principal.CompanyID,
+		AfterID:   afterID,
+		Limit:     limit,
+	})
+}
+
+// CountUsers counts the users of the company of the principal
+func (service *Service) CountUsers(ctx context.Context, principal *token.Payload) (int64, error) {
+	return service.store.CountCompanyUsers(ctx, principal.CompanyID)
+}
+
+// UpdateUser changes the fields set in arg on a user the principal manages. A new phone has
+// to be verified again and a new role applies at once, tokens already issued included. A company
+// always keeps an owner, db.ErrLastOwner refuses demoting the last one. It returns the user
+// before and after the change.
+func (service *Service) UpdateUser(ctx context.Context, principal *token.Payload, arg db.UpdateUserParams) (before db.User, after db.User, err error) {
+	before, err = service.getManagedUser(ctx, principal, arg.ID)
+	if err != nil {
+		return
+	}
+	if arg.Role.Valid && arg.Role.String == util.OwnerRole && principal.Role != util.OwnerRole {
+		return before, after, ErrOwnerOnly
+	}
+
+	after, err = service.store.UpdateUserTx(ctx, arg)
+	return
+}
+
+// DeactivateUser stops a user the principal manages from logging in without deleting them.
+// It returns the user before and after the change.
+func (service *Service) DeactivateUser(ctx context.Context, principal *token.Payload, id int64) (before db.User, after db.User, err error) {
+	before, err = service.getManagedUser(ctx, principal, id)
+	if err != nil {
+		return
+	}
+
+	after, err = service.store.DeactivateUserTx(ctx, id)
+	return
+}
+
+// ReactivateUser lets a deactivated user the principal manages log in again.
+// It returns the user before and after the change.
+func (service *Service) ReactivateUser(ctx context.Context, principal *token.Payload, id int64) (before db.User, after db.User, err error) {
+	before, err = service.getManagedUser(ctx, principal, id)
+	if err != nil {
+		return
+	}
+
+	after, err = service.store.ReactivateUser(ctx, id)
+	return
+}
+
+// DeleteUser deletes a user the principal manages and returns them as they were
+func (service *Service) DeleteUser(ctx context.Context, principal *token.Payload, id int64) (db.User, error) {
+	user, err := service.getManagedUser(ctx, principal, id)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	err = service.store.DeleteUserTx(ctx, id)
+	if err != nil {
+		return db.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	db "github.com/lenimbugua/bot/db/sqlc"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUser(t *testing.T) {
+	companyID := util.RandInt(1, 100)
+	member := db.User{ID: 1003, CompanyID: companyID, Name: util.RandomString(6), Role: util.MemberRole}
+	owner := db.User{ID: 1001, CompanyID: companyID, Name: util.RandomString(6), Role: util.OwnerRole}
+	name := sql.NullString{String: util.RandomString(6), Valid: true}
+	ownerRole := sql.NullString{String: util.OwnerRole, Valid: true}
+
+	testCases := []struct {
+		name       string
+		role       string
+		companyID  int64
+		arg        db.UpdateUserParams
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, before db.User, after db.User, err error)
+	}{
+		{
+			name:      "OK",
+			role:      util.AdminRole,
+			companyID: companyID,
+			arg:       db.UpdateUserParams{ID: member.ID, Name: name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Eq(db.UpdateUserParams{ID: member.ID, Name: name})).
+					Times(1).
+					Return(db.User{ID: member.ID, CompanyID: companyID, Name: name.String, Role: member.Role}, nil)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, member, before)
+				require.Equal(t, name.String, after.Name)
+			},
+		},
+		{
+			name:      "OtherCompany",
+			role:      util.OwnerRole,
+			companyID: companyID + 1,
+			arg:       db.UpdateUserParams{ID: member.ID, Name: name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.ErrorIs(t, err, ErrNotOwner)
+			},
+		},
+		{
+			name:      "NotAdmin",
+			role:      util.MemberRole,
+			companyID: companyID,
+			arg:       db.UpdateUserParams{ID: member.ID, Name: name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.ErrorIs(t, err, ErrNotAdmin)
+			},
+		},
+		{
+			name:      "AdminChangingOwner",
+			role:      util.AdminRole,
+			companyID: companyID,
+			arg:       db.UpdateUserParams{ID: owner.ID, Name: name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(owner.ID)).Times(1).Return(owner, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.ErrorIs(t, err, ErrOwnerOnly)
+			},
+		},
+		{
+			name:      "AdminAppointingOwner",
+			role:      util.AdminRole,
+			companyID: companyID,
+			arg:       db.UpdateUserParams{ID: member.ID, Role: ownerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.ErrorIs(t, err, ErrOwnerOnly)
+			},
+		},
+		{
+			name:      "OwnerAppointingOwner",
+			role:      util.OwnerRole,
+			companyID: companyID,
+			arg:       db.UpdateUserParams{ID: member.ID, Role: ownerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(member.ID)).Times(1).Return(member, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Eq(db.UpdateUserParams{ID: member.ID, Role: ownerRole})).
+					Times(1).
+					Return(db.User{}, db.ErrLastOwner)
+			},
+			check: func(t *testing.T, before db.User, after db.User, err error) {
+				require.ErrorIs(t, err, db.ErrLastOwner)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			principal := randomPrincipal(tc.companyID)
+			principal.Role = tc.role
+
+			before, after, err := newTestService(t, store).UpdateUser(context.Background(), principal, tc.arg)
+			tc.check(t, before, after, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+// ETag is the entity tag of a version of a resource. Every update sets updated_at,
+// which the database keeps to the microsecond, so the tag changes with every version.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// IfMatch lists the entity tags of the versions of a resource that a change may be made
+// to, as the If-Match header of HTTP does. * matches any version, and an empty list
+// makes the change unconditional. Weak tags never match, as If-Match uses the strong comparison.
+type IfMatch []string
+
+// Matches reports whether the version of a resource last updated at updatedAt may be changed
+func (ifMatch IfMatch) Matches(updatedAt time.Time) bool {
+	if ifMatch.anyVersion() {
+		return true
+	}
+
+	current := ETag(updatedAt)
+	for _, tag := range ifMatch {
+		if tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// version is the updated_at a change must still find when it is written,
+// null when the change is not conditional on one version
+func (ifMatch IfMatch) version(updatedAt time.Time) sql.NullTime {
+	if ifMatch.anyVersion() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: updatedAt, Valid: true}
+}
+
+func (ifMatch IfMatch) anyVersion() bool {
+	return len(ifMatch) == 0 || len(ifMatch) == 1 && ifMatch[0] == "*"
+}
@@ -0,0 +1,48 @@
+package sso
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// domainRecordPrefix names the TXT record a company publishes to prove it owns an email domain
+const domainRecordPrefix = "_bot-sso."
+
+// domainTokenPrefix starts the value of the TXT record
+const domainTokenPrefix = "bot-sso-verification="
+
+// LookupTXT returns the TXT records of a DNS name, as net.Resolver.LookupTXT does
+type LookupTXT func(ctx context.Context, name string) ([]string, error)
+
+// DomainRecord returns the name of the TXT record proving the ownership of the domain
+func DomainRecord(domain string) string {
+	return domainRecordPrefix + strings.TrimSuffix(domain, ".")
+}
+
+// DomainRecordValue returns the value the TXT record must hold for the verification token
+func DomainRecordValue(token string) string {
+	return domainTokenPrefix + token
+}
+
+// VerifyDomain reports whether the TXT record of the domain holds the verification token.
+// A record that does not exist is not an error: the domain is simply not verified yet.
+func VerifyDomain(ctx context.Context, lookup LookupTXT, domain string, token string) (bool, error) {
+	records, err := lookup(ctx, DomainRecord(domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	want := DomainRecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,41 @@
+package sso_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/lenimbugua/bot/sso"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDomain(t *testing.T) {
+	records := map[string][]string{
+		"_bot-sso.example.com": {"v=spf1 -all", sso.DomainRecordValue("token")},
+	}
+	lookup := func(_ context.Context, name string) ([]string, error) {
+		if name == "_bot-sso.broken.com" {
+			return nil, errors.New("server failure")
+		}
+		if txt, ok := records[name]; ok {
+			return txt, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	verified, err := sso.VerifyDomain(context.Background(), lookup, "example.com", "token")
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	verified, err = sso.VerifyDomain(context.Background(), lookup, "example.com", "other token")
+	require.NoError(t, err)
+	require.False(t, verified)
+
+	verified, err = sso.VerifyDomain(context.Background(), lookup, "example.org", "token")
+	require.NoError(t, err)
+	require.False(t, verified)
+
+	_, err = sso.VerifyDomain(context.Background(), lookup, "broken.com", "token")
+	require.Error(t, err)
+}
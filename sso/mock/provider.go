@@ -0,0 +1,231 @@
+// Package mocksso runs a local OpenID Connect provider for tests and development
+package mocksso
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lenimbugua/bot/sso"
+	"github.com/lenimbugua/bot/util"
+)
+
+const keyID = "mock"
+
+// Identity is the user the provider logs in
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified *bool
+	Name          string
+}
+
+type authorization struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+	identity    Identity
+}
+
+// Provider is an OpenID Connect provider serving the authorization code flow with PKCE.
+// Every authorization request is approved for the current identity.
+type Provider struct {
+	ClientID     string
+	ClientSecret string
+	// IDTokenDuration is how long issued id tokens are valid for
+	IDTokenDuration time.Duration
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu             sync.Mutex
+	identity       Identity
+	authorizations map[string]authorization
+}
+
+// NewProvider starts a provider on a local address. Call Close when done.
+func NewProvider() (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &Provider{
+		ClientID:        util.RandomString(12),
+		ClientSecret:    util.RandomString(32),
+		key:             key,
+		authorizations:  make(map[string]authorization),
+		IDTokenDuration: time.Minute,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.discovery)
+	mux.HandleFunc("/authorize", provider.authorize)
+	mux.HandleFunc("/token", provider.token)
+	mux.HandleFunc("/jwks", provider.jwks)
+	provider.server = httptest.NewServer(mux)
+	return provider, nil
+}
+
+// Issuer returns the issuer URL of the provider
+func (provider *Provider) Issuer() string {
+	return provider.server.URL
+}
+
+// Close shuts the provider down
+func (provider *Provider) Close() {
+	provider.server.Close()
+}
+
+// SetIdentity sets the user logged in by the following authorization requests
+func (provider *Provider) SetIdentity(identity Identity) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	provider.identity = identity
+}
+
+// Authorize runs the authorization request at authURL like a browser would
+// and returns the code and state sent back to the redirect URI
+func (provider *Provider) Authorize(authURL string) (code string, state string, err error) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	location, err := res.Location()
+	if err != nil {
+		return "", "", err
+	}
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (provider *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 provider.Issuer(),
+		"authorization_endpoint": provider.Issuer() + "/authorize",
+		"token_endpoint":         provider.Issuer() + "/token",
+		"jwks_uri":               provider.Issuer() + "/jwks",
+	})
+}
+
+func (provider *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("redirect_uri") == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("client_id") != provider.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := util.RandomString(32)
+	provider.mu.Lock()
+	provider.authorizations[code] = authorization{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectURI: redirectURI.String(),
+		identity:    provider.identity,
+	}
+	provider.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (provider *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("client_id") != provider.ClientID || r.PostForm.Get("client_secret") != provider.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	// codes can only be redeemed once
+	provider.mu.Lock()
+	auth, ok := provider.authorizations[r.PostForm.Get("code")]
+	delete(provider.authorizations, r.PostForm.Get("code"))
+	provider.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != auth.redirectURI ||
+		sso.PKCEChallenge(r.PostForm.Get("code_verifier")) != auth.challenge {
+		writeError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := sso.Claims{
+		Email:         auth.identity.Email,
+		EmailVerified: auth.identity.EmailVerified,
+		Name:          auth.identity.Name,
+		Nonce:         auth.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    provider.Issuer(),
+			Subject:   auth.identity.Subject,
+			Audience:  jwt.ClaimStrings{provider.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(provider.IDTokenDuration)),
+		},
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(provider.key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": util.RandomString(32),
+		"token_type":   "Bearer",
+		"id_token":     signed,
+	})
+}
+
+func (provider *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	publicKey := provider.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,19 @@
+package sso
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/lenimbugua/bot/util"
+)
+
+// NewPKCEVerifier returns a random code verifier as described by RFC 7636
+func NewPKCEVerifier() (string, error) {
+	return util.NewSecret(32)
+}
+
+// PKCEChallenge returns the S256 code challenge of the verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,368 @@
+package sso
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Different types of error returned while logging in with an identity provider
+var (
+	ErrInvalidIDToken = errors.New("id token is invalid")
+	ErrNonceMismatch  = errors.New("id token nonce does not match the login")
+	ErrEmailMissing   = errors.New("id token does not contain an email")
+	ErrEmailNotProven = errors.New("identity provider has not verified the email")
+	ErrUnsafeURL      = errors.New("identity provider must be served over https from a public address")
+)
+
+// Config identifies this application to an identity provider
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Provider is an OpenID Connect identity provider found through discovery
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	httpClient *http.Client
+}
+
+// Client discovers identity providers and caches their configuration
+type Client struct {
+	httpClient *http.Client
+	// requireHTTPS refuses the issuers and endpoints that are not https
+	requireHTTPS bool
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+// NewClient creates a client making its requests with httpClient
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		httpClient: httpClient,
+		providers:  make(map[string]*Provider),
+	}
+}
+
+// NewPublicClient creates a client for the identity providers registered by company admins.
+// Their issuer and endpoints must be https and may only be reached on public addresses,
+// so that a connection cannot make the server call into its own network.
+func NewPublicClient(timeout time.Duration) *Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublic}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// the addresses are checked once resolved, when dialing, which a proxy would do instead
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	client := NewClient(&http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return ErrUnsafeURL
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		},
+	})
+	client.requireHTTPS = true
+	return client
+}
+
+// dialPublic refuses to connect to loopback, private, link-local and other non public addresses
+func dialPublic(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return ErrUnsafeURL
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is used by carrier-grade NAT, net.IP.IsPrivate leaves it out
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// checkURL refuses the URLs the client must not call
+func (client *Client) checkURL(rawURL string) error {
+	if !client.requireHTTPS {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrUnsafeURL
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return ErrUnsafeURL
+	}
+	return nil
+}
+
+// Provider returns the provider of the issuer, reading its discovery document on first use
+func (client *Client) Provider(ctx context.Context, issuer string) (*Provider, error) {
+	if err := client.checkURL(issuer); err != nil {
+		return nil, err
+	}
+
+	client.mu.Lock()
+	provider, ok := client.providers[issuer]
+	client.mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+
+	provider, err := Discover(ctx, client.httpClient, issuer)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range []string{provider.AuthorizationEndpoint, provider.TokenEndpoint, provider.JWKSURI} {
+		if err := client.checkURL(endpoint); err != nil {
+			return nil, fmt.Errorf("discovery document of %s: %w", issuer, err)
+		}
+	}
+
+	client.mu.Lock()
+	client.providers[issuer] = provider
+	client.mu.Unlock()
+	return provider, nil
+}
+
+// Discover reads the OpenID Connect discovery document of the issuer
+func Discover(ctx context.Context, httpClient *http.Client, issuer string) (*Provider, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	provider := &Provider{httpClient: httpClient}
+	if err := getJSON(ctx, httpClient, wellKnown, provider); err != nil {
+		return nil, fmt.Errorf("cannot discover %s: %w", issuer, err)
+	}
+
+	if provider.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", provider.Issuer, issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is incomplete", issuer)
+	}
+	return provider, nil
+}
+
+// AuthCodeURL returns the URL the browser is sent to so the user can log in.
+// The PKCE challenge is derived from verifier, which must be kept for Exchange.
+func (provider *Provider) AuthCodeURL(config Config, state string, nonce string, verifier string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {config.ClientID},
+		"redirect_uri":          {config.RedirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {PKCEChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + query.Encode()
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems the authorization code and returns the raw id token
+func (provider *Provider) Exchange(ctx context.Context, config Config, code string, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {config.RedirectURL},
+		"client_id":     {config.ClientID},
+		"client_secret": {config.ClientSecret},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := provider.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var rsp tokenResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&rsp); err != nil {
+		return "", fmt.Errorf("cannot decode token response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", res.StatusCode, rsp.Error, rsp.ErrorDescription)
+	}
+	if rsp.IDToken == "" {
+		return "", errors.New("token response does not contain an id token")
+	}
+	return rsp.IDToken, nil
+}
+
+// Claims are the id token claims used to find the user
+type Claims struct {
+	Email string `json:"email"`
+	// EmailVerified is nil when the provider does not send the claim
+	EmailVerified *bool  `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of the id token.
+// The provider must state that it verified the email: an id token without
+// email_verified is refused.
+func (provider *Provider) VerifyIDToken(ctx context.Context, config Config, rawIDToken string, nonce string) (*Claims, error) {
+	keys, err := provider.signingKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrInvalidIDToken
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		// a provider publishing a single key may leave out the key ID
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		return nil, ErrInvalidIDToken
+	})
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.ExpiresAt == nil || !claims.VerifyIssuer(provider.Issuer, true) || !claims.VerifyAudience(config.ClientID, true) {
+		return nil, ErrInvalidIDToken
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	if claims.Email == "" {
+		return nil, ErrEmailMissing
+	}
+	if claims.EmailVerified == nil || !*claims.EmailVerified {
+		return nil, ErrEmailNotProven
+	}
+	return claims, nil
+}
+
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// signingKeys fetches the RSA signing keys of the provider by key ID
+func (provider *Provider) signingKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := getJSON(ctx, provider.httpClient, provider.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("cannot fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			continue
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("provider does not publish any RSA signing key")
+	}
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, url string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
@@ -0,0 +1,265 @@
+package sso_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/sso"
+	mocksso "github.com/lenimbugua/bot/sso/mock"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockProvider(t *testing.T) (*mocksso.Provider, sso.Config) {
+	mock, err := mocksso.NewProvider()
+	require.NoError(t, err)
+	t.Cleanup(mock.Close)
+
+	config := sso.Config{
+		ClientID:     mock.ClientID,
+		ClientSecret: mock.ClientSecret,
+		RedirectURL:  "http://localhost:8080/sso/callback",
+	}
+	return mock, config
+}
+
+func verified(b bool) *bool {
+	return &b
+}
+
+// login runs the authorization request and redeems the code as the api would
+func login(t *testing.T, provider *sso.Provider, mock *mocksso.Provider, config sso.Config, nonce string) (*sso.Claims, error) {
+	verifier, err := sso.NewPKCEVerifier()
+	require.NoError(t, err)
+
+	state := util.RandomString(16)
+	code, gotState, err := mock.Authorize(provider.AuthCodeURL(config, state, nonce, verifier))
+	require.NoError(t, err)
+	require.Equal(t, state, gotState)
+
+	rawIDToken, err := provider.Exchange(context.Background(), config, code, verifier)
+	require.NoError(t, err)
+
+	return provider.VerifyIDToken(context.Background(), config, rawIDToken, nonce)
+}
+
+func TestDiscover(t *testing.T) {
+	mock, _ := newMockProvider(t)
+
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+	require.Equal(t, mock.Issuer(), provider.Issuer)
+	require.Equal(t, mock.Issuer()+"/authorize", provider.AuthorizationEndpoint)
+	require.Equal(t, mock.Issuer()+"/token", provider.TokenEndpoint)
+
+	// the issuer in the document must be the issuer asked for
+	_, err = sso.Discover(context.Background(), http.DefaultClient, mock.Issuer()+"/")
+	require.Error(t, err)
+}
+
+func TestClientCachesProvider(t *testing.T) {
+	mock, _ := newMockProvider(t)
+	client := sso.NewClient(http.DefaultClient)
+
+	provider1, err := client.Provider(context.Background(), mock.Issuer())
+	require.NoError(t, err)
+
+	provider2, err := client.Provider(context.Background(), mock.Issuer())
+	require.NoError(t, err)
+	require.Same(t, provider1, provider2)
+}
+
+func TestPublicClientRefusesUnsafeIssuer(t *testing.T) {
+	local := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(local.Close)
+	localURL, err := url.Parse(local.URL)
+	require.NoError(t, err)
+
+	client := sso.NewPublicClient(time.Second)
+	for _, issuer := range []string{
+		"http://accounts.example.com",
+		"https://127.0.0.1",
+		"https://[::1]:8443",
+		"https://10.0.0.1",
+		"https://192.168.1.1",
+		"https://169.254.169.254",
+		"https://user@accounts.example.com",
+		// a name resolving to a loopback address is refused when dialing
+		"https://localhost:" + localURL.Port(),
+	} {
+		_, err := client.Provider(context.Background(), issuer)
+		require.ErrorIs(t, err, sso.ErrUnsafeURL, issuer)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(provider.AuthCodeURL(config, "state", "nonce", "verifier"))
+	require.NoError(t, err)
+
+	query := authURL.Query()
+	require.Equal(t, "code", query.Get("response_type"))
+	require.Equal(t, config.ClientID, query.Get("client_id"))
+	require.Equal(t, config.RedirectURL, query.Get("redirect_uri"))
+	require.Equal(t, "state", query.Get("state"))
+	require.Equal(t, "nonce", query.Get("nonce"))
+	require.Equal(t, sso.PKCEChallenge("verifier"), query.Get("code_challenge"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	require.Contains(t, query.Get("scope"), "openid")
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// example from RFC 7636 appendix B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", sso.PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestLogin(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	email := util.RandomEmail()
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: email, EmailVerified: verified(true), Name: "Alice"})
+
+	claims, err := login(t, provider, mock, config, "nonce")
+	require.NoError(t, err)
+	require.Equal(t, email, claims.Email)
+	require.Equal(t, "1", claims.Subject)
+	require.Equal(t, "Alice", claims.Name)
+}
+
+func TestLoginEmailVerifiedMissing(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail()})
+
+	// a provider that does not say it verified the email is not trusted for it
+	_, err = login(t, provider, mock, config, "nonce")
+	require.ErrorIs(t, err, sso.ErrEmailNotProven)
+}
+
+func TestLoginEmailNotVerified(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail(), EmailVerified: verified(false)})
+
+	_, err = login(t, provider, mock, config, "nonce")
+	require.ErrorIs(t, err, sso.ErrEmailNotProven)
+}
+
+func TestLoginEmailMissing(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1"})
+
+	_, err = login(t, provider, mock, config, "nonce")
+	require.ErrorIs(t, err, sso.ErrEmailMissing)
+}
+
+func TestLoginExpiredIDToken(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.IDTokenDuration = -time.Minute
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail()})
+
+	_, err = login(t, provider, mock, config, "nonce")
+	require.ErrorIs(t, err, sso.ErrInvalidIDToken)
+}
+
+func TestVerifyIDTokenWrongNonce(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail()})
+
+	verifier, err := sso.NewPKCEVerifier()
+	require.NoError(t, err)
+	code, _, err := mock.Authorize(provider.AuthCodeURL(config, "state", "nonce", verifier))
+	require.NoError(t, err)
+
+	rawIDToken, err := provider.Exchange(context.Background(), config, code, verifier)
+	require.NoError(t, err)
+
+	_, err = provider.VerifyIDToken(context.Background(), config, rawIDToken, "other nonce")
+	require.ErrorIs(t, err, sso.ErrNonceMismatch)
+}
+
+func TestVerifyIDTokenWrongAudience(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail()})
+
+	verifier, err := sso.NewPKCEVerifier()
+	require.NoError(t, err)
+	code, _, err := mock.Authorize(provider.AuthCodeURL(config, "state", "nonce", verifier))
+	require.NoError(t, err)
+
+	rawIDToken, err := provider.Exchange(context.Background(), config, code, verifier)
+	require.NoError(t, err)
+
+	config.ClientID = "another client"
+	_, err = provider.VerifyIDToken(context.Background(), config, rawIDToken, "nonce")
+	require.ErrorIs(t, err, sso.ErrInvalidIDToken)
+}
+
+func TestVerifyIDTokenTampered(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	_, err = provider.VerifyIDToken(context.Background(), config, "not.a.token", "nonce")
+	require.ErrorIs(t, err, sso.ErrInvalidIDToken)
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	mock.SetIdentity(mocksso.Identity{Subject: "1", Email: util.RandomEmail()})
+
+	verifier, err := sso.NewPKCEVerifier()
+	require.NoError(t, err)
+	code, _, err := mock.Authorize(provider.AuthCodeURL(config, "state", "nonce", verifier))
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(context.Background(), config, code, "wrong verifier")
+	require.Error(t, err)
+
+	// a failed exchange burns the code
+	_, err = provider.Exchange(context.Background(), config, code, verifier)
+	require.Error(t, err)
+}
+
+func TestExchangeWrongSecret(t *testing.T) {
+	mock, config := newMockProvider(t)
+	provider, err := sso.Discover(context.Background(), http.DefaultClient, mock.Issuer())
+	require.NoError(t, err)
+
+	verifier, err := sso.NewPKCEVerifier()
+	require.NoError(t, err)
+	code, _, err := mock.Authorize(provider.AuthCodeURL(config, "state", "nonce", verifier))
+	require.NoError(t, err)
+
+	config.ClientSecret = "wrong"
+	_, err = provider.Exchange(context.Background(), config, code, verifier)
+	require.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lenimbugua/bot/util"
+)
+
+// Token types selectable with TOKEN_TYPE
+const (
+	TypePaseto       = "paseto"
+	TypeJWT          = "jwt"
+	TypePasetoPublic = "paseto_public"
+	TypeJWTPublic    = "jwt_public"
+)
+
+// NewMakerFromConfig creates the token maker selected by TOKEN_TYPE.
+// Symmetric makers use the key ring in TOKEN_SYMMETRIC_KEYS, falling back to the
+// single TOKEN_SYMMETRIC_KEY; public key makers sign with TOKEN_PRIVATE_KEY_FILE.
+func NewMakerFromConfig(config util.Config) (Maker, error) {
+	switch config.TokenType {
+	case "", TypePaseto, TypeJWT:
+		keys := NewSingleKeyRing(config.TokenSymmetricKey)
+		if config.TokenSymmetricKeys != "" {
+			var err error
+			keys, err = ParseKeyRing(config.TokenSymmetricKeys, config.TokenActiveKeyID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if config.TokenType == TypeJWT {
+			return NewJWTMakerWithKeyRing(keys)
+		}
+		return NewPasetoMakerWithKeyRing(keys)
+
+	case TypePasetoPublic, TypeJWTPublic:
+		privateKey, publicKeys, err := loadTokenKeys(config)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.TokenType == TypeJWTPublic {
+			return NewJWTPublicMaker(config.TokenActiveKeyID, privateKey, publicKeys)
+		}
+
+		ed25519Key, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s needs an Ed25519 private key", TypePasetoPublic)
+		}
+		return NewPasetoPublicMaker(config.TokenActiveKeyID, ed25519Key, publicKeys)
+	}
+
+	return nil, fmt.Errorf("unknown token type %q", config.TokenType)
+}
+
+// loadTokenKeys reads the signing key from TOKEN_PRIVATE_KEY_FILE and the extra
+// verification keys from TOKEN_PUBLIC_KEY_FILES, a comma separated list of id:path pairs
+func loadTokenKeys(config util.Config) (crypto.Signer, map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(config.TokenPrivateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read token private key: %w", err)
+	}
+
+	privateKey, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse token private key: %w", err)
+	}
+
+	publicKeys := make(map[string]crypto.PublicKey)
+	for _, entry := range strings.Split(config.TokenPublicKeyFiles, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, path, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid public key entry: must be formatted as id:path")
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read public key %q: %w", id, err)
+		}
+
+		publicKeys[id], err = ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse public key %q: %w", id, err)
+		}
+	}
+
+	return privateKey, publicKeys, nil
+}
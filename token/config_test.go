@@ -0,0 +1,133 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMakerFromConfigKeyRing(t *testing.T) {
+	oldKey := util.RandomString(32)
+	newKey := util.RandomString(32)
+
+	oldMaker, err := NewMakerFromConfig(util.Config{TokenSymmetricKey: oldKey})
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	maker, err := NewMakerFromConfig(util.Config{
+		TokenSymmetricKey:  oldKey,
+		TokenSymmetricKeys: "old:" + oldKey + ",new:" + newKey,
+		TokenActiveKeyID:   "new",
+	})
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	_, err = NewMakerFromConfig(util.Config{
+		TokenSymmetricKeys: "old:" + oldKey + ",new:" + newKey,
+		TokenActiveKeyID:   "missing",
+	})
+	require.Error(t, err)
+}
+
+func writeKeyFile(t *testing.T, block *pem.Block) string {
+	path := filepath.Join(t.TempDir(), util.RandomString(6)+".pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+	return path
+}
+
+func TestNewMakerFromConfigTypes(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	privateKeyFile := writeKeyFile(t, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	oldPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(oldPublicKey)
+	require.NoError(t, err)
+	publicKeyFile := writeKeyFile(t, &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	testCases := []struct {
+		name      string
+		config    util.Config
+		makerType interface{}
+		keys      int
+	}{
+		{
+			name:      "Default",
+			config:    util.Config{TokenSymmetricKey: util.RandomString(32)},
+			makerType: &PasetoMaker{},
+		},
+		{
+			name:      "JWT",
+			config:    util.Config{TokenType: "jwt", TokenSymmetricKey: util.RandomString(32)},
+			makerType: &JWTMaker{},
+		},
+		{
+			name: "PasetoPublic",
+			config: util.Config{
+				TokenType:           "paseto_public",
+				TokenActiveKeyID:    "new",
+				TokenPrivateKeyFile: privateKeyFile,
+				TokenPublicKeyFiles: "old:" + publicKeyFile,
+			},
+			makerType: &PasetoPublicMaker{},
+			keys:      2,
+		},
+		{
+			name: "JWTPublic",
+			config: util.Config{
+				TokenType:           "jwt_public",
+				TokenActiveKeyID:    "new",
+				TokenPrivateKeyFile: privateKeyFile,
+			},
+			makerType: &JWTPublicMaker{},
+			keys:      1,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewMakerFromConfig(tc.config)
+			require.NoError(t, err)
+			require.IsType(t, tc.makerType, maker)
+
+			if tc.keys > 0 {
+				require.Len(t, maker.(PublicKeyProvider).PublicKeys(), tc.keys)
+			}
+		})
+	}
+}
+
+func TestNewMakerFromConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config util.Config
+	}{
+		{name: "UnknownType", config: util.Config{TokenType: "magic"}},
+		{name: "MissingPrivateKey", config: util.Config{TokenType: "paseto_public", TokenPrivateKeyFile: "/does/not/exist"}},
+		{name: "InvalidPublicKeyEntry", config: util.Config{TokenType: "jwt_public", TokenPublicKeyFiles: "no-path"}},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewMakerFromConfig(tc.config)
+			require.Error(t, err)
+		})
+	}
+}
@@ -12,37 +12,80 @@ const minSecretKeySize = 32
 
 // JWTMaker is a JSON Web Token maker
 type JWTMaker struct {
-	secretKey string
+	keys *KeyRing
 }
 
 // NewJWTMaker creates a new JWTMaker
 func NewJWTMaker(secretKey string) (Maker, error) {
-	if len(secretKey) < minSecretKeySize {
-		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	return NewJWTMakerWithKeyRing(NewSingleKeyRing(secretKey))
+}
+
+// NewJWTMakerWithKeyRing creates a new JWTMaker that supports key rotation
+func NewJWTMakerWithKeyRing(keys *KeyRing) (Maker, error) {
+	err := keys.validate(func(key []byte) error {
+		if len(key) < minSecretKeySize {
+			return fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &JWTMaker{secretKey}, nil
+	return &JWTMaker{keys}, nil
 }
 
 // CreateToken creates a new token for a specific user
-func (maker *JWTMaker) CreateToken(phone string, userID int64, name string, companyID int64, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(phone, userID, name, companyID, duration)
+func (maker *JWTMaker) CreateToken(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(phone, userID, name, companyID, role, scopes, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
+	keyID, key := maker.keys.Active()
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	token, err := jwtToken.SignedString([]byte(maker.secretKey))
+	if keyID != "" {
+		jwtToken.Header["kid"] = keyID
+	}
+	token, err := jwtToken.SignedString(key)
 	return token, payload, err
 }
 
 // VerifyToken checks if the token is valid or not
 func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
+	parser := jwt.NewParser()
+	unverified, _, err := parser.ParseUnverified(token, &Payload{})
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	keyID, _ := unverified.Header["kid"].(string)
+	if keyID != "" {
+		key, ok := maker.keys.Key(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return maker.verify(token, key)
+	}
+
+	// tokens issued before key IDs were introduced are tried against every key
+	var payload *Payload
+	for _, id := range maker.keys.IDs() {
+		key, _ := maker.keys.Key(id)
+		payload, err = maker.verify(token, key)
+		if err != ErrInvalidToken {
+			break
+		}
+	}
+	return payload, err
+}
+
+func (maker *JWTMaker) verify(token string, key []byte) (*Payload, error) {
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
 			return nil, ErrInvalidToken
 		}
-		return []byte(maker.secretKey), nil
+		return key, nil
 	}
 
 	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
@@ -18,12 +18,14 @@ func TestJWTMaker(t *testing.T) {
 	userID := util.RandInt(1, 1000)
 	companyID := util.RandInt(1, 1000)
 	name := util.RandomString(6)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(phone, userID, name, companyID, duration)
+	token, payload, err := maker.CreateToken(phone, userID, name, companyID, role, scopes, duration)
 	require.NoError(err)
 	require.NotEmpty(token)
 	require.NotEmpty(payload)
@@ -34,6 +36,7 @@ func TestJWTMaker(t *testing.T) {
 
 	require.NotZero(payload.ID)
 	require.Equal(name, payload.Name)
+	require.Equal(role, payload.Role)
 	require.Equal(userID, payload.UserID)
 	require.Equal(phone, payload.Phone)
 	require.WithinDuration(issuedAt, payload.IssuedAt, time.Second)
@@ -49,8 +52,10 @@ func TestExpiredJWTToken(t *testing.T) {
 	userID := util.RandInt(1, 1000)
 	companyID := util.RandInt(1, 1000)
 	name := util.RandomString(6)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
 
-	token, payload, err := maker.CreateToken(phone, userID, name, companyID, -time.Minute)
+	token, payload, err := maker.CreateToken(phone, userID, name, companyID, role, scopes, -time.Minute)
 	require.NoError(err)
 	require.NotEmpty(token)
 	require.NotEmpty(payload)
@@ -66,7 +71,9 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	userID := util.RandInt(1, 1000)
 	companyID := util.RandInt(1, 1000)
 	name := util.RandomString(6)
-	payload, err := NewPayload(phone, userID, name, companyID, time.Minute)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
+	payload, err := NewPayload(phone, userID, name, companyID, role, scopes, time.Minute)
 	require := require.New(t)
 	require.NoError(err)
 
@@ -82,3 +89,64 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	require.EqualError(err, ErrInvalidToken.Error())
 	require.Nil(payload)
 }
+
+func TestJWTMakerKeyRotation(t *testing.T) {
+	oldKey := util.RandomString(32)
+	newKey := util.RandomString(32)
+
+	oldRing, err := ParseKeyRing("old:"+oldKey, "old")
+	require.NoError(t, err)
+	oldMaker, err := NewJWTMakerWithKeyRing(oldRing)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	rotatedRing, err := ParseKeyRing("old:"+oldKey+",new:"+newKey, "new")
+	require.NoError(t, err)
+	rotatedMaker, err := NewJWTMakerWithKeyRing(rotatedRing)
+	require.NoError(t, err)
+
+	_, err = rotatedMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := rotatedMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	retiredRing, err := ParseKeyRing("new:"+newKey, "new")
+	require.NoError(t, err)
+	retiredMaker, err := NewJWTMakerWithKeyRing(retiredRing)
+	require.NoError(t, err)
+
+	_, err = retiredMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestJWTMakerAcceptsTokensWithoutKeyID(t *testing.T) {
+	key := util.RandomString(32)
+	payload, err := NewPayload(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString([]byte(key))
+	require.NoError(t, err)
+
+	ring, err := ParseKeyRing("legacy:"+key+",new:"+util.RandomString(32), "new")
+	require.NoError(t, err)
+	maker, err := NewJWTMakerWithKeyRing(ring)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+
+	expiredPayload, err := NewPayload(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, -time.Minute)
+	require.NoError(t, err)
+	expiredToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, expiredPayload).SignedString([]byte(key))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(expiredToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+}
@@ -0,0 +1,143 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minRSAKeyBits = 2048
+
+// JWTPublicMaker is a JSON Web Token maker signing with a private key.
+// RSA keys sign with RS256 and Ed25519 keys with EdDSA, so other services
+// can verify tokens with the public key only.
+type JWTPublicMaker struct {
+	keyID      string
+	privateKey crypto.Signer
+	method     jwt.SigningMethod
+	publicKeys map[string]crypto.PublicKey
+}
+
+// NewJWTPublicMaker creates a new JWTPublicMaker.
+// The public key of the signing key is always accepted; verificationKeys holds
+// extra keys, e.g. the previous signing key during a rotation.
+// A nil private key creates a maker that can only verify tokens.
+func NewJWTPublicMaker(keyID string, privateKey crypto.Signer, verificationKeys map[string]crypto.PublicKey) (Maker, error) {
+	maker := &JWTPublicMaker{
+		keyID:      keyID,
+		privateKey: privateKey,
+		publicKeys: make(map[string]crypto.PublicKey),
+	}
+
+	for id, key := range verificationKeys {
+		if _, err := jwtSigningMethod(key); err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		maker.publicKeys[id] = key
+	}
+
+	if privateKey != nil {
+		if keyID == "" {
+			return nil, fmt.Errorf("signing key ID cannot be empty")
+		}
+
+		method, err := jwtSigningMethod(privateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+		maker.method = method
+		maker.publicKeys[keyID] = privateKey.Public()
+	}
+
+	if len(maker.publicKeys) == 0 {
+		return nil, fmt.Errorf("at least one key is needed")
+	}
+	return maker, nil
+}
+
+// jwtSigningMethod returns the signing method matching the type of the key
+func jwtSigningMethod(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("invalid key size: RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", publicKey)
+}
+
+// CreateToken creates a new token for a specific user
+func (maker *JWTPublicMaker) CreateToken(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(phone, userID, name, companyID, role, scopes, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	if maker.privateKey == nil {
+		return "", payload, ErrSigningDisabled
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.method, payload)
+	jwtToken.Header["kid"] = maker.keyID
+	token, err := jwtToken.SignedString(maker.privateKey)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *JWTPublicMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		publicKey, ok := maker.publicKeys[keyID]
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		// the algorithm must match the key so that a public key is never used as an HMAC secret
+		method, err := jwtSigningMethod(publicKey)
+		if err != nil || token.Method.Alg() != method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		return publicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// PublicKeys returns every key tokens of the maker can be verified with
+func (maker *JWTPublicMaker) PublicKeys() []JSONWebKey {
+	ids := make([]string, 0, len(maker.publicKeys))
+	for id := range maker.publicKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	keys := make([]JSONWebKey, 0, len(ids))
+	for _, id := range ids {
+		method, _ := jwtSigningMethod(maker.publicKeys[id])
+		keys = append(keys, newJSONWebKey(id, method.Alg(), maker.publicKeys[id]))
+	}
+	return keys
+}
@@ -0,0 +1,126 @@
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomRSAKey(t *testing.T) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestJWTPublicMaker(t *testing.T) {
+	testCases := []struct {
+		name       string
+		privateKey crypto.Signer
+		alg        string
+	}{
+		{name: "RS256", privateKey: randomRSAKey(t), alg: "RS256"},
+		{name: "EdDSA", privateKey: randomEd25519Key(t), alg: "EdDSA"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			maker, err := NewJWTPublicMaker("k1", tc.privateKey, nil)
+			require.NoError(err)
+
+			phone := util.RandomPhoneNumber()
+			userID := util.RandInt(1, 1000)
+			name := util.RandomString(6)
+
+			token, _, err := maker.CreateToken(phone, userID, name, 1, util.MemberRole, nil, time.Minute)
+			require.NoError(err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Payload{})
+			require.NoError(err)
+			require.Equal(tc.alg, parsed.Method.Alg())
+			require.Equal("k1", parsed.Header["kid"])
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(err)
+			require.Equal(phone, payload.Phone)
+			require.Equal(userID, payload.UserID)
+			require.Equal(name, payload.Name)
+
+			keys := maker.(PublicKeyProvider).PublicKeys()
+			require.Len(keys, 1)
+			require.Equal("k1", keys[0].KeyID)
+			require.Equal(tc.alg, keys[0].Algorithm)
+
+			expired, _, err := maker.CreateToken(phone, userID, name, 1, util.MemberRole, nil, -time.Minute)
+			require.NoError(err)
+			_, err = maker.VerifyToken(expired)
+			require.EqualError(err, ErrExpiredToken.Error())
+		})
+	}
+}
+
+func TestJWTPublicMakerRejectsForeignTokens(t *testing.T) {
+	privateKey := randomRSAKey(t)
+	maker, err := NewJWTPublicMaker("k1", privateKey, nil)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	// an HMAC token must not be accepted even when it names a known key
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	hmacToken.Header["kid"] = "k1"
+	token, err := hmacToken.SignedString([]byte(util.RandomString(32)))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	otherMaker, err := NewJWTPublicMaker("k1", randomRSAKey(t), nil)
+	require.NoError(t, err)
+	otherToken, _, err := otherMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(otherToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestJWTPublicMakerKeyRotation(t *testing.T) {
+	oldKey := randomEd25519Key(t)
+	oldMaker, err := NewJWTPublicMaker("old", oldKey, nil)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	newMaker, err := NewJWTPublicMaker("new", randomRSAKey(t), map[string]crypto.PublicKey{
+		"old": oldKey.Public(),
+	})
+	require.NoError(t, err)
+
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	verifier, err := NewJWTPublicMaker("", nil, map[string]crypto.PublicKey{"old": oldKey.Public()})
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(oldToken)
+	require.NoError(t, err)
+	_, _, err = verifier.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.ErrorIs(t, err, ErrSigningDisabled)
+}
+
+func TestJWTPublicMakerWeakRSAKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = NewJWTPublicMaker("k1", privateKey, nil)
+	require.Error(t, err)
+}
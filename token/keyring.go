@@ -0,0 +1,109 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeyRing holds the secret keys a maker signs and verifies tokens with.
+// New tokens are signed with the active key. Tokens signed with any key still
+// in the ring are accepted, so a key is retired by removing it from the ring.
+type KeyRing struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing creates a new KeyRing from keys indexed by their ID
+func NewKeyRing(activeID string, keys map[string]string) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key ring needs at least one key")
+	}
+
+	if activeID == "" && len(keys) == 1 {
+		for id := range keys {
+			activeID = id
+		}
+	}
+
+	ring := &KeyRing{
+		activeID: activeID,
+		keys:     make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		if id == "" {
+			return nil, fmt.Errorf("key ID cannot be empty")
+		}
+		ring.keys[id] = []byte(key)
+	}
+
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the key ring", activeID)
+	}
+	return ring, nil
+}
+
+// NewSingleKeyRing creates a KeyRing holding only the given key.
+// The key has no ID, so tokens signed with it carry none and stay valid
+// when the key is later moved into a ring under any ID.
+func NewSingleKeyRing(key string) *KeyRing {
+	return &KeyRing{
+		keys: map[string][]byte{"": []byte(key)},
+	}
+}
+
+// ParseKeyRing creates a KeyRing from a comma separated list of id:key pairs,
+// e.g. "2022-10:first-secret,2023-01:second-secret"
+func ParseKeyRing(keyList string, activeID string) (*KeyRing, error) {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(keyList, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, key, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key entry: must be formatted as id:key")
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", id)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyRing(activeID, keys)
+}
+
+// Active returns the ID and the secret of the key new tokens are signed with
+func (ring *KeyRing) Active() (string, []byte) {
+	return ring.activeID, ring.keys[ring.activeID]
+}
+
+// Key returns the secret of the key with the given ID
+func (ring *KeyRing) Key(id string) ([]byte, bool) {
+	key, ok := ring.keys[id]
+	return key, ok
+}
+
+// IDs returns the IDs of every key in the ring, active key first
+func (ring *KeyRing) IDs() []string {
+	ids := make([]string, 0, len(ring.keys))
+	for id := range ring.keys {
+		if id != ring.activeID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return append([]string{ring.activeID}, ids...)
+}
+
+// validate checks every key of the ring with the given function
+func (ring *KeyRing) validate(check func(key []byte) error) error {
+	for _, id := range ring.IDs() {
+		if err := check(ring.keys[id]); err != nil {
+			return fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyRing(t *testing.T) {
+	first := util.RandomString(32)
+	second := util.RandomString(32)
+
+	ring, err := ParseKeyRing("k1:"+first+", k2:"+second, "k2")
+	require.NoError(t, err)
+
+	id, key := ring.Active()
+	require.Equal(t, "k2", id)
+	require.Equal(t, []byte(second), key)
+
+	key, ok := ring.Key("k1")
+	require.True(t, ok)
+	require.Equal(t, []byte(first), key)
+
+	_, ok = ring.Key("k3")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"k2", "k1"}, ring.IDs())
+}
+
+func TestParseKeyRingSingleKeyIsActive(t *testing.T) {
+	ring, err := ParseKeyRing("only:"+util.RandomString(32), "")
+	require.NoError(t, err)
+
+	id, _ := ring.Active()
+	require.Equal(t, "only", id)
+}
+
+func TestParseKeyRingErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		keys     string
+		activeID string
+	}{
+		{name: "Empty", keys: "", activeID: ""},
+		{name: "MissingID", keys: "no-separator", activeID: ""},
+		{name: "EmptyID", keys: ":secret", activeID: ""},
+		{name: "Duplicate", keys: "k1:a,k1:b", activeID: "k1"},
+		{name: "UnknownActive", keys: "k1:a,k2:b", activeID: "k3"},
+		{name: "AmbiguousActive", keys: "k1:a,k2:b", activeID: ""},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseKeyRing(tc.keys, tc.activeID)
+			require.Error(t, err)
+		})
+	}
+}
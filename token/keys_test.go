@@ -0,0 +1,60 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyPEM(t *testing.T) {
+	ed25519Key := randomEd25519Key(t)
+	rsaKey := randomRSAKey(t)
+
+	for _, privateKey := range []interface{}{ed25519Key, rsaKey} {
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err)
+
+		signer, err := ParsePrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		require.NoError(t, err)
+
+		der, err = x509.MarshalPKIXPublicKey(signer.Public())
+		require.NoError(t, err)
+
+		publicKey, err := ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		require.NoError(t, err)
+		require.Equal(t, signer.Public(), publicKey)
+	}
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	signer, err := ParsePrivateKeyPEM(pkcs1)
+	require.NoError(t, err)
+	require.IsType(t, &rsa.PrivateKey{}, signer)
+
+	_, err = ParsePrivateKeyPEM([]byte("not a key"))
+	require.Error(t, err)
+	_, err = ParsePublicKeyPEM([]byte("not a key"))
+	require.Error(t, err)
+}
+
+func TestNewJSONWebKey(t *testing.T) {
+	ed25519Key := randomEd25519Key(t)
+	jwk := newJSONWebKey("k1", "EdDSA", ed25519Key.Public())
+	require.Equal(t, "OKP", jwk.KeyType)
+	require.Equal(t, "Ed25519", jwk.Curve)
+	require.Equal(t, "sig", jwk.Use)
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	require.NoError(t, err)
+	require.Equal(t, []byte(ed25519Key.Public().(ed25519.PublicKey)), x)
+
+	rsaKey := randomRSAKey(t)
+	jwk = newJSONWebKey("k2", "RS256", rsaKey.Public())
+	require.Equal(t, "RSA", jwk.KeyType)
+	require.Equal(t, "AQAB", jwk.E)
+	require.NotEmpty(t, jwk.N)
+}
@@ -7,7 +7,7 @@ import (
 // Maker is an interface for managing tokens
 type Maker interface {
 	// CreateToken creates a new token for a specific user
-	CreateToken(phone string, userID int64, name string, companyID int64, duration time.Duration) (string, *Payload, error)
+	CreateToken(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
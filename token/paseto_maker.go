@@ -10,32 +10,54 @@ import (
 
 // PasetoMaker is a PASETO token maker
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto *paseto.V2
+	keys   *KeyRing
+}
+
+// pasetoFooter is stored unencrypted but authenticated at the end of the token
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
 }
 
 // NewPasetoMaker creates a new PasetoMaker
 func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+	return NewPasetoMakerWithKeyRing(NewSingleKeyRing(symmetricKey))
+}
+
+// NewPasetoMakerWithKeyRing creates a new PasetoMaker that supports key rotation
+func NewPasetoMakerWithKeyRing(keys *KeyRing) (Maker, error) {
+	err := keys.validate(func(key []byte) error {
+		if len(key) != chacha20poly1305.KeySize {
+			return fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto: paseto.NewV2(),
+		keys:   keys,
 	}
 
 	return maker, nil
 }
 
 // CreateToken creates a new token for a specific username and duration
-func (maker *PasetoMaker) CreateToken(phone string, userID int64, name string,companyID int64, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(phone, userID, name, companyID, duration)
+func (maker *PasetoMaker) CreateToken(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(phone, userID, name, companyID, role, scopes, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
-	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	keyID, key := maker.keys.Active()
+	var footer interface{}
+	if keyID != "" {
+		footer = pasetoFooter{KeyID: keyID}
+	}
+
+	token, err := maker.paseto.Encrypt(key, payload, footer)
 	return token, payload, err
 }
 
@@ -43,7 +65,7 @@ func (maker *PasetoMaker) CreateToken(phone string, userID int64, name string,co
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	payload := &Payload{}
 
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
+	err := maker.decrypt(token, payload)
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
@@ -55,3 +77,24 @@ func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+// decrypt opens the token with the key named in its footer.
+// Tokens issued before key IDs were introduced have no footer and are tried against every key.
+func (maker *PasetoMaker) decrypt(token string, payload *Payload) error {
+	var footer pasetoFooter
+	if err := paseto.ParseFooter(token, &footer); err == nil && footer.KeyID != "" {
+		key, ok := maker.keys.Key(footer.KeyID)
+		if !ok {
+			return ErrInvalidToken
+		}
+		return maker.paseto.Decrypt(token, key, payload, nil)
+	}
+
+	for _, id := range maker.keys.IDs() {
+		key, _ := maker.keys.Key(id)
+		if err := maker.paseto.Decrypt(token, key, payload, nil); err == nil {
+			return nil
+		}
+	}
+	return ErrInvalidToken
+}
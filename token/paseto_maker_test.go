@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lenimbugua/bot/util"
+	"github.com/o1egl/paseto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,11 +17,13 @@ func TestPasetoMaker(t *testing.T) {
 	userID := util.RandInt(1, 1000)
 	companyID := util.RandInt(1, 1000)
 	name := util.RandomString(6)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
-	token, payload, err := maker.CreateToken(phone, userID, name, companyID, duration)
+	token, payload, err := maker.CreateToken(phone, userID, name, companyID, role, scopes, duration)
 
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
@@ -32,6 +35,8 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, name, payload.Name)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, scopes, payload.Scopes)
 	require.Equal(t, userID, payload.UserID)
 	require.Equal(t, phone, payload.Phone)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
@@ -46,7 +51,9 @@ func TestExpiredPasetoToken(t *testing.T) {
 	userID := util.RandInt(1, 1000)
 	companyID := util.RandInt(1, 1000)
 	name := util.RandomString(6)
-	token, payload, err := maker.CreateToken(phone, userID, name, companyID, -time.Minute)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
+	token, payload, err := maker.CreateToken(phone, userID, name, companyID, role, scopes, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -56,3 +63,69 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestPasetoMakerKeyRotation(t *testing.T) {
+	oldKey := util.RandomString(32)
+	newKey := util.RandomString(32)
+
+	oldRing, err := ParseKeyRing("old:"+oldKey, "old")
+	require.NoError(t, err)
+	oldMaker, err := NewPasetoMakerWithKeyRing(oldRing)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	// the new key is active and the old one is still accepted
+	rotatedRing, err := ParseKeyRing("old:"+oldKey+",new:"+newKey, "new")
+	require.NoError(t, err)
+	rotatedMaker, err := NewPasetoMakerWithKeyRing(rotatedRing)
+	require.NoError(t, err)
+
+	_, err = rotatedMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := rotatedMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// the old key is retired
+	retiredRing, err := ParseKeyRing("new:"+newKey, "new")
+	require.NoError(t, err)
+	retiredMaker, err := NewPasetoMakerWithKeyRing(retiredRing)
+	require.NoError(t, err)
+
+	_, err = retiredMaker.VerifyToken(newToken)
+	require.NoError(t, err)
+	_, err = retiredMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestPasetoMakerAcceptsTokensWithoutKeyID(t *testing.T) {
+	key := util.RandomString(32)
+	payload, err := NewPayload(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	// tokens issued before key IDs were introduced have no footer
+	legacyToken, err := paseto.NewV2().Encrypt([]byte(key), payload, nil)
+	require.NoError(t, err)
+
+	ring, err := ParseKeyRing("legacy:"+key+",new:"+util.RandomString(32), "new")
+	require.NoError(t, err)
+	maker, err := NewPasetoMakerWithKeyRing(ring)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(legacyToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+}
+
+func TestPasetoMakerInvalidKeyRing(t *testing.T) {
+	ring, err := ParseKeyRing("good:"+util.RandomString(32)+",short:abc", "good")
+	require.NoError(t, err)
+
+	_, err = NewPasetoMakerWithKeyRing(ring)
+	require.Error(t, err)
+}
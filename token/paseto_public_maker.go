@@ -0,0 +1,199 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const pasetoV4PublicHeader = "v4.public."
+
+// PasetoPublicMaker is a PASETO v4.public token maker.
+// Tokens are signed with Ed25519 so that other services can verify them
+// with the public key only.
+type PasetoPublicMaker struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+	publicKeys map[string]ed25519.PublicKey
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker.
+// The public key of the signing key is always accepted; verificationKeys holds
+// extra keys, e.g. the previous signing key during a rotation.
+// A nil private key creates a maker that can only verify tokens.
+func NewPasetoPublicMaker(keyID string, privateKey ed25519.PrivateKey, verificationKeys map[string]crypto.PublicKey) (Maker, error) {
+	maker := &PasetoPublicMaker{
+		keyID:      keyID,
+		privateKey: privateKey,
+		publicKeys: make(map[string]ed25519.PublicKey),
+	}
+
+	for id, key := range verificationKeys {
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key %q: PASETO v4.public needs an Ed25519 key", id)
+		}
+		maker.publicKeys[id] = publicKey
+	}
+
+	if privateKey != nil {
+		if len(privateKey) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid private key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+		}
+		if keyID == "" {
+			return nil, fmt.Errorf("signing key ID cannot be empty")
+		}
+		maker.publicKeys[keyID] = privateKey.Public().(ed25519.PublicKey)
+	}
+
+	if len(maker.publicKeys) == 0 {
+		return nil, fmt.Errorf("at least one key is needed")
+	}
+	return maker, nil
+}
+
+// CreateToken creates a new token for a specific user
+func (maker *PasetoPublicMaker) CreateToken(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(phone, userID, name, companyID, role, scopes, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	if maker.privateKey == nil {
+		return "", payload, ErrSigningDisabled
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	footer, err := json.Marshal(pasetoFooter{KeyID: maker.keyID})
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.sign(message, footer, nil), payload, nil
+}
+
+// sign creates a token of the message and footer, signing the implicit assertion with them.
+// Tokens of the maker carry no implicit assertion, it is kept for the spec test vectors.
+func (maker *PasetoPublicMaker) sign(message []byte, footer []byte, implicit []byte) string {
+	signature := ed25519.Sign(maker.privateKey, pae([]byte(pasetoV4PublicHeader), message, footer, implicit))
+	body := append(append([]byte{}, message...), signature...)
+
+	token := pasetoV4PublicHeader + base64.RawURLEncoding.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	message, err := maker.verify(token, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	err = json.Unmarshal(message, payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// verify checks the signature of the token and implicit assertion and returns the signed message
+func (maker *PasetoPublicMaker) verify(token string, implicit []byte) ([]byte, error) {
+	if !strings.HasPrefix(token, pasetoV4PublicHeader) {
+		return nil, ErrInvalidToken
+	}
+
+	parts := strings.Split(token[len(pasetoV4PublicHeader):], ".")
+	if len(parts) > 2 {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+
+	var footer []byte
+	if len(parts) == 2 {
+		footer, err = base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	var keyIDs []string
+	var decoded pasetoFooter
+	if len(footer) > 0 && json.Unmarshal(footer, &decoded) == nil && decoded.KeyID != "" {
+		keyIDs = []string{decoded.KeyID}
+	} else {
+		keyIDs = maker.keyIDs()
+	}
+
+	signed := pae([]byte(pasetoV4PublicHeader), message, footer, implicit)
+	for _, id := range keyIDs {
+		publicKey, ok := maker.publicKeys[id]
+		if ok && ed25519.Verify(publicKey, signed, signature) {
+			return message, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+func (maker *PasetoPublicMaker) keyIDs() []string {
+	ids := make([]string, 0, len(maker.publicKeys))
+	for id := range maker.publicKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// PublicKeys returns every key tokens of the maker can be verified with
+func (maker *PasetoPublicMaker) PublicKeys() []JSONWebKey {
+	keys := make([]JSONWebKey, 0, len(maker.publicKeys))
+	for _, id := range maker.keyIDs() {
+		keys = append(keys, newJSONWebKey(id, "", maker.publicKeys[id]))
+	}
+	return keys
+}
+
+// pae is the pre-authentication encoding of PASETO: the number of pieces
+// followed by each piece prefixed with its length, all as 64 bit little endian integers
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	writeLength := func(n int) {
+		var b [8]byte
+		// the most significant bit is cleared for compatibility with languages without unsigned integers
+		binary.LittleEndian.PutUint64(b[:], uint64(n)&^(1<<63))
+		buf.Write(b[:])
+	}
+
+	writeLength(len(pieces))
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
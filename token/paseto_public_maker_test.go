@@ -0,0 +1,197 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker("k1", randomEd25519Key(t), nil)
+	require.NoError(t, err)
+
+	phone := util.RandomPhoneNumber()
+	userID := util.RandInt(1, 1000)
+	companyID := util.RandInt(1, 1000)
+	name := util.RandomString(6)
+	role := util.MemberRole
+	scopes := []string{ScopeBotsRead, ScopeAnalyticsRead}
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+	token, payload, err := maker.CreateToken(phone, userID, name, companyID, role, scopes, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, name, payload.Name)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, scopes, payload.Scopes)
+	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, companyID, payload.CompanyID)
+	require.Equal(t, phone, payload.Phone)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker("k1", randomEd25519Key(t), nil)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestTamperedPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker("k1", randomEd25519Key(t), nil)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	otherMaker, err := NewPasetoPublicMaker("k1", randomEd25519Key(t), nil)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	testCases := []struct {
+		name  string
+		token string
+		maker Maker
+	}{
+		{name: "OtherKey", token: token, maker: otherMaker},
+		{name: "ChangedFooter", token: strings.Join(parts[:3], ".") + ".e30", maker: maker},
+		{name: "ChangedBody", token: "v4.public.AAAA" + parts[2][4:] + "." + parts[3], maker: maker},
+		{name: "LocalToken", token: "v4.local." + parts[2], maker: maker},
+		{name: "Garbage", token: "v4.public.!!!", maker: maker},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := tc.maker.VerifyToken(tc.token)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestPasetoPublicMakerKeyRotation(t *testing.T) {
+	oldKey := randomEd25519Key(t)
+	oldMaker, err := NewPasetoPublicMaker("old", oldKey, nil)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	newMaker, err := NewPasetoPublicMaker("new", randomEd25519Key(t), map[string]crypto.PublicKey{
+		"old": oldKey.Public(),
+	})
+	require.NoError(t, err)
+
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	require.Len(t, newMaker.(PublicKeyProvider).PublicKeys(), 2)
+}
+
+func TestPasetoPublicVerifierOnly(t *testing.T) {
+	privateKey := randomEd25519Key(t)
+	signer, err := NewPasetoPublicMaker("k1", privateKey, nil)
+	require.NoError(t, err)
+
+	verifier, err := NewPasetoPublicMaker("", nil, map[string]crypto.PublicKey{"k1": privateKey.Public()})
+	require.NoError(t, err)
+
+	token, _, err := signer.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(token)
+	require.NoError(t, err)
+
+	_, _, err = verifier.CreateToken(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, nil, time.Minute)
+	require.ErrorIs(t, err, ErrSigningDisabled)
+}
+
+func TestPAE(t *testing.T) {
+	require.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00"), pae())
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pae([]byte("")))
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"), pae([]byte("test")))
+}
+
+// TestPasetoPublicTestVectors checks the maker against the official v4.public test vectors
+// of the PASETO specification, so that tokens verify with any conforming implementation
+func TestPasetoPublicTestVectors(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	require.Equal(t, ed25519.PublicKey(publicKey), ed25519.PrivateKey(secretKey).Public())
+
+	keyID := "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"
+	maker, err := NewPasetoPublicMaker(keyID, ed25519.PrivateKey(secretKey), nil)
+	require.NoError(t, err)
+
+	message := `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+	footer := `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+
+	testCases := []struct {
+		name     string
+		footer   string
+		implicit string
+		token    string
+	}{
+		{
+			name:  "4-S-1",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		},
+		{
+			name:   "4-S-2",
+			footer: footer,
+			token:  "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+		{
+			name:     "4-S-3",
+			footer:   footer,
+			implicit: `{"test-vector":"4-S-3"}`,
+			token:    "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			pasetoMaker := maker.(*PasetoPublicMaker)
+			token := pasetoMaker.sign([]byte(message), []byte(tc.footer), []byte(tc.implicit))
+			require.Equal(t, tc.token, token)
+
+			verified, err := pasetoMaker.verify(tc.token, []byte(tc.implicit))
+			require.NoError(t, err)
+			require.Equal(t, message, string(verified))
+
+			_, err = pasetoMaker.verify(tc.token, []byte(`{"test-vector":"other"}`))
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
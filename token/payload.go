@@ -20,12 +20,15 @@ type Payload struct {
 	Name      string    `json:"name"`
 	Phone     string    `json:"phone"`
 	CompanyID int64     `json:"ccompany_id"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	APIKeyID  int64     `json:"api_key_id,omitempty"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(phone string, userID int64, name string,companyID int64, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, scopes and duration
+func NewPayload(phone string, userID int64, name string, companyID int64, role string, scopes []string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -37,6 +40,8 @@ func NewPayload(phone string, userID int64, name string,companyID int64, duratio
 		Name:      name,
 		Phone:     phone,
 		CompanyID: companyID,
+		Role:      role,
+		Scopes:    scopes,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -50,3 +55,13 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// HasScope reports whether the payload grants the scope
+func (payload *Payload) HasScope(scope string) bool {
+	for _, granted := range payload.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lenimbugua/bot/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadHasScope(t *testing.T) {
+	payload, err := NewPayload(util.RandomPhoneNumber(), 1, util.RandomString(6), 1, util.MemberRole, []string{ScopeBotsRead}, time.Minute)
+	require.NoError(t, err)
+
+	require.True(t, payload.HasScope(ScopeBotsRead))
+	require.False(t, payload.HasScope(ScopeBotsWrite))
+
+	payload.Scopes = nil
+	require.False(t, payload.HasScope(ScopeBotsRead))
+}
@@ -0,0 +1,73 @@
+package token
+
+// Scopes limit what a principal may do
+const (
+	ScopeBotsRead           = "bots:read"
+	ScopeBotsWrite          = "bots:write"
+	ScopeChannelsRead       = "channels:read"
+	ScopeChannelsWrite      = "channels:write"
+	ScopeCompaniesRead      = "companies:read"
+	ScopeCompaniesWrite     = "companies:write"
+	ScopeQuestionsRead      = "questions:read"
+	ScopeQuestionsWrite     = "questions:write"
+	ScopeConversationsWrite = "conversations:write"
+	ScopeAnalyticsRead      = "analytics:read"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeAccountWrite       = "account:write"
+	ScopeAPIKeysRead        = "api_keys:read"
+	ScopeAPIKeysWrite       = "api_keys:write"
+	ScopeSSORead            = "sso:read"
+	ScopeSSOWrite           = "sso:write"
+	ScopeAuditRead          = "audit:read"
+)
+
+// ScopeMFAPending marks a token issued after the password but before the second factor
+// of a login. It only grants access to the endpoint completing the login and is never
+// part of AllScopes.
+const ScopeMFAPending = "mfa:pending"
+
+// AllScopes lists every scope that can be granted
+var AllScopes = []string{
+	ScopeBotsRead,
+	ScopeBotsWrite,
+	ScopeChannelsRead,
+	ScopeChannelsWrite,
+	ScopeCompaniesRead,
+	ScopeCompaniesWrite,
+	ScopeQuestionsRead,
+	ScopeQuestionsWrite,
+	ScopeConversationsWrite,
+	ScopeAnalyticsRead,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeAccountWrite,
+	ScopeAPIKeysRead,
+	ScopeAPIKeysWrite,
+	ScopeSSORead,
+	ScopeSSOWrite,
+	ScopeAuditRead,
+}
+
+// IsKnownScope reports whether the scope can be granted
+func IsKnownScope(scope string) bool {
+	for _, known := range AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadScopes lists the scopes that grant read only access
+var ReadScopes = []string{
+	ScopeBotsRead,
+	ScopeChannelsRead,
+	ScopeCompaniesRead,
+	ScopeQuestionsRead,
+	ScopeAnalyticsRead,
+	ScopeUsersRead,
+	ScopeAPIKeysRead,
+	ScopeSSORead,
+	ScopeAuditRead,
+}
@@ -14,10 +14,37 @@ type Config struct {
 	DBSource             string        `mapstructure:"DB_SOURCE"`
 	MigrationURL         string        `mapstructure:"MIGRATION_URL"`
 	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	TrustedProxies       []string      `mapstructure:"TRUSTED_PROXIES"`
 	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	MetricsServerAddress string        `mapstructure:"METRICS_SERVER_ADDRESS"`
+	TokenType            string        `mapstructure:"TOKEN_TYPE"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenSymmetricKeys   string        `mapstructure:"TOKEN_SYMMETRIC_KEYS"`
+	TokenActiveKeyID     string        `mapstructure:"TOKEN_ACTIVE_KEY_ID"`
+	TokenPrivateKeyFile  string        `mapstructure:"TOKEN_PRIVATE_KEY_FILE"`
+	TokenPublicKeyFiles  string        `mapstructure:"TOKEN_PUBLIC_KEY_FILES"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	OTPSender            string        `mapstructure:"OTP_SENDER"`
+	OTPLength            int           `mapstructure:"OTP_LENGTH"`
+	OTPDuration          time.Duration `mapstructure:"OTP_DURATION"`
+	OTPMaxAttempts       int32         `mapstructure:"OTP_MAX_ATTEMPTS"`
+	OTPResendCooldown    time.Duration `mapstructure:"OTP_RESEND_COOLDOWN"`
+	SMSGatewayURL        string        `mapstructure:"SMS_GATEWAY_URL"`
+	SMSAPIKey            string        `mapstructure:"SMS_API_KEY"`
+	SMSSenderID          string        `mapstructure:"SMS_SENDER_ID"`
+	SSORedirectURL       string        `mapstructure:"SSO_REDIRECT_URL"`
+	SSOStateDuration     time.Duration `mapstructure:"SSO_STATE_DURATION"`
+	SSOSecretKey         string        `mapstructure:"SSO_SECRET_KEY"`
+	DefaultPageSize      int32         `mapstructure:"DEFAULT_PAGE_SIZE"`
+	MaxPageSize          int32         `mapstructure:"MAX_PAGE_SIZE"`
+	SoftDeleteRetention  time.Duration `mapstructure:"SOFT_DELETE_RETENTION"`
+	PurgeInterval        time.Duration `mapstructure:"PURGE_INTERVAL"`
+	HTTPReadTimeout      time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout     time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout      time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownDelay        time.Duration `mapstructure:"SHUTDOWN_DELAY"`
+	ShutdownTimeout      time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
 }
 
 // LoadConfig reads the config variable from the file or the environment variable
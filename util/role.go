@@ -0,0 +1,16 @@
+package util
+
+// Roles a user can hold within their company
+const (
+	OwnerRole  = "owner"
+	AdminRole  = "admin"
+	MemberRole = "member"
+	ViewerRole = "viewer"
+)
+
+// OperatorRole is the role of the people running the platform. It is not a role
+// within a company: it is given in the database and never through the APIs.
+const OperatorRole = "operator"
+
+// APIKeyRole is the role of requests authenticated with a company API key
+const APIKeyRole = "api_key"
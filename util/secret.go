@@ -0,0 +1,78 @@
+package util
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// NewSecret returns a URL safe random string built from n bytes of crypto/rand
+func NewSecret(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashSecret returns the SHA-256 of a high entropy secret so that it can be stored and looked up
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SecretBoxKeySize is the length of the key of a SecretBox
+const SecretBoxKeySize = chacha20poly1305.KeySize
+
+// ErrSecretBoxKeySize refuses a SecretBox key that is not SecretBoxKeySize long
+var ErrSecretBoxKeySize = fmt.Errorf("secret key must be exactly %d characters", SecretBoxKeySize)
+
+// SecretBox encrypts the secrets that have to be stored yet used again in clear text,
+// such as the client secrets of single sign-on connections, with XChaCha20-Poly1305
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox creates a SecretBox with a key of SecretBoxKeySize characters
+func NewSecretBox(key string) (*SecretBox, error) {
+	if len(key) != SecretBoxKeySize {
+		return nil, ErrSecretBoxKeySize
+	}
+
+	aead, err := chacha20poly1305.NewX([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts the secret with a random nonce, which is kept in front of the ciphertext
+func (box *SecretBox) Seal(secret string) (string, error) {
+	nonce := make([]byte, box.aead.NonceSize(), box.aead.NonceSize()+len(secret)+box.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := box.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed by a SecretBox with the same key
+func (box *SecretBox) Open(sealed string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < box.aead.NonceSize() {
+		return "", errors.New("sealed secret is malformed")
+	}
+
+	secret, err := box.aead.Open(nil, data[:box.aead.NonceSize()], data[box.aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("sealed secret cannot be opened with this key")
+	}
+	return string(secret), nil
+}
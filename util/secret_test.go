@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	require := require.New(t)
+	secret1, err := NewSecret(32)
+	require.NoError(err)
+	require.Len(secret1, 43)
+
+	secret2, err := NewSecret(32)
+	require.NoError(err)
+	require.NotEqual(secret1, secret2)
+
+	require.Equal(HashSecret(secret1), HashSecret(secret1))
+	require.NotEqual(HashSecret(secret1), HashSecret(secret2))
+	require.Len(HashSecret(secret1), 64)
+}
+
+func TestSecretBox(t *testing.T) {
+	require := require.New(t)
+	box, err := NewSecretBox(RandomString(SecretBoxKeySize))
+	require.NoError(err)
+
+	secret := RandomString(32)
+	sealed1, err := box.Seal(secret)
+	require.NoError(err)
+	require.NotContains(sealed1, secret)
+
+	sealed2, err := box.Seal(secret)
+	require.NoError(err)
+	require.NotEqual(sealed1, sealed2)
+
+	opened, err := box.Open(sealed1)
+	require.NoError(err)
+	require.Equal(secret, opened)
+
+	otherBox, err := NewSecretBox(RandomString(SecretBoxKeySize))
+	require.NoError(err)
+	_, err = otherBox.Open(sealed1)
+	require.Error(err)
+
+	_, err = box.Open(secret)
+	require.Error(err)
+
+	_, err = NewSecretBox(RandomString(SecretBoxKeySize - 1))
+	require.ErrorIs(err, ErrSecretBoxKeySize)
+}
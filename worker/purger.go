@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/lenimbugua/bot/db/sqlc"
+)
+
+// Retention and interval used when SOFT_DELETE_RETENTION and PURGE_INTERVAL are not set
+const (
+	defaultRetention = 30 * 24 * time.Hour
+	defaultInterval  = time.Hour
+)
+
+// Purger permanently deletes the companies, bots and questions that were soft deleted
+// longer ago than the retention period. Until then they can be restored.
+type Purger struct {
+	store     db.Store
+	retention time.Duration
+	interval  time.Duration
+	stop      chan struct{}
+	stopOnce  sync.Once
+	running   atomic.Bool
+}
+
+// NewPurger creates a purger checking for expired rows every interval
+func NewPurger(store db.Store, retention time.Duration, interval time.Duration) *Purger {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Purger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Run purges the expired rows right away and then every interval until Stop is called
+// or ctx is done. Cancelling ctx abandons a purge in progress, which is rolled back.
+func (purger *Purger) Run(ctx context.Context) {
+	purger.running.Store(true)
+	defer purger.running.Store(false)
+
+	ticker := time.NewTicker(purger.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := purger.Purge(ctx, time.Now())
+		if err != nil {
+			log.Printf("cannot purge deleted rows: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted rows", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-purger.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check returns an error unless Run is running
+func (purger *Purger) Check(ctx context.Context) error {
+	if !purger.running.Load() {
+		return errors.New("purger is not running")
+	}
+	return nil
+}
+
+// Stop makes Run return once the purge in progress, if any, completes
+func (purger *Purger) Stop() {
+	purger.stopOnce.Do(func() { close(purger.stop) })
+}
+
+// Purge deletes the rows soft deleted before now minus the retention period and returns how many there were.
+// Companies go first since deleting them also deletes their bots and questions.
+func (purger *Purger) Purge(ctx context.Context, now time.Time) (int64, error) {
+	before := now.Add(-purger.retention)
+
+	companies, err := purger.store.PurgeDeletedCompanies(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	bots, err := purger.store.PurgeDeletedBots(ctx, before)
+	if err != nil {
+		return companies, err
+	}
+
+	questions, err := purger.store.PurgeDeletedQuestions(ctx, before)
+	if err != nil {
+		return companies + bots, err
+	}
+
+	return companies + bots + questions, nil
+}
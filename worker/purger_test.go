@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/lenimbugua/bot/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	retention := 30 * 24 * time.Hour
+	before := now.Add(-retention)
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().PurgeDeletedCompanies(gomock.Any(), gomock.Eq(before)).Times(1).Return(int64(1), nil),
+		store.EXPECT().PurgeDeletedBots(gomock.Any(), gomock.Eq(before)).Times(1).Return(int64(2), nil),
+		store.EXPECT().PurgeDeletedQuestions(gomock.Any(), gomock.Eq(before)).Times(1).Return(int64(3), nil),
+	)
+
+	purger := NewPurger(store, retention, time.Hour)
+	purged, err := purger.Purge(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), purged)
+}
+
+func TestPurgeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().PurgeDeletedCompanies(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+	store.EXPECT().PurgeDeletedBots(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+	store.EXPECT().PurgeDeletedQuestions(gomock.Any(), gomock.Any()).Times(0)
+
+	purger := NewPurger(store, time.Hour, time.Hour)
+	purged, err := purger.Purge(context.Background(), time.Now())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Equal(t, int64(1), purged)
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().PurgeDeletedCompanies(gomock.Any(), gomock.Any()).MinTimes(1).Return(int64(0), nil)
+	store.EXPECT().PurgeDeletedBots(gomock.Any(), gomock.Any()).MinTimes(1).Return(int64(0), nil)
+	store.EXPECT().PurgeDeletedQuestions(gomock.Any(), gomock.Any()).MinTimes(1).Return(int64(0), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewPurger(store, time.Hour, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop")
+	}
+}
+
+func TestStopFinishesPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		PurgeDeletedCompanies(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, before time.Time) (int64, error) {
+			close(started)
+			<-release
+			return 0, nil
+		})
+	store.EXPECT().PurgeDeletedBots(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().PurgeDeletedQuestions(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+
+	purger := NewPurger(store, time.Hour, time.Hour)
+	require.Error(t, purger.Check(context.Background()))
+
+	done := make(chan struct{})
+	go func() {
+		purger.Run(context.Background())
+		close(done)
+	}()
+
+	<-started
+	require.NoError(t, purger.Check(context.Background()))
+	purger.Stop()
+	purger.Stop()
+
+	select {
+	case <-done:
+		t.Fatal("purger stopped before its purge completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop")
+	}
+	require.Error(t, purger.Check(context.Background()))
+}